@@ -109,6 +109,14 @@ func main() {
 		}
 		rep, err = client.GetUser(ctx, &req)
 
+	case "GetUsers":
+		client := account.NewServiceClient(tp)
+		var req account.GetUsersRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.GetUsers(ctx, &req)
+
 	case "DeleteUser":
 		client := account.NewServiceClient(tp)
 		var req account.DeleteUserRequest
@@ -117,6 +125,110 @@ func main() {
 		}
 		rep, err = client.DeleteUser(ctx, &req)
 
+	case "LinkIdentity":
+		client := account.NewServiceClient(tp)
+		var req account.LinkIdentityRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.LinkIdentity(ctx, &req)
+
+	case "UnlinkIdentity":
+		client := account.NewServiceClient(tp)
+		var req account.UnlinkIdentityRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.UnlinkIdentity(ctx, &req)
+
+	case "ListIdentities":
+		client := account.NewServiceClient(tp)
+		var req account.ListIdentitiesRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.ListIdentities(ctx, &req)
+
+	case "UpdateUser":
+		client := account.NewServiceClient(tp)
+		var req account.UpdateUserRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.UpdateUser(ctx, &req)
+
+	case "RequestEmailChange":
+		client := account.NewServiceClient(tp)
+		var req account.RequestEmailChangeRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.RequestEmailChange(ctx, &req)
+
+	case "ConfirmEmailChange":
+		client := account.NewServiceClient(tp)
+		var req account.ConfirmEmailChangeRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.ConfirmEmailChange(ctx, &req)
+
+	case "VerifyOrcid":
+		client := account.NewServiceClient(tp)
+		var req account.VerifyOrcidRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.VerifyOrcid(ctx, &req)
+
+	case "CreateToken":
+		client := account.NewServiceClient(tp)
+		var req account.CreateTokenRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.CreateToken(ctx, &req)
+
+	case "ListTokens":
+		client := account.NewServiceClient(tp)
+		var req account.ListTokensRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.ListTokens(ctx, &req)
+
+	case "RevokeToken":
+		client := account.NewServiceClient(tp)
+		var req account.RevokeTokenRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.RevokeToken(ctx, &req)
+
+	case "VerifyToken":
+		client := account.NewServiceClient(tp)
+		var req account.VerifyTokenRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.VerifyToken(ctx, &req)
+
+	case "CreateSession":
+		client := account.NewServiceClient(tp)
+		var req account.CreateSessionRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.CreateSession(ctx, &req)
+
+	case "RotateSession":
+		client := account.NewServiceClient(tp)
+		var req account.RotateSessionRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.RotateSession(ctx, &req)
+
 	default:
 		log.Fatalf("unknown method %s", meth)
 	}
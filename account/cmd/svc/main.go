@@ -77,7 +77,7 @@ func main() {
 	db := session.DB("")
 
 	// Initialize the service.
-	svc, err := account.NewService(db)
+	svc, err := account.NewService(tp, db)
 	if err != nil {
 		log.Fatal(err)
 	}
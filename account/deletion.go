@@ -0,0 +1,111 @@
+package account
+
+import (
+	"log"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/golang/protobuf/proto"
+	"github.com/rdm-academy/api/commitlog"
+)
+
+const (
+	// How often deleted users are checked for missing acknowledgements.
+	redriveInterval = time.Hour
+)
+
+// newAckHandler initializes a handler that records which services removed
+// the data of deleted users.
+func newAckHandler(s *service) transport.Handler {
+	return func(msg *transport.Message) (proto.Message, error) {
+		var e commitlog.Event
+		if err := msg.Decode(&e); err != nil {
+			return nil, err
+		}
+
+		svc := commitlog.AckService(&e)
+		if e.Type != "account.deleted" || e.Author == "" || svc == "" {
+			return nil, nil
+		}
+
+		return nil, s.ack(e.Author, svc)
+	}
+}
+
+// ack records that the service removed the data of the deleted user. The
+// user is removed once all services did.
+func (s *service) ack(id, svc string) error {
+	q := bson.M{
+		"_id": id,
+		"deleting": bson.M{
+			"$exists": true,
+		},
+	}
+
+	u := bson.M{
+		"$addToSet": bson.M{
+			"acks": svc,
+		},
+	}
+
+	if err := s.db.C(usersCol).Update(q, u); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil
+		}
+
+		return err
+	}
+
+	q["acks"] = bson.M{
+		"$all": commitlog.AccountDeletedAcks,
+	}
+
+	if err := s.db.C(usersCol).Remove(q); err != nil && err != mgo.ErrNotFound {
+		return err
+	}
+
+	return nil
+}
+
+// redrive publishes the account.deleted event again for users deleted
+// before the last check that not all services acknowledged yet.
+func (s *service) redrive() error {
+	q := bson.M{
+		"deleting": bson.M{
+			"$lt": time.Now().Add(-redriveInterval),
+		},
+	}
+
+	var docs []*User
+	if err := s.db.C(usersCol).Find(q).Select(bson.M{"acks": 1}).All(&docs); err != nil {
+		return err
+	}
+
+	for _, u := range docs {
+		log.Printf("account: user %s not acknowledged as deleted by all services (%v)", u.ID, u.Acks)
+
+		s.logEvent("events.account", &logEvent{
+			Type:   "account.deleted",
+			Author: u.ID,
+		})
+	}
+
+	return nil
+}
+
+// runRedrive periodically redrives the deletion of users.
+func (s *service) runRedrive() {
+	t := time.NewTicker(redriveInterval)
+	defer t.Stop()
+
+	for {
+		if err := s.redrive(); err != nil {
+			log.Printf("account: redrive error: %s", err)
+		}
+
+		<-t.C
+	}
+}
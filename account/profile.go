@@ -0,0 +1,322 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// How long an email change can be confirmed.
+	emailChangeTTL = 24 * time.Hour
+)
+
+var orcidRe = regexp.MustCompile(`^\d{4}-\d{4}-\d{4}-\d{3}[\dX]$`)
+
+// emailChange is a pending change of the email address. Only the hash of
+// the token is stored.
+type emailChange struct {
+	Email   string    `bson:"email"`
+	Token   string    `bson:"token"`
+	Expires time.Time `bson:"expires"`
+}
+
+func userResponse(u *User) *GetUserResponse {
+	rep := &GetUserResponse{
+		Id:            u.ID,
+		Email:         u.Email,
+		Name:          u.Name,
+		Affiliation:   u.Affiliation,
+		Orcid:         u.Orcid,
+		AvatarUrl:     u.AvatarURL,
+		OrcidVerified: !u.OrcidVerified.IsZero(),
+		EmailVerified: !u.EmailVerified.IsZero(),
+		Created:       u.Created.Unix(),
+		Modified:      u.Modified.Unix(),
+	}
+
+	if u.EmailChange != nil && time.Now().Before(u.EmailChange.Expires) {
+		rep.PendingEmail = u.EmailChange.Email
+	}
+
+	return rep
+}
+
+// validOrcid checks the format and the ISO 7064 11,2 check digit of an
+// ORCID iD.
+func validOrcid(id string) bool {
+	if !orcidRe.MatchString(id) {
+		return false
+	}
+
+	digits := strings.Replace(id, "-", "", -1)
+
+	var total int
+	for _, c := range digits[:15] {
+		total = (total + int(c-'0')) * 2
+	}
+
+	check := (12 - total%11) % 11
+
+	if check == 10 {
+		return digits[15] == 'X'
+	}
+
+	return int(digits[15]-'0') == check
+}
+
+func hashToken(t string) string {
+	h := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(h[:])
+}
+
+func (s *service) UpdateUser(ctx context.Context, req *UpdateUserRequest) (*UpdateUserResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name required")
+	}
+
+	if req.Orcid != "" && !validOrcid(req.Orcid) {
+		return nil, status.Error(codes.InvalidArgument, "invalid ORCID iD")
+	}
+
+	if req.AvatarUrl != "" {
+		u, err := url.Parse(req.AvatarUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, status.Error(codes.InvalidArgument, "avatar url must be an http or https url")
+		}
+	}
+
+	var cur User
+	if err := s.db.C(usersCol).Find(userQuery(req.Id)).Select(bson.M{"orcid": 1}).One(&cur); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+
+		return nil, err
+	}
+
+	u := bson.M{
+		"$set": bson.M{
+			"name":        req.Name,
+			"affiliation": req.Affiliation,
+			"orcid":       req.Orcid,
+			"avatar_url":  req.AvatarUrl,
+			"modified":    time.Now(),
+		},
+	}
+
+	// Only the verified iD can be kept.
+	if req.Orcid != cur.Orcid {
+		u["$unset"] = bson.M{
+			"orcid_verified": "",
+			"verified_orcid": "",
+		}
+	}
+
+	change := mgo.Change{
+		Update:    u,
+		ReturnNew: true,
+	}
+
+	var user User
+	if _, err := s.db.C(usersCol).Find(userQuery(req.Id)).Apply(change, &user); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+
+		return nil, err
+	}
+
+	s.logEvent("events.account", &logEvent{
+		Type:   "account.updated",
+		Author: req.Id,
+	})
+
+	return &UpdateUserResponse{
+		User: userResponse(&user),
+	}, nil
+}
+
+// RequestEmailChange starts a change of the email address. The change takes
+// effect once the returned token is confirmed. A new request replaces a
+// pending one.
+func (s *service) RequestEmailChange(ctx context.Context, req *RequestEmailChangeRequest) (*RequestEmailChangeResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if a, err := mail.ParseAddress(email); err != nil || a.Address != email {
+		return nil, status.Error(codes.InvalidArgument, "valid email required")
+	}
+
+	n, err := s.db.C(usersCol).Find(bson.M{"email": email}).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	if n > 0 {
+		return nil, status.Error(codes.AlreadyExists, "email already in use")
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	expires := time.Now().Add(emailChangeTTL)
+
+	err = s.db.C(usersCol).Update(userQuery(req.Id), bson.M{
+		"$set": bson.M{
+			"email_change": &emailChange{
+				Email:   email,
+				Token:   hashToken(token),
+				Expires: expires,
+			},
+		},
+	})
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+
+		return nil, err
+	}
+
+	return &RequestEmailChangeResponse{
+		Token:   token,
+		Expires: expires.Unix(),
+	}, nil
+}
+
+// ConfirmEmailChange applies the pending email change if the token matches
+// and has not expired. The token can only be used once.
+func (s *service) ConfirmEmailChange(ctx context.Context, req *ConfirmEmailChangeRequest) (*ConfirmEmailChangeResponse, error) {
+	if req.Id == "" || req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id and token required")
+	}
+
+	var user User
+	if err := s.db.C(usersCol).Find(userQuery(req.Id)).Select(bson.M{"email_change": 1}).One(&user); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+
+		return nil, err
+	}
+
+	c := user.EmailChange
+	if c == nil || c.Token != hashToken(req.Token) {
+		return nil, status.Error(codes.InvalidArgument, "invalid token")
+	}
+
+	now := time.Now()
+
+	if now.After(c.Expires) {
+		return nil, status.Error(codes.InvalidArgument, "token expired")
+	}
+
+	// The token condition makes the change apply once.
+	q := bson.M{
+		"_id":                req.Id,
+		"email_change.token": c.Token,
+	}
+
+	err := s.db.C(usersCol).Update(q, bson.M{
+		"$set": bson.M{
+			"email":          c.Email,
+			"email_verified": now,
+			"modified":       now,
+		},
+		"$unset": bson.M{
+			"email_change": "",
+		},
+	})
+	if err != nil {
+		// Taken since the change was requested.
+		if mgo.IsDup(err) {
+			return nil, status.Error(codes.AlreadyExists, "email already in use")
+		}
+
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.InvalidArgument, "invalid token")
+		}
+
+		return nil, err
+	}
+
+	s.logEvent("events.account", &logEvent{
+		Type:   "account.updated",
+		Author: req.Id,
+	})
+
+	return &ConfirmEmailChangeResponse{
+		Email: c.Email,
+	}, nil
+}
+
+func (s *service) VerifyOrcid(ctx context.Context, req *VerifyOrcidRequest) (*VerifyOrcidResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+
+	if !validOrcid(req.Orcid) {
+		return nil, status.Error(codes.InvalidArgument, "invalid ORCID iD")
+	}
+
+	now := time.Now()
+
+	change := mgo.Change{
+		Update: bson.M{
+			"$set": bson.M{
+				"orcid":          req.Orcid,
+				"orcid_verified": now,
+				"verified_orcid": req.Orcid,
+				"modified":       now,
+			},
+		},
+		ReturnNew: true,
+	}
+
+	var user User
+	if _, err := s.db.C(usersCol).Find(userQuery(req.Id)).Apply(change, &user); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+
+		// A verified iD belongs to one user.
+		if mgo.IsDup(err) {
+			return nil, status.Error(codes.AlreadyExists, "ORCID iD verified by another user")
+		}
+
+		return nil, err
+	}
+
+	s.logEvent("events.account", &logEvent{
+		Type:   "account.updated",
+		Author: req.Id,
+	})
+
+	return &VerifyOrcidResponse{
+		User: userResponse(&user),
+	}, nil
+}
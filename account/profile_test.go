@@ -0,0 +1,204 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestValidOrcid(t *testing.T) {
+	for _, x := range []struct {
+		id    string
+		valid bool
+	}{
+		{"0000-0002-1825-0097", true},
+		{"0000-0001-5109-3700", true},
+		// Check digit of 10.
+		{"0000-0002-1694-233X", true},
+		{"0000-0002-1825-0098", false},
+		{"0000-0002-1694-2330", false},
+		{"0000-0002-1694-233x", false},
+		{"0000-0002-1825-009X", false},
+		{"0000000218250097", false},
+		{"https://orcid.org/0000-0002-1825-0097", false},
+		{"0000-0002-1825-009", false},
+		{"", false},
+	} {
+		if ok := validOrcid(x.id); ok != x.valid {
+			t.Errorf("%q: expected %t, got %t", x.id, x.valid, ok)
+		}
+	}
+}
+
+func TestUserResponse(t *testing.T) {
+	now := time.Now()
+
+	u := &User{
+		ID:            "u1",
+		Email:         "a@example.com",
+		EmailVerified: now,
+		EmailChange: &emailChange{
+			Email:   "b@example.com",
+			Expires: now.Add(time.Hour),
+		},
+	}
+
+	rep := userResponse(u)
+	if !rep.EmailVerified || rep.OrcidVerified {
+		t.Errorf("expected only the email to be verified, got %v", rep)
+	}
+	if rep.PendingEmail != "b@example.com" {
+		t.Errorf("expected the pending email, got %q", rep.PendingEmail)
+	}
+
+	// Expired changes are not pending.
+	u.EmailChange.Expires = now.Add(-time.Second)
+	if rep := userResponse(u); rep.PendingEmail != "" {
+		t.Errorf("expected no pending email, got %q", rep.PendingEmail)
+	}
+}
+
+func TestEmailChange(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, &CreateUserRequest{Issuer: testIssuer, Subject: "1", Email: "joe@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.CreateUser(ctx, &CreateUserRequest{Issuer: testIssuer, Subject: "2", Email: "ann@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, x := range []struct {
+		name  string
+		email string
+		code  codes.Code
+	}{
+		{"invalid", "joe", codes.InvalidArgument},
+		{"display name", "Joe <joe@example.org>", codes.InvalidArgument},
+		{"in use", "Ann@example.com", codes.AlreadyExists},
+	} {
+		_, err := s.RequestEmailChange(ctx, &RequestEmailChangeRequest{Id: user.Id, Email: x.email})
+		if code := status.Code(err); code != x.code {
+			t.Errorf("%s: expected %s, got %s", x.name, x.code, code)
+		}
+	}
+
+	request := func(email string) string {
+		rep, err := s.RequestEmailChange(ctx, &RequestEmailChangeRequest{Id: user.Id, Email: email})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rep.Token
+	}
+
+	// A new request replaces the pending one.
+	old := request("joe@example.net")
+	token := request("Joe@Example.org")
+
+	rep, err := s.GetUser(ctx, &GetUserRequest{Id: user.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.PendingEmail != "joe@example.org" || rep.Email != "joe@example.com" {
+		t.Errorf("expected pending change, got %s and %s", rep.Email, rep.PendingEmail)
+	}
+
+	// Only the hash of the token is stored.
+	var u User
+	if err := s.db.C(usersCol).FindId(user.Id).One(&u); err != nil {
+		t.Fatal(err)
+	}
+	if u.EmailChange.Token != hashToken(token) {
+		t.Error("expected the hash of the token to be stored")
+	}
+
+	for _, x := range []struct {
+		name string
+		req  *ConfirmEmailChangeRequest
+		code codes.Code
+	}{
+		{"no token", &ConfirmEmailChangeRequest{Id: user.Id}, codes.InvalidArgument},
+		{"not found", &ConfirmEmailChangeRequest{Id: "missing", Token: token}, codes.NotFound},
+		{"replaced token", &ConfirmEmailChangeRequest{Id: user.Id, Token: old}, codes.InvalidArgument},
+		{"wrong token", &ConfirmEmailChangeRequest{Id: user.Id, Token: token + "x"}, codes.InvalidArgument},
+	} {
+		_, err := s.ConfirmEmailChange(ctx, x.req)
+		if code := status.Code(err); code != x.code {
+			t.Errorf("%s: expected %s, got %s", x.name, x.code, code)
+		}
+	}
+
+	crep, err := s.ConfirmEmailChange(ctx, &ConfirmEmailChangeRequest{Id: user.Id, Token: token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crep.Email != "joe@example.org" {
+		t.Errorf("expected joe@example.org, got %s", crep.Email)
+	}
+
+	rep, err = s.GetUser(ctx, &GetUserRequest{Id: user.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Email != "joe@example.org" || !rep.EmailVerified || rep.PendingEmail != "" {
+		t.Errorf("expected verified email change, got %v", rep)
+	}
+
+	// The token can only be used once.
+	if _, err := s.ConfirmEmailChange(ctx, &ConfirmEmailChangeRequest{Id: user.Id, Token: token}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("used: expected InvalidArgument, got %v", err)
+	}
+
+	// Expired tokens are rejected.
+	token = request("joe@example.net")
+
+	err = s.db.C(usersCol).UpdateId(user.Id, bson.M{
+		"$set": bson.M{
+			"email_change.expires": time.Now().Add(-time.Second),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ConfirmEmailChange(ctx, &ConfirmEmailChangeRequest{Id: user.Id, Token: token}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expired: expected InvalidArgument, got %v", err)
+	}
+
+	rep, err = s.GetUser(ctx, &GetUserRequest{Id: user.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Email != "joe@example.org" || rep.PendingEmail != "" {
+		t.Errorf("expected expired change to be ignored, got %s and %s", rep.Email, rep.PendingEmail)
+	}
+
+	// The address was taken since the change was requested.
+	token = request("sam@example.com")
+
+	if _, err := s.CreateUser(ctx, &CreateUserRequest{Issuer: testIssuer, Subject: "3", Email: "sam@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ConfirmEmailChange(ctx, &ConfirmEmailChangeRequest{Id: user.Id, Token: token}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("taken: expected AlreadyExists, got %v", err)
+	}
+
+	rep, err = s.GetUser(ctx, &GetUserRequest{Id: user.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Email != "joe@example.org" {
+		t.Errorf("expected email to be unchanged, got %s", rep.Email)
+	}
+}
@@ -2,6 +2,8 @@ package account
 
 import (
 	"context"
+	"encoding/json"
+	"log"
 	"strings"
 	"time"
 
@@ -11,14 +13,25 @@ import (
 	mgo "gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/rdm-academy/api/commitlog"
 	uuid "github.com/satori/go.uuid"
 )
 
 const (
 	usersCol = "users"
+
+	// Maximum number of ids of a GetUsers request.
+	maxGetUsers = 1000
 )
 
-type Identity struct {
+type logEvent struct {
+	Type   string
+	Author string
+	Data   interface{}
+}
+
+type identity struct {
 	Subject string    `bson:"subject"`
 	Email   string    `bson:"email"`
 	Name    string    `bson:"name"`
@@ -27,16 +40,56 @@ type Identity struct {
 }
 
 type User struct {
-	ID         string      `bson:"_id"`
-	Email      string      `bson:"email"`
-	Name       string      `bson:"name"`
-	Identities []*Identity `bson:"identities"`
-	Created    time.Time   `bson:"created"`
-	Modified   time.Time   `bson:"modified"`
+	ID            string       `bson:"_id"`
+	Email         string       `bson:"email"`
+	EmailVerified time.Time    `bson:"email_verified,omitempty"`
+	Name          string       `bson:"name"`
+	Affiliation   string       `bson:"affiliation,omitempty"`
+	Orcid         string       `bson:"orcid,omitempty"`
+	OrcidVerified time.Time    `bson:"orcid_verified,omitempty"`
+	VerifiedOrcid string       `bson:"verified_orcid,omitempty"`
+	AvatarURL     string       `bson:"avatar_url,omitempty"`
+	Identities    []*identity  `bson:"identities"`
+	EmailChange   *emailChange `bson:"email_change,omitempty"`
+	Created       time.Time    `bson:"created"`
+	Modified      time.Time    `bson:"modified"`
+
+	// Set once the user is deleted. The user is kept until all services
+	// acknowledged the account.deleted event.
+	Deleting time.Time `bson:"deleting,omitempty"`
+	Acks     []string  `bson:"acks,omitempty"`
 }
 
 type service struct {
 	db *mgo.Database
+	tp transport.Transport
+}
+
+// userQuery matches the user unless it is being deleted.
+func userQuery(id string) bson.M {
+	return bson.M{
+		"_id": id,
+		"deleting": bson.M{
+			"$exists": false,
+		},
+	}
+}
+
+func (s *service) logEvent(t string, e *logEvent) {
+	b, err := json.Marshal(e.Data)
+	if err != nil {
+		log.Printf("eventlog: data encoding error: %s", err)
+	}
+
+	_, err = s.tp.Publish(t, &commitlog.Event{
+		Time:   time.Now().Unix(),
+		Type:   e.Type,
+		Author: e.Author,
+		Data:   b,
+	})
+	if err != nil {
+		log.Printf("eventlog: publish error: %s", err)
+	}
 }
 
 func (s *service) CreateUser(ctx context.Context, req *CreateUserRequest) (*CreateUserResponse, error) {
@@ -55,8 +108,8 @@ func (s *service) CreateUser(ctx context.Context, req *CreateUserRequest) (*Crea
 		Name:     req.Name,
 		Created:  now,
 		Modified: now,
-		Identities: []*Identity{
-			&Identity{
+		Identities: []*identity{
+			&identity{
 				Subject: req.Subject,
 				Email:   email,
 				Name:    req.Name,
@@ -66,6 +119,10 @@ func (s *service) CreateUser(ctx context.Context, req *CreateUserRequest) (*Crea
 		},
 	}
 
+	if req.EmailVerified {
+		user.EmailVerified = now
+	}
+
 	if err := s.db.C(usersCol).Insert(&user); err != nil {
 		// Already exists by ID or email.
 		if mgo.IsDup(err) {
@@ -89,15 +146,22 @@ func (s *service) GetUser(ctx context.Context, req *GetUserRequest) (*GetUserRes
 	var query bson.M
 
 	if req.Id != "" {
-		query = bson.M{
-			"_id": req.Id,
-		}
+		query = userQuery(req.Id)
 	} else if req.Email != "" {
 		query = bson.M{
 			"email": strings.ToLower(req.Email),
 		}
+	} else if req.Issuer != "" && req.Subject != "" {
+		query = bson.M{
+			"identities": bson.M{
+				"$elemMatch": bson.M{
+					"issuer":  req.Issuer,
+					"subject": req.Subject,
+				},
+			},
+		}
 	} else {
-		return nil, status.Error(codes.InvalidArgument, "id or email must be specified")
+		return nil, status.Error(codes.InvalidArgument, "id, email or issuer and subject must be specified")
 	}
 
 	var user User
@@ -109,19 +173,68 @@ func (s *service) GetUser(ctx context.Context, req *GetUserRequest) (*GetUserRes
 		return nil, err
 	}
 
-	return &GetUserResponse{
-		Id:    user.ID,
-		Email: user.Email,
-		Name:  user.Name,
+	return userResponse(&user), nil
+}
+
+// GetUsers returns the users of the ids in one query.
+func (s *service) GetUsers(ctx context.Context, req *GetUsersRequest) (*GetUsersResponse, error) {
+	if len(req.Ids) > maxGetUsers {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d ids can be requested", maxGetUsers)
+	}
+
+	if len(req.Ids) == 0 {
+		return &GetUsersResponse{}, nil
+	}
+
+	q := bson.M{
+		"_id": bson.M{
+			"$in": req.Ids,
+		},
+		"deleting": bson.M{
+			"$exists": false,
+		},
+	}
+
+	var docs []*User
+	if err := s.db.C(usersCol).Find(q).All(&docs); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*User, len(docs))
+	for _, u := range docs {
+		byID[u.ID] = u
+	}
+
+	users := make([]*GetUserResponse, 0, len(docs))
+	for _, id := range req.Ids {
+		if u, ok := byID[id]; ok {
+			users = append(users, userResponse(u))
+			// Duplicate ids are returned once.
+			delete(byID, id)
+		}
+	}
+
+	return &GetUsersResponse{
+		Users: users,
 	}, nil
 }
 
+// DeleteUser erases the user immediately but keeps the record until all
+// services removed the data of the user.
 func (s *service) DeleteUser(ctx context.Context, req *DeleteUserRequest) (*DeleteUserResponse, error) {
 	if req.Id == "" {
 		return nil, status.Error(codes.InvalidArgument, "user id required")
 	}
 
-	if err := s.db.C(usersCol).RemoveId(req.Id); err != nil {
+	// Only what is needed to redrive the deletion is kept. The email is
+	// unique so it is replaced rather than cleared.
+	tomb := bson.M{
+		"email":      "#deleted:" + req.Id,
+		"identities": []*identity{},
+		"deleting":   time.Now(),
+	}
+
+	if err := s.db.C(usersCol).Update(userQuery(req.Id), tomb); err != nil {
 		if err == mgo.ErrNotFound {
 			return nil, status.Error(codes.NotFound, "user not found")
 		}
@@ -129,19 +242,337 @@ func (s *service) DeleteUser(ctx context.Context, req *DeleteUserRequest) (*Dele
 		return nil, err
 	}
 
+	if _, err := s.db.C(tokensCol).RemoveAll(bson.M{"account": req.Id}); err != nil {
+		return nil, err
+	}
+
+	if err := s.removeSessions(bson.M{"account": req.Id}); err != nil {
+		return nil, err
+	}
+
+	// The other services delete the projects, files and references of
+	// the user in response.
+	s.logEvent("events.account", &logEvent{
+		Type:   "account.deleted",
+		Author: req.Id,
+	})
+
 	return &DeleteUserResponse{}, nil
 }
 
-func NewService(db *mgo.Database) (Service, error) {
+func (s *service) LinkIdentity(ctx context.Context, req *LinkIdentityRequest) (*LinkIdentityResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+
+	if req.Issuer == "" || req.Subject == "" {
+		return nil, status.Error(codes.InvalidArgument, "issuer and subject required")
+	}
+
+	// Linked to this user already.
+	n, err := s.db.C(usersCol).Find(bson.M{
+		"_id": req.Id,
+		"identities": bson.M{
+			"$elemMatch": bson.M{
+				"issuer":  req.Issuer,
+				"subject": req.Subject,
+			},
+		},
+	}).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	if n > 0 {
+		return &LinkIdentityResponse{}, nil
+	}
+
+	now := time.Now()
+
+	err = s.db.C(usersCol).Update(userQuery(req.Id), bson.M{
+		"$push": bson.M{
+			"identities": &identity{
+				Subject: req.Subject,
+				Email:   strings.ToLower(req.Email),
+				Name:    req.Name,
+				Issuer:  req.Issuer,
+				Created: now,
+			},
+		},
+		"$set": bson.M{
+			"modified": now,
+		},
+	})
+	if err != nil {
+		// Linked to another user.
+		if mgo.IsDup(err) {
+			return nil, status.Error(codes.AlreadyExists, "identity linked to another user")
+		}
+
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+
+		return nil, err
+	}
+
+	s.logEvent("events.account", &logEvent{
+		Type:   "account.updated",
+		Author: req.Id,
+	})
+
+	return &LinkIdentityResponse{}, nil
+}
+
+func (s *service) UnlinkIdentity(ctx context.Context, req *UnlinkIdentityRequest) (*UnlinkIdentityResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+
+	if req.Issuer == "" || req.Subject == "" {
+		return nil, status.Error(codes.InvalidArgument, "issuer and subject required")
+	}
+
+	var user User
+	if err := s.db.C(usersCol).Find(userQuery(req.Id)).Select(bson.M{"identities": 1}).One(&user); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+
+		return nil, err
+	}
+
+	var found bool
+	for _, i := range user.Identities {
+		if i.Issuer == req.Issuer && i.Subject == req.Subject {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return nil, status.Error(codes.NotFound, "identity not found")
+	}
+
+	// The user would not be able to sign in anymore.
+	if len(user.Identities) == 1 {
+		return nil, status.Error(codes.FailedPrecondition, "cannot unlink the last identity")
+	}
+
+	// The size condition guards against a concurrent unlink of the
+	// other identities.
+	q := bson.M{
+		"_id":        req.Id,
+		"identities": bson.M{"$size": len(user.Identities)},
+	}
+
+	err := s.db.C(usersCol).Update(q, bson.M{
+		"$pull": bson.M{
+			"identities": bson.M{
+				"issuer":  req.Issuer,
+				"subject": req.Subject,
+			},
+		},
+		"$set": bson.M{
+			"modified": time.Now(),
+		},
+	})
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.Aborted, "identities changed concurrently")
+		}
+
+		return nil, err
+	}
+
+	// Refresh tokens of sign ins with the identity are revoked.
+	err = s.removeSessions(bson.M{
+		"account": req.Id,
+		"issuer":  req.Issuer,
+		"subject": req.Subject,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logEvent("events.account", &logEvent{
+		Type:   "account.updated",
+		Author: req.Id,
+	})
+
+	return &UnlinkIdentityResponse{}, nil
+}
+
+func (s *service) ListIdentities(ctx context.Context, req *ListIdentitiesRequest) (*ListIdentitiesResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+
+	var user User
+	if err := s.db.C(usersCol).Find(userQuery(req.Id)).Select(bson.M{"identities": 1}).One(&user); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+
+		return nil, err
+	}
+
+	list := make([]*Identity, len(user.Identities))
+	for i, x := range user.Identities {
+		list[i] = &Identity{
+			Issuer:  x.Issuer,
+			Subject: x.Subject,
+			Email:   x.Email,
+			Name:    x.Name,
+			Created: x.Created.Unix(),
+		}
+	}
+
+	return &ListIdentitiesResponse{
+		Identities: list,
+	}, nil
+}
+
+// indexVerifiedOrcids sets the verified_orcid of users verified before it
+// was stored. If an iD was verified by several users, only the first keeps
+// the verification.
+func indexVerifiedOrcids(db *mgo.Database) error {
+	q := bson.M{
+		"orcid_verified": bson.M{
+			"$exists": true,
+		},
+		"verified_orcid": bson.M{
+			"$exists": false,
+		},
+	}
+
+	var users []*User
+	if err := db.C(usersCol).Find(q).Select(bson.M{"orcid": 1}).Sort("orcid_verified").All(&users); err != nil {
+		return err
+	}
+
+	seen := make(map[string]struct{}, len(users))
+
+	for _, u := range users {
+		_, dup := seen[u.Orcid]
+
+		if !dup {
+			seen[u.Orcid] = struct{}{}
+
+			err := db.C(usersCol).UpdateId(u.ID, bson.M{
+				"$set": bson.M{
+					"verified_orcid": u.Orcid,
+				},
+			})
+			if mgo.IsDup(err) {
+				dup = true
+			} else if err != nil {
+				return err
+			}
+		}
+
+		if dup {
+			log.Printf("account: ORCID iD of user %s was verified by another user", u.ID)
+
+			err := db.C(usersCol).UpdateId(u.ID, bson.M{
+				"$unset": bson.M{
+					"orcid_verified": "",
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ensureIndexes creates the indexes the lookups and uniqueness checks rely
+// on.
+func ensureIndexes(db *mgo.Database) error {
 	err := db.C(usersCol).EnsureIndex(mgo.Index{
 		Key:    []string{"email"},
 		Unique: true,
 	})
 	if err != nil {
+		return err
+	}
+
+	// An identity can only be linked to one user.
+	err = db.C(usersCol).EnsureIndex(mgo.Index{
+		Key:    []string{"identities.issuer", "identities.subject"},
+		Unique: true,
+		Sparse: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := indexVerifiedOrcids(db); err != nil {
+		return err
+	}
+
+	// A verified ORCID iD can only belong to one user.
+	err = db.C(usersCol).EnsureIndex(mgo.Index{
+		Key:    []string{"verified_orcid"},
+		Unique: true,
+		Sparse: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = db.C(tokensCol).EnsureIndex(mgo.Index{
+		Key:    []string{"hash"},
+		Unique: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = db.C(tokensCol).EnsureIndex(mgo.Index{
+		Key: []string{"account"},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = db.C(sessionsCol).EnsureIndex(mgo.Index{
+		Key: []string{"account", "issuer", "subject"},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Expired sessions are removed by MongoDB.
+	err = db.C(sessionsCol).EnsureIndex(mgo.Index{
+		Key:         []string{"expires"},
+		ExpireAfter: time.Second,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewService(tp transport.Transport, db *mgo.Database) (Service, error) {
+	if err := ensureIndexes(db); err != nil {
 		return nil, err
 	}
 
-	return &service{
+	s := &service{
 		db: db,
-	}, nil
+		tp: tp,
+	}
+
+	// This will be auto-unsubscribed when the transport is closed.
+	if _, err := tp.Subscribe(commitlog.AccountAckSubject, newAckHandler(s)); err != nil {
+		return nil, err
+	}
+
+	go s.runRedrive()
+
+	return s, nil
 }
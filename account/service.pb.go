@@ -13,8 +13,38 @@ It has these top-level messages:
 	CreateUserResponse
 	GetUserRequest
 	GetUserResponse
+	GetUsersRequest
+	GetUsersResponse
 	DeleteUserRequest
 	DeleteUserResponse
+	Identity
+	LinkIdentityRequest
+	LinkIdentityResponse
+	UnlinkIdentityRequest
+	UnlinkIdentityResponse
+	ListIdentitiesRequest
+	ListIdentitiesResponse
+	UpdateUserRequest
+	UpdateUserResponse
+	RequestEmailChangeRequest
+	RequestEmailChangeResponse
+	ConfirmEmailChangeRequest
+	ConfirmEmailChangeResponse
+	VerifyOrcidRequest
+	VerifyOrcidResponse
+	Token
+	CreateTokenRequest
+	CreateTokenResponse
+	ListTokensRequest
+	ListTokensResponse
+	RevokeTokenRequest
+	RevokeTokenResponse
+	VerifyTokenRequest
+	VerifyTokenResponse
+	CreateSessionRequest
+	CreateSessionResponse
+	RotateSessionRequest
+	RotateSessionResponse
 */
 package account
 
@@ -38,6 +68,8 @@ type CreateUserRequest struct {
 	Subject string `protobuf:"bytes,2,opt,name=subject" json:"subject,omitempty"`
 	Name    string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
 	Email   string `protobuf:"bytes,4,opt,name=email" json:"email,omitempty"`
+	// True if the issuer of the identity verified the email address.
+	EmailVerified bool `protobuf:"varint,5,opt,name=email_verified,json=emailVerified" json:"email_verified,omitempty"`
 }
 
 func (m *CreateUserRequest) Reset()                    { *m = CreateUserRequest{} }
@@ -73,6 +105,13 @@ func (m *CreateUserRequest) GetEmail() string {
 	return ""
 }
 
+func (m *CreateUserRequest) GetEmailVerified() bool {
+	if m != nil {
+		return m.EmailVerified
+	}
+	return false
+}
+
 type CreateUserResponse struct {
 	Id    string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	Name  string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
@@ -109,6 +148,9 @@ type GetUserRequest struct {
 	Id    string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	Email string `protobuf:"bytes,2,opt,name=email" json:"email,omitempty"`
 	Name  string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	// Lookup by a linked identity. Both must be set.
+	Issuer  string `protobuf:"bytes,4,opt,name=issuer" json:"issuer,omitempty"`
+	Subject string `protobuf:"bytes,5,opt,name=subject" json:"subject,omitempty"`
 }
 
 func (m *GetUserRequest) Reset()                    { *m = GetUserRequest{} }
@@ -137,10 +179,36 @@ func (m *GetUserRequest) GetName() string {
 	return ""
 }
 
+func (m *GetUserRequest) GetIssuer() string {
+	if m != nil {
+		return m.Issuer
+	}
+	return ""
+}
+
+func (m *GetUserRequest) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
 type GetUserResponse struct {
-	Id    string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
-	Name  string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
-	Email string `protobuf:"bytes,3,opt,name=email" json:"email,omitempty"`
+	Id          string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Name        string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Email       string `protobuf:"bytes,3,opt,name=email" json:"email,omitempty"`
+	Affiliation string `protobuf:"bytes,4,opt,name=affiliation" json:"affiliation,omitempty"`
+	Orcid       string `protobuf:"bytes,5,opt,name=orcid" json:"orcid,omitempty"`
+	AvatarUrl   string `protobuf:"bytes,6,opt,name=avatar_url,json=avatarUrl" json:"avatar_url,omitempty"`
+	Created     int64  `protobuf:"varint,7,opt,name=created" json:"created,omitempty"`
+	Modified    int64  `protobuf:"varint,8,opt,name=modified" json:"modified,omitempty"`
+	// Email address awaiting verification, if any.
+	PendingEmail string `protobuf:"bytes,9,opt,name=pending_email,json=pendingEmail" json:"pending_email,omitempty"`
+	// True if the ORCID iD was verified with ORCID.
+	OrcidVerified bool `protobuf:"varint,10,opt,name=orcid_verified,json=orcidVerified" json:"orcid_verified,omitempty"`
+	// True if the email address was verified by the issuer of the identity
+	// the account was created with or by confirming a change.
+	EmailVerified bool `protobuf:"varint,11,opt,name=email_verified,json=emailVerified" json:"email_verified,omitempty"`
 }
 
 func (m *GetUserResponse) Reset()                    { *m = GetUserResponse{} }
@@ -169,6 +237,95 @@ func (m *GetUserResponse) GetEmail() string {
 	return ""
 }
 
+func (m *GetUserResponse) GetAffiliation() string {
+	if m != nil {
+		return m.Affiliation
+	}
+	return ""
+}
+
+func (m *GetUserResponse) GetOrcid() string {
+	if m != nil {
+		return m.Orcid
+	}
+	return ""
+}
+
+func (m *GetUserResponse) GetAvatarUrl() string {
+	if m != nil {
+		return m.AvatarUrl
+	}
+	return ""
+}
+
+func (m *GetUserResponse) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *GetUserResponse) GetModified() int64 {
+	if m != nil {
+		return m.Modified
+	}
+	return 0
+}
+
+func (m *GetUserResponse) GetPendingEmail() string {
+	if m != nil {
+		return m.PendingEmail
+	}
+	return ""
+}
+
+func (m *GetUserResponse) GetOrcidVerified() bool {
+	if m != nil {
+		return m.OrcidVerified
+	}
+	return false
+}
+
+func (m *GetUserResponse) GetEmailVerified() bool {
+	if m != nil {
+		return m.EmailVerified
+	}
+	return false
+}
+
+type GetUsersRequest struct {
+	Ids []string `protobuf:"bytes,1,rep,name=ids" json:"ids,omitempty"`
+}
+
+func (m *GetUsersRequest) Reset()                    { *m = GetUsersRequest{} }
+func (m *GetUsersRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetUsersRequest) ProtoMessage()               {}
+func (*GetUsersRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *GetUsersRequest) GetIds() []string {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+type GetUsersResponse struct {
+	// Users in the order of the ids. Ids that do not exist are omitted.
+	Users []*GetUserResponse `protobuf:"bytes,1,rep,name=users" json:"users,omitempty"`
+}
+
+func (m *GetUsersResponse) Reset()                    { *m = GetUsersResponse{} }
+func (m *GetUsersResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetUsersResponse) ProtoMessage()               {}
+func (*GetUsersResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *GetUsersResponse) GetUsers() []*GetUserResponse {
+	if m != nil {
+		return m.Users
+	}
+	return nil
+}
+
 type DeleteUserRequest struct {
 	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 }
@@ -176,7 +333,7 @@ type DeleteUserRequest struct {
 func (m *DeleteUserRequest) Reset()                    { *m = DeleteUserRequest{} }
 func (m *DeleteUserRequest) String() string            { return proto.CompactTextString(m) }
 func (*DeleteUserRequest) ProtoMessage()               {}
-func (*DeleteUserRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*DeleteUserRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
 func (m *DeleteUserRequest) GetId() string {
 	if m != nil {
@@ -191,36 +348,854 @@ type DeleteUserResponse struct {
 func (m *DeleteUserResponse) Reset()                    { *m = DeleteUserResponse{} }
 func (m *DeleteUserResponse) String() string            { return proto.CompactTextString(m) }
 func (*DeleteUserResponse) ProtoMessage()               {}
-func (*DeleteUserResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*DeleteUserResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+type Identity struct {
+	Issuer  string `protobuf:"bytes,1,opt,name=issuer" json:"issuer,omitempty"`
+	Subject string `protobuf:"bytes,2,opt,name=subject" json:"subject,omitempty"`
+	Email   string `protobuf:"bytes,3,opt,name=email" json:"email,omitempty"`
+	Name    string `protobuf:"bytes,4,opt,name=name" json:"name,omitempty"`
+	Created int64  `protobuf:"varint,5,opt,name=created" json:"created,omitempty"`
+}
+
+func (m *Identity) Reset()                    { *m = Identity{} }
+func (m *Identity) String() string            { return proto.CompactTextString(m) }
+func (*Identity) ProtoMessage()               {}
+func (*Identity) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *Identity) GetIssuer() string {
+	if m != nil {
+		return m.Issuer
+	}
+	return ""
+}
+
+func (m *Identity) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *Identity) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *Identity) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Identity) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+type LinkIdentityRequest struct {
+	Id      string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Issuer  string `protobuf:"bytes,2,opt,name=issuer" json:"issuer,omitempty"`
+	Subject string `protobuf:"bytes,3,opt,name=subject" json:"subject,omitempty"`
+	Email   string `protobuf:"bytes,4,opt,name=email" json:"email,omitempty"`
+	Name    string `protobuf:"bytes,5,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *LinkIdentityRequest) Reset()                    { *m = LinkIdentityRequest{} }
+func (m *LinkIdentityRequest) String() string            { return proto.CompactTextString(m) }
+func (*LinkIdentityRequest) ProtoMessage()               {}
+func (*LinkIdentityRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *LinkIdentityRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *LinkIdentityRequest) GetIssuer() string {
+	if m != nil {
+		return m.Issuer
+	}
+	return ""
+}
+
+func (m *LinkIdentityRequest) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *LinkIdentityRequest) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *LinkIdentityRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type LinkIdentityResponse struct {
+}
+
+func (m *LinkIdentityResponse) Reset()                    { *m = LinkIdentityResponse{} }
+func (m *LinkIdentityResponse) String() string            { return proto.CompactTextString(m) }
+func (*LinkIdentityResponse) ProtoMessage()               {}
+func (*LinkIdentityResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+type UnlinkIdentityRequest struct {
+	Id      string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Issuer  string `protobuf:"bytes,2,opt,name=issuer" json:"issuer,omitempty"`
+	Subject string `protobuf:"bytes,3,opt,name=subject" json:"subject,omitempty"`
+}
+
+func (m *UnlinkIdentityRequest) Reset()                    { *m = UnlinkIdentityRequest{} }
+func (m *UnlinkIdentityRequest) String() string            { return proto.CompactTextString(m) }
+func (*UnlinkIdentityRequest) ProtoMessage()               {}
+func (*UnlinkIdentityRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *UnlinkIdentityRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *UnlinkIdentityRequest) GetIssuer() string {
+	if m != nil {
+		return m.Issuer
+	}
+	return ""
+}
+
+func (m *UnlinkIdentityRequest) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+type UnlinkIdentityResponse struct {
+}
+
+func (m *UnlinkIdentityResponse) Reset()                    { *m = UnlinkIdentityResponse{} }
+func (m *UnlinkIdentityResponse) String() string            { return proto.CompactTextString(m) }
+func (*UnlinkIdentityResponse) ProtoMessage()               {}
+func (*UnlinkIdentityResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+type ListIdentitiesRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *ListIdentitiesRequest) Reset()                    { *m = ListIdentitiesRequest{} }
+func (m *ListIdentitiesRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListIdentitiesRequest) ProtoMessage()               {}
+func (*ListIdentitiesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *ListIdentitiesRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type ListIdentitiesResponse struct {
+	Identities []*Identity `protobuf:"bytes,1,rep,name=identities" json:"identities,omitempty"`
+}
+
+func (m *ListIdentitiesResponse) Reset()                    { *m = ListIdentitiesResponse{} }
+func (m *ListIdentitiesResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListIdentitiesResponse) ProtoMessage()               {}
+func (*ListIdentitiesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *ListIdentitiesResponse) GetIdentities() []*Identity {
+	if m != nil {
+		return m.Identities
+	}
+	return nil
+}
+
+// UpdateUserRequest replaces the profile of the user. Changing the ORCID iD
+// clears its verification.
+type UpdateUserRequest struct {
+	Id          string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Name        string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Affiliation string `protobuf:"bytes,3,opt,name=affiliation" json:"affiliation,omitempty"`
+	Orcid       string `protobuf:"bytes,4,opt,name=orcid" json:"orcid,omitempty"`
+	AvatarUrl   string `protobuf:"bytes,5,opt,name=avatar_url,json=avatarUrl" json:"avatar_url,omitempty"`
+}
+
+func (m *UpdateUserRequest) Reset()                    { *m = UpdateUserRequest{} }
+func (m *UpdateUserRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateUserRequest) ProtoMessage()               {}
+func (*UpdateUserRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *UpdateUserRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *UpdateUserRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *UpdateUserRequest) GetAffiliation() string {
+	if m != nil {
+		return m.Affiliation
+	}
+	return ""
+}
+
+func (m *UpdateUserRequest) GetOrcid() string {
+	if m != nil {
+		return m.Orcid
+	}
+	return ""
+}
+
+func (m *UpdateUserRequest) GetAvatarUrl() string {
+	if m != nil {
+		return m.AvatarUrl
+	}
+	return ""
+}
+
+type UpdateUserResponse struct {
+	User *GetUserResponse `protobuf:"bytes,1,opt,name=user" json:"user,omitempty"`
+}
+
+func (m *UpdateUserResponse) Reset()                    { *m = UpdateUserResponse{} }
+func (m *UpdateUserResponse) String() string            { return proto.CompactTextString(m) }
+func (*UpdateUserResponse) ProtoMessage()               {}
+func (*UpdateUserResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *UpdateUserResponse) GetUser() *GetUserResponse {
+	if m != nil {
+		return m.User
+	}
+	return nil
+}
+
+type RequestEmailChangeRequest struct {
+	Id    string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Email string `protobuf:"bytes,2,opt,name=email" json:"email,omitempty"`
+}
+
+func (m *RequestEmailChangeRequest) Reset()                    { *m = RequestEmailChangeRequest{} }
+func (m *RequestEmailChangeRequest) String() string            { return proto.CompactTextString(m) }
+func (*RequestEmailChangeRequest) ProtoMessage()               {}
+func (*RequestEmailChangeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *RequestEmailChangeRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *RequestEmailChangeRequest) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+type RequestEmailChangeResponse struct {
+	// Verification token to deliver to the new address. Only its hash is
+	// stored.
+	Token   string `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	Expires int64  `protobuf:"varint,2,opt,name=expires" json:"expires,omitempty"`
+}
+
+func (m *RequestEmailChangeResponse) Reset()                    { *m = RequestEmailChangeResponse{} }
+func (m *RequestEmailChangeResponse) String() string            { return proto.CompactTextString(m) }
+func (*RequestEmailChangeResponse) ProtoMessage()               {}
+func (*RequestEmailChangeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *RequestEmailChangeResponse) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *RequestEmailChangeResponse) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+type ConfirmEmailChangeRequest struct {
+	Id    string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Token string `protobuf:"bytes,2,opt,name=token" json:"token,omitempty"`
+}
+
+func (m *ConfirmEmailChangeRequest) Reset()                    { *m = ConfirmEmailChangeRequest{} }
+func (m *ConfirmEmailChangeRequest) String() string            { return proto.CompactTextString(m) }
+func (*ConfirmEmailChangeRequest) ProtoMessage()               {}
+func (*ConfirmEmailChangeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *ConfirmEmailChangeRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ConfirmEmailChangeRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+type ConfirmEmailChangeResponse struct {
+	Email string `protobuf:"bytes,1,opt,name=email" json:"email,omitempty"`
+}
+
+func (m *ConfirmEmailChangeResponse) Reset()                    { *m = ConfirmEmailChangeResponse{} }
+func (m *ConfirmEmailChangeResponse) String() string            { return proto.CompactTextString(m) }
+func (*ConfirmEmailChangeResponse) ProtoMessage()               {}
+func (*ConfirmEmailChangeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *ConfirmEmailChangeResponse) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+// VerifyOrcidRequest sets the ORCID iD of the user after it was
+// authenticated with ORCID.
+type VerifyOrcidRequest struct {
+	Id    string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Orcid string `protobuf:"bytes,2,opt,name=orcid" json:"orcid,omitempty"`
+}
+
+func (m *VerifyOrcidRequest) Reset()                    { *m = VerifyOrcidRequest{} }
+func (m *VerifyOrcidRequest) String() string            { return proto.CompactTextString(m) }
+func (*VerifyOrcidRequest) ProtoMessage()               {}
+func (*VerifyOrcidRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *VerifyOrcidRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *VerifyOrcidRequest) GetOrcid() string {
+	if m != nil {
+		return m.Orcid
+	}
+	return ""
+}
+
+type VerifyOrcidResponse struct {
+	User *GetUserResponse `protobuf:"bytes,1,opt,name=user" json:"user,omitempty"`
+}
+
+func (m *VerifyOrcidResponse) Reset()                    { *m = VerifyOrcidResponse{} }
+func (m *VerifyOrcidResponse) String() string            { return proto.CompactTextString(m) }
+func (*VerifyOrcidResponse) ProtoMessage()               {}
+func (*VerifyOrcidResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *VerifyOrcidResponse) GetUser() *GetUserResponse {
+	if m != nil {
+		return m.User
+	}
+	return nil
+}
+
+// Token is a personal access token. The secret is only returned when it is
+// created.
+type Token struct {
+	Id      string   `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Account string   `protobuf:"bytes,2,opt,name=account" json:"account,omitempty"`
+	Name    string   `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	Scopes  []string `protobuf:"bytes,4,rep,name=scopes" json:"scopes,omitempty"`
+	// Start of the secret to recognize the token.
+	Prefix  string `protobuf:"bytes,5,opt,name=prefix" json:"prefix,omitempty"`
+	Created int64  `protobuf:"varint,6,opt,name=created" json:"created,omitempty"`
+	// Zero if the token does not expire.
+	Expires int64 `protobuf:"varint,7,opt,name=expires" json:"expires,omitempty"`
+	// Zero if the token was never used.
+	LastUsed int64 `protobuf:"varint,8,opt,name=last_used,json=lastUsed" json:"last_used,omitempty"`
+}
+
+func (m *Token) Reset()                    { *m = Token{} }
+func (m *Token) String() string            { return proto.CompactTextString(m) }
+func (*Token) ProtoMessage()               {}
+func (*Token) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *Token) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Token) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *Token) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Token) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *Token) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+func (m *Token) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *Token) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+func (m *Token) GetLastUsed() int64 {
+	if m != nil {
+		return m.LastUsed
+	}
+	return 0
+}
+
+type CreateTokenRequest struct {
+	Account string   `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	Name    string   `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Scopes  []string `protobuf:"bytes,3,rep,name=scopes" json:"scopes,omitempty"`
+	// Unix time the token expires at. Zero if it does not expire.
+	Expires int64 `protobuf:"varint,4,opt,name=expires" json:"expires,omitempty"`
+}
+
+func (m *CreateTokenRequest) Reset()                    { *m = CreateTokenRequest{} }
+func (m *CreateTokenRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateTokenRequest) ProtoMessage()               {}
+func (*CreateTokenRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *CreateTokenRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *CreateTokenRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CreateTokenRequest) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *CreateTokenRequest) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+type CreateTokenResponse struct {
+	Token  *Token `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	Secret string `protobuf:"bytes,2,opt,name=secret" json:"secret,omitempty"`
+}
+
+func (m *CreateTokenResponse) Reset()                    { *m = CreateTokenResponse{} }
+func (m *CreateTokenResponse) String() string            { return proto.CompactTextString(m) }
+func (*CreateTokenResponse) ProtoMessage()               {}
+func (*CreateTokenResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *CreateTokenResponse) GetToken() *Token {
+	if m != nil {
+		return m.Token
+	}
+	return nil
+}
+
+func (m *CreateTokenResponse) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+type ListTokensRequest struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+}
+
+func (m *ListTokensRequest) Reset()                    { *m = ListTokensRequest{} }
+func (m *ListTokensRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListTokensRequest) ProtoMessage()               {}
+func (*ListTokensRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *ListTokensRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+type ListTokensResponse struct {
+	Tokens []*Token `protobuf:"bytes,1,rep,name=tokens" json:"tokens,omitempty"`
+}
+
+func (m *ListTokensResponse) Reset()                    { *m = ListTokensResponse{} }
+func (m *ListTokensResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListTokensResponse) ProtoMessage()               {}
+func (*ListTokensResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *ListTokensResponse) GetTokens() []*Token {
+	if m != nil {
+		return m.Tokens
+	}
+	return nil
+}
+
+type RevokeTokenRequest struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	Id      string `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *RevokeTokenRequest) Reset()                    { *m = RevokeTokenRequest{} }
+func (m *RevokeTokenRequest) String() string            { return proto.CompactTextString(m) }
+func (*RevokeTokenRequest) ProtoMessage()               {}
+func (*RevokeTokenRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+func (m *RevokeTokenRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *RevokeTokenRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type RevokeTokenResponse struct {
+}
+
+func (m *RevokeTokenResponse) Reset()                    { *m = RevokeTokenResponse{} }
+func (m *RevokeTokenResponse) String() string            { return proto.CompactTextString(m) }
+func (*RevokeTokenResponse) ProtoMessage()               {}
+func (*RevokeTokenResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+type VerifyTokenRequest struct {
+	Secret string `protobuf:"bytes,1,opt,name=secret" json:"secret,omitempty"`
+}
+
+func (m *VerifyTokenRequest) Reset()                    { *m = VerifyTokenRequest{} }
+func (m *VerifyTokenRequest) String() string            { return proto.CompactTextString(m) }
+func (*VerifyTokenRequest) ProtoMessage()               {}
+func (*VerifyTokenRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *VerifyTokenRequest) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+type VerifyTokenResponse struct {
+	Token *Token           `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	User  *GetUserResponse `protobuf:"bytes,2,opt,name=user" json:"user,omitempty"`
+}
+
+func (m *VerifyTokenResponse) Reset()                    { *m = VerifyTokenResponse{} }
+func (m *VerifyTokenResponse) String() string            { return proto.CompactTextString(m) }
+func (*VerifyTokenResponse) ProtoMessage()               {}
+func (*VerifyTokenResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *VerifyTokenResponse) GetToken() *Token {
+	if m != nil {
+		return m.Token
+	}
+	return nil
+}
+
+func (m *VerifyTokenResponse) GetUser() *GetUserResponse {
+	if m != nil {
+		return m.User
+	}
+	return nil
+}
+
+// CreateSessionRequest starts a session for a sign in with the identity.
+// Sessions back the refresh tokens minted by the gateway.
+type CreateSessionRequest struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	Issuer  string `protobuf:"bytes,2,opt,name=issuer" json:"issuer,omitempty"`
+	Subject string `protobuf:"bytes,3,opt,name=subject" json:"subject,omitempty"`
+	// Time the session expires, in seconds since the epoch.
+	Expires int64 `protobuf:"varint,4,opt,name=expires" json:"expires,omitempty"`
+}
+
+func (m *CreateSessionRequest) Reset()                    { *m = CreateSessionRequest{} }
+func (m *CreateSessionRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateSessionRequest) ProtoMessage()               {}
+func (*CreateSessionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+
+func (m *CreateSessionRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *CreateSessionRequest) GetIssuer() string {
+	if m != nil {
+		return m.Issuer
+	}
+	return ""
+}
+
+func (m *CreateSessionRequest) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *CreateSessionRequest) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+type CreateSessionResponse struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *CreateSessionResponse) Reset()                    { *m = CreateSessionResponse{} }
+func (m *CreateSessionResponse) String() string            { return proto.CompactTextString(m) }
+func (*CreateSessionResponse) ProtoMessage()               {}
+func (*CreateSessionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
+
+func (m *CreateSessionResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+// RotateSessionRequest replaces the session with a new one, so each refresh
+// token can only be used once. Unknown and expired sessions are
+// Unauthenticated. Sessions are removed when the identity they were started
+// with is unlinked or the account is deleted.
+type RotateSessionRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	// Time the new session expires, in seconds since the epoch.
+	Expires int64 `protobuf:"varint,2,opt,name=expires" json:"expires,omitempty"`
+}
+
+func (m *RotateSessionRequest) Reset()                    { *m = RotateSessionRequest{} }
+func (m *RotateSessionRequest) String() string            { return proto.CompactTextString(m) }
+func (*RotateSessionRequest) ProtoMessage()               {}
+func (*RotateSessionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+func (m *RotateSessionRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *RotateSessionRequest) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+type RotateSessionResponse struct {
+	Id      string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Account string `protobuf:"bytes,2,opt,name=account" json:"account,omitempty"`
+}
+
+func (m *RotateSessionResponse) Reset()                    { *m = RotateSessionResponse{} }
+func (m *RotateSessionResponse) String() string            { return proto.CompactTextString(m) }
+func (*RotateSessionResponse) ProtoMessage()               {}
+func (*RotateSessionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *RotateSessionResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *RotateSessionResponse) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
 
 func init() {
 	proto.RegisterType((*CreateUserRequest)(nil), "account.CreateUserRequest")
 	proto.RegisterType((*CreateUserResponse)(nil), "account.CreateUserResponse")
 	proto.RegisterType((*GetUserRequest)(nil), "account.GetUserRequest")
 	proto.RegisterType((*GetUserResponse)(nil), "account.GetUserResponse")
+	proto.RegisterType((*GetUsersRequest)(nil), "account.GetUsersRequest")
+	proto.RegisterType((*GetUsersResponse)(nil), "account.GetUsersResponse")
 	proto.RegisterType((*DeleteUserRequest)(nil), "account.DeleteUserRequest")
 	proto.RegisterType((*DeleteUserResponse)(nil), "account.DeleteUserResponse")
+	proto.RegisterType((*Identity)(nil), "account.Identity")
+	proto.RegisterType((*LinkIdentityRequest)(nil), "account.LinkIdentityRequest")
+	proto.RegisterType((*LinkIdentityResponse)(nil), "account.LinkIdentityResponse")
+	proto.RegisterType((*UnlinkIdentityRequest)(nil), "account.UnlinkIdentityRequest")
+	proto.RegisterType((*UnlinkIdentityResponse)(nil), "account.UnlinkIdentityResponse")
+	proto.RegisterType((*ListIdentitiesRequest)(nil), "account.ListIdentitiesRequest")
+	proto.RegisterType((*ListIdentitiesResponse)(nil), "account.ListIdentitiesResponse")
+	proto.RegisterType((*UpdateUserRequest)(nil), "account.UpdateUserRequest")
+	proto.RegisterType((*UpdateUserResponse)(nil), "account.UpdateUserResponse")
+	proto.RegisterType((*RequestEmailChangeRequest)(nil), "account.RequestEmailChangeRequest")
+	proto.RegisterType((*RequestEmailChangeResponse)(nil), "account.RequestEmailChangeResponse")
+	proto.RegisterType((*ConfirmEmailChangeRequest)(nil), "account.ConfirmEmailChangeRequest")
+	proto.RegisterType((*ConfirmEmailChangeResponse)(nil), "account.ConfirmEmailChangeResponse")
+	proto.RegisterType((*VerifyOrcidRequest)(nil), "account.VerifyOrcidRequest")
+	proto.RegisterType((*VerifyOrcidResponse)(nil), "account.VerifyOrcidResponse")
+	proto.RegisterType((*Token)(nil), "account.Token")
+	proto.RegisterType((*CreateTokenRequest)(nil), "account.CreateTokenRequest")
+	proto.RegisterType((*CreateTokenResponse)(nil), "account.CreateTokenResponse")
+	proto.RegisterType((*ListTokensRequest)(nil), "account.ListTokensRequest")
+	proto.RegisterType((*ListTokensResponse)(nil), "account.ListTokensResponse")
+	proto.RegisterType((*RevokeTokenRequest)(nil), "account.RevokeTokenRequest")
+	proto.RegisterType((*RevokeTokenResponse)(nil), "account.RevokeTokenResponse")
+	proto.RegisterType((*VerifyTokenRequest)(nil), "account.VerifyTokenRequest")
+	proto.RegisterType((*VerifyTokenResponse)(nil), "account.VerifyTokenResponse")
+	proto.RegisterType((*CreateSessionRequest)(nil), "account.CreateSessionRequest")
+	proto.RegisterType((*CreateSessionResponse)(nil), "account.CreateSessionResponse")
+	proto.RegisterType((*RotateSessionRequest)(nil), "account.RotateSessionRequest")
+	proto.RegisterType((*RotateSessionResponse)(nil), "account.RotateSessionResponse")
 }
 
 func init() { proto.RegisterFile("service.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 271 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x52, 0x3d, 0x4f, 0xc3, 0x30,
-	0x10, 0x55, 0xdc, 0xd2, 0x88, 0x27, 0x51, 0xd4, 0x53, 0x05, 0x56, 0x58, 0x50, 0x58, 0x98, 0x32,
-	0xc0, 0xca, 0x06, 0x15, 0x12, 0x48, 0x0c, 0x45, 0xfc, 0x80, 0x34, 0xbd, 0xc1, 0xa8, 0x8d, 0x8b,
-	0xed, 0xf0, 0x4f, 0xf9, 0x3f, 0x48, 0x8e, 0x49, 0xdd, 0x26, 0x1b, 0x9b, 0xef, 0xeb, 0xdd, 0x7b,
-	0xf7, 0x8c, 0x33, 0xcb, 0xe6, 0x5b, 0x55, 0x5c, 0xec, 0x8c, 0x76, 0x9a, 0xd2, 0xb2, 0xaa, 0x74,
-	0x53, 0xbb, 0x5c, 0x63, 0xf6, 0x68, 0xb8, 0x74, 0xfc, 0x61, 0xd9, 0x2c, 0xf9, 0xab, 0x61, 0xeb,
-	0xe8, 0x02, 0x13, 0x65, 0x6d, 0xc3, 0x46, 0x26, 0xd7, 0xc9, 0xed, 0xe9, 0x32, 0x44, 0x24, 0x91,
-	0xda, 0x66, 0xf5, 0xc9, 0x95, 0x93, 0xc2, 0x17, 0xfe, 0x42, 0x22, 0x8c, 0xeb, 0x72, 0xcb, 0x72,
-	0xe4, 0xd3, 0xfe, 0x4d, 0x73, 0x9c, 0xf0, 0xb6, 0x54, 0x1b, 0x39, 0xf6, 0xc9, 0x36, 0xc8, 0xdf,
-	0x40, 0xf1, 0x42, 0xbb, 0xd3, 0xb5, 0x65, 0x9a, 0x42, 0xa8, 0x75, 0xd8, 0x26, 0xd4, 0xba, 0xc3,
-	0x13, 0x43, 0x78, 0xa3, 0x18, 0xef, 0x05, 0xd3, 0x67, 0x76, 0x31, 0xfb, 0x63, 0xac, 0x6e, 0x4e,
-	0x44, 0x73, 0x43, 0x8c, 0xf3, 0x57, 0x9c, 0x77, 0x58, 0xff, 0x26, 0x76, 0x83, 0xd9, 0x13, 0x6f,
-	0xf8, 0xf0, 0xb2, 0x47, 0x70, 0xf9, 0x1c, 0x14, 0x37, 0xb5, 0x4b, 0xef, 0x7e, 0x12, 0xa4, 0xef,
-	0xad, 0x5f, 0xb4, 0x00, 0xf6, 0xf7, 0xa2, 0xac, 0x08, 0xc6, 0x15, 0x3d, 0xd7, 0xb2, 0xab, 0xc1,
-	0x5a, 0xd0, 0xf1, 0x80, 0x34, 0x48, 0xa3, 0xcb, 0xae, 0xef, 0xf0, 0x70, 0x99, 0xec, 0x17, 0xc2,
-	0xf4, 0x02, 0xd8, 0xd3, 0x8c, 0x48, 0xf4, 0x04, 0x46, 0x24, 0xfa, 0xba, 0x56, 0x13, 0xff, 0xf9,
-	0xee, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x2d, 0x97, 0xc9, 0xe5, 0x8d, 0x02, 0x00, 0x00,
+	// 1155 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x58, 0x5d, 0x6f, 0xe3, 0x44,
+	0x17, 0x96, 0x9d, 0xef, 0x93, 0x6d, 0xde, 0xcd, 0x24, 0xe9, 0xeb, 0x9d, 0xa4, 0xdd, 0x68, 0x02,
+	0x6c, 0x2f, 0x96, 0x4a, 0x94, 0x3b, 0xb4, 0xe2, 0x63, 0x4b, 0x05, 0x68, 0xab, 0x45, 0xa4, 0x04,
+	0x89, 0x0b, 0x54, 0x79, 0xe3, 0xc9, 0x32, 0x34, 0xb5, 0x83, 0xc7, 0xa9, 0x76, 0xa5, 0xbd, 0x80,
+	0x3f, 0xc0, 0x25, 0x77, 0xfc, 0x1d, 0x6e, 0xf8, 0x53, 0xc8, 0xe3, 0xb1, 0x67, 0x6c, 0x8f, 0x43,
+	0xbb, 0x88, 0xbb, 0xcc, 0x9c, 0xe3, 0x67, 0x9e, 0xf3, 0x7d, 0x14, 0xd8, 0xe3, 0x34, 0xbc, 0x61,
+	0x4b, 0x7a, 0xbc, 0x09, 0x83, 0x28, 0x40, 0x2d, 0x77, 0xb9, 0x0c, 0xb6, 0x7e, 0x44, 0x7e, 0xb7,
+	0xa0, 0x7f, 0x1a, 0x52, 0x37, 0xa2, 0x0b, 0x4e, 0xc3, 0x39, 0xfd, 0x79, 0x4b, 0x79, 0x84, 0xf6,
+	0xa1, 0xc9, 0x38, 0xdf, 0xd2, 0xd0, 0xb1, 0xa6, 0xd6, 0x51, 0x67, 0x2e, 0x4f, 0xc8, 0x81, 0x16,
+	0xdf, 0xbe, 0xf8, 0x89, 0x2e, 0x23, 0xc7, 0x16, 0x82, 0xf4, 0x88, 0x10, 0xd4, 0x7d, 0xf7, 0x9a,
+	0x3a, 0x35, 0x71, 0x2d, 0x7e, 0xa3, 0x21, 0x34, 0xe8, 0xb5, 0xcb, 0xd6, 0x4e, 0x5d, 0x5c, 0x26,
+	0x07, 0xf4, 0x2e, 0xf4, 0xc4, 0x8f, 0xcb, 0x1b, 0x1a, 0xb2, 0x15, 0xa3, 0x9e, 0xd3, 0x98, 0x5a,
+	0x47, 0xed, 0xf9, 0x9e, 0xb8, 0xfd, 0x4e, 0x5e, 0x92, 0xe7, 0x80, 0x74, 0x5e, 0x7c, 0x13, 0xf8,
+	0x9c, 0xa2, 0x1e, 0xd8, 0xcc, 0x93, 0xa4, 0x6c, 0xe6, 0x65, 0xcf, 0xda, 0xa6, 0x67, 0x6b, 0xda,
+	0xb3, 0xe4, 0x0d, 0xf4, 0xbe, 0xa0, 0x91, 0x6e, 0x64, 0x11, 0x2b, 0xfb, 0xce, 0xd6, 0xe9, 0x9a,
+	0x0c, 0x53, 0xee, 0xa9, 0x57, 0xb9, 0xa7, 0x91, 0x73, 0x0f, 0xf9, 0xcb, 0x86, 0xff, 0x65, 0xcf,
+	0xff, 0x5b, 0x5b, 0xd0, 0x14, 0xba, 0xee, 0x6a, 0xc5, 0xd6, 0xcc, 0x8d, 0x58, 0xe0, 0x4b, 0x12,
+	0xfa, 0x55, 0xfc, 0x5d, 0x10, 0x2e, 0x99, 0x27, 0x79, 0x24, 0x07, 0x74, 0x00, 0xe0, 0xde, 0xb8,
+	0x91, 0x1b, 0x5e, 0x6e, 0xc3, 0xb5, 0xd3, 0x14, 0xa2, 0x4e, 0x72, 0xb3, 0x08, 0xd7, 0x31, 0xfd,
+	0xa5, 0x70, 0xb9, 0xe7, 0xb4, 0xa6, 0xd6, 0x51, 0x6d, 0x9e, 0x1e, 0x11, 0x86, 0xf6, 0x75, 0xe0,
+	0x25, 0xd1, 0x6a, 0x0b, 0x51, 0x76, 0x46, 0x33, 0xd8, 0xdb, 0x50, 0xdf, 0x63, 0xfe, 0xcb, 0xcb,
+	0x84, 0x6a, 0x47, 0xe0, 0xde, 0x93, 0x97, 0x67, 0x69, 0xd0, 0x05, 0x05, 0x15, 0x74, 0x48, 0x82,
+	0x2e, 0x6e, 0xd3, 0xa0, 0x1b, 0x72, 0xa3, 0x6b, 0xca, 0x8d, 0x59, 0xe6, 0x4c, 0x9e, 0x06, 0xf3,
+	0x3e, 0xd4, 0x98, 0xc7, 0x1d, 0x6b, 0x5a, 0x3b, 0xea, 0xcc, 0xe3, 0x9f, 0xe4, 0x29, 0xdc, 0x57,
+	0x4a, 0xd2, 0xe5, 0xc7, 0xd0, 0xd8, 0xc6, 0x17, 0x42, 0xaf, 0x7b, 0xe2, 0x1c, 0xcb, 0x32, 0x38,
+	0x2e, 0xc4, 0x66, 0x9e, 0xa8, 0x91, 0x19, 0xf4, 0x3f, 0xa7, 0x6b, 0x9a, 0x2f, 0x8e, 0x42, 0xdc,
+	0xc8, 0x10, 0x90, 0xae, 0x94, 0x20, 0x90, 0x5f, 0x2c, 0x68, 0x7f, 0xe5, 0x51, 0x3f, 0x62, 0xd1,
+	0xeb, 0xb7, 0xa8, 0x27, 0x73, 0xe0, 0xd3, 0x14, 0xa9, 0x6b, 0x29, 0xa2, 0x45, 0xad, 0x91, 0x8b,
+	0x1a, 0xf9, 0xd5, 0x82, 0xc1, 0x39, 0xf3, 0xaf, 0x52, 0x1a, 0x55, 0x89, 0xaf, 0xd8, 0xd9, 0x55,
+	0xec, 0x6a, 0x15, 0xec, 0xea, 0x26, 0x76, 0x0d, 0xc5, 0x8e, 0xec, 0xc3, 0x30, 0x4f, 0x41, 0xba,
+	0xe7, 0x7b, 0x18, 0x2d, 0xfc, 0xf5, 0x7f, 0x41, 0x8e, 0x38, 0xb0, 0x5f, 0x84, 0x96, 0x8f, 0x3e,
+	0x82, 0xd1, 0x39, 0xe3, 0x91, 0xbc, 0x67, 0x94, 0x57, 0x85, 0xf4, 0x19, 0xec, 0x17, 0x15, 0x65,
+	0x06, 0x7d, 0x00, 0xc0, 0xb2, 0x5b, 0x99, 0x46, 0xfd, 0x2c, 0x8d, 0xb2, 0x17, 0x35, 0x25, 0xf2,
+	0x9b, 0x05, 0xfd, 0xc5, 0xc6, 0x73, 0x77, 0x66, 0x91, 0xb1, 0xfa, 0x0b, 0x75, 0x5e, 0xdb, 0x51,
+	0xe7, 0xf5, 0xea, 0x3a, 0x6f, 0x14, 0xea, 0x9c, 0x3c, 0x05, 0xa4, 0xf3, 0x91, 0x96, 0x3d, 0x86,
+	0x7a, 0x9c, 0xf4, 0x82, 0xd2, 0xae, 0xd2, 0x10, 0x5a, 0xe4, 0x33, 0x78, 0x20, 0x2d, 0x11, 0x05,
+	0x7e, 0xfa, 0xa3, 0xeb, 0xbf, 0xa4, 0x77, 0xea, 0xac, 0xe4, 0x1c, 0xb0, 0x09, 0x42, 0xd2, 0x19,
+	0x42, 0x23, 0x0a, 0xae, 0xa8, 0x2f, 0x61, 0x92, 0x43, 0x1c, 0x75, 0xfa, 0x6a, 0xc3, 0x42, 0xca,
+	0x05, 0x56, 0x6d, 0x9e, 0x1e, 0x63, 0x42, 0xa7, 0x81, 0xbf, 0x62, 0xe1, 0xf5, 0xed, 0x08, 0x25,
+	0xe0, 0xb6, 0x06, 0x4e, 0x4e, 0x00, 0x9b, 0x20, 0x14, 0xa1, 0xc4, 0x08, 0x4b, 0x37, 0xe2, 0x23,
+	0x40, 0xa2, 0x2d, 0xbd, 0xfe, 0x3a, 0xf6, 0xfc, 0x8e, 0xf7, 0x92, 0x30, 0xd9, 0x5a, 0x98, 0xc8,
+	0x29, 0x0c, 0x72, 0xdf, 0xbe, 0x55, 0x20, 0xfe, 0xb4, 0xa0, 0xf1, 0xad, 0xf0, 0x4d, 0xf1, 0x51,
+	0x07, 0xd2, 0x29, 0x9f, 0x36, 0x17, 0x79, 0xac, 0x9a, 0x69, 0x7c, 0x19, 0x6c, 0x28, 0x77, 0xea,
+	0xa2, 0x87, 0xca, 0x53, 0x7c, 0xbf, 0x09, 0xe9, 0x8a, 0xbd, 0x92, 0x79, 0x24, 0x4f, 0x7a, 0xdb,
+	0x69, 0xe6, 0x87, 0x85, 0x16, 0xa3, 0x56, 0x2e, 0x46, 0x68, 0x0c, 0x9d, 0xb5, 0xcb, 0xa3, 0xcb,
+	0x2d, 0x57, 0x73, 0x24, 0xbe, 0x58, 0x70, 0xea, 0x91, 0x28, 0x1d, 0xf8, 0xc2, 0x9a, 0xd4, 0x93,
+	0x9a, 0x11, 0x96, 0xd9, 0x08, 0xdb, 0x68, 0x44, 0x2d, 0x67, 0x84, 0x46, 0xa9, 0x9e, 0x4f, 0x9b,
+	0x0b, 0x18, 0xe4, 0x5e, 0x95, 0x31, 0x78, 0x47, 0xcf, 0xbe, 0xee, 0x49, 0x2f, 0x0b, 0x42, 0xa2,
+	0x96, 0x08, 0xc5, 0x73, 0x74, 0x19, 0xd2, 0xd4, 0xc1, 0xf2, 0x44, 0xde, 0x87, 0x7e, 0xdc, 0x3e,
+	0x84, 0x2e, 0xff, 0x47, 0x4b, 0xc8, 0x13, 0x40, 0xba, 0xba, 0xa4, 0xf0, 0x1e, 0x34, 0xc5, 0x2b,
+	0x69, 0x97, 0x29, 0x72, 0x90, 0x52, 0xf2, 0x31, 0xa0, 0x39, 0xbd, 0x09, 0xae, 0x6e, 0xeb, 0xb7,
+	0x24, 0x4d, 0xec, 0xac, 0xd7, 0x8d, 0x60, 0x90, 0xfb, 0x5e, 0xf6, 0xca, 0xc7, 0x69, 0x62, 0xe7,
+	0x60, 0x95, 0xc5, 0x56, 0xce, 0x62, 0x06, 0x83, 0x9c, 0xf6, 0x9d, 0xdc, 0x98, 0x26, 0xbc, 0x7d,
+	0xab, 0x84, 0x7f, 0x03, 0xc3, 0x24, 0x62, 0x17, 0x94, 0x73, 0x16, 0xdc, 0xc2, 0xe2, 0xbb, 0xcf,
+	0xb7, 0xea, 0x7c, 0x79, 0x04, 0xa3, 0xc2, 0xeb, 0xe6, 0x6d, 0x8e, 0x7c, 0x0a, 0xc3, 0x79, 0x10,
+	0x95, 0x69, 0x1a, 0xaa, 0xb4, 0xb2, 0xa3, 0x8d, 0x0a, 0x08, 0xe6, 0xa7, 0xaa, 0x0b, 0xfd, 0xe4,
+	0x0f, 0x80, 0xd6, 0x45, 0xb2, 0xf8, 0xa3, 0x33, 0x00, 0xb5, 0x50, 0x23, 0x9c, 0x79, 0xb9, 0xb4,
+	0xfd, 0xe3, 0xb1, 0x51, 0x26, 0x1f, 0x7f, 0x02, 0x2d, 0x19, 0x17, 0xf4, 0xff, 0x72, 0xa4, 0x12,
+	0x80, 0xca, 0x10, 0xa2, 0x4f, 0xa0, 0x2d, 0xaf, 0x38, 0x2a, 0x69, 0xa5, 0xa5, 0x82, 0x1f, 0x18,
+	0x24, 0x12, 0xe0, 0x0c, 0x40, 0x2d, 0x5b, 0x9a, 0x15, 0xa5, 0x35, 0x0d, 0x8f, 0x8d, 0x32, 0x09,
+	0xf3, 0x0c, 0xee, 0xe9, 0x6b, 0x09, 0x9a, 0x64, 0xca, 0x86, 0x85, 0x09, 0x1f, 0x54, 0x48, 0x25,
+	0xd8, 0x37, 0xd0, 0xcb, 0x2f, 0x1c, 0xe8, 0x30, 0xfb, 0xc0, 0xb8, 0xe4, 0xe0, 0x87, 0x95, 0x72,
+	0x05, 0x99, 0x5f, 0x40, 0x34, 0x48, 0xe3, 0x0a, 0x83, 0x1f, 0x56, 0xca, 0x95, 0xe7, 0xd4, 0xd4,
+	0xd7, 0x3c, 0x57, 0x5a, 0x4d, 0xf0, 0xd8, 0x28, 0x93, 0x30, 0x3f, 0x00, 0x92, 0x7a, 0xda, 0x90,
+	0x44, 0x24, 0xfb, 0xa4, 0x72, 0x2b, 0xc0, 0xb3, 0x9d, 0x3a, 0x0a, 0xbe, 0x3c, 0x83, 0x35, 0xf8,
+	0xca, 0x19, 0x8f, 0x67, 0x3b, 0x75, 0x24, 0xfc, 0x97, 0xd0, 0xd5, 0x46, 0x2e, 0x52, 0x96, 0x96,
+	0x87, 0x38, 0x9e, 0x98, 0x85, 0x0a, 0x49, 0x1b, 0x1c, 0xa8, 0x58, 0x33, 0x7a, 0xd7, 0xc4, 0x13,
+	0xb3, 0x50, 0x05, 0x46, 0xb5, 0x7f, 0x2d, 0x30, 0xa5, 0x11, 0x82, 0xc7, 0x46, 0x99, 0x22, 0xa4,
+	0xf5, 0x71, 0x8d, 0x50, 0x79, 0x3a, 0xe0, 0x89, 0x59, 0x58, 0x74, 0x52, 0x11, 0xa9, 0x3c, 0x10,
+	0xf0, 0xc4, 0x2c, 0x94, 0x48, 0xcf, 0x61, 0x2f, 0xd7, 0x2d, 0xd1, 0x41, 0xc1, 0x13, 0xf9, 0xe6,
+	0x88, 0x0f, 0xab, 0xc4, 0x0a, 0x2f, 0xd7, 0x12, 0x35, 0x3c, 0x53, 0xb3, 0xc5, 0x87, 0x55, 0xe2,
+	0x04, 0xef, 0x45, 0x53, 0xfc, 0x1b, 0xf2, 0xe1, 0xdf, 0x03, 0x00, 0x79, 0xa1, 0x8f, 0x41, 0x1e,
+	0x11, 0x00, 0x00,
 }
@@ -17,13 +17,41 @@ var (
 type Service interface {
 	CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error)
 	GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error)
+	GetUsers(context.Context, *GetUsersRequest) (*GetUsersResponse, error)
 	DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error)
+	LinkIdentity(context.Context, *LinkIdentityRequest) (*LinkIdentityResponse, error)
+	UnlinkIdentity(context.Context, *UnlinkIdentityRequest) (*UnlinkIdentityResponse, error)
+	ListIdentities(context.Context, *ListIdentitiesRequest) (*ListIdentitiesResponse, error)
+	UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error)
+	RequestEmailChange(context.Context, *RequestEmailChangeRequest) (*RequestEmailChangeResponse, error)
+	ConfirmEmailChange(context.Context, *ConfirmEmailChangeRequest) (*ConfirmEmailChangeResponse, error)
+	VerifyOrcid(context.Context, *VerifyOrcidRequest) (*VerifyOrcidResponse, error)
+	CreateToken(context.Context, *CreateTokenRequest) (*CreateTokenResponse, error)
+	ListTokens(context.Context, *ListTokensRequest) (*ListTokensResponse, error)
+	RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenResponse, error)
+	VerifyToken(context.Context, *VerifyTokenRequest) (*VerifyTokenResponse, error)
+	CreateSession(context.Context, *CreateSessionRequest) (*CreateSessionResponse, error)
+	RotateSession(context.Context, *RotateSessionRequest) (*RotateSessionResponse, error)
 }
 
 type ServiceClient interface {
 	CreateUser(context.Context, *CreateUserRequest, ...transport.RequestOption) (*CreateUserResponse, error)
 	GetUser(context.Context, *GetUserRequest, ...transport.RequestOption) (*GetUserResponse, error)
+	GetUsers(context.Context, *GetUsersRequest, ...transport.RequestOption) (*GetUsersResponse, error)
 	DeleteUser(context.Context, *DeleteUserRequest, ...transport.RequestOption) (*DeleteUserResponse, error)
+	LinkIdentity(context.Context, *LinkIdentityRequest, ...transport.RequestOption) (*LinkIdentityResponse, error)
+	UnlinkIdentity(context.Context, *UnlinkIdentityRequest, ...transport.RequestOption) (*UnlinkIdentityResponse, error)
+	ListIdentities(context.Context, *ListIdentitiesRequest, ...transport.RequestOption) (*ListIdentitiesResponse, error)
+	UpdateUser(context.Context, *UpdateUserRequest, ...transport.RequestOption) (*UpdateUserResponse, error)
+	RequestEmailChange(context.Context, *RequestEmailChangeRequest, ...transport.RequestOption) (*RequestEmailChangeResponse, error)
+	ConfirmEmailChange(context.Context, *ConfirmEmailChangeRequest, ...transport.RequestOption) (*ConfirmEmailChangeResponse, error)
+	VerifyOrcid(context.Context, *VerifyOrcidRequest, ...transport.RequestOption) (*VerifyOrcidResponse, error)
+	CreateToken(context.Context, *CreateTokenRequest, ...transport.RequestOption) (*CreateTokenResponse, error)
+	ListTokens(context.Context, *ListTokensRequest, ...transport.RequestOption) (*ListTokensResponse, error)
+	RevokeToken(context.Context, *RevokeTokenRequest, ...transport.RequestOption) (*RevokeTokenResponse, error)
+	VerifyToken(context.Context, *VerifyTokenRequest, ...transport.RequestOption) (*VerifyTokenResponse, error)
+	CreateSession(context.Context, *CreateSessionRequest, ...transport.RequestOption) (*CreateSessionResponse, error)
+	RotateSession(context.Context, *RotateSessionRequest, ...transport.RequestOption) (*RotateSessionResponse, error)
 }
 
 // serviceClient an implementation of Service client.
@@ -53,6 +81,17 @@ func (c *serviceClient) GetUser(ctx context.Context, req *GetUserRequest, opts .
 	return &rep, nil
 }
 
+func (c *serviceClient) GetUsers(ctx context.Context, req *GetUsersRequest, opts ...transport.RequestOption) (*GetUsersResponse, error) {
+	var rep GetUsersResponse
+
+	_, err := c.tp.Request("account.GetUsers", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
 func (c *serviceClient) DeleteUser(ctx context.Context, req *DeleteUserRequest, opts ...transport.RequestOption) (*DeleteUserResponse, error) {
 	var rep DeleteUserResponse
 
@@ -64,6 +103,149 @@ func (c *serviceClient) DeleteUser(ctx context.Context, req *DeleteUserRequest,
 	return &rep, nil
 }
 
+func (c *serviceClient) LinkIdentity(ctx context.Context, req *LinkIdentityRequest, opts ...transport.RequestOption) (*LinkIdentityResponse, error) {
+	var rep LinkIdentityResponse
+
+	_, err := c.tp.Request("account.LinkIdentity", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) UnlinkIdentity(ctx context.Context, req *UnlinkIdentityRequest, opts ...transport.RequestOption) (*UnlinkIdentityResponse, error) {
+	var rep UnlinkIdentityResponse
+
+	_, err := c.tp.Request("account.UnlinkIdentity", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) ListIdentities(ctx context.Context, req *ListIdentitiesRequest, opts ...transport.RequestOption) (*ListIdentitiesResponse, error) {
+	var rep ListIdentitiesResponse
+
+	_, err := c.tp.Request("account.ListIdentities", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) UpdateUser(ctx context.Context, req *UpdateUserRequest, opts ...transport.RequestOption) (*UpdateUserResponse, error) {
+	var rep UpdateUserResponse
+
+	_, err := c.tp.Request("account.UpdateUser", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) RequestEmailChange(ctx context.Context, req *RequestEmailChangeRequest, opts ...transport.RequestOption) (*RequestEmailChangeResponse, error) {
+	var rep RequestEmailChangeResponse
+
+	_, err := c.tp.Request("account.RequestEmailChange", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) ConfirmEmailChange(ctx context.Context, req *ConfirmEmailChangeRequest, opts ...transport.RequestOption) (*ConfirmEmailChangeResponse, error) {
+	var rep ConfirmEmailChangeResponse
+
+	_, err := c.tp.Request("account.ConfirmEmailChange", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) VerifyOrcid(ctx context.Context, req *VerifyOrcidRequest, opts ...transport.RequestOption) (*VerifyOrcidResponse, error) {
+	var rep VerifyOrcidResponse
+
+	_, err := c.tp.Request("account.VerifyOrcid", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) CreateToken(ctx context.Context, req *CreateTokenRequest, opts ...transport.RequestOption) (*CreateTokenResponse, error) {
+	var rep CreateTokenResponse
+
+	_, err := c.tp.Request("account.CreateToken", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) ListTokens(ctx context.Context, req *ListTokensRequest, opts ...transport.RequestOption) (*ListTokensResponse, error) {
+	var rep ListTokensResponse
+
+	_, err := c.tp.Request("account.ListTokens", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) RevokeToken(ctx context.Context, req *RevokeTokenRequest, opts ...transport.RequestOption) (*RevokeTokenResponse, error) {
+	var rep RevokeTokenResponse
+
+	_, err := c.tp.Request("account.RevokeToken", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) VerifyToken(ctx context.Context, req *VerifyTokenRequest, opts ...transport.RequestOption) (*VerifyTokenResponse, error) {
+	var rep VerifyTokenResponse
+
+	_, err := c.tp.Request("account.VerifyToken", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) CreateSession(ctx context.Context, req *CreateSessionRequest, opts ...transport.RequestOption) (*CreateSessionResponse, error) {
+	var rep CreateSessionResponse
+
+	_, err := c.tp.Request("account.CreateSession", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) RotateSession(ctx context.Context, req *RotateSessionRequest, opts ...transport.RequestOption) (*RotateSessionResponse, error) {
+	var rep RotateSessionResponse
+
+	_, err := c.tp.Request("account.RotateSession", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
 // NewServiceClient creates a new Service client.
 func NewServiceClient(tp transport.Transport) ServiceClient {
 	return &serviceClient{tp}
@@ -115,6 +297,19 @@ func (s *ServiceServer) Serve(ctx context.Context, opts ...transport.SubscribeOp
 	if err != nil {
 		return err
 	}
+	_, err = s.tp.Subscribe("account.GetUsers", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req GetUsersRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.GetUsers(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
 	_, err = s.tp.Subscribe("account.DeleteUser", func(msg *transport.Message) (proto.Message, error) {
 		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
 
@@ -128,6 +323,175 @@ func (s *ServiceServer) Serve(ctx context.Context, opts ...transport.SubscribeOp
 	if err != nil {
 		return err
 	}
+	_, err = s.tp.Subscribe("account.LinkIdentity", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req LinkIdentityRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.LinkIdentity(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("account.UnlinkIdentity", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req UnlinkIdentityRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.UnlinkIdentity(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("account.ListIdentities", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req ListIdentitiesRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.ListIdentities(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("account.UpdateUser", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req UpdateUserRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.UpdateUser(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("account.RequestEmailChange", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req RequestEmailChangeRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.RequestEmailChange(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("account.ConfirmEmailChange", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req ConfirmEmailChangeRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.ConfirmEmailChange(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("account.VerifyOrcid", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req VerifyOrcidRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.VerifyOrcid(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("account.CreateToken", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req CreateTokenRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.CreateToken(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("account.ListTokens", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req ListTokensRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.ListTokens(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("account.RevokeToken", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req RevokeTokenRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.RevokeToken(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("account.VerifyToken", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req VerifyTokenRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.VerifyToken(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("account.CreateSession", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req CreateSessionRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.CreateSession(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("account.RotateSession", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req RotateSessionRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.RotateSession(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
 
 	sigchan := make(chan os.Signal)
 	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,271 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rdm-academy/api/commitlog"
+)
+
+const (
+	testIssuer      = "https://idp.example.com"
+	testOtherIssuer = "https://orcid.org"
+)
+
+func TestLinkIdentity(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	u1, err := s.CreateUser(ctx, &CreateUserRequest{Issuer: testIssuer, Subject: "1", Email: "joe@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u2, err := s.CreateUser(ctx, &CreateUserRequest{Issuer: testIssuer, Subject: "2", Email: "ann@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, x := range []struct {
+		name string
+		req  *LinkIdentityRequest
+		code codes.Code
+	}{
+		{"no user", &LinkIdentityRequest{Issuer: testOtherIssuer, Subject: "a"}, codes.InvalidArgument},
+		{"no issuer", &LinkIdentityRequest{Id: u1.Id, Subject: "a"}, codes.InvalidArgument},
+		{"no subject", &LinkIdentityRequest{Id: u1.Id, Issuer: testOtherIssuer}, codes.InvalidArgument},
+		{"not found", &LinkIdentityRequest{Id: "missing", Issuer: testOtherIssuer, Subject: "a"}, codes.NotFound},
+		{"linked", &LinkIdentityRequest{Id: u1.Id, Issuer: testOtherIssuer, Subject: "a", Email: "Joe@Example.com"}, codes.OK},
+		{"linked again", &LinkIdentityRequest{Id: u1.Id, Issuer: testOtherIssuer, Subject: "a"}, codes.OK},
+		{"linked to another user", &LinkIdentityRequest{Id: u2.Id, Issuer: testOtherIssuer, Subject: "a"}, codes.AlreadyExists},
+		{"sign in of another user", &LinkIdentityRequest{Id: u1.Id, Issuer: testIssuer, Subject: "2"}, codes.AlreadyExists},
+	} {
+		_, err := s.LinkIdentity(ctx, x.req)
+		if code := status.Code(err); code != x.code {
+			t.Errorf("%s: expected %s, got %s", x.name, x.code, code)
+		}
+	}
+
+	list, err := s.ListIdentities(ctx, &ListIdentitiesRequest{Id: u1.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Linking the same identity again does not duplicate it.
+	if len(list.Identities) != 2 {
+		t.Fatalf("expected 2 identities, got %d", len(list.Identities))
+	}
+	if i := list.Identities[1]; i.Issuer != testOtherIssuer || i.Subject != "a" || i.Email != "joe@example.com" {
+		t.Errorf("unexpected identity: %v", i)
+	}
+
+	// The user signs in with either identity.
+	for _, subj := range [][2]string{{testIssuer, "1"}, {testOtherIssuer, "a"}} {
+		rep, err := s.GetUser(ctx, &GetUserRequest{Issuer: subj[0], Subject: subj[1]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rep.Id != u1.Id {
+			t.Errorf("%s %s: expected %s, got %s", subj[0], subj[1], u1.Id, rep.Id)
+		}
+	}
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, &CreateUserRequest{Issuer: testIssuer, Subject: "1", Email: "joe@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.LinkIdentity(ctx, &LinkIdentityRequest{Id: user.Id, Issuer: testOtherIssuer, Subject: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expires := time.Now().Add(time.Hour).Unix()
+
+	// Sessions signed in with each identity.
+	var sessions []string
+	for _, subj := range [][2]string{{testIssuer, "1"}, {testOtherIssuer, "a"}} {
+		rep, err := s.CreateSession(ctx, &CreateSessionRequest{Account: user.Id, Issuer: subj[0], Subject: subj[1], Expires: expires})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions = append(sessions, rep.Id)
+	}
+
+	for _, x := range []struct {
+		name string
+		req  *UnlinkIdentityRequest
+		code codes.Code
+	}{
+		{"no user", &UnlinkIdentityRequest{Issuer: testOtherIssuer, Subject: "a"}, codes.InvalidArgument},
+		{"no subject", &UnlinkIdentityRequest{Id: user.Id, Issuer: testOtherIssuer}, codes.InvalidArgument},
+		{"user not found", &UnlinkIdentityRequest{Id: "missing", Issuer: testOtherIssuer, Subject: "a"}, codes.NotFound},
+		{"identity not found", &UnlinkIdentityRequest{Id: user.Id, Issuer: testOtherIssuer, Subject: "b"}, codes.NotFound},
+		{"unlinked", &UnlinkIdentityRequest{Id: user.Id, Issuer: testOtherIssuer, Subject: "a"}, codes.OK},
+		{"unlinked again", &UnlinkIdentityRequest{Id: user.Id, Issuer: testOtherIssuer, Subject: "a"}, codes.NotFound},
+		{"last identity", &UnlinkIdentityRequest{Id: user.Id, Issuer: testIssuer, Subject: "1"}, codes.FailedPrecondition},
+	} {
+		_, err := s.UnlinkIdentity(ctx, x.req)
+		if code := status.Code(err); code != x.code {
+			t.Errorf("%s: expected %s, got %s", x.name, x.code, code)
+		}
+	}
+
+	// The unlinked identity no longer signs in.
+	if _, err := s.GetUser(ctx, &GetUserRequest{Issuer: testOtherIssuer, Subject: "a"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+
+	// Only the sessions of the unlinked identity are revoked.
+	for i, kept := range []bool{true, false} {
+		n, err := s.db.C(sessionsCol).FindId(sessions[i]).Count()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (n == 1) != kept {
+			t.Errorf("session %d: expected kept to be %t", i, kept)
+		}
+	}
+
+	// The identity can be linked to another user afterwards.
+	other, err := s.CreateUser(ctx, &CreateUserRequest{Issuer: testIssuer, Subject: "2", Email: "ann@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LinkIdentity(ctx, &LinkIdentityRequest{Id: other.Id, Issuer: testOtherIssuer, Subject: "a"}); err != nil {
+		t.Errorf("expected identity to be linked, got %s", err)
+	}
+
+	var u User
+	if err := s.db.C(usersCol).FindId(user.Id).Select(bson.M{"identities": 1}).One(&u); err != nil {
+		t.Fatal(err)
+	}
+	if len(u.Identities) != 1 {
+		t.Errorf("expected 1 identity, got %d", len(u.Identities))
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, &CreateUserRequest{Issuer: testIssuer, Subject: "1", Email: "joe@example.com", Name: "Joe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.CreateToken(ctx, &CreateTokenRequest{Account: user.Id, Name: "ci", Scopes: Scopes}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, x := range []struct {
+		name string
+		req  *DeleteUserRequest
+		code codes.Code
+	}{
+		{"no user", &DeleteUserRequest{}, codes.InvalidArgument},
+		{"not found", &DeleteUserRequest{Id: "missing"}, codes.NotFound},
+		{"deleted", &DeleteUserRequest{Id: user.Id}, codes.OK},
+		{"deleted again", &DeleteUserRequest{Id: user.Id}, codes.NotFound},
+	} {
+		_, err := s.DeleteUser(ctx, x.req)
+		if code := status.Code(err); code != x.code {
+			t.Errorf("%s: expected %s, got %s", x.name, x.code, code)
+		}
+	}
+
+	// The user is gone for every lookup.
+	for _, x := range []struct {
+		name string
+		req  *GetUserRequest
+	}{
+		{"id", &GetUserRequest{Id: user.Id}},
+		{"email", &GetUserRequest{Email: "joe@example.com"}},
+		{"identity", &GetUserRequest{Issuer: testIssuer, Subject: "1"}},
+	} {
+		if _, err := s.GetUser(ctx, x.req); status.Code(err) != codes.NotFound {
+			t.Errorf("%s: expected NotFound, got %v", x.name, err)
+		}
+	}
+
+	users, err := s.GetUsers(ctx, &GetUsersRequest{Ids: []string{user.Id}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users.Users) != 0 {
+		t.Errorf("expected no users, got %d", len(users.Users))
+	}
+
+	// Only what is needed to finish the deletion is kept.
+	var u User
+	if err := s.db.C(usersCol).FindId(user.Id).One(&u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "" || len(u.Identities) != 0 || u.Deleting.IsZero() {
+		t.Errorf("expected the user to be erased, got %v", u)
+	}
+
+	n, err := s.db.C(tokensCol).Find(bson.M{"account": user.Id}).Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("expected tokens to be removed, got %d", n)
+	}
+
+	// The address and identity can be used by a new user.
+	if _, err := s.CreateUser(ctx, &CreateUserRequest{Issuer: testIssuer, Subject: "1", Email: "joe@example.com"}); err != nil {
+		t.Errorf("expected new user, got %s", err)
+	}
+
+	// The event is published again until every service acknowledged it.
+	err = s.db.C(usersCol).UpdateId(user.Id, bson.M{
+		"$set": bson.M{
+			"deleting": time.Now().Add(-2 * redriveInterval),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tp := s.tp.(*testTransport)
+	tp.events = nil
+
+	if err := s.redrive(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tp.events) != 1 || tp.events[0].Type != "account.deleted" || tp.events[0].Author != user.Id {
+		t.Errorf("expected account.deleted event, got %v", tp.events)
+	}
+
+	for i, svc := range commitlog.AccountDeletedAcks {
+		if err := s.ack(user.Id, svc); err != nil {
+			t.Fatal(err)
+		}
+
+		n, err := s.db.C(usersCol).FindId(user.Id).Count()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if last := i == len(commitlog.AccountDeletedAcks)-1; (n == 0) != last {
+			t.Errorf("%s: expected removed to be %t", svc, last)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const sessionsCol = "sessions"
+
+type session struct {
+	ID      string    `bson:"_id"`
+	Account string    `bson:"account"`
+	Issuer  string    `bson:"issuer"`
+	Subject string    `bson:"subject"`
+	Created time.Time `bson:"created"`
+	Expires time.Time `bson:"expires"`
+}
+
+func (s *service) CreateSession(ctx context.Context, req *CreateSessionRequest) (*CreateSessionResponse, error) {
+	if req.Account == "" {
+		return nil, status.Error(codes.InvalidArgument, "account required")
+	}
+
+	now := time.Now()
+	expires := time.Unix(req.Expires, 0)
+
+	if !expires.After(now) {
+		return nil, status.Error(codes.InvalidArgument, "expiry must be in the future")
+	}
+
+	n, err := s.db.C(usersCol).Find(userQuery(req.Account)).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	x := &session{
+		ID:      uuid.NewV4().String(),
+		Account: req.Account,
+		Issuer:  req.Issuer,
+		Subject: req.Subject,
+		Created: now,
+		Expires: expires,
+	}
+
+	if err := s.db.C(sessionsCol).Insert(x); err != nil {
+		return nil, err
+	}
+
+	return &CreateSessionResponse{
+		Id: x.ID,
+	}, nil
+}
+
+// RotateSession removes the session and starts a new one for the same
+// account and identity. Removing it first makes the rotation happen once.
+func (s *service) RotateSession(ctx context.Context, req *RotateSessionRequest) (*RotateSessionResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid session")
+	}
+
+	now := time.Now()
+	expires := time.Unix(req.Expires, 0)
+
+	if !expires.After(now) {
+		return nil, status.Error(codes.InvalidArgument, "expiry must be in the future")
+	}
+
+	var old session
+	_, err := s.db.C(sessionsCol).FindId(req.Id).Apply(mgo.Change{
+		Remove: true,
+	}, &old)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.Unauthenticated, "invalid session")
+		}
+
+		return nil, err
+	}
+
+	if now.After(old.Expires) {
+		return nil, status.Error(codes.Unauthenticated, "session expired")
+	}
+
+	// The account may have been deleted since.
+	n, err := s.db.C(usersCol).Find(userQuery(old.Account)).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return nil, status.Error(codes.Unauthenticated, "invalid session")
+	}
+
+	x := &session{
+		ID:      uuid.NewV4().String(),
+		Account: old.Account,
+		Issuer:  old.Issuer,
+		Subject: old.Subject,
+		Created: now,
+		Expires: expires,
+	}
+
+	if err := s.db.C(sessionsCol).Insert(x); err != nil {
+		return nil, err
+	}
+
+	return &RotateSessionResponse{
+		Id:      x.ID,
+		Account: x.Account,
+	}, nil
+}
+
+// removeSessions ends the sessions matching the query.
+func (s *service) removeSessions(q bson.M) error {
+	_, err := s.db.C(sessionsCol).RemoveAll(q)
+	return err
+}
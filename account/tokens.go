@@ -0,0 +1,253 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	tokensCol = "tokens"
+
+	// TokenPrefix starts the secret of personal access tokens so they can
+	// be told apart from JWTs.
+	TokenPrefix = "rdm_"
+
+	// Length of the secret shown to recognize a token.
+	tokenPrefixLen = len(TokenPrefix) + 6
+
+	// Minimum time between updates of the last use.
+	tokenUseInterval = time.Minute
+)
+
+// Scopes of the API a token can be restricted to.
+const (
+	ScopeProjectsRead  = "projects:read"
+	ScopeProjectsWrite = "projects:write"
+	ScopeFilesRead     = "files:read"
+	ScopeFilesWrite    = "files:write"
+	ScopeLogCommit     = "log:commit"
+	ScopeAccountAdmin  = "account:admin"
+)
+
+// Scopes lists all scopes.
+var Scopes = []string{
+	ScopeProjectsRead,
+	ScopeProjectsWrite,
+	ScopeFilesRead,
+	ScopeFilesWrite,
+	ScopeLogCommit,
+	ScopeAccountAdmin,
+}
+
+// ValidScope returns true if the scope is one of Scopes.
+func ValidScope(s string) bool {
+	for _, x := range Scopes {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+type token struct {
+	ID       string    `bson:"_id"`
+	Account  string    `bson:"account"`
+	Name     string    `bson:"name"`
+	Hash     string    `bson:"hash"`
+	Prefix   string    `bson:"prefix"`
+	Scopes   []string  `bson:"scopes"`
+	Created  time.Time `bson:"created"`
+	Expires  time.Time `bson:"expires,omitempty"`
+	LastUsed time.Time `bson:"last_used,omitempty"`
+}
+
+func (t *token) proto() *Token {
+	x := &Token{
+		Id:      t.ID,
+		Account: t.Account,
+		Name:    t.Name,
+		Scopes:  t.Scopes,
+		Prefix:  t.Prefix,
+		Created: t.Created.Unix(),
+	}
+
+	if !t.Expires.IsZero() {
+		x.Expires = t.Expires.Unix()
+	}
+
+	if !t.LastUsed.IsZero() {
+		x.LastUsed = t.LastUsed.Unix()
+	}
+
+	return x
+}
+
+func (s *service) CreateToken(ctx context.Context, req *CreateTokenRequest) (*CreateTokenResponse, error) {
+	if req.Account == "" {
+		return nil, status.Error(codes.InvalidArgument, "account required")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name required")
+	}
+
+	if len(req.Scopes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "scopes required")
+	}
+
+	for _, x := range req.Scopes {
+		if !ValidScope(x) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown scope %q", x)
+		}
+	}
+
+	now := time.Now()
+
+	var expires time.Time
+	if req.Expires != 0 {
+		expires = time.Unix(req.Expires, 0)
+
+		if !expires.After(now) {
+			return nil, status.Error(codes.InvalidArgument, "expiry must be in the future")
+		}
+	}
+
+	n, err := s.db.C(usersCol).Find(userQuery(req.Account)).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	secret := TokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	t := &token{
+		ID:      uuid.NewV4().String(),
+		Account: req.Account,
+		Name:    name,
+		Hash:    hashToken(secret),
+		Prefix:  secret[:tokenPrefixLen],
+		Scopes:  req.Scopes,
+		Created: now,
+		Expires: expires,
+	}
+
+	if err := s.db.C(tokensCol).Insert(t); err != nil {
+		return nil, err
+	}
+
+	return &CreateTokenResponse{
+		Token:  t.proto(),
+		Secret: secret,
+	}, nil
+}
+
+func (s *service) ListTokens(ctx context.Context, req *ListTokensRequest) (*ListTokensResponse, error) {
+	if req.Account == "" {
+		return nil, status.Error(codes.InvalidArgument, "account required")
+	}
+
+	var docs []*token
+	if err := s.db.C(tokensCol).Find(bson.M{"account": req.Account}).Sort("created").All(&docs); err != nil {
+		return nil, err
+	}
+
+	list := make([]*Token, len(docs))
+	for i, t := range docs {
+		list[i] = t.proto()
+	}
+
+	return &ListTokensResponse{
+		Tokens: list,
+	}, nil
+}
+
+func (s *service) RevokeToken(ctx context.Context, req *RevokeTokenRequest) (*RevokeTokenResponse, error) {
+	if req.Account == "" || req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "account and id required")
+	}
+
+	err := s.db.C(tokensCol).Remove(bson.M{
+		"_id":     req.Id,
+		"account": req.Account,
+	})
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "token not found")
+		}
+
+		return nil, err
+	}
+
+	return &RevokeTokenResponse{}, nil
+}
+
+// VerifyToken returns the token and user of the secret and records the
+// use. Unknown, revoked and expired tokens are Unauthenticated.
+func (s *service) VerifyToken(ctx context.Context, req *VerifyTokenRequest) (*VerifyTokenResponse, error) {
+	if !strings.HasPrefix(req.Secret, TokenPrefix) {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	var t token
+	if err := s.db.C(tokensCol).Find(bson.M{"hash": hashToken(req.Secret)}).One(&t); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if !t.Expires.IsZero() && now.After(t.Expires) {
+		return nil, status.Error(codes.Unauthenticated, "token expired")
+	}
+
+	var user User
+	if err := s.db.C(usersCol).Find(userQuery(t.Account)).One(&user); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		return nil, err
+	}
+
+	// Limit the writes of frequently used tokens.
+	if now.Sub(t.LastUsed) > tokenUseInterval {
+		t.LastUsed = now
+
+		err := s.db.C(tokensCol).UpdateId(t.ID, bson.M{
+			"$set": bson.M{
+				"last_used": now,
+			},
+		})
+		if err != nil && err != mgo.ErrNotFound {
+			return nil, err
+		}
+	}
+
+	return &VerifyTokenResponse{
+		Token: t.proto(),
+		User:  userResponse(&user),
+	}, nil
+}
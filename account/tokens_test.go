@@ -0,0 +1,227 @@
+package account
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/golang/protobuf/proto"
+	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/internal/mongotest"
+)
+
+// testTransport records the published events.
+type testTransport struct {
+	transport.Transport
+
+	events []*commitlog.Event
+}
+
+func (t *testTransport) Publish(subj string, msg proto.Message) (*transport.Message, error) {
+	t.events = append(t.events, msg.(*commitlog.Event))
+	return &transport.Message{}, nil
+}
+
+// testService returns a service on a scratch database and a function that
+// drops it.
+func testService(t *testing.T) (*service, func()) {
+	db, drop := mongotest.DB(t, "account")
+
+	if err := ensureIndexes(db); err != nil {
+		drop()
+		t.Fatal(err)
+	}
+
+	s := &service{
+		db: db,
+		tp: &testTransport{},
+	}
+
+	return s, func() {
+		drop()
+	}
+}
+
+func TestValidScope(t *testing.T) {
+	for _, s := range Scopes {
+		if !ValidScope(s) {
+			t.Errorf("expected %s to be valid", s)
+		}
+	}
+
+	if ValidScope("projects:admin") {
+		t.Error("expected unknown scope to be invalid")
+	}
+}
+
+func TestTokens(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, &CreateUserRequest{
+		Issuer:  "https://idp.example.com",
+		Subject: "1",
+		Email:   "joe@example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := s.CreateToken(ctx, &CreateTokenRequest{
+		Account: user.Id,
+		Name:    "ci",
+		Scopes:  []string{ScopeProjectsRead},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(rep.Secret, rep.Token.Prefix) {
+		t.Errorf("expected the prefix of the secret, got %s", rep.Token.Prefix)
+	}
+
+	// Only the hash of the secret is stored.
+	var x token
+	if err := s.db.C(tokensCol).FindId(rep.Token.Id).One(&x); err != nil {
+		t.Fatal(err)
+	}
+	if x.Hash != hashToken(rep.Secret) || strings.Contains(x.Hash, rep.Secret) {
+		t.Error("expected the hash of the secret to be stored")
+	}
+
+	v, err := s.VerifyToken(ctx, &VerifyTokenRequest{Secret: rep.Secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.User.Id != user.Id || v.Token.Id != rep.Token.Id {
+		t.Errorf("expected token %s of %s, got %s of %s", rep.Token.Id, user.Id, v.Token.Id, v.User.Id)
+	}
+	if v.Token.LastUsed == 0 {
+		t.Error("expected the use to be recorded")
+	}
+
+	for name, secret := range map[string]string{
+		"unknown":   TokenPrefix + "unknown",
+		"no prefix": strings.TrimPrefix(rep.Secret, TokenPrefix),
+	} {
+		if _, err := s.VerifyToken(ctx, &VerifyTokenRequest{Secret: secret}); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: expected Unauthenticated, got %v", name, err)
+		}
+	}
+
+	// Only the owner can revoke the token.
+	if _, err := s.RevokeToken(ctx, &RevokeTokenRequest{Account: "other", Id: rep.Token.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+
+	if _, err := s.RevokeToken(ctx, &RevokeTokenRequest{Account: user.Id, Id: rep.Token.Id}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.VerifyToken(ctx, &VerifyTokenRequest{Secret: rep.Secret}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("revoked: expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, &CreateUserRequest{
+		Issuer:  "https://idp.example.com",
+		Subject: "1",
+		Email:   "joe@example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.CreateToken(ctx, &CreateTokenRequest{
+		Account: user.Id,
+		Name:    "past",
+		Scopes:  []string{ScopeProjectsRead},
+		Expires: time.Now().Add(-time.Minute).Unix(),
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an expiry in the past, got %v", err)
+	}
+
+	rep, err := s.CreateToken(ctx, &CreateTokenRequest{
+		Account: user.Id,
+		Name:    "ci",
+		Scopes:  []string{ScopeProjectsRead},
+		Expires: time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.VerifyToken(ctx, &VerifyTokenRequest{Secret: rep.Secret}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.db.C(tokensCol).UpdateId(rep.Token.Id, bson.M{
+		"$set": bson.M{
+			"expires": time.Now().Add(-time.Second),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.VerifyToken(ctx, &VerifyTokenRequest{Secret: rep.Secret}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expired: expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestTokenDeletedUser(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	if _, err := s.CreateToken(ctx, &CreateTokenRequest{
+		Account: "missing",
+		Name:    "ci",
+		Scopes:  []string{ScopeProjectsRead},
+	}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown user, got %v", err)
+	}
+
+	user, err := s.CreateUser(ctx, &CreateUserRequest{
+		Issuer:  "https://idp.example.com",
+		Subject: "1",
+		Email:   "joe@example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := s.CreateToken(ctx, &CreateTokenRequest{
+		Account: user.Id,
+		Name:    "ci",
+		Scopes:  []string{ScopeProjectsRead},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The token outlives the user, e.g. if the deletion was interrupted.
+	if err := s.db.C(usersCol).RemoveId(user.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.VerifyToken(ctx, &VerifyTokenRequest{Secret: rep.Secret}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("deleted user: expected Unauthenticated, got %v", err)
+	}
+}
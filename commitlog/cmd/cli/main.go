@@ -117,6 +117,22 @@ func main() {
 		}
 		rep, err = client.Pending(ctx, &req)
 
+	case "Events":
+		client := commitlog.NewServiceClient(tp)
+		var req commitlog.EventsRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.Events(ctx, &req)
+
+	case "Replay":
+		client := commitlog.NewServiceClient(tp)
+		var req commitlog.ReplayRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.Replay(ctx, &req)
+
 	default:
 		log.Fatalf("unknown method %s", meth)
 	}
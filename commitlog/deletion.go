@@ -0,0 +1,66 @@
+package commitlog
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+)
+
+// Subjects the services acknowledge the project.deleted and account.deleted
+// events on once they removed the associated data. They are outside of
+// events.> so the acknowledgements are not recorded.
+const (
+	ProjectAckSubject = "acks.project"
+	AccountAckSubject = "acks.account"
+)
+
+// Services that remove data of deleted projects and accounts. The record
+// of a project or account is kept, and the event published again, until
+// all of them acknowledged the deletion.
+var (
+	ProjectDeletedAcks = []string{"commitlog", "data", "nodes", "webhook"}
+	AccountDeletedAcks = []string{"commitlog", "data", "project", "webhook"}
+)
+
+type ackData struct {
+	Service string `json:"service"`
+}
+
+// Ack publishes that the service handled the project.deleted or
+// account.deleted event. Other events are ignored.
+func Ack(tp transport.Transport, service string, e *Event) error {
+	var subj string
+	switch e.Type {
+	case "project.deleted":
+		subj = ProjectAckSubject
+	case "account.deleted":
+		subj = AccountAckSubject
+	default:
+		return nil
+	}
+
+	b, err := json.Marshal(&ackData{Service: service})
+	if err != nil {
+		return err
+	}
+
+	_, err = tp.Publish(subj, &Event{
+		Id:      NewEventID(),
+		Project: e.Project,
+		Time:    time.Now().Unix(),
+		Type:    e.Type,
+		Author:  e.Author,
+		Data:    b,
+	})
+	return err
+}
+
+// AckService returns the service that published the acknowledgement.
+func AckService(e *Event) string {
+	var d ackData
+	if err := json.Unmarshal(e.Data, &d); err != nil {
+		return ""
+	}
+	return d.Service
+}
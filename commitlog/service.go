@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
@@ -19,12 +20,58 @@ import (
 
 const eventSubject = "events.>"
 
+// CommitSubject is the subject commits are published on as events of type
+// commit.created. It is outside of the event subjects so commits are not
+// logged as events themselves.
+const CommitSubject = "commits.project"
+
+// commitEventData is the data of a commit.created event.
+type commitEventData struct {
+	ID     string `json:"id"`
+	Msg    string `json:"msg"`
+	Parent string `json:"parent"`
+}
+
+// Default and maximum number of events returned by Events.
+const (
+	defaultEventsLimit = 100
+	maxEventsLimit     = 1000
+)
+
+// NewEventID returns an id for an event. Publishers assign it, rather than
+// the commitlog, so subscribers streaming the events see the id the event
+// is listed with.
+func NewEventID() string {
+	return bson.NewObjectId().Hex()
+}
+
+// dbEvent is a recorded event. The id is assigned on insert so events are
+// ordered as they are recorded, which the commits rely on. The id the
+// event was published with is kept separately.
 type dbEvent struct {
-	ID     bson.ObjectId          `bson:"_id"`
-	Time   int64                  `bson:"time"`
-	Type   string                 `bson:"type"`
-	Author string                 `bson:"author"`
-	Data   map[string]interface{} `bson:"data"`
+	ID      bson.ObjectId          `bson:"_id"`
+	EventID string                 `bson:"event_id,omitempty"`
+	Time    int64                  `bson:"time"`
+	Type    string                 `bson:"type"`
+	Author  string                 `bson:"author"`
+	Data    map[string]interface{} `bson:"data"`
+}
+
+// id returns the id the event was published with, or the recorded id of
+// events published without one, e.g. replayed events.
+func (e *dbEvent) id() string {
+	if e.EventID != "" {
+		return e.EventID
+	}
+	return e.ID.Hex()
+}
+
+// eventIndex makes redelivered events fail to insert and resolves the
+// published ids.
+var eventIndex = mgo.Index{
+	Key:    []string{"event_id"},
+	Unique: true,
+	Sparse: true,
 }
 
 type dbCommit struct {
@@ -38,13 +85,34 @@ type dbCommit struct {
 
 // newEventHandler initializes a handler taking events from a stream
 // and recording them into project-specific collections.
-func newEventHandler(db *mgo.Database) transport.Handler {
+func newEventHandler(tp transport.Transport, db *mgo.Database) transport.Handler {
 	return func(msg *transport.Message) (proto.Message, error) {
 		var event Event
 		if err := msg.Decode(&event); err != nil {
 			return nil, err
 		}
 
+		switch event.Type {
+		// The project was purged so its history is removed.
+		case "project.deleted":
+			if err := dropProject(db, event.Project); err != nil {
+				return nil, err
+			}
+			return nil, Ack(tp, "commitlog", &event)
+
+		// References to the deleted account are removed.
+		case "account.deleted":
+			if err := anonymizeAuthor(db, event.Author); err != nil {
+				return nil, err
+			}
+			return nil, Ack(tp, "commitlog", &event)
+		}
+
+		// Only project events are recorded.
+		if event.Project == "" {
+			return nil, nil
+		}
+
 		var data map[string]interface{}
 		if event.Data != nil {
 			if err := json.Unmarshal(event.Data, &data); err != nil {
@@ -53,15 +121,26 @@ func newEventHandler(db *mgo.Database) transport.Handler {
 		}
 
 		e := &dbEvent{
-			ID:     bson.NewObjectId(),
-			Time:   event.Time,
-			Type:   event.Type,
-			Author: event.Author,
-			Data:   data,
+			ID:      bson.NewObjectId(),
+			EventID: event.Id,
+			Time:    event.Time,
+			Type:    event.Type,
+			Author:  event.Author,
+			Data:    data,
+		}
+
+		col := db.C(fmt.Sprintf("%s_events", event.Project))
+
+		// Cached by the session after the first call.
+		if err := col.EnsureIndex(eventIndex); err != nil {
+			return nil, err
 		}
 
-		eventCol := fmt.Sprintf("%s_events", event.Project)
-		if err := db.C(eventCol).Insert(e); err != nil {
+		if err := col.Insert(e); err != nil {
+			// Redelivered event.
+			if mgo.IsDup(err) {
+				return nil, nil
+			}
 			return nil, err
 		}
 
@@ -69,6 +148,55 @@ func newEventHandler(db *mgo.Database) transport.Handler {
 	}
 }
 
+// dropProject drops the event and commit collections of the project.
+func dropProject(db *mgo.Database, project string) error {
+	for _, col := range []string{
+		fmt.Sprintf("%s_events", project),
+		fmt.Sprintf("%s_commit", project),
+	} {
+		// The collection does not exist if nothing was logged.
+		if err := db.C(col).DropCollection(); err != nil && err.Error() != "ns not found" {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// anonymizeAuthor removes the author from all events and commits.
+func anonymizeAuthor(db *mgo.Database, author string) error {
+	if author == "" {
+		return nil
+	}
+
+	cols, err := db.CollectionNames()
+	if err != nil {
+		return err
+	}
+
+	q := bson.M{
+		"author": author,
+	}
+
+	u := bson.M{
+		"$set": bson.M{
+			"author": "",
+		},
+	}
+
+	for _, col := range cols {
+		if !strings.HasSuffix(col, "_events") && !strings.HasSuffix(col, "_commit") {
+			continue
+		}
+
+		if _, err := db.C(col).UpdateAll(q, u); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type service struct {
 	db *mgo.Database
 	tp transport.Transport
@@ -149,11 +277,38 @@ func (s *service) Commit(ctx context.Context, req *CommitRequest) (*CommitReply,
 		return nil, fmt.Errorf("commit insert failed: %s", err)
 	}
 
+	s.publishCommit(req.Project, &c)
+
 	return &CommitReply{
 		Id: c.ID.Hex(),
 	}, nil
 }
 
+// publishCommit notifies subscribers, e.g. webhooks, of the commit.
+func (s *service) publishCommit(project string, c *dbCommit) {
+	b, err := json.Marshal(&commitEventData{
+		ID:     c.ID.Hex(),
+		Msg:    c.Msg,
+		Parent: c.Parent,
+	})
+	if err != nil {
+		log.Printf("commitlog: data encoding error: %s", err)
+		return
+	}
+
+	_, err = s.tp.Publish(CommitSubject, &Event{
+		Project: project,
+		Id:      c.ID.Hex(),
+		Time:    c.Time,
+		Type:    "commit.created",
+		Author:  c.Author,
+		Data:    b,
+	})
+	if err != nil {
+		log.Printf("commitlog: publish error: %s", err)
+	}
+}
+
 func (s *service) History(ctx context.Context, req *HistoryRequest) (*HistoryReply, error) {
 	if req.Project == "" {
 		return nil, status.Error(codes.InvalidArgument, "project required")
@@ -256,7 +411,7 @@ func (s *service) History(ctx context.Context, req *HistoryRequest) (*HistoryRep
 		}
 
 		cm.Events[i] = &Event{
-			Id:     e.ID.Hex(),
+			Id:     e.id(),
 			Time:   e.Time,
 			Type:   e.Type,
 			Author: e.Author,
@@ -326,7 +481,7 @@ func (s *service) Pending(ctx context.Context, req *PendingRequest) (*PendingRep
 		}
 
 		events[i] = &Event{
-			Id:     e.ID.Hex(),
+			Id:     e.id(),
 			Time:   e.Time,
 			Type:   e.Type,
 			Author: e.Author,
@@ -339,9 +494,181 @@ func (s *service) Pending(ctx context.Context, req *PendingRequest) (*PendingRep
 	}, nil
 }
 
+func (s *service) Events(ctx context.Context, req *EventsRequest) (*EventsReply, error) {
+	if req.Project == "" {
+		return nil, status.Error(codes.InvalidArgument, "project required")
+	}
+
+	eventCol := fmt.Sprintf("%s_events", req.Project)
+
+	var q bson.M
+	if req.After != "" {
+		after, err := s.recordedID(eventCol, req.After)
+		if err != nil {
+			return nil, err
+		}
+
+		q = bson.M{
+			"_id": bson.M{
+				"$gt": after,
+			},
+		}
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultEventsLimit
+	} else if limit > maxEventsLimit {
+		limit = maxEventsLimit
+	}
+
+	// One more to tell if there are more events.
+	var dbEvents []*dbEvent
+	err := s.db.C(eventCol).
+		Find(q).
+		Sort("_id").
+		Limit(limit + 1).
+		All(&dbEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(dbEvents) > limit
+	if more {
+		dbEvents = dbEvents[:limit]
+	}
+
+	events := make([]*Event, len(dbEvents))
+
+	for i, e := range dbEvents {
+		var b []byte
+		if e.Data != nil {
+			b, _ = json.Marshal(e.Data)
+		}
+
+		events[i] = &Event{
+			Project: req.Project,
+			Id:      e.id(),
+			Time:    e.Time,
+			Type:    e.Type,
+			Author:  e.Author,
+			Data:    b,
+		}
+	}
+
+	return &EventsReply{
+		Events: events,
+		More:   more,
+	}, nil
+}
+
+// recordedID returns the recorded id of the event with the published id,
+// or the recorded id of an event published without one.
+func (s *service) recordedID(eventCol, id string) (bson.ObjectId, error) {
+	q := bson.M{"event_id": id}
+	if bson.IsObjectIdHex(id) {
+		q = bson.M{
+			"$or": []bson.M{
+				{"event_id": id},
+				{"_id": bson.ObjectIdHex(id), "event_id": bson.M{"$exists": false}},
+			},
+		}
+	}
+
+	var e dbEvent
+	err := s.db.C(eventCol).
+		Find(q).
+		Select(bson.M{"_id": 1}).
+		One(&e)
+
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return "", status.Error(codes.NotFound, "event not found")
+		}
+		return "", err
+	}
+
+	return e.ID, nil
+}
+
+func (s *service) Replay(ctx context.Context, req *ReplayRequest) (*ReplayReply, error) {
+	if req.Project == "" {
+		return nil, status.Error(codes.InvalidArgument, "project required")
+	}
+
+	eventCol := fmt.Sprintf("%s_events", req.Project)
+	commitCol := fmt.Sprintf("%s_commit", req.Project)
+
+	n, err := s.db.C(commitCol).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	if n > 0 {
+		return nil, status.Error(codes.FailedPrecondition, "project already has commits")
+	}
+
+	// The replayed events supersede the pending ones.
+	if _, err := s.db.C(eventCol).RemoveAll(nil); err != nil {
+		return nil, err
+	}
+
+	var parent string
+
+	for _, c := range req.Commits {
+		// A commit must reference its latest event.
+		if len(c.Events) == 0 {
+			continue
+		}
+
+		var last bson.ObjectId
+
+		for _, event := range c.Events {
+			var data map[string]interface{}
+			if event.Data != nil {
+				if err := json.Unmarshal(event.Data, &data); err != nil {
+					return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid event data: %s", err))
+				}
+			}
+
+			e := &dbEvent{
+				ID:     bson.NewObjectId(),
+				Time:   event.Time,
+				Type:   event.Type,
+				Author: event.Author,
+				Data:   data,
+			}
+
+			if err := s.db.C(eventCol).Insert(e); err != nil {
+				return nil, err
+			}
+
+			last = e.ID
+		}
+
+		dc := dbCommit{
+			ID:     bson.NewObjectId(),
+			Time:   c.Time,
+			Author: c.Author,
+			Msg:    c.Msg,
+			Event:  last,
+			Parent: parent,
+		}
+
+		if err := s.db.C(commitCol).Insert(dc); err != nil {
+			return nil, fmt.Errorf("commit insert failed: %s", err)
+		}
+
+		parent = dc.ID.Hex()
+	}
+
+	return &ReplayReply{}, nil
+}
+
 func NewService(tp transport.Transport, db *mgo.Database) (Service, error) {
 	// This will be auto-sunscribed when the transport is closed.
-	_, err := tp.Subscribe(eventSubject, newEventHandler(db))
+	_, err := tp.Subscribe(eventSubject, newEventHandler(tp, db))
 	if err != nil {
 		return nil, err
 	}
@@ -17,6 +17,10 @@ It has these top-level messages:
 	HistoryReply
 	PendingRequest
 	PendingReply
+	EventsRequest
+	EventsReply
+	ReplayRequest
+	ReplayReply
 */
 package commitlog
 
@@ -279,6 +283,105 @@ func (m *PendingReply) GetEvents() []*Event {
 	return nil
 }
 
+// EventsRequest gets the events of a project logged after an event,
+// regardless of whether they are committed.
+type EventsRequest struct {
+	Project string `protobuf:"bytes,1,opt,name=project" json:"project,omitempty"`
+	// The id of the event to start after, as it was published. If not
+	// specified, the events are fetched from the first one.
+	After string `protobuf:"bytes,2,opt,name=after" json:"after,omitempty"`
+	// Maximum number of events to fetch.
+	Limit int32 `protobuf:"varint,3,opt,name=limit" json:"limit,omitempty"`
+}
+
+func (m *EventsRequest) Reset()                    { *m = EventsRequest{} }
+func (m *EventsRequest) String() string            { return proto.CompactTextString(m) }
+func (*EventsRequest) ProtoMessage()               {}
+func (*EventsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *EventsRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *EventsRequest) GetAfter() string {
+	if m != nil {
+		return m.After
+	}
+	return ""
+}
+
+func (m *EventsRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type EventsReply struct {
+	// The events ordered from oldest to latest.
+	Events []*Event `protobuf:"bytes,1,rep,name=events" json:"events,omitempty"`
+	// True if there are more events than the limit.
+	More bool `protobuf:"varint,2,opt,name=more" json:"more,omitempty"`
+}
+
+func (m *EventsReply) Reset()                    { *m = EventsReply{} }
+func (m *EventsReply) String() string            { return proto.CompactTextString(m) }
+func (*EventsReply) ProtoMessage()               {}
+func (*EventsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *EventsReply) GetEvents() []*Event {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *EventsReply) GetMore() bool {
+	if m != nil {
+		return m.More
+	}
+	return false
+}
+
+// ReplayRequest restores the history of a project which has no commits.
+// Pending events are replaced by the replayed ones.
+type ReplayRequest struct {
+	Project string `protobuf:"bytes,1,opt,name=project" json:"project,omitempty"`
+	// The commits ordered from oldest to latest. The events of each commit
+	// are ordered from oldest to latest as well.
+	Commits []*Commit `protobuf:"bytes,2,rep,name=commits" json:"commits,omitempty"`
+}
+
+func (m *ReplayRequest) Reset()                    { *m = ReplayRequest{} }
+func (m *ReplayRequest) String() string            { return proto.CompactTextString(m) }
+func (*ReplayRequest) ProtoMessage()               {}
+func (*ReplayRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *ReplayRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *ReplayRequest) GetCommits() []*Commit {
+	if m != nil {
+		return m.Commits
+	}
+	return nil
+}
+
+type ReplayReply struct {
+}
+
+func (m *ReplayReply) Reset()                    { *m = ReplayReply{} }
+func (m *ReplayReply) String() string            { return proto.CompactTextString(m) }
+func (*ReplayReply) ProtoMessage()               {}
+func (*ReplayReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
 func init() {
 	proto.RegisterType((*Commit)(nil), "commitlog.Commit")
 	proto.RegisterType((*Event)(nil), "commitlog.Event")
@@ -288,34 +391,44 @@ func init() {
 	proto.RegisterType((*HistoryReply)(nil), "commitlog.HistoryReply")
 	proto.RegisterType((*PendingRequest)(nil), "commitlog.PendingRequest")
 	proto.RegisterType((*PendingReply)(nil), "commitlog.PendingReply")
+	proto.RegisterType((*EventsRequest)(nil), "commitlog.EventsRequest")
+	proto.RegisterType((*EventsReply)(nil), "commitlog.EventsReply")
+	proto.RegisterType((*ReplayRequest)(nil), "commitlog.ReplayRequest")
+	proto.RegisterType((*ReplayReply)(nil), "commitlog.ReplayReply")
 }
 
 func init() { proto.RegisterFile("service.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 383 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x53, 0xcb, 0x4e, 0xeb, 0x30,
-	0x10, 0x95, 0x93, 0x34, 0x55, 0xa7, 0x0f, 0xf5, 0x7a, 0xd1, 0xeb, 0x5b, 0xe9, 0x4a, 0x91, 0x57,
-	0x81, 0x45, 0x17, 0x65, 0x83, 0x90, 0xd8, 0x80, 0x90, 0xd8, 0x20, 0xa1, 0xf4, 0x0b, 0x42, 0x63,
-	0x15, 0xa3, 0x26, 0x0e, 0x89, 0x5b, 0xd1, 0x0f, 0xe0, 0x1f, 0xf8, 0x1f, 0x7e, 0x0c, 0xf9, 0x91,
-	0x34, 0x29, 0x55, 0x61, 0x37, 0xe3, 0x99, 0x39, 0xe7, 0xcc, 0xc9, 0x04, 0x86, 0x25, 0x2b, 0xb6,
-	0x7c, 0xc9, 0x66, 0x79, 0x21, 0xa4, 0xc0, 0xbd, 0xa5, 0x48, 0x53, 0x2e, 0xd7, 0x62, 0x45, 0x3f,
-	0x10, 0xf8, 0xb7, 0x3a, 0xc3, 0x23, 0x70, 0x78, 0x42, 0x50, 0x80, 0xc2, 0x5e, 0xe4, 0xf0, 0x04,
-	0x8f, 0xc1, 0x4d, 0xcb, 0x15, 0x71, 0xf4, 0x83, 0x0a, 0xf1, 0x04, 0xfc, 0x78, 0x23, 0x9f, 0x45,
-	0x41, 0x5c, 0xfd, 0x68, 0x33, 0x8c, 0xc1, 0x93, 0x3c, 0x65, 0xc4, 0x0b, 0x50, 0xe8, 0x46, 0x3a,
-	0xc6, 0x21, 0xf8, 0x6c, 0xcb, 0x32, 0x59, 0x92, 0x4e, 0xe0, 0x86, 0xfd, 0xf9, 0x78, 0x56, 0x93,
-	0xce, 0xee, 0x54, 0x21, 0xb2, 0x75, 0x85, 0x9a, 0xc7, 0x05, 0xcb, 0x24, 0xf1, 0x0d, 0xaa, 0xc9,
-	0xe8, 0x3b, 0x82, 0x8e, 0xee, 0xc4, 0x04, 0xba, 0x79, 0x21, 0x5e, 0xd8, 0x52, 0x5a, 0x79, 0x55,
-	0x6a, 0x35, 0xfb, 0xb5, 0xe6, 0x4a, 0x89, 0xd3, 0x50, 0xa2, 0xde, 0x76, 0x39, 0xb3, 0x9a, 0x75,
-	0xdc, 0xd8, 0xc4, 0x3b, 0xdc, 0x24, 0x89, 0x65, 0x4c, 0x3a, 0x01, 0x0a, 0x07, 0x91, 0x8e, 0xe9,
-	0x02, 0x86, 0xc6, 0xa1, 0x88, 0xbd, 0x6e, 0x58, 0x79, 0x4a, 0xce, 0x1e, 0xd6, 0x69, 0xc1, 0x5a,
-	0x2b, 0xdd, 0xda, 0x4a, 0xfa, 0x1f, 0xfa, 0x15, 0x68, 0xbe, 0xde, 0x1d, 0x7a, 0x4f, 0x6f, 0x60,
-	0x74, 0xcf, 0x4b, 0x29, 0x8a, 0xdd, 0xaf, 0x48, 0x8d, 0xb5, 0x15, 0xa9, 0xc9, 0xe8, 0x03, 0x0c,
-	0x6a, 0x0c, 0xc5, 0x81, 0xc1, 0xcb, 0xd8, 0x5b, 0x35, 0xae, 0x63, 0x7c, 0xd6, 0x9a, 0xed, 0xcf,
-	0xff, 0x34, 0xbe, 0x92, 0xd5, 0x57, 0xc1, 0x9d, 0xc3, 0xe8, 0x91, 0x65, 0x09, 0xcf, 0x56, 0x3f,
-	0x4a, 0xa2, 0x97, 0x30, 0xa8, 0x7b, 0x15, 0xf5, 0xfe, 0x18, 0xd0, 0xe9, 0x63, 0x98, 0x7f, 0x22,
-	0xe8, 0x2e, 0xcc, 0xb1, 0xe2, 0xab, 0xfa, 0x34, 0xc9, 0x77, 0x59, 0x46, 0xc3, 0x74, 0x72, 0xa4,
-	0xa2, 0x18, 0xaf, 0xa1, 0x6b, 0x97, 0xc7, 0xff, 0x1a, 0x2d, 0x6d, 0x53, 0xa7, 0x7f, 0x8f, 0x95,
-	0xec, 0xb8, 0x5d, 0xa0, 0x35, 0xde, 0x36, 0xa0, 0x35, 0xde, 0xdc, 0xf7, 0xc9, 0xd7, 0xff, 0xd9,
-	0xc5, 0x57, 0x00, 0x00, 0x00, 0xff, 0xff, 0xb1, 0x45, 0xd6, 0x3e, 0x78, 0x03, 0x00, 0x00,
+	// 480 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x8a, 0xd4, 0x40,
+	0x10, 0x26, 0xc9, 0x24, 0xe3, 0x56, 0x26, 0xc3, 0xda, 0xc8, 0x6c, 0x1b, 0x10, 0x42, 0x9f, 0xa2,
+	0xc2, 0x1c, 0xc6, 0x8b, 0x2c, 0x78, 0x51, 0x04, 0x41, 0x04, 0xc9, 0xa2, 0xf7, 0x38, 0xd3, 0x8e,
+	0x2d, 0x49, 0x3a, 0x26, 0xbd, 0x8b, 0x79, 0x00, 0xdf, 0xc1, 0x77, 0xf1, 0xe5, 0xa4, 0xff, 0xb2,
+	0xe9, 0x35, 0xec, 0xae, 0xb7, 0xfa, 0xe9, 0xfa, 0xea, 0xab, 0xaf, 0x2a, 0x81, 0xa4, 0xa7, 0xdd,
+	0x15, 0xdb, 0xd3, 0x6d, 0xdb, 0x71, 0xc1, 0xd1, 0xc9, 0x9e, 0xd7, 0x35, 0x13, 0x15, 0x3f, 0x92,
+	0xdf, 0x1e, 0x44, 0x6f, 0x94, 0x87, 0xd6, 0xe0, 0xb3, 0x03, 0xf6, 0x32, 0x2f, 0x3f, 0x29, 0x7c,
+	0x76, 0x40, 0xa7, 0x10, 0xd4, 0xfd, 0x11, 0xfb, 0x2a, 0x20, 0x4d, 0xb4, 0x81, 0xa8, 0xbc, 0x14,
+	0xdf, 0x78, 0x87, 0x03, 0x15, 0x34, 0x1e, 0x42, 0xb0, 0x10, 0xac, 0xa6, 0x78, 0x91, 0x79, 0x79,
+	0x50, 0x28, 0x1b, 0xe5, 0x10, 0xd1, 0x2b, 0xda, 0x88, 0x1e, 0x87, 0x59, 0x90, 0xc7, 0xbb, 0xd3,
+	0xed, 0xd8, 0x74, 0xfb, 0x56, 0x26, 0x0a, 0x93, 0x97, 0xa8, 0x6d, 0xd9, 0xd1, 0x46, 0xe0, 0x48,
+	0xa3, 0x6a, 0x8f, 0xfc, 0xf2, 0x20, 0x54, 0x2f, 0x11, 0x86, 0x65, 0xdb, 0xf1, 0xef, 0x74, 0x2f,
+	0x0c, 0x3d, 0xeb, 0x1a, 0xce, 0xd1, 0xc8, 0xd9, 0x32, 0xf1, 0x27, 0x4c, 0x64, 0x6c, 0x68, 0xa9,
+	0xe1, 0xac, 0xec, 0xc9, 0x24, 0x8b, 0x9b, 0x93, 0x1c, 0x4a, 0x51, 0xe2, 0x30, 0xf3, 0xf2, 0x55,
+	0xa1, 0x6c, 0x72, 0x01, 0x89, 0x56, 0xa8, 0xa0, 0x3f, 0x2e, 0x69, 0x7f, 0x1b, 0x9d, 0x6b, 0x58,
+	0xdf, 0x81, 0x35, 0x52, 0x06, 0xa3, 0x94, 0xe4, 0x09, 0xc4, 0x16, 0xb4, 0xad, 0x86, 0x9b, 0xda,
+	0x93, 0xd7, 0xb0, 0x7e, 0xc7, 0x7a, 0xc1, 0xbb, 0xe1, 0x5e, 0x4d, 0xb5, 0xb4, 0xb6, 0xa9, 0xf6,
+	0xc8, 0x07, 0x58, 0x8d, 0x18, 0xb2, 0x07, 0x82, 0x45, 0x43, 0x7f, 0xda, 0x72, 0x65, 0xa3, 0xa7,
+	0x4e, 0x6d, 0xbc, 0x7b, 0x38, 0xd9, 0x92, 0xe1, 0x67, 0xe1, 0x9e, 0xc1, 0xfa, 0x23, 0x6d, 0x0e,
+	0xac, 0x39, 0xde, 0x49, 0x89, 0xbc, 0x84, 0xd5, 0xf8, 0x56, 0xb6, 0xbe, 0x3e, 0x06, 0xef, 0xf6,
+	0x63, 0x20, 0x9f, 0x20, 0x51, 0x81, 0xfe, 0xee, 0xb9, 0x1f, 0x41, 0x58, 0x7e, 0x15, 0xd4, 0x6a,
+	0xad, 0x1d, 0x19, 0xad, 0x98, 0x1c, 0x48, 0x8a, 0x1d, 0x16, 0xda, 0x21, 0xef, 0x21, 0xb6, 0xb0,
+	0xff, 0xc5, 0x47, 0x8a, 0x56, 0xf3, 0x4e, 0x1f, 0xd4, 0x83, 0x42, 0xd9, 0xe4, 0x33, 0x24, 0x12,
+	0xa6, 0xbc, 0xc7, 0x6e, 0x9e, 0xc3, 0x52, 0x23, 0xf7, 0xd8, 0xcf, 0x82, 0x79, 0x81, 0xed, 0x0b,
+	0x92, 0x40, 0x6c, 0x71, 0xdb, 0x6a, 0xd8, 0xfd, 0xf1, 0x61, 0x79, 0xa1, 0xbf, 0x5b, 0x74, 0x3e,
+	0x7e, 0xa5, 0xf8, 0x5f, 0x00, 0xcd, 0x22, 0xdd, 0xcc, 0x64, 0xe4, 0xb0, 0xaf, 0x60, 0x69, 0xee,
+	0x00, 0x3d, 0x9e, 0x3c, 0x71, 0xef, 0x2b, 0x3d, 0x9b, 0x4b, 0x99, 0x72, 0xb3, 0x4b, 0xa7, 0xdc,
+	0xbd, 0x85, 0xf4, 0x6c, 0x2e, 0x25, 0xcb, 0xcf, 0x21, 0xd2, 0xca, 0x3b, 0xcc, 0x9d, 0x1d, 0xa7,
+	0x9b, 0x99, 0x8c, 0xa9, 0xd5, 0x82, 0x38, 0xb5, 0x8e, 0xf6, 0xe9, 0x66, 0x26, 0xd3, 0x56, 0xc3,
+	0x97, 0x48, 0xfd, 0xea, 0x5e, 0xfc, 0x1d, 0x00, 0xe2, 0x91, 0xea, 0xaa, 0xfb, 0x04, 0x00, 0x00,
 }
@@ -18,12 +18,16 @@ type Service interface {
 	Commit(context.Context, *CommitRequest) (*CommitReply, error)
 	History(context.Context, *HistoryRequest) (*HistoryReply, error)
 	Pending(context.Context, *PendingRequest) (*PendingReply, error)
+	Events(context.Context, *EventsRequest) (*EventsReply, error)
+	Replay(context.Context, *ReplayRequest) (*ReplayReply, error)
 }
 
 type ServiceClient interface {
 	Commit(context.Context, *CommitRequest, ...transport.RequestOption) (*CommitReply, error)
 	History(context.Context, *HistoryRequest, ...transport.RequestOption) (*HistoryReply, error)
 	Pending(context.Context, *PendingRequest, ...transport.RequestOption) (*PendingReply, error)
+	Events(context.Context, *EventsRequest, ...transport.RequestOption) (*EventsReply, error)
+	Replay(context.Context, *ReplayRequest, ...transport.RequestOption) (*ReplayReply, error)
 }
 
 // serviceClient an implementation of Service client.
@@ -64,6 +68,28 @@ func (c *serviceClient) Pending(ctx context.Context, req *PendingRequest, opts .
 	return &rep, nil
 }
 
+func (c *serviceClient) Events(ctx context.Context, req *EventsRequest, opts ...transport.RequestOption) (*EventsReply, error) {
+	var rep EventsReply
+
+	_, err := c.tp.Request("commitlog.Events", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) Replay(ctx context.Context, req *ReplayRequest, opts ...transport.RequestOption) (*ReplayReply, error) {
+	var rep ReplayReply
+
+	_, err := c.tp.Request("commitlog.Replay", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
 // NewServiceClient creates a new Service client.
 func NewServiceClient(tp transport.Transport) ServiceClient {
 	return &serviceClient{tp}
@@ -128,6 +154,32 @@ func (s *ServiceServer) Serve(ctx context.Context, opts ...transport.SubscribeOp
 	if err != nil {
 		return err
 	}
+	_, err = s.tp.Subscribe("commitlog.Events", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req EventsRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Events(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("commitlog.Replay", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req ReplayRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Replay(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
 
 	sigchan := make(chan os.Signal)
 	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
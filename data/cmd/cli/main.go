@@ -133,6 +133,22 @@ func main() {
 		}
 		rep, err = client.Get(ctx, &req)
 
+	case "Usage":
+		client := data.NewServiceClient(tp)
+		var req data.UsageRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.Usage(ctx, &req)
+
+	case "Complete":
+		client := data.NewServiceClient(tp)
+		var req data.CompleteRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.Complete(ctx, &req)
+
 	default:
 		log.Fatalf("unknown method %s", meth)
 	}
@@ -8,14 +8,11 @@ import (
 
 	mgo "gopkg.in/mgo.v2"
 
-	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/chop-dbhi/nats-rpc/log"
 	"github.com/chop-dbhi/nats-rpc/transport"
 	"github.com/nats-io/go-nats"
 	"github.com/rdm-academy/api/data"
-	"github.com/rdm-academy/api/storage"
-	"github.com/rdm-academy/api/storage/aws"
-	"github.com/rdm-academy/api/storage/gcp"
+	"github.com/rdm-academy/api/storage/flags"
 
 	"go.uber.org/zap"
 )
@@ -35,15 +32,8 @@ func main() {
 
 		bucketName string
 
-		awsAccessKey    string
-		awsSecretKey    string
-		awsSessionToken string
-		awsRegion       string
-		awsProfile      string
-		awsSSE          string
-
-		gcpProjectId          string
-		gcpServiceAccountFile string
+		accountQuota int64
+		projectQuota int64
 
 		printVersion bool
 	)
@@ -53,15 +43,10 @@ func main() {
 
 	flag.StringVar(&bucketName, "bucket", "", "Bucket name.")
 
-	flag.StringVar(&awsAccessKey, "aws.access-key", "", "AWS access key.")
-	flag.StringVar(&awsSecretKey, "aws.secret-key", "", "AWS secret key.")
-	flag.StringVar(&awsSessionToken, "aws.session-token", "", "AWS token.")
-	flag.StringVar(&awsRegion, "aws.region", "us-east-1", "AWS region.")
-	flag.StringVar(&awsProfile, "aws.profile", "", "AWS profile.")
-	flag.StringVar(&awsSSE, "aws.sse", "", "AWS server-side encryption.")
+	flag.Int64Var(&accountQuota, "quota.account", 0, "Storage quota per account in bytes. Zero disables the quota.")
+	flag.Int64Var(&projectQuota, "quota.project", 0, "Storage quota per project in bytes. Zero disables the quota.")
 
-	flag.StringVar(&gcpProjectId, "gcp.project", "", "GCP project id")
-	flag.StringVar(&gcpServiceAccountFile, "gcp.service-account", "", "GCP service account file")
+	storageOpts := flags.Register(flag.CommandLine)
 
 	flag.BoolVar(&printVersion, "version", false, "Print version.")
 
@@ -98,33 +83,7 @@ func main() {
 
 	ctx := context.Background()
 
-	var stg storage.Storage
-
-	// AWS S3 or Minio.
-	if awsProfile != "" {
-		stg, err = aws.NewStorage(aws.Config{
-			Region:               awsRegion,
-			ServerSideEncryption: awsSSE,
-			Credentials:          credentials.NewSharedCredentials("", awsProfile),
-		})
-	} else if awsSecretKey != "" {
-		stg, err = aws.NewStorage(aws.Config{
-			Region:               awsRegion,
-			ServerSideEncryption: awsSSE,
-			Credentials: credentials.NewStaticCredentialsFromCreds(credentials.Value{
-				AccessKeyID:     awsAccessKey,
-				SecretAccessKey: awsSecretKey,
-				SessionToken:    awsSessionToken,
-			}),
-		})
-		// GCP.
-	} else if gcpProjectId != "" {
-		stg, err = gcp.NewStorage(gcp.Config{
-			Context:            ctx,
-			Project:            gcpProjectId,
-			ServiceAccountFile: gcpServiceAccountFile,
-		})
-	}
+	stg, err := storageOpts.Open(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -148,6 +107,11 @@ func main() {
 		DB:      db,
 		Storage: stg,
 		Bucket:  bucketName,
+
+		AccountQuota: accountQuota,
+		ProjectQuota: projectQuota,
+
+		Transport: tp,
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,142 @@
+package data
+
+import (
+	"context"
+	"log"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/golang/protobuf/proto"
+	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/nodes"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	projectEventSubject = "events.project"
+	accountEventSubject = "events.account"
+)
+
+// newEventHandler initializes a handler that removes the objects of
+// purged projects and deleted accounts.
+func newEventHandler(s *service, nodeSvc nodes.ServiceClient) transport.Handler {
+	return func(msg *transport.Message) (proto.Message, error) {
+		var e commitlog.Event
+		if err := msg.Decode(&e); err != nil {
+			return nil, err
+		}
+
+		ctx := context.Background()
+
+		var err error
+
+		switch e.Type {
+		case "project.deleted":
+			if e.Project == "" {
+				return nil, nil
+			}
+
+			err = s.purgeProject(ctx, nodeSvc, e.Project)
+
+		case "account.deleted":
+			if e.Author == "" {
+				return nil, nil
+			}
+
+			// Objects of projects are removed as the projects are
+			// deleted.
+			err = s.removeObjects(ctx, bson.M{
+				"account": e.Author,
+				"project": "",
+			})
+
+		default:
+			return nil, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, commitlog.Ack(s.tp, "data", &e)
+	}
+}
+
+// removeObjects deletes the objects matching the query from storage and
+// removes their records.
+func (s *service) removeObjects(ctx context.Context, q bson.M) error {
+	var objs []*object
+	if err := s.db.C(objectsCol).Find(q).All(&objs); err != nil {
+		return err
+	}
+
+	for _, o := range objs {
+		if err := s.removeObject(ctx, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *service) removeObject(ctx context.Context, o *object) error {
+	// Objects that were never stored only have a record.
+	if o.State == State_DONE {
+		if err := s.object(o).Delete(ctx); err != nil {
+			log.Printf("data: failed to delete object %s: %s", o.ID, err)
+			return nil
+		}
+	}
+
+	return s.db.C(objectsCol).RemoveId(o.ID)
+}
+
+// purgeProject deletes the objects of the project. Objects that are still
+// referenced by another project, such as a fork, are reassigned to it.
+func (s *service) purgeProject(ctx context.Context, nodeSvc nodes.ServiceClient, project string) error {
+	var objs []*object
+	if err := s.db.C(objectsCol).Find(bson.M{"project": project}).All(&objs); err != nil {
+		return err
+	}
+
+	if len(objs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(objs))
+	for i, o := range objs {
+		ids[i] = o.ID
+	}
+
+	rep, err := nodeSvc.FileUsage(ctx, &nodes.FileUsageRequest{
+		Ids:            ids,
+		ExcludeProject: project,
+	})
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]string, len(rep.Usages))
+	for _, u := range rep.Usages {
+		used[u.Id] = u.Project
+	}
+
+	for _, o := range objs {
+		if p, ok := used[o.ID]; ok {
+			err := s.db.C(objectsCol).UpdateId(o.ID, bson.M{
+				"$set": bson.M{
+					"project": p,
+				},
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.removeObject(ctx, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
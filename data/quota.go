@@ -0,0 +1,115 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// usage is the aggregated size and count of a set of objects.
+type usage struct {
+	Project string `bson:"_id"`
+	Size    int64  `bson:"size"`
+	Count   int64  `bson:"count"`
+}
+
+// projectUsage aggregates the usage of all objects for an account
+// grouped by project. Artifacts are not included.
+func (s *service) projectUsage(account string) ([]*usage, error) {
+	pipe := []bson.M{
+		{
+			"$match": bson.M{
+				"account": account,
+				"artifact": bson.M{
+					"$ne": true,
+				},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": "$project",
+				"size": bson.M{
+					"$sum": "$size",
+				},
+				"count": bson.M{
+					"$sum": 1,
+				},
+			},
+		},
+	}
+
+	var res []*usage
+	if err := s.db.C(objectsCol).Pipe(pipe).All(&res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// checkQuota checks whether adding size bytes would exceed the account
+// or project quota.
+func (s *service) checkQuota(account, project string, size int64) error {
+	if s.accountQuota <= 0 && s.projectQuota <= 0 {
+		return nil
+	}
+
+	if account == "" {
+		return status.Error(codes.InvalidArgument, "account required")
+	}
+
+	res, err := s.projectUsage(account)
+	if err != nil {
+		return err
+	}
+
+	return s.compareQuota(res, project, size)
+}
+
+// compareQuota checks whether adding size bytes to the project would exceed
+// the quotas given the usage of the account.
+func (s *service) compareQuota(res []*usage, project string, size int64) error {
+	var total, projectTotal int64
+	for _, u := range res {
+		total += u.Size
+
+		if u.Project == project {
+			projectTotal = u.Size
+		}
+	}
+
+	if s.projectQuota > 0 && projectTotal+size > s.projectQuota {
+		return status.Error(codes.ResourceExhausted, fmt.Sprintf("project quota of %d bytes exceeded", s.projectQuota))
+	}
+
+	if s.accountQuota > 0 && total+size > s.accountQuota {
+		return status.Error(codes.ResourceExhausted, fmt.Sprintf("account quota of %d bytes exceeded", s.accountQuota))
+	}
+
+	return nil
+}
+
+// enforceQuota checks the quotas once the object is stored and its size is
+// known. The size declared upfront is optional and objects count as zero
+// until they are done, so this is what bounds the usage. An object over
+// the quota is failed and removed from storage.
+func (s *service) enforceQuota(ctx context.Context, o *object) error {
+	if o.Account == "" || o.Artifact {
+		return nil
+	}
+
+	// The object is counted already.
+	err := s.checkQuota(o.Account, o.Project, 0)
+	if status.Code(err) != codes.ResourceExhausted {
+		return err
+	}
+
+	// Concurrent uploads may all be failed, but none get past the quota.
+	if derr := s.discard(ctx, o, status.Convert(err).Message()); derr != nil {
+		return derr
+	}
+
+	return err
+}
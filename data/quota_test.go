@@ -0,0 +1,220 @@
+package data
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rdm-academy/api/internal/mongotest"
+	"github.com/rdm-academy/api/storage"
+)
+
+// testService returns a service on a scratch database with local storage
+// in a temporary directory, and a function that removes both.
+func testService(t *testing.T) (*service, func()) {
+	db, drop := mongotest.DB(t, "data")
+
+	dir, err := ioutil.TempDir("", "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stg, err := storage.New(context.Background(), storage.Config{
+		Base: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &service{
+		db:      db,
+		storage: stg,
+		bucket:  "test",
+	}
+
+	return s, func() {
+		drop()
+		os.RemoveAll(dir)
+	}
+}
+
+// put stores an object with the given contents.
+func put(t *testing.T, s *service, o *object, b []byte) {
+	w, err := s.object(o).Writer(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompareQuota(t *testing.T) {
+	res := []*usage{
+		{Project: "p1", Size: 60, Count: 2},
+		{Project: "p2", Size: 30, Count: 1},
+	}
+
+	for _, x := range []struct {
+		name         string
+		accountQuota int64
+		projectQuota int64
+		project      string
+		size         int64
+		code         codes.Code
+	}{
+		{"under project quota", 0, 100, "p1", 30, codes.OK},
+		{"at project quota", 0, 100, "p1", 40, codes.OK},
+		{"over project quota", 0, 100, "p1", 41, codes.ResourceExhausted},
+		{"other project", 0, 100, "p2", 70, codes.OK},
+		{"new project", 0, 100, "p4", 100, codes.OK},
+		{"under account quota", 100, 0, "p2", 10, codes.OK},
+		{"over account quota", 100, 0, "p4", 11, codes.ResourceExhausted},
+		{"account already over quota", 80, 100, "p2", 0, codes.ResourceExhausted},
+	} {
+		s := &service{
+			accountQuota: x.accountQuota,
+			projectQuota: x.projectQuota,
+		}
+
+		err := s.compareQuota(res, x.project, x.size)
+		if code := status.Code(err); code != x.code {
+			t.Errorf("%s: expected %s, got %s", x.name, x.code, code)
+		}
+	}
+
+	// The usage is not aggregated without quotas or an account.
+	s := &service{}
+	if err := s.checkQuota("a1", "p1", 1<<40); err != nil {
+		t.Errorf("expected no quotas to pass, got %s", err)
+	}
+
+	s.accountQuota = 100
+	if err := s.checkQuota("", "p1", 0); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected the account to be required, got %v", err)
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	for _, o := range []*object{
+		{ID: "1", Account: "a1", Project: "p1", Size: 40},
+		{ID: "2", Account: "a1", Project: "p1", Size: 20},
+		{ID: "3", Account: "a1", Project: "p2", Size: 30},
+		{ID: "4", Account: "a2", Project: "p3", Size: 100},
+		// Artifacts are not counted.
+		{ID: "5", Account: "a1", Project: "p1", Size: 1000, Artifact: true},
+	} {
+		if err := s.db.C(objectsCol).Insert(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, x := range []struct {
+		name         string
+		accountQuota int64
+		projectQuota int64
+		account      string
+		project      string
+		size         int64
+		code         codes.Code
+	}{
+		{"no quotas", 0, 0, "a1", "p1", 1 << 40, codes.OK},
+		{"under project quota", 0, 100, "a1", "p1", 30, codes.OK},
+		{"at project quota", 0, 100, "a1", "p1", 40, codes.OK},
+		{"over project quota", 0, 100, "a1", "p1", 41, codes.ResourceExhausted},
+		{"other project", 0, 100, "a1", "p2", 70, codes.OK},
+		{"new project", 0, 100, "a1", "p4", 100, codes.OK},
+		{"under account quota", 100, 0, "a1", "p2", 10, codes.OK},
+		{"over account quota", 100, 0, "a1", "p4", 11, codes.ResourceExhausted},
+		{"other account", 100, 0, "a2", "p3", 0, codes.OK},
+		{"account already over quota", 80, 100, "a1", "p2", 0, codes.ResourceExhausted},
+		{"no account", 100, 0, "", "p1", 0, codes.InvalidArgument},
+	} {
+		s.accountQuota = x.accountQuota
+		s.projectQuota = x.projectQuota
+
+		err := s.checkQuota(x.account, x.project, x.size)
+		if code := status.Code(err); code != x.code {
+			t.Errorf("%s: expected %s, got %s", x.name, x.code, code)
+		}
+	}
+}
+
+func TestEnforceQuota(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	s.projectQuota = 100
+
+	ctx := context.Background()
+
+	for _, x := range []struct {
+		name string
+		obj  *object
+		err  codes.Code
+	}{
+		{"under quota", &object{ID: "1", Account: "a1", Project: "p1", Size: 60}, codes.OK},
+		{"artifact", &object{ID: "2", Account: "a1", Project: "p1", Size: 60, Artifact: true}, codes.OK},
+		{"over quota", &object{ID: "3", Account: "a1", Project: "p1", Size: 60}, codes.ResourceExhausted},
+	} {
+		x.obj.State = State_DONE
+		x.obj.Bucket = s.bucket
+
+		if err := s.db.C(objectsCol).Insert(x.obj); err != nil {
+			t.Fatal(err)
+		}
+
+		put(t, s, x.obj, make([]byte, x.obj.Size))
+
+		err := s.enforceQuota(ctx, x.obj)
+		if code := status.Code(err); code != x.err {
+			t.Errorf("%s: expected %s, got %s", x.name, x.err, code)
+		}
+
+		var o object
+		if err := s.db.C(objectsCol).FindId(x.obj.ID).One(&o); err != nil {
+			t.Fatal(err)
+		}
+
+		ok, err := s.object(&o).Exists(ctx)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+
+		if x.err == codes.OK {
+			if o.State != State_DONE || o.Size != x.obj.Size {
+				t.Errorf("%s: expected object to be kept, got %s with size %d", x.name, o.State, o.Size)
+			}
+			if !ok {
+				t.Errorf("%s: expected object in storage", x.name)
+			}
+			continue
+		}
+
+		// The object over the quota is failed and removed.
+		if o.State != State_ERROR || o.Size != 0 {
+			t.Errorf("%s: expected failed object with no size, got %s with size %d", x.name, o.State, o.Size)
+		}
+		if o.Error == "" {
+			t.Errorf("%s: expected an error", x.name)
+		}
+		if ok {
+			t.Errorf("%s: expected object to be removed from storage", x.name)
+		}
+	}
+
+	// The usage is back under the quota.
+	if err := s.checkQuota("a1", "p1", 40); err != nil {
+		t.Errorf("expected usage under the quota, got %s", err)
+	}
+}
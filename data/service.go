@@ -16,6 +16,8 @@ import (
 	mgo "gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/rdm-academy/api/nodes"
 	"github.com/rdm-academy/api/storage"
 	uuid "github.com/satori/go.uuid"
 )
@@ -27,12 +29,33 @@ const (
 var (
 	urlExpiryTime = time.Hour
 	importTimeout = 10 * time.Minute
+
+	// Time allowed to verify a completed upload.
+	completeTimeout = time.Hour
+
+	// Interval of the sweep for interrupted uploads and imports and
+	// expired objects.
+	sweepInterval = 10 * time.Minute
+
+	// Time after which an object that was never completed is removed.
+	// The signed URL of an upload has expired by then.
+	pendingTimeout = urlExpiryTime + sweepInterval
 )
 
 type Config struct {
 	DB      *mgo.Database
 	Storage storage.Storage
 	Bucket  string
+
+	// Quotas in bytes per account and per project. Zero means
+	// no quota is enforced.
+	AccountQuota int64
+	ProjectQuota int64
+
+	// Transport is used to subscribe to project and account events in
+	// order to remove the objects and to add completed uploads to their
+	// nodes. Uploads cannot be completed without it.
+	Transport transport.Transport
 }
 
 type object struct {
@@ -45,6 +68,13 @@ type object struct {
 	Bucket  string `bson:"bucket"`
 	Storage string `bson:"storage"`
 
+	// Name of the stored object if it is not the id. Completed uploads
+	// are moved out of reach of the signed URL they were uploaded to.
+	Key string `bson:"key"`
+
+	Account string `bson:"account"`
+	Project string `bson:"project"`
+
 	ImportURL  string    `bson:"import_url"`
 	ImportTime time.Time `bson:"import_time"`
 
@@ -55,10 +85,32 @@ type object struct {
 	Mediatype   string `bson:"mediatype"`
 	Compression string `bson:"compression"`
 
+	// Size declared by the uploader, if any.
+	DeclaredSize int64 `bson:"declared_size"`
+
+	// Node a completed upload is added to once it is verified, its local
+	// name and the hash expected by the uploader.
+	Node         string `bson:"node"`
+	Name         string `bson:"name"`
+	ExpectedHash string `bson:"expected_hash"`
+
+	// Artifacts are not counted towards the quotas.
+	Artifact bool `bson:"artifact"`
+
+	// Time the object is removed, if set.
+	Expires time.Time `bson:"expires,omitempty"`
+
 	Version      int       `bson:"version"`
 	ModifiedTime time.Time `bson:"modified_time"`
 }
 
+func (o *object) key() string {
+	if o.Key != "" {
+		return o.Key
+	}
+	return o.ID
+}
+
 type fileMeta struct {
 	Size      int64
 	Mediatype string
@@ -67,8 +119,18 @@ type fileMeta struct {
 
 type service struct {
 	db      *mgo.Database
+	tp      transport.Transport
 	storage storage.Storage
 	bucket  string
+	nodes   nodes.ServiceClient
+
+	accountQuota int64
+	projectQuota int64
+}
+
+// object returns the stored object of the record.
+func (s *service) object(o *object) storage.Object {
+	return s.storage.Bucket(o.Bucket).Object(o.key())
 }
 
 func (s *service) fetch(ctx context.Context, o *object) {
@@ -93,7 +155,7 @@ func (s *service) fetch(ctx context.Context, o *object) {
 		}
 
 		// Get a writer for this object.
-		w, err := s.storage.Bucket(o.Bucket).Object(o.ID).Writer(ctx)
+		w, err := s.object(o).Writer(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -177,6 +239,12 @@ func (s *service) Import(ctx context.Context, req *ImportRequest) (*ImportReply,
 		return nil, status.Error(codes.InvalidArgument, "url required")
 	}
 
+	// The size is not known until the import is done, when the quotas
+	// are checked again.
+	if err := s.checkQuota(req.Account, req.Project, 0); err != nil {
+		return nil, err
+	}
+
 	id := uuid.NewV4().String()
 
 	d := &object{
@@ -185,6 +253,8 @@ func (s *service) Import(ctx context.Context, req *ImportRequest) (*ImportReply,
 		CreateTime:   time.Now(),
 		Bucket:       s.bucket,
 		Storage:      s.storage.Name(),
+		Account:      req.Account,
+		Project:      req.Project,
 		ImportURL:    req.Url,
 		ModifiedTime: time.Now(),
 	}
@@ -204,7 +274,17 @@ func (s *service) Import(ctx context.Context, req *ImportRequest) (*ImportReply,
 	}, nil
 }
 
-func (s *service) Upload(ctx context.Context, _ *UploadRequest) (*UploadReply, error) {
+func (s *service) Upload(ctx context.Context, req *UploadRequest) (*UploadReply, error) {
+	if req.Size < 0 {
+		return nil, status.Error(codes.InvalidArgument, "size must not be negative")
+	}
+
+	if !req.Artifact {
+		if err := s.checkQuota(req.Account, req.Project, req.Size); err != nil {
+			return nil, err
+		}
+	}
+
 	id := uuid.NewV4().String()
 
 	d := &object{
@@ -213,9 +293,19 @@ func (s *service) Upload(ctx context.Context, _ *UploadRequest) (*UploadReply, e
 		CreateTime:   time.Now(),
 		Bucket:       s.bucket,
 		Storage:      s.storage.Name(),
+		Account:      req.Account,
+		Project:      req.Project,
+		Mediatype:    req.Mediatype,
+		DeclaredSize: req.Size,
+		Name:         req.Name,
+		Artifact:     req.Artifact,
 		ModifiedTime: time.Now(),
 	}
 
+	if req.Expires > 0 {
+		d.Expires = time.Unix(req.Expires, 0)
+	}
+
 	if err := s.db.C(objectsCol).Insert(d); err != nil {
 		if mgo.IsDup(err) {
 			return nil, status.Error(codes.AlreadyExists, "object already exists")
@@ -238,6 +328,358 @@ func (s *service) Upload(ctx context.Context, _ *UploadRequest) (*UploadReply, e
 	}, nil
 }
 
+func (s *service) Complete(ctx context.Context, req *CompleteRequest) (*CompleteReply, error) {
+	if req.Node == "" || req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "node and name required")
+	}
+
+	if s.nodes == nil {
+		return nil, status.Error(codes.Unavailable, "uploads cannot be added to nodes")
+	}
+
+	var d object
+	if err := s.db.C(objectsCol).FindId(req.Id).One(&d); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "object not found")
+		}
+
+		return nil, err
+	}
+
+	if d.Account != req.Account {
+		return nil, status.Error(codes.PermissionDenied, "object belongs to another account")
+	}
+
+	if d.Project != req.Project {
+		return nil, status.Error(codes.PermissionDenied, "object belongs to another project")
+	}
+
+	if d.ImportURL != "" || d.State != State_CREATED {
+		return nil, status.Error(codes.FailedPrecondition, "object is not a pending upload")
+	}
+
+	if d.Name != "" && d.Name != req.Name {
+		return nil, status.Error(codes.InvalidArgument, "name does not match the declared name")
+	}
+
+	ok, err := s.object(&d).Exists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, status.Error(codes.FailedPrecondition, "object has not been uploaded")
+	}
+
+	d.Node = req.Node
+	d.Name = req.Name
+	d.ExpectedHash = req.Hash
+	d.ImportTime = time.Now()
+
+	// Claim the upload so it is only completed once. The node is stored
+	// so the verification can be resumed if it is interrupted.
+	err = s.db.C(objectsCol).Update(bson.M{
+		"_id":   d.ID,
+		"state": State_CREATED,
+	}, bson.M{
+		"$set": bson.M{
+			"state":         State_INPROGRESS,
+			"error":         "",
+			"node":          d.Node,
+			"name":          d.Name,
+			"expected_hash": d.ExpectedHash,
+			"import_time":   d.ImportTime,
+			"modified_time": time.Now(),
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	})
+	if err == mgo.ErrNotFound {
+		return nil, status.Error(codes.FailedPrecondition, "object is not a pending upload")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Reading back large objects takes longer than a request.
+	go s.verify(context.Background(), &d)
+
+	return &CompleteReply{
+		State: State_INPROGRESS,
+	}, nil
+}
+
+// verify moves a completed upload out of reach of its signed URL, so the
+// contents cannot be replaced, then computes the hash and size of the
+// object, marks it as done and adds it to its node. An upload that fails
+// is removed from storage and never added to the node.
+func (s *service) verify(ctx context.Context, d *object) {
+	ctx, cancel := context.WithTimeout(ctx, completeTimeout)
+	defer cancel()
+
+	// Closure to simplify error handling.
+	check := func() (*fileMeta, error) {
+		// The new name is stored before the move, so a resumed
+		// verification finds the object under either name.
+		if d.Key == "" {
+			d.Key = uuid.NewV4().String()
+
+			err := s.db.C(objectsCol).UpdateId(d.ID, bson.M{
+				"$set": bson.M{
+					"key": d.Key,
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		obj := s.object(d)
+
+		moved, err := obj.Exists(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if !moved {
+			obj, err = s.storage.Bucket(d.Bucket).Object(d.ID).Move(ctx, d.Key)
+			if err != nil {
+				return nil, fmt.Errorf("move error: %s", err)
+			}
+		}
+
+		rc, err := obj.Reader(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+
+		hsh := sha256.New()
+
+		size, err := io.Copy(hsh, rc)
+		if err != nil {
+			return nil, fmt.Errorf("read error: %s", err)
+		}
+
+		if d.DeclaredSize > 0 && size != d.DeclaredSize {
+			return nil, fmt.Errorf("size mismatch: declared %d, got %d", d.DeclaredSize, size)
+		}
+
+		hash := fmt.Sprintf("sha256:%x", hsh.Sum(nil))
+
+		// Compare with the expected hash. The prefix is optional.
+		expected := d.ExpectedHash
+		if expected != "" && expected != hash && "sha256:"+expected != hash {
+			return nil, fmt.Errorf("hash mismatch: expected %s, got %s", expected, hash)
+		}
+
+		return &fileMeta{
+			Size:      size,
+			Hash:      hash,
+			Mediatype: d.Mediatype,
+		}, nil
+	}
+
+	meta, err := check()
+	if err != nil {
+		if err2 := s.discard(ctx, d, err.Error()); err2 != nil {
+			log.Printf("failed to set ERROR state: %s\n%s\noriginal error: %s", d.ID, err2, err)
+		}
+		return
+	}
+
+	// The quotas are checked with the stored size.
+	_, err = s.Update(ctx, &UpdateRequest{
+		Id:        d.ID,
+		State:     State_DONE,
+		Mediatype: meta.Mediatype,
+		PutTime:   time.Now().Unix(),
+		Size:      meta.Size,
+		Hash:      meta.Hash,
+	})
+	if err != nil {
+		// Log so this can be manually set if need be.
+		if status.Code(err) != codes.ResourceExhausted {
+			log.Printf("failed to set DONE state: %s\n%s", d.ID, err)
+		}
+		return
+	}
+
+	_, err = s.nodes.AddFiles(ctx, &nodes.AddFilesRequest{
+		Project: d.Project,
+		Id:      d.Node,
+		Account: d.Account,
+		Files: []*nodes.File{
+			&nodes.File{
+				Id:   d.ID,
+				Name: d.Name,
+			},
+		},
+	})
+	if err != nil {
+		// The file is not referenced, e.g. the node was removed.
+		if err2 := s.discard(ctx, d, fmt.Sprintf("failed to add file to node: %s", err)); err2 != nil {
+			log.Printf("failed to set ERROR state: %s\n%s\noriginal error: %s", d.ID, err2, err)
+		}
+	}
+}
+
+// discard fails the object and removes its contents from storage, so it
+// does not count towards the quotas.
+func (s *service) discard(ctx context.Context, o *object, msg string) error {
+	err := s.db.C(objectsCol).UpdateId(o.ID, bson.M{
+		"$set": bson.M{
+			"state":         State_ERROR,
+			"error":         msg,
+			"size":          0,
+			"hash":          "",
+			"modified_time": time.Now(),
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Uploads that were not moved yet are stored under the id.
+	objs := []storage.Object{s.object(o)}
+	if o.Key != "" {
+		objs = append(objs, s.storage.Bucket(o.Bucket).Object(o.ID))
+	}
+
+	for _, obj := range objs {
+		if ok, err := obj.Exists(ctx); err == nil && !ok {
+			continue
+		}
+
+		if err := obj.Delete(ctx); err != nil {
+			log.Printf("data: failed to delete object %s: %s", o.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// sweep removes expired objects and objects that were never completed,
+// resumes the verification of completed uploads and fails imports that
+// were interrupted, e.g. by a restart. Objects are only picked up once
+// they have been in progress for longer than their timeout, so the work
+// of other replicas is left alone.
+func (s *service) sweep(ctx context.Context) error {
+	now := time.Now()
+
+	err := s.removeObjects(ctx, bson.M{
+		"expires": bson.M{
+			"$lt": now,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Uploads that were put but never completed would otherwise keep
+	// their contents without counting towards the quotas.
+	var pending []*object
+	err = s.db.C(objectsCol).Find(bson.M{
+		"state":       State_CREATED,
+		"create_time": bson.M{"$lt": now.Add(-pendingTimeout)},
+	}).All(&pending)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range pending {
+		// Claim the object so it is not completed concurrently.
+		err := s.db.C(objectsCol).Update(bson.M{
+			"_id":   o.ID,
+			"state": State_CREATED,
+		}, bson.M{
+			"$set": bson.M{
+				"state":         State_INPROGRESS,
+				"import_time":   now,
+				"modified_time": now,
+			},
+			"$inc": bson.M{
+				"version": 1,
+			},
+		})
+		if err == mgo.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.discard(ctx, o, "object was not completed in time"); err != nil {
+			return err
+		}
+	}
+
+	var objs []*object
+	err = s.db.C(objectsCol).Find(bson.M{
+		"state": State_INPROGRESS,
+		"$or": []bson.M{
+			{
+				"import_url":  "",
+				"import_time": bson.M{"$lt": now.Add(-completeTimeout - sweepInterval)},
+			},
+			{
+				"import_url":  bson.M{"$ne": ""},
+				"import_time": bson.M{"$lt": now.Add(-importTimeout - sweepInterval)},
+			},
+		},
+	}).All(&objs)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range objs {
+		// Claim the object so only one replica resumes it.
+		err := s.db.C(objectsCol).Update(bson.M{
+			"_id":         o.ID,
+			"state":       State_INPROGRESS,
+			"import_time": o.ImportTime,
+		}, bson.M{
+			"$set": bson.M{
+				"import_time":   now,
+				"modified_time": now,
+			},
+			"$inc": bson.M{
+				"version": 1,
+			},
+		})
+		if err == mgo.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// Imports are not resumed, the remote file may have changed.
+		if o.ImportURL != "" {
+			if err := s.discard(ctx, o, "import was interrupted"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Uploads without a node were claimed before the node was
+		// stored with them.
+		if o.Node == "" || s.nodes == nil {
+			if err := s.discard(ctx, o, "upload verification was interrupted"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		go s.verify(context.Background(), o)
+	}
+
+	return nil
+}
+
 func (s *service) Describe(ctx context.Context, req *DescribeRequest) (*DescribeReply, error) {
 	var d object
 
@@ -262,6 +704,10 @@ func (s *service) Describe(ctx context.Context, req *DescribeRequest) (*Describe
 		Mediatype:    d.Mediatype,
 		Compression:  d.Compression,
 		ModifiedTime: d.ModifiedTime.Unix(),
+		Account:      d.Account,
+		Project:      d.Project,
+		Bucket:       d.Bucket,
+		Key:          d.key(),
 	}, nil
 }
 
@@ -313,6 +759,10 @@ func (s *service) Update(ctx context.Context, req *UpdateRequest) (*UpdateReply,
 		set["hash"] = req.Hash
 		set["size"] = req.Size
 
+		if req.Mediatype != "" {
+			set["mediatype"] = req.Mediatype
+		}
+
 	default:
 		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("object cannot transition to state %s", req.State))
 	}
@@ -342,6 +792,12 @@ func (s *service) Update(ctx context.Context, req *UpdateRequest) (*UpdateReply,
 		return nil, err
 	}
 
+	if req.State == State_DONE {
+		if err := s.enforceQuota(ctx, &d); err != nil {
+			return nil, err
+		}
+	}
+
 	return &UpdateReply{}, nil
 }
 
@@ -356,9 +812,20 @@ func (s *service) Get(ctx context.Context, req *GetRequest) (*GetReply, error) {
 		return nil, err
 	}
 
-	obj := s.storage.Bucket(d.Bucket).Object(d.ID)
+	obj := s.object(&d)
 
-	url, err := obj.URL().Get(urlExpiryTime)
+	var opts *storage.ResponseOptions
+	if req.ContentDisposition != "" || req.ContentType != "" {
+		opts = &storage.ResponseOptions{
+			ContentDisposition: req.ContentDisposition,
+			ContentType:        req.ContentType,
+		}
+	}
+
+	url, err := obj.URL().Get(urlExpiryTime, opts)
+	if err == storage.ErrSignedURLUnsupported {
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -370,6 +837,37 @@ func (s *service) Get(ctx context.Context, req *GetRequest) (*GetReply, error) {
 	}, nil
 }
 
+func (s *service) Usage(ctx context.Context, req *UsageRequest) (*UsageReply, error) {
+	if req.Account == "" {
+		return nil, status.Error(codes.InvalidArgument, "account required")
+	}
+
+	res, err := s.projectUsage(req.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &UsageReply{
+		Account:  req.Account,
+		Quota:    s.accountQuota,
+		Projects: make([]*ProjectUsage, len(res)),
+	}
+
+	for i, u := range res {
+		rep.Size += u.Size
+		rep.Count += u.Count
+
+		rep.Projects[i] = &ProjectUsage{
+			Project: u.Project,
+			Size:    u.Size,
+			Count:   u.Count,
+			Quota:   s.projectQuota,
+		}
+	}
+
+	return rep, nil
+}
+
 func NewService(cfg Config) (Service, error) {
 	if cfg.Storage == nil {
 		return nil, errors.New("storage required")
@@ -383,9 +881,47 @@ func NewService(cfg Config) (Service, error) {
 		return nil, errors.New("mongo database required")
 	}
 
-	return &service{
-		db:      cfg.DB,
-		storage: cfg.Storage,
-		bucket:  cfg.Bucket,
-	}, nil
+	// Index for aggregating usage.
+	err := cfg.DB.C(objectsCol).EnsureIndex(mgo.Index{
+		Key: []string{"account", "project"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &service{
+		db:           cfg.DB,
+		storage:      cfg.Storage,
+		bucket:       cfg.Bucket,
+		accountQuota: cfg.AccountQuota,
+		projectQuota: cfg.ProjectQuota,
+	}
+
+	if cfg.Transport != nil {
+		s.tp = cfg.Transport
+		s.nodes = nodes.NewServiceClient(cfg.Transport)
+
+		h := newEventHandler(s, s.nodes)
+
+		// This will be auto-unsubscribed when the transport is closed.
+		for _, subj := range []string{projectEventSubject, accountEventSubject} {
+			if _, err := cfg.Transport.Subscribe(subj, h); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Pick up uploads and imports interrupted by a restart and remove
+	// expired objects.
+	go func() {
+		for {
+			if err := s.sweep(context.Background()); err != nil {
+				log.Printf("data: sweep failed: %s", err)
+			}
+
+			time.Sleep(sweepInterval)
+		}
+	}()
+
+	return s, nil
 }
@@ -13,12 +13,17 @@ It has these top-level messages:
 	ImportReply
 	UploadRequest
 	UploadReply
+	CompleteRequest
+	CompleteReply
 	DescribeRequest
 	DescribeReply
 	UpdateRequest
 	UpdateReply
 	GetRequest
 	GetReply
+	UsageRequest
+	ProjectUsage
+	UsageReply
 */
 package data
 
@@ -72,6 +77,9 @@ func (State) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0}
 // is asynchronous, but this id can be used to check the state.
 type ImportRequest struct {
 	Url string `protobuf:"bytes,1,opt,name=url" json:"url,omitempty"`
+	// Account and project the data is accounted to.
+	Account string `protobuf:"bytes,2,opt,name=account" json:"account,omitempty"`
+	Project string `protobuf:"bytes,3,opt,name=project" json:"project,omitempty"`
 }
 
 func (m *ImportRequest) Reset()                    { *m = ImportRequest{} }
@@ -86,6 +94,20 @@ func (m *ImportRequest) GetUrl() string {
 	return ""
 }
 
+func (m *ImportRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *ImportRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
 type ImportReply struct {
 	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 }
@@ -105,6 +127,23 @@ func (m *ImportReply) GetId() string {
 // UploadRequest is request to upload data. It returns a unique data
 // id and a pre-signed URL the client can use to PUT the data to.
 type UploadRequest struct {
+	// Account and project the data is accounted to.
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	Project string `protobuf:"bytes,2,opt,name=project" json:"project,omitempty"`
+	// Expected size of the data in bytes, if known. This is used to
+	// check the quotas before the upload begins. The quotas are checked
+	// again with the stored size when the object is done.
+	Size int64 `protobuf:"varint,3,opt,name=size" json:"size,omitempty"`
+	// The mediatype of the data if known.
+	Mediatype string `protobuf:"bytes,4,opt,name=mediatype" json:"mediatype,omitempty"`
+	// Artifacts generated by the services, such as export packages, are
+	// not counted towards the quotas.
+	Artifact bool `protobuf:"varint,5,opt,name=artifact" json:"artifact,omitempty"`
+	// Unix time the object is removed at, if set.
+	Expires int64 `protobuf:"varint,6,opt,name=expires" json:"expires,omitempty"`
+	// Local name of an upload that is completed into a node. If set, the
+	// upload can only be completed with this name.
+	Name string `protobuf:"bytes,7,opt,name=name" json:"name,omitempty"`
 }
 
 func (m *UploadRequest) Reset()                    { *m = UploadRequest{} }
@@ -112,6 +151,55 @@ func (m *UploadRequest) String() string            { return proto.CompactTextStr
 func (*UploadRequest) ProtoMessage()               {}
 func (*UploadRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
+func (m *UploadRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *UploadRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *UploadRequest) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *UploadRequest) GetMediatype() string {
+	if m != nil {
+		return m.Mediatype
+	}
+	return ""
+}
+
+func (m *UploadRequest) GetArtifact() bool {
+	if m != nil {
+		return m.Artifact
+	}
+	return false
+}
+
+func (m *UploadRequest) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+func (m *UploadRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
 type UploadReply struct {
 	Id        string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	SignedUrl string `protobuf:"bytes,2,opt,name=signed_url,json=signedUrl" json:"signed_url,omitempty"`
@@ -136,6 +224,88 @@ func (m *UploadReply) GetSignedUrl() string {
 	return ""
 }
 
+// CompleteRequest completes an upload made directly to the signed URL.
+// The object is verified to exist in storage and is then verified
+// asynchronously: it is moved so the signed URL can no longer replace
+// the contents, and its size and hash are computed. The object is DONE
+// or in the ERROR state once it is verified.
+type CompleteRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	// Account and project that created the upload.
+	Account string `protobuf:"bytes,2,opt,name=account" json:"account,omitempty"`
+	Project string `protobuf:"bytes,4,opt,name=project" json:"project,omitempty"`
+	// Expected hash of the object. If set, the upload fails if
+	// the hash of the stored object does not match.
+	Hash string `protobuf:"bytes,3,opt,name=hash" json:"hash,omitempty"`
+	// Node the file is added to once it is verified and its local name.
+	Node string `protobuf:"bytes,5,opt,name=node" json:"node,omitempty"`
+	Name string `protobuf:"bytes,6,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *CompleteRequest) Reset()                    { *m = CompleteRequest{} }
+func (m *CompleteRequest) String() string            { return proto.CompactTextString(m) }
+func (*CompleteRequest) ProtoMessage()               {}
+func (*CompleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *CompleteRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *CompleteRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *CompleteRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *CompleteRequest) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *CompleteRequest) GetNode() string {
+	if m != nil {
+		return m.Node
+	}
+	return ""
+}
+
+func (m *CompleteRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type CompleteReply struct {
+	// State of the object, INPROGRESS until it is verified.
+	State State `protobuf:"varint,1,opt,name=state,enum=data.State" json:"state,omitempty"`
+}
+
+func (m *CompleteReply) Reset()                    { *m = CompleteReply{} }
+func (m *CompleteReply) String() string            { return proto.CompactTextString(m) }
+func (*CompleteReply) ProtoMessage()               {}
+func (*CompleteReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *CompleteReply) GetState() State {
+	if m != nil {
+		return m.State
+	}
+	return State_UNKNOWN
+}
+
 // DescribeRequest takes a data id for returns information about the data.
 type DescribeRequest struct {
 	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
@@ -144,7 +314,7 @@ type DescribeRequest struct {
 func (m *DescribeRequest) Reset()                    { *m = DescribeRequest{} }
 func (m *DescribeRequest) String() string            { return proto.CompactTextString(m) }
 func (*DescribeRequest) ProtoMessage()               {}
-func (*DescribeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*DescribeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
 func (m *DescribeRequest) GetId() string {
 	if m != nil {
@@ -177,12 +347,18 @@ type DescribeReply struct {
 	// The compression method used if any.
 	Compression  string `protobuf:"bytes,12,opt,name=compression" json:"compression,omitempty"`
 	ModifiedTime int64  `protobuf:"varint,13,opt,name=modified_time,json=modifiedTime" json:"modified_time,omitempty"`
+	// The account and project the object is accounted to.
+	Account string `protobuf:"bytes,14,opt,name=account" json:"account,omitempty"`
+	Project string `protobuf:"bytes,15,opt,name=project" json:"project,omitempty"`
+	// Bucket and name of the object in storage.
+	Bucket string `protobuf:"bytes,16,opt,name=bucket" json:"bucket,omitempty"`
+	Key    string `protobuf:"bytes,17,opt,name=key" json:"key,omitempty"`
 }
 
 func (m *DescribeReply) Reset()                    { *m = DescribeReply{} }
 func (m *DescribeReply) String() string            { return proto.CompactTextString(m) }
 func (*DescribeReply) ProtoMessage()               {}
-func (*DescribeReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*DescribeReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
 func (m *DescribeReply) GetId() string {
 	if m != nil {
@@ -268,6 +444,34 @@ func (m *DescribeReply) GetModifiedTime() int64 {
 	return 0
 }
 
+func (m *DescribeReply) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *DescribeReply) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *DescribeReply) GetBucket() string {
+	if m != nil {
+		return m.Bucket
+	}
+	return ""
+}
+
+func (m *DescribeReply) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
 // UpdateRequest updates the state of the object. Once the object
 // is in the DONE state, no more updates can be made.
 type UpdateRequest struct {
@@ -292,7 +496,7 @@ type UpdateRequest struct {
 func (m *UpdateRequest) Reset()                    { *m = UpdateRequest{} }
 func (m *UpdateRequest) String() string            { return proto.CompactTextString(m) }
 func (*UpdateRequest) ProtoMessage()               {}
-func (*UpdateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*UpdateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
 func (m *UpdateRequest) GetId() string {
 	if m != nil {
@@ -356,17 +560,21 @@ type UpdateReply struct {
 func (m *UpdateReply) Reset()                    { *m = UpdateReply{} }
 func (m *UpdateReply) String() string            { return proto.CompactTextString(m) }
 func (*UpdateReply) ProtoMessage()               {}
-func (*UpdateReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (*UpdateReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
 // GetRequest requests a pre-signed URL for downloading the data.
 type GetRequest struct {
 	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	// Overrides the Content-Disposition header of the signed URL response.
+	ContentDisposition string `protobuf:"bytes,2,opt,name=content_disposition,json=contentDisposition" json:"content_disposition,omitempty"`
+	// Overrides the Content-Type header of the signed URL response.
+	ContentType string `protobuf:"bytes,3,opt,name=content_type,json=contentType" json:"content_type,omitempty"`
 }
 
 func (m *GetRequest) Reset()                    { *m = GetRequest{} }
 func (m *GetRequest) String() string            { return proto.CompactTextString(m) }
 func (*GetRequest) ProtoMessage()               {}
-func (*GetRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (*GetRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
 func (m *GetRequest) GetId() string {
 	if m != nil {
@@ -375,6 +583,20 @@ func (m *GetRequest) GetId() string {
 	return ""
 }
 
+func (m *GetRequest) GetContentDisposition() string {
+	if m != nil {
+		return m.ContentDisposition
+	}
+	return ""
+}
+
+func (m *GetRequest) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
 type GetReply struct {
 	SignedUrl string `protobuf:"bytes,1,opt,name=signed_url,json=signedUrl" json:"signed_url,omitempty"`
 	Mediatype string `protobuf:"bytes,2,opt,name=mediatype" json:"mediatype,omitempty"`
@@ -384,7 +606,7 @@ type GetReply struct {
 func (m *GetReply) Reset()                    { *m = GetReply{} }
 func (m *GetReply) String() string            { return proto.CompactTextString(m) }
 func (*GetReply) ProtoMessage()               {}
-func (*GetReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (*GetReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
 func (m *GetReply) GetSignedUrl() string {
 	if m != nil {
@@ -407,58 +629,194 @@ func (m *GetReply) GetSize() int64 {
 	return 0
 }
 
+// UsageRequest requests the storage usage of an account.
+type UsageRequest struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+}
+
+func (m *UsageRequest) Reset()                    { *m = UsageRequest{} }
+func (m *UsageRequest) String() string            { return proto.CompactTextString(m) }
+func (*UsageRequest) ProtoMessage()               {}
+func (*UsageRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *UsageRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+type ProjectUsage struct {
+	Project string `protobuf:"bytes,1,opt,name=project" json:"project,omitempty"`
+	// Total size of the objects in bytes.
+	Size int64 `protobuf:"varint,2,opt,name=size" json:"size,omitempty"`
+	// Number of objects.
+	Count int64 `protobuf:"varint,3,opt,name=count" json:"count,omitempty"`
+	// The quota in bytes. Zero means no quota is enforced.
+	Quota int64 `protobuf:"varint,4,opt,name=quota" json:"quota,omitempty"`
+}
+
+func (m *ProjectUsage) Reset()                    { *m = ProjectUsage{} }
+func (m *ProjectUsage) String() string            { return proto.CompactTextString(m) }
+func (*ProjectUsage) ProtoMessage()               {}
+func (*ProjectUsage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *ProjectUsage) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *ProjectUsage) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *ProjectUsage) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *ProjectUsage) GetQuota() int64 {
+	if m != nil {
+		return m.Quota
+	}
+	return 0
+}
+
+type UsageReply struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	// Total size of the objects in bytes.
+	Size int64 `protobuf:"varint,2,opt,name=size" json:"size,omitempty"`
+	// Number of objects.
+	Count int64 `protobuf:"varint,3,opt,name=count" json:"count,omitempty"`
+	// The quota in bytes. Zero means no quota is enforced.
+	Quota int64 `protobuf:"varint,4,opt,name=quota" json:"quota,omitempty"`
+	// Usage broken down by project.
+	Projects []*ProjectUsage `protobuf:"bytes,5,rep,name=projects" json:"projects,omitempty"`
+}
+
+func (m *UsageReply) Reset()                    { *m = UsageReply{} }
+func (m *UsageReply) String() string            { return proto.CompactTextString(m) }
+func (*UsageReply) ProtoMessage()               {}
+func (*UsageReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *UsageReply) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *UsageReply) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *UsageReply) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *UsageReply) GetQuota() int64 {
+	if m != nil {
+		return m.Quota
+	}
+	return 0
+}
+
+func (m *UsageReply) GetProjects() []*ProjectUsage {
+	if m != nil {
+		return m.Projects
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ImportRequest)(nil), "data.ImportRequest")
 	proto.RegisterType((*ImportReply)(nil), "data.ImportReply")
 	proto.RegisterType((*UploadRequest)(nil), "data.UploadRequest")
 	proto.RegisterType((*UploadReply)(nil), "data.UploadReply")
+	proto.RegisterType((*CompleteRequest)(nil), "data.CompleteRequest")
+	proto.RegisterType((*CompleteReply)(nil), "data.CompleteReply")
 	proto.RegisterType((*DescribeRequest)(nil), "data.DescribeRequest")
 	proto.RegisterType((*DescribeReply)(nil), "data.DescribeReply")
 	proto.RegisterType((*UpdateRequest)(nil), "data.UpdateRequest")
 	proto.RegisterType((*UpdateReply)(nil), "data.UpdateReply")
 	proto.RegisterType((*GetRequest)(nil), "data.GetRequest")
 	proto.RegisterType((*GetReply)(nil), "data.GetReply")
+	proto.RegisterType((*UsageRequest)(nil), "data.UsageRequest")
+	proto.RegisterType((*ProjectUsage)(nil), "data.ProjectUsage")
+	proto.RegisterType((*UsageReply)(nil), "data.UsageReply")
 	proto.RegisterEnum("data.State", State_name, State_value)
 }
 
 func init() { proto.RegisterFile("service.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 562 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0xc5, 0x1f, 0x49, 0x9c, 0x71, 0x9d, 0x9a, 0x2d, 0x48, 0x26, 0x6a, 0x45, 0x62, 0x0e, 0x54,
-	0x1c, 0xa2, 0xaa, 0x48, 0x9c, 0xb8, 0x20, 0x12, 0xa2, 0x0a, 0x29, 0x41, 0x4e, 0x23, 0x0e, 0x1c,
-	0x2a, 0x37, 0x1e, 0xe8, 0x4a, 0x76, 0xbd, 0xac, 0x37, 0x48, 0xe1, 0xc4, 0xef, 0xe4, 0xc6, 0x3f,
-	0x41, 0xbb, 0x1b, 0xc7, 0x89, 0xe5, 0xc2, 0x6d, 0xfd, 0xde, 0x7a, 0xde, 0xf3, 0x9b, 0x19, 0x83,
-	0x57, 0x20, 0xff, 0x41, 0x57, 0x38, 0x62, 0x3c, 0x17, 0x39, 0xb1, 0x93, 0x58, 0xc4, 0xe1, 0x10,
-	0xbc, 0xab, 0x8c, 0xe5, 0x5c, 0x44, 0xf8, 0x7d, 0x8d, 0x85, 0x20, 0x3e, 0x58, 0x6b, 0x9e, 0x06,
-	0xc6, 0xc0, 0x38, 0xef, 0x46, 0xf2, 0x18, 0x9e, 0x81, 0x5b, 0x5e, 0x61, 0xe9, 0x86, 0xf4, 0xc0,
-	0xa4, 0xc9, 0x96, 0x37, 0x69, 0x12, 0x1e, 0x83, 0xb7, 0x64, 0x69, 0x1e, 0x27, 0xdb, 0x0a, 0xe1,
-	0x5b, 0x70, 0x4b, 0xa0, 0xe1, 0x3e, 0x39, 0x03, 0x28, 0xe8, 0xb7, 0x7b, 0x4c, 0x6e, 0xa4, 0x8e,
-	0xa9, 0xf0, 0xae, 0x46, 0x96, 0x3c, 0x0d, 0x87, 0x70, 0x3c, 0xc6, 0x62, 0xc5, 0xe9, 0x2d, 0x96,
-	0x96, 0xea, 0x8a, 0x7f, 0x4c, 0xf0, 0xaa, 0x3b, 0x4d, 0x1a, 0xcf, 0xc1, 0x5d, 0x71, 0x8c, 0x05,
-	0xde, 0x08, 0x9a, 0xa1, 0x12, 0xb1, 0x22, 0xd0, 0xd0, 0x35, 0xcd, 0x90, 0x0c, 0xa1, 0x55, 0x88,
-	0x58, 0x60, 0x60, 0x0d, 0x8c, 0xf3, 0xde, 0xa5, 0x3b, 0x92, 0x61, 0x8c, 0x16, 0x12, 0x8a, 0x34,
-	0x43, 0x9e, 0x40, 0x0b, 0x39, 0xcf, 0x79, 0x60, 0xab, 0xb2, 0xfa, 0x41, 0xba, 0xa7, 0x2a, 0x0c,
-	0xe5, 0xbe, 0xad, 0xdd, 0x6b, 0x64, 0xc9, 0x53, 0x29, 0xbc, 0xa5, 0x95, 0x70, 0x47, 0x0b, 0x6b,
-	0x48, 0x09, 0x3f, 0x03, 0x87, 0xad, 0xb7, 0xac, 0xa3, 0xd8, 0x0e, 0x5b, 0x6b, 0x8a, 0x80, 0x7d,
-	0x17, 0x17, 0x77, 0x41, 0x57, 0x15, 0x55, 0x67, 0x89, 0x15, 0xf4, 0x27, 0x06, 0xa0, 0xae, 0xaa,
-	0x33, 0x39, 0x85, 0x6e, 0x86, 0x09, 0x8d, 0xc5, 0x86, 0x61, 0xe0, 0x6a, 0x07, 0x3b, 0x80, 0x0c,
-	0xc0, 0x5d, 0xe5, 0x19, 0xe3, 0x58, 0x14, 0x34, 0xbf, 0x0f, 0x8e, 0x14, 0xbf, 0x0f, 0x91, 0x17,
-	0xe0, 0x65, 0x79, 0x42, 0xbf, 0x52, 0x4c, 0xb4, 0x0f, 0x4f, 0x15, 0x3f, 0x2a, 0x41, 0x69, 0x26,
-	0xfc, 0x6d, 0xc8, 0xb6, 0x26, 0x32, 0x8f, 0xe6, 0x2e, 0x54, 0x11, 0x9a, 0xff, 0x8f, 0xd0, 0xda,
-	0x8f, 0xb0, 0x96, 0x91, 0xf3, 0xcf, 0x8c, 0xec, 0xe6, 0x8c, 0x5a, 0x0d, 0x19, 0xb5, 0x1f, 0xca,
-	0xa8, 0x53, 0xcb, 0x28, 0xf4, 0xe4, 0x84, 0xea, 0x6f, 0x63, 0xe9, 0x26, 0x3c, 0x05, 0x98, 0xa2,
-	0x78, 0x68, 0xda, 0xbe, 0x80, 0xa3, 0x58, 0x39, 0x67, 0x87, 0xb3, 0x6b, 0xd4, 0x66, 0xf7, 0x50,
-	0xd5, 0xac, 0x77, 0xa6, 0xf4, 0x69, 0x55, 0x3e, 0x5f, 0x7d, 0x80, 0x96, 0x4a, 0x8c, 0xb8, 0xd0,
-	0x59, 0xce, 0x3e, 0xce, 0xe6, 0x9f, 0x67, 0xfe, 0x23, 0xf9, 0xf0, 0x3e, 0x9a, 0xbc, 0xbb, 0x9e,
-	0x8c, 0x7d, 0x83, 0xf4, 0x00, 0xae, 0x66, 0x9f, 0xa2, 0xf9, 0x34, 0x9a, 0x2c, 0x16, 0xbe, 0x49,
-	0xba, 0xd0, 0x9a, 0x44, 0xd1, 0x3c, 0xf2, 0x2d, 0xe2, 0x80, 0x3d, 0x9e, 0xcf, 0x26, 0xbe, 0x7d,
-	0xf9, 0xcb, 0x84, 0xce, 0x42, 0xaf, 0x37, 0xb9, 0x80, 0xb6, 0xde, 0x57, 0x72, 0xa2, 0x7b, 0x72,
-	0xb0, 0xe0, 0xfd, 0xc7, 0x87, 0xa0, 0xfc, 0xac, 0x0b, 0x68, 0xeb, 0x8d, 0x2d, 0xdf, 0x38, 0x58,
-	0xe8, 0xf2, 0x8d, 0xfd, 0xa5, 0x7e, 0x03, 0x4e, 0xb9, 0x81, 0xe4, 0xa9, 0xa6, 0x6b, 0x5b, 0xdb,
-	0x3f, 0xa9, 0xc3, 0x3b, 0x25, 0x99, 0x7c, 0xa5, 0xb4, 0x37, 0x63, 0x95, 0xd2, 0xae, 0x39, 0xe4,
-	0x25, 0x58, 0x53, 0x14, 0xc4, 0xd7, 0x4c, 0xd5, 0xa7, 0x7e, 0x6f, 0x0f, 0x61, 0xe9, 0xe6, 0xb6,
-	0xad, 0x7e, 0x6b, 0xaf, 0xff, 0x06, 0x00, 0x00, 0xff, 0xff, 0xd7, 0x0b, 0x59, 0x30, 0xe7, 0x04,
-	0x00, 0x00,
+	// 865 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0xdd, 0x8e, 0xdb, 0x44,
+	0x14, 0xc6, 0x76, 0x9c, 0x38, 0xc7, 0x71, 0xd6, 0x3b, 0x29, 0xc8, 0x58, 0x54, 0x64, 0xcd, 0x05,
+	0x11, 0x12, 0xa1, 0x0a, 0x12, 0x57, 0xdc, 0xa0, 0x26, 0xac, 0x2a, 0xa4, 0xa4, 0x72, 0x36, 0xe2,
+	0x82, 0x8b, 0x95, 0xd7, 0x9e, 0x76, 0x87, 0xda, 0x19, 0xd7, 0x9e, 0x20, 0xc2, 0x5b, 0x70, 0xc3,
+	0xeb, 0xf4, 0x3d, 0x78, 0x01, 0x5e, 0x03, 0xcd, 0x8f, 0x13, 0xdb, 0xc4, 0x14, 0x89, 0xbb, 0x39,
+	0xdf, 0xf1, 0xcc, 0xf9, 0xf9, 0xbe, 0x39, 0x63, 0x70, 0x4a, 0x5c, 0xfc, 0x42, 0x62, 0x3c, 0xcf,
+	0x0b, 0xca, 0x28, 0xea, 0x25, 0x11, 0x8b, 0x82, 0x1d, 0x38, 0x2f, 0xb2, 0x9c, 0x16, 0x2c, 0xc4,
+	0x6f, 0x0f, 0xb8, 0x64, 0xc8, 0x05, 0xe3, 0x50, 0xa4, 0x9e, 0x36, 0xd5, 0x66, 0xc3, 0x90, 0x2f,
+	0x91, 0x07, 0x83, 0x28, 0x8e, 0xe9, 0x61, 0xcf, 0x3c, 0x5d, 0xa0, 0x95, 0xc9, 0x3d, 0x79, 0x41,
+	0x7f, 0xc6, 0x31, 0xf3, 0x0c, 0xe9, 0x51, 0x66, 0xf0, 0x14, 0xec, 0xea, 0xd8, 0x3c, 0x3d, 0xa2,
+	0x31, 0xe8, 0x24, 0x51, 0x67, 0xea, 0x24, 0x09, 0xde, 0x69, 0xe0, 0xec, 0xf2, 0x94, 0x46, 0x49,
+	0x15, 0xb6, 0x16, 0x44, 0xeb, 0x0c, 0xa2, 0x37, 0x82, 0x20, 0x04, 0xbd, 0x92, 0xfc, 0x86, 0x45,
+	0x6c, 0x23, 0x14, 0x6b, 0xf4, 0x09, 0x0c, 0x33, 0x9c, 0x90, 0x88, 0x1d, 0x73, 0xec, 0xf5, 0xc4,
+	0xf7, 0x67, 0x00, 0xf9, 0x60, 0x45, 0x05, 0x23, 0xaf, 0xa2, 0x98, 0x79, 0xe6, 0x54, 0x9b, 0x59,
+	0xe1, 0xc9, 0xe6, 0x71, 0xf0, 0xaf, 0x39, 0x29, 0x70, 0xe9, 0xf5, 0xc5, 0x81, 0x95, 0xc9, 0xe3,
+	0xec, 0xa3, 0x0c, 0x7b, 0x03, 0x71, 0x9c, 0x58, 0x07, 0xdf, 0x82, 0x5d, 0x15, 0x70, 0xa1, 0x40,
+	0xf4, 0x14, 0xa0, 0x24, 0xaf, 0xf7, 0x38, 0xb9, 0xe7, 0xcd, 0x94, 0x79, 0x0f, 0x25, 0xb2, 0x2b,
+	0xd2, 0xe0, 0x77, 0x0d, 0xae, 0x9e, 0xd3, 0x2c, 0x4f, 0x31, 0xc3, 0x55, 0x07, 0xda, 0x47, 0xfc,
+	0xa7, 0xb6, 0xf7, 0xfe, 0xd1, 0x91, 0xc7, 0xa8, 0x7c, 0x54, 0x6c, 0x88, 0xb5, 0xc8, 0x9e, 0x26,
+	0xd8, 0x33, 0x55, 0xf6, 0x34, 0xc1, 0xa7, 0x8a, 0xfa, 0xb5, 0x8a, 0x16, 0xe0, 0x9c, 0x53, 0xe2,
+	0x35, 0xdd, 0x80, 0x59, 0xb2, 0x88, 0x61, 0x91, 0xd3, 0x78, 0x61, 0xcf, 0xb9, 0x60, 0xe6, 0x5b,
+	0x0e, 0x85, 0xd2, 0x13, 0xdc, 0xc0, 0xd5, 0x12, 0x97, 0x71, 0x41, 0x1e, 0xba, 0xca, 0x08, 0xde,
+	0x19, 0xe0, 0x9c, 0xbf, 0xb9, 0xd4, 0xab, 0x4f, 0xc1, 0x8e, 0x0b, 0x1c, 0x31, 0x7c, 0xcf, 0x48,
+	0x86, 0x45, 0xb1, 0x46, 0x08, 0x12, 0xba, 0x23, 0x19, 0x3e, 0x27, 0x62, 0x74, 0x25, 0x82, 0x9e,
+	0x80, 0x89, 0x8b, 0x82, 0x16, 0xaa, 0x21, 0xd2, 0xe0, 0x2c, 0x10, 0xa1, 0x42, 0xc1, 0x82, 0x2c,
+	0x76, 0x28, 0x91, 0x5d, 0x91, 0xf2, 0xc0, 0xca, 0xcd, 0x88, 0xa2, 0xd7, 0x08, 0xd5, 0x0e, 0x11,
+	0xf8, 0x63, 0xb0, 0xf2, 0x83, 0xf2, 0x5a, 0x52, 0x13, 0xf9, 0x41, 0xba, 0xaa, 0x4e, 0x0f, 0x9b,
+	0x9d, 0x16, 0x7a, 0x84, 0x2e, 0x3d, 0xda, 0x6d, 0x3d, 0x4e, 0xc1, 0x8e, 0x69, 0x96, 0x17, 0xb8,
+	0x2c, 0x09, 0xdd, 0x7b, 0x23, 0xe1, 0xaf, 0x43, 0xe8, 0x33, 0x70, 0x32, 0x9a, 0x90, 0x57, 0x04,
+	0x27, 0x32, 0x0f, 0x47, 0x1c, 0x3e, 0xaa, 0x40, 0x91, 0x4c, 0x4d, 0x2a, 0xe3, 0x4e, 0xa9, 0x5c,
+	0x35, 0xa5, 0xf2, 0x11, 0xf4, 0x1f, 0x0e, 0xf1, 0x1b, 0xcc, 0x3c, 0x57, 0x38, 0x94, 0xc5, 0xef,
+	0xff, 0x1b, 0x7c, 0xf4, 0xae, 0x05, 0xc8, 0x97, 0xc1, 0x9f, 0xe2, 0xb2, 0x26, 0x51, 0xb7, 0x54,
+	0x4f, 0x04, 0xe9, 0xef, 0x27, 0xc8, 0xa8, 0x13, 0xd4, 0x62, 0xc0, 0xfa, 0x57, 0x06, 0x7a, 0x97,
+	0x19, 0x30, 0x2f, 0x30, 0xd0, 0xef, 0x62, 0x60, 0xd0, 0x62, 0x20, 0x70, 0xc0, 0xae, 0x6a, 0xcb,
+	0xd3, 0x63, 0x90, 0x03, 0xdc, 0x62, 0xd6, 0x55, 0xe7, 0x57, 0x30, 0x89, 0xe9, 0x9e, 0xe1, 0x3d,
+	0xbb, 0x4f, 0x48, 0x99, 0xd3, 0x92, 0x30, 0x4e, 0x9b, 0xbc, 0x9e, 0x48, 0xb9, 0x96, 0x67, 0x0f,
+	0xba, 0x81, 0x51, 0xb5, 0x41, 0x84, 0x37, 0x2a, 0x82, 0x05, 0x76, 0xc7, 0x13, 0xf8, 0x09, 0x2c,
+	0x11, 0x91, 0xdf, 0x8c, 0xe6, 0xd4, 0xd0, 0x5a, 0x53, 0xa3, 0x59, 0x89, 0xde, 0xd6, 0xd2, 0x85,
+	0x69, 0x18, 0xcc, 0x60, 0xb4, 0x2b, 0xa3, 0xd7, 0xf8, 0xbd, 0x53, 0x36, 0x78, 0x84, 0xd1, 0x4b,
+	0xa9, 0x0c, 0xb1, 0xa1, 0x2e, 0x1c, 0xed, 0xf2, 0xd4, 0xd5, 0x6b, 0x3d, 0x7e, 0x02, 0xa6, 0x3c,
+	0x55, 0x06, 0x97, 0x06, 0x47, 0xdf, 0x1e, 0x28, 0x8b, 0x14, 0x73, 0xd2, 0x08, 0xfe, 0xd0, 0x00,
+	0x54, 0x52, 0xbc, 0xe6, 0xee, 0xc1, 0xff, 0x3f, 0x03, 0xa1, 0x39, 0x58, 0x2a, 0xe7, 0xd2, 0x33,
+	0xa7, 0xc6, 0xcc, 0x5e, 0x20, 0x29, 0xcc, 0x7a, 0xa1, 0xe1, 0xe9, 0x9b, 0x2f, 0xbe, 0x07, 0x53,
+	0x48, 0x16, 0xd9, 0x30, 0xd8, 0xad, 0x7f, 0x58, 0x6f, 0x7e, 0x5c, 0xbb, 0x1f, 0x70, 0xe3, 0x79,
+	0xb8, 0xfa, 0xee, 0x6e, 0xb5, 0x74, 0x35, 0x34, 0x06, 0x78, 0xb1, 0x7e, 0x19, 0x6e, 0x6e, 0xc3,
+	0xd5, 0x76, 0xeb, 0xea, 0x68, 0x08, 0xe6, 0x2a, 0x0c, 0x37, 0xa1, 0x6b, 0x20, 0x0b, 0x7a, 0xcb,
+	0xcd, 0x7a, 0xe5, 0xf6, 0x16, 0x7f, 0xe9, 0x30, 0xd8, 0xca, 0xa7, 0x16, 0x3d, 0x83, 0xbe, 0x7c,
+	0x07, 0xd1, 0x44, 0xc6, 0x6e, 0x3c, 0xb6, 0xfe, 0x75, 0x13, 0xe4, 0xfd, 0x78, 0x06, 0x7d, 0xf9,
+	0xb0, 0x54, 0x3b, 0x1a, 0xef, 0xa4, 0x7f, 0xdd, 0x04, 0xf9, 0x8e, 0x6f, 0xc0, 0xaa, 0x06, 0x2c,
+	0xfa, 0x50, 0xba, 0x5b, 0x43, 0xd9, 0x9f, 0xb4, 0xe1, 0x53, 0x24, 0x2e, 0xfd, 0x73, 0xa4, 0xda,
+	0x25, 0xf7, 0xaf, 0x9b, 0x20, 0xdf, 0xf1, 0x39, 0x18, 0xb7, 0x7c, 0x44, 0x48, 0xcf, 0xf9, 0xa2,
+	0xf8, 0xe3, 0x1a, 0xc2, 0x3f, 0xfc, 0x12, 0x4c, 0x29, 0x23, 0xd5, 0xf1, 0xba, 0x08, 0x7d, 0xb7,
+	0x81, 0xa9, 0x0a, 0xaa, 0xa7, 0xa7, 0xaa, 0xa0, 0xf5, 0x3a, 0xfa, 0x93, 0x36, 0x9c, 0xa7, 0xc7,
+	0x87, 0xbe, 0xf8, 0x93, 0xf9, 0xfa, 0xef, 0x01, 0x00, 0x0c, 0x7b, 0xfe, 0xa8, 0xda, 0x08, 0x00,
+	0x00,
 }
@@ -20,6 +20,8 @@ type Service interface {
 	Describe(context.Context, *DescribeRequest) (*DescribeReply, error)
 	Update(context.Context, *UpdateRequest) (*UpdateReply, error)
 	Get(context.Context, *GetRequest) (*GetReply, error)
+	Usage(context.Context, *UsageRequest) (*UsageReply, error)
+	Complete(context.Context, *CompleteRequest) (*CompleteReply, error)
 }
 
 type ServiceClient interface {
@@ -28,6 +30,8 @@ type ServiceClient interface {
 	Describe(context.Context, *DescribeRequest, ...transport.RequestOption) (*DescribeReply, error)
 	Update(context.Context, *UpdateRequest, ...transport.RequestOption) (*UpdateReply, error)
 	Get(context.Context, *GetRequest, ...transport.RequestOption) (*GetReply, error)
+	Usage(context.Context, *UsageRequest, ...transport.RequestOption) (*UsageReply, error)
+	Complete(context.Context, *CompleteRequest, ...transport.RequestOption) (*CompleteReply, error)
 }
 
 // serviceClient an implementation of Service client.
@@ -90,6 +94,28 @@ func (c *serviceClient) Get(ctx context.Context, req *GetRequest, opts ...transp
 	return &rep, nil
 }
 
+func (c *serviceClient) Usage(ctx context.Context, req *UsageRequest, opts ...transport.RequestOption) (*UsageReply, error) {
+	var rep UsageReply
+
+	_, err := c.tp.Request("data.Usage", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) Complete(ctx context.Context, req *CompleteRequest, opts ...transport.RequestOption) (*CompleteReply, error) {
+	var rep CompleteReply
+
+	_, err := c.tp.Request("data.Complete", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
 // NewServiceClient creates a new Service client.
 func NewServiceClient(tp transport.Transport) ServiceClient {
 	return &serviceClient{tp}
@@ -180,6 +206,32 @@ func (s *ServiceServer) Serve(ctx context.Context, opts ...transport.SubscribeOp
 	if err != nil {
 		return err
 	}
+	_, err = s.tp.Subscribe("data.Usage", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req UsageRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Usage(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("data.Complete", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req CompleteRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Complete(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
 
 	sigchan := make(chan os.Signal)
 	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,285 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/rdm-academy/api/nodes"
+)
+
+// testNodes records the files added to nodes.
+type testNodes struct {
+	nodes.ServiceClient
+
+	err   error
+	added chan *nodes.AddFilesRequest
+}
+
+func newTestNodes() *testNodes {
+	return &testNodes{
+		added: make(chan *nodes.AddFilesRequest, 10),
+	}
+}
+
+func (n *testNodes) AddFiles(ctx context.Context, req *nodes.AddFilesRequest, opts ...transport.RequestOption) (*nodes.NoReply, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+
+	n.added <- req
+	return &nodes.NoReply{}, nil
+}
+
+// pending inserts an upload in the given state and stores its contents
+// under its id.
+func pending(t *testing.T, s *service, o *object, b []byte) *object {
+	o.Bucket = s.bucket
+	o.CreateTime = time.Now()
+
+	if o.State == State_UNKNOWN {
+		o.State = State_CREATED
+	}
+
+	if err := s.db.C(objectsCol).Insert(o); err != nil {
+		t.Fatal(err)
+	}
+
+	if b != nil {
+		put(t, s, o, b)
+	}
+
+	return o
+}
+
+func hashOf(b []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+}
+
+func TestComplete(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	s.nodes = newTestNodes()
+
+	ctx := context.Background()
+
+	pending(t, s, &object{ID: "1", Account: "a1", Project: "p1"}, []byte("hello"))
+	pending(t, s, &object{ID: "2", Account: "a1", Project: "p1"}, nil)
+	pending(t, s, &object{ID: "3", Account: "a1", Project: "p1", ImportURL: "http://example.com/x"}, []byte("hello"))
+	pending(t, s, &object{ID: "5", Account: "a1", Project: "p1", Name: "b.txt"}, []byte("hello"))
+
+	for _, x := range []struct {
+		name string
+		req  *CompleteRequest
+		code codes.Code
+	}{
+		{"no node", &CompleteRequest{Id: "1", Account: "a1", Project: "p1", Name: "a.txt"}, codes.InvalidArgument},
+		{"no name", &CompleteRequest{Id: "1", Account: "a1", Project: "p1", Node: "n1"}, codes.InvalidArgument},
+		{"not found", &CompleteRequest{Id: "4", Account: "a1", Project: "p1", Node: "n1", Name: "a.txt"}, codes.NotFound},
+		{"other account", &CompleteRequest{Id: "1", Account: "a2", Project: "p1", Node: "n1", Name: "a.txt"}, codes.PermissionDenied},
+		{"other project", &CompleteRequest{Id: "1", Account: "a1", Project: "p2", Node: "n1", Name: "a.txt"}, codes.PermissionDenied},
+		{"not uploaded", &CompleteRequest{Id: "2", Account: "a1", Project: "p1", Node: "n1", Name: "a.txt"}, codes.FailedPrecondition},
+		{"import", &CompleteRequest{Id: "3", Account: "a1", Project: "p1", Node: "n1", Name: "a.txt"}, codes.FailedPrecondition},
+		{"other name than declared", &CompleteRequest{Id: "5", Account: "a1", Project: "p1", Node: "n1", Name: "a.txt"}, codes.InvalidArgument},
+	} {
+		_, err := s.Complete(ctx, x.req)
+		if code := status.Code(err); code != x.code {
+			t.Errorf("%s: expected %s, got %s", x.name, x.code, code)
+		}
+	}
+
+	rep, err := s.Complete(ctx, &CompleteRequest{
+		Id:      "1",
+		Account: "a1",
+		Project: "p1",
+		Node:    "n1",
+		Name:    "a.txt",
+		Hash:    hashOf([]byte("hello")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.State != State_INPROGRESS {
+		t.Errorf("expected %s, got %s", State_INPROGRESS, rep.State)
+	}
+
+	// Uploads are only completed once.
+	_, err = s.Complete(ctx, &CompleteRequest{Id: "1", Account: "a1", Project: "p1", Node: "n1", Name: "a.txt"})
+	if code := status.Code(err); code != codes.FailedPrecondition {
+		t.Errorf("expected %s, got %s", codes.FailedPrecondition, code)
+	}
+
+	// The file is added to the node once it is verified.
+	select {
+	case req := <-s.nodes.(*testNodes).added:
+		if req.Id != "n1" || req.Project != "p1" || len(req.Files) != 1 || req.Files[0].Id != "1" || req.Files[0].Name != "a.txt" {
+			t.Errorf("unexpected files added: %v", req)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatal("file was not added to the node")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	contents := []byte("hello")
+
+	for i, x := range []struct {
+		name    string
+		obj     *object
+		moved   bool
+		nodeErr error
+		state   State
+	}{
+		{"no hash", &object{}, false, nil, State_DONE},
+		{"hash", &object{ExpectedHash: hashOf(contents)}, false, nil, State_DONE},
+		{"hash without prefix", &object{ExpectedHash: hashOf(contents)[len("sha256:"):]}, false, nil, State_DONE},
+		{"declared size", &object{DeclaredSize: 5}, false, nil, State_DONE},
+		{"resumed after the move", &object{Key: "moved"}, true, nil, State_DONE},
+		{"hash mismatch", &object{ExpectedHash: hashOf([]byte("other"))}, false, nil, State_ERROR},
+		{"size mismatch", &object{DeclaredSize: 4}, false, nil, State_ERROR},
+		{"node error", &object{}, false, errors.New("node not found"), State_ERROR},
+	} {
+		n := newTestNodes()
+		n.err = x.nodeErr
+		s.nodes = n
+
+		o := x.obj
+		o.ID = fmt.Sprint(i)
+		o.Account = "a1"
+		o.Project = "p1"
+		o.Node = "n1"
+		o.Name = "a.txt"
+		o.State = State_INPROGRESS
+
+		if x.moved {
+			pending(t, s, o, nil)
+			put(t, s, o, contents)
+		} else {
+			pending(t, s, o, contents)
+		}
+
+		s.verify(ctx, o)
+
+		var d object
+		if err := s.db.C(objectsCol).FindId(o.ID).One(&d); err != nil {
+			t.Fatal(err)
+		}
+
+		if d.State != x.state {
+			t.Errorf("%s: expected %s, got %s (%s)", x.name, x.state, d.State, d.Error)
+			continue
+		}
+
+		// The contents can no longer be replaced using the signed URL.
+		if ok, _ := s.storage.Bucket(s.bucket).Object(o.ID).Exists(ctx); ok {
+			t.Errorf("%s: expected object to be moved", x.name)
+		}
+
+		stored, _ := s.object(&d).Exists(ctx)
+
+		if x.state == State_DONE {
+			if d.Size != int64(len(contents)) || d.Hash != hashOf(contents) {
+				t.Errorf("%s: unexpected size %d and hash %s", x.name, d.Size, d.Hash)
+			}
+			if !stored {
+				t.Errorf("%s: expected object in storage", x.name)
+			}
+			if len(n.added) != 1 {
+				t.Errorf("%s: expected file to be added to the node", x.name)
+			}
+			continue
+		}
+
+		if stored {
+			t.Errorf("%s: expected object to be removed from storage", x.name)
+		}
+		if len(n.added) != 0 {
+			t.Errorf("%s: expected file not to be added to the node", x.name)
+		}
+	}
+}
+
+func TestSweep(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	n := newTestNodes()
+	s.nodes = n
+
+	ctx := context.Background()
+	stale := time.Now().Add(-completeTimeout - sweepInterval - time.Minute)
+
+	pending(t, s, &object{ID: "upload", Account: "a1", Project: "p1", Node: "n1", Name: "a.txt", State: State_INPROGRESS, ImportTime: stale}, []byte("hello"))
+	pending(t, s, &object{ID: "import", Account: "a1", Project: "p1", ImportURL: "http://example.com/x", State: State_INPROGRESS, ImportTime: stale}, []byte("hel"))
+	pending(t, s, &object{ID: "running", Account: "a1", Project: "p1", Node: "n1", Name: "b.txt", State: State_INPROGRESS, ImportTime: time.Now()}, []byte("hello"))
+	pending(t, s, &object{ID: "expired", Account: "a1", Project: "p1", State: State_DONE, Artifact: true, Expires: time.Now().Add(-time.Minute)}, []byte("package"))
+	pending(t, s, &object{ID: "kept", Account: "a1", Project: "p1", State: State_DONE, Artifact: true, Expires: time.Now().Add(time.Hour)}, []byte("package"))
+
+	// Put to the signed URL but never completed.
+	abandoned := pending(t, s, &object{ID: "abandoned", Account: "a1", Project: "p1"}, []byte("hello"))
+	if err := s.db.C(objectsCol).UpdateId("abandoned", bson.M{"$set": bson.M{"create_time": time.Now().Add(-pendingTimeout - time.Minute)}}); err != nil {
+		t.Fatal(err)
+	}
+	pending(t, s, &object{ID: "created", Account: "a1", Project: "p1"}, []byte("hello"))
+
+	if err := s.sweep(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The interrupted upload is verified again.
+	select {
+	case req := <-n.added:
+		if req.Files[0].Id != "upload" {
+			t.Errorf("expected upload to be added, got %s", req.Files[0].Id)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatal("upload was not verified")
+	}
+
+	for id, state := range map[string]State{
+		"import":    State_ERROR,
+		"running":   State_INPROGRESS,
+		"abandoned": State_ERROR,
+		"created":   State_CREATED,
+	} {
+		var d object
+		if err := s.db.C(objectsCol).FindId(id).One(&d); err != nil {
+			t.Fatal(err)
+		}
+
+		if d.State != state {
+			t.Errorf("%s: expected %s, got %s", id, state, d.State)
+		}
+	}
+
+	// Expired objects are removed with their contents.
+	if n, _ := s.db.C(objectsCol).FindId("expired").Count(); n != 0 {
+		t.Error("expected expired object to be removed")
+	}
+	if ok, _ := s.storage.Bucket(s.bucket).Object("expired").Exists(ctx); ok {
+		t.Error("expected expired object to be removed from storage")
+	}
+	if n, _ := s.db.C(objectsCol).FindId("kept").Count(); n != 1 {
+		t.Error("expected object that has not expired to be kept")
+	}
+
+	// Contents that were never completed are removed.
+	if ok, _ := s.object(abandoned).Exists(ctx); ok {
+		t.Error("expected abandoned upload to be removed from storage")
+	}
+}
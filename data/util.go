@@ -8,6 +8,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rdm-academy/api/storage"
 )
 
 // readSizer wraps a reader and counts the number of bytes read.
@@ -68,8 +73,10 @@ func upload(ctx context.Context, url string, mediatype string, body io.Reader) (
 	}, nil
 }
 
-func Upload(ctx context.Context, svc ServiceClient, mediatype string, body io.Reader) (string, error) {
-	rep, err := svc.Upload(ctx, &UploadRequest{})
+// Upload creates an object using the request, uploads the body to the
+// signed URL and marks the object as done.
+func Upload(ctx context.Context, svc ServiceClient, req *UploadRequest, mediatype string, body io.Reader) (string, error) {
+	rep, err := svc.Upload(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -114,8 +121,35 @@ func Upload(ctx context.Context, svc ServiceClient, mediatype string, body io.Re
 
 	// Log so this can be manually set if need be.
 	if err != nil {
+		// The object exceeded a quota and was removed.
+		if status.Code(err) == codes.ResourceExhausted {
+			return "", err
+		}
+
 		return "", fmt.Errorf("failed to set DONE state: %s\n%s", id, err)
 	}
 
 	return id, nil
 }
+
+// Object returns the stored object of the description.
+func Object(stg storage.Storage, desc *DescribeReply) storage.Object {
+	return stg.Bucket(desc.Bucket).Object(desc.Key)
+}
+
+// Open returns a reader for the contents of the object. It is read from
+// storage directly. The caller is responsible for closing the reader.
+func Open(ctx context.Context, svc ServiceClient, stg storage.Storage, id string) (io.ReadCloser, error) {
+	desc, err := svc.Describe(ctx, &DescribeRequest{
+		Id: id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if desc.State != State_DONE {
+		return nil, status.Error(codes.FailedPrecondition, "object is not stored")
+	}
+
+	return Object(stg, desc).Reader(ctx)
+}
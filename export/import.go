@@ -0,0 +1,486 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/internal/rpcerr"
+	"github.com/rdm-academy/api/nodes"
+	"github.com/rdm-academy/api/project"
+)
+
+// importReader provides access to the payload of a package.
+type importReader struct {
+	files map[string]*zip.File
+
+	// Prefix of the payload within the archive.
+	payload string
+
+	// Checksums of the payload files from the bag manifest keyed by
+	// the path relative to the payload.
+	sums map[string]string
+}
+
+// openPayload returns a reader for the payload file.
+func (r *importReader) openPayload(name string) (io.ReadCloser, error) {
+	f, ok := r.files[r.payload+name]
+	if !ok {
+		return nil, fmt.Errorf("%s: missing from package", name)
+	}
+
+	return f.Open()
+}
+
+func (r *importReader) readPayload(name string) ([]byte, error) {
+	rc, err := r.openPayload(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return ioutil.ReadAll(rc)
+}
+
+// verify checks the payload file against the bag manifest and the
+// expected hash, if provided.
+func (r *importReader) verify(name string, hash string) error {
+	rc, err := r.openPayload(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	if hash != "" && sum != strings.TrimPrefix(hash, "sha256:") {
+		return fmt.Errorf("%s: checksum mismatch", name)
+	}
+
+	if x, ok := r.sums[name]; ok && x != sum {
+		return fmt.Errorf("%s: bag manifest checksum mismatch", name)
+	}
+
+	return nil
+}
+
+// newImportReader locates the payload of the package. A BagIt bag is
+// identified by its bagit.txt and an RO-Crate by its metadata file.
+func newImportReader(zr *zip.Reader) (*importReader, error) {
+	r := &importReader{
+		files: make(map[string]*zip.File, len(zr.File)),
+		sums:  make(map[string]string),
+	}
+
+	var (
+		bag   string
+		crate string
+		isBag bool
+	)
+
+	for _, f := range zr.File {
+		r.files[f.Name] = f
+
+		dir, name := path.Split(f.Name)
+
+		switch name {
+		case "bagit.txt":
+			if !isBag || len(dir) < len(bag) {
+				bag = dir
+				isBag = true
+			}
+
+		case roCrateMetadata:
+			if crate == "" || len(f.Name) < len(crate) {
+				crate = f.Name
+			}
+		}
+	}
+
+	switch {
+	case isBag:
+		r.payload = bag + "data/"
+
+		if f, ok := r.files[bag+"manifest-sha256.txt"]; ok {
+			if err := r.readManifest(f); err != nil {
+				return nil, err
+			}
+		}
+
+	case crate != "":
+		r.payload = strings.TrimSuffix(crate, roCrateMetadata)
+
+	default:
+		return nil, errors.New("package is not a BagIt bag or RO-Crate")
+	}
+
+	return r, nil
+}
+
+func (r *importReader) readManifest(f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	sc := bufio.NewScanner(rc)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+
+		toks := strings.SplitN(line, " ", 2)
+		if len(toks) != 2 {
+			return fmt.Errorf("invalid manifest line: %s", line)
+		}
+
+		name := strings.TrimSpace(toks[1])
+		if !strings.HasPrefix(name, "data/") {
+			continue
+		}
+
+		r.sums[strings.TrimPrefix(name, "data/")] = strings.ToLower(toks[0])
+	}
+
+	return sc.Err()
+}
+
+// downloadPackage copies the package from storage into a
+// temporary file since zip archives require random access.
+func (s *service) downloadPackage(ctx context.Context, id string) (*os.File, int64, error) {
+	rc, err := data.Open(ctx, s.dataSvc, s.storage, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rc.Close()
+
+	f, err := ioutil.TempFile("", "import-")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	n, err := io.Copy(f, rc)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, 0, err
+	}
+
+	return f, n, nil
+}
+
+// importPackage creates a new project from the package of the job.
+func (s *service) importPackage(ctx context.Context, j *job, req *ImportRequest) (bson.M, error) {
+	f, size, err := s.downloadPackage(ctx, j.File)
+	if err != nil {
+		return nil, fmt.Errorf("download package: %s", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		return nil, fmt.Errorf("open package: %s", err)
+	}
+
+	projectId, err := s.importArchive(ctx, zr, j, req, func(id string) {
+		s.setState(j.ID, bson.M{
+			"project": id,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bson.M{
+		"project": projectId,
+	}, nil
+}
+
+// importArchive creates a new project from the package archive. The
+// package is verified in full before anything is created. setProject is
+// called with the id of the project once it is created and with an empty
+// id if the partial project is removed again.
+func (s *service) importArchive(ctx context.Context, zr *zip.Reader, j *job, req *ImportRequest, setProject func(string)) (string, error) {
+	r, err := newImportReader(zr)
+	if err != nil {
+		return "", err
+	}
+
+	buf, err := r.readPayload("project.json")
+	if err != nil {
+		return "", err
+	}
+
+	var pp packageProject
+	if err := json.Unmarshal(buf, &pp); err != nil {
+		return "", fmt.Errorf("project.json: %s", err)
+	}
+
+	source, err := r.readPayload("workflow.yml")
+	if err != nil {
+		return "", err
+	}
+
+	// Verify every payload file listed in the bag manifest as well as
+	// the files referenced by the project.
+	for name := range r.sums {
+		if err := r.verify(name, ""); err != nil {
+			return "", err
+		}
+	}
+
+	for _, n := range pp.Nodes {
+		for _, x := range n.Files {
+			if err := r.verify(x.Path, x.Hash); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	var commits []*packageCommit
+	if req.History {
+		buf, err := r.readPayload("history.json")
+		if err != nil {
+			return "", err
+		}
+
+		if err := json.Unmarshal(buf, &commits); err != nil {
+			return "", fmt.Errorf("history.json: %s", err)
+		}
+	}
+
+	name := req.Name
+	if name == "" {
+		name = pp.Name
+	}
+
+	prep, err := s.projectSvc.CreateProject(ctx, &project.CreateProjectRequest{
+		Account:     j.Account,
+		Name:        name,
+		Description: pp.Description,
+	})
+	if err != nil {
+		return "", fmt.Errorf("create project: %s", rpcerr.Message(err))
+	}
+
+	projectId := prep.Project.Id
+
+	// Make the project available to the caller as soon as possible.
+	setProject(projectId)
+
+	if err := s.restoreProject(ctx, r, j.Account, projectId, &pp, source, commits); err != nil {
+		if s.discardProject(j, projectId) {
+			setProject("")
+		}
+		return "", err
+	}
+
+	return projectId, nil
+}
+
+// restoreProject restores the workflow, nodes, files and history of the
+// package into the new project.
+func (s *service) restoreProject(ctx context.Context, r *importReader, account, projectId string, pp *packageProject, source []byte, commits []*packageCommit) error {
+	if len(source) > 0 {
+		_, err := s.projectSvc.UpdateWorkflow(ctx, &project.UpdateWorkflowRequest{
+			Account: account,
+			Id:      projectId,
+			Source:  string(source),
+		})
+		if err != nil {
+			return fmt.Errorf("update workflow: %s", rpcerr.Message(err))
+		}
+	}
+
+	for _, n := range pp.Nodes {
+		if err := s.importNode(ctx, r, account, projectId, n); err != nil {
+			return fmt.Errorf("node %s: %s", n.ID, rpcerr.Message(err))
+		}
+	}
+
+	if len(commits) > 0 {
+		if err := s.replayHistory(ctx, projectId, commits); err != nil {
+			return fmt.Errorf("replay history: %s", rpcerr.Message(err))
+		}
+	}
+
+	return nil
+}
+
+// discardProject removes a partially imported project and reports whether
+// it was removed. Purging it removes its nodes, files and history as well.
+// A new context is used since the import may have failed because the job
+// timed out.
+func (s *service) discardProject(j *job, projectId string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	_, err := s.projectSvc.DeleteProject(ctx, &project.DeleteProjectRequest{
+		Account: j.Account,
+		Id:      projectId,
+		Purge:   true,
+	})
+	if err != nil {
+		log.Printf("export: failed to remove project %s of job %s: %s", projectId, j.ID, err)
+		return false
+	}
+
+	return true
+}
+
+// importNode creates the node and restores its title, notes and files.
+// The node may have already been created in response to the workflow
+// update.
+func (s *service) importNode(ctx context.Context, r *importReader, account, projectId string, n *packageNode) error {
+	_, err := s.nodeSvc.Create(ctx, &nodes.CreateRequest{
+		Id:      n.ID,
+		Project: projectId,
+		Type:    nodes.NodeType(nodes.NodeType_value[strings.ToUpper(n.Type)]),
+		Title:   n.Title,
+		Account: account,
+	})
+	if err != nil {
+		sts, ok := status.FromError(err)
+		if !ok || sts.Code() != codes.AlreadyExists {
+			return err
+		}
+
+		_, err = s.nodeSvc.SetTitle(ctx, &nodes.SetTitleRequest{
+			Id:      n.ID,
+			Project: projectId,
+			Title:   n.Title,
+			Account: account,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if n.Notes != "" {
+		_, err := s.nodeSvc.SetNotes(ctx, &nodes.SetNotesRequest{
+			Id:      n.ID,
+			Project: projectId,
+			Notes:   n.Notes,
+			Account: account,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(n.Files) == 0 {
+		return nil
+	}
+
+	files := make([]*nodes.File, len(n.Files))
+
+	for i, x := range n.Files {
+		id, err := s.importFile(ctx, r, account, projectId, x)
+		if err != nil {
+			return fmt.Errorf("%s: %s", x.Path, err)
+		}
+
+		files[i] = &nodes.File{
+			Id:   id,
+			Name: x.Name,
+		}
+	}
+
+	_, err = s.nodeSvc.AddFiles(ctx, &nodes.AddFilesRequest{
+		Id:      n.ID,
+		Project: projectId,
+		Files:   files,
+		Account: account,
+	})
+	return err
+}
+
+// importFile uploads the file to the data service and confirms the
+// stored hash matches the package.
+func (s *service) importFile(ctx context.Context, r *importReader, account, projectId string, x *packageFile) (string, error) {
+	rc, err := r.openPayload(x.Path)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	id, err := data.Upload(ctx, s.dataSvc, &data.UploadRequest{
+		Account:   account,
+		Project:   projectId,
+		Size:      x.Size,
+		Mediatype: x.Mediatype,
+	}, x.Mediatype, rc)
+	if err != nil {
+		return "", err
+	}
+
+	desc, err := s.dataSvc.Describe(ctx, &data.DescribeRequest{
+		Id: id,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if x.Hash != "" && desc.Hash != x.Hash {
+		return "", errors.New("stored checksum mismatch")
+	}
+
+	return id, nil
+}
+
+// replayHistory restores the commits of the package. The commits and
+// their events are stored latest first.
+func (s *service) replayHistory(ctx context.Context, projectId string, commits []*packageCommit) error {
+	req := &commitlog.ReplayRequest{
+		Project: projectId,
+		Commits: make([]*commitlog.Commit, len(commits)),
+	}
+
+	for i, c := range commits {
+		events := make([]*commitlog.Event, len(c.Events))
+		for k, e := range c.Events {
+			events[len(events)-k-1] = &commitlog.Event{
+				Project: projectId,
+				Time:    e.Time,
+				Type:    e.Type,
+				Author:  e.Author,
+				Data:    []byte(e.Data),
+			}
+		}
+
+		req.Commits[len(commits)-i-1] = &commitlog.Commit{
+			Msg:    c.Msg,
+			Author: c.Author,
+			Time:   c.Time,
+			Events: events,
+		}
+	}
+
+	_, err := s.commitlogSvc.Replay(ctx, req)
+	return err
+}
@@ -0,0 +1,170 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// openPackage opens the package, replacing the contents of the payload
+// files ending in one of the keys.
+func openPackage(t *testing.T, buf []byte, replace map[string]string) *zip.Reader {
+	if len(replace) > 0 {
+		zr, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		zw := zip.NewWriter(&out)
+
+		for _, f := range zr.File {
+			b := readZipFile(t, f)
+			for suffix, v := range replace {
+				if strings.HasSuffix(f.Name, suffix) {
+					b = []byte(v)
+				}
+			}
+
+			w, err := zw.Create(f.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write(b); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		buf = out.Bytes()
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return zr
+}
+
+func TestImportArchive(t *testing.T) {
+	s, cleanup := testService()
+	defer cleanup()
+
+	ctx := context.Background()
+	buf := testPackage(t, s)
+
+	var states []string
+	setProject := func(id string) {
+		states = append(states, id)
+	}
+
+	j := &job{ID: "j1", Account: "u2"}
+
+	id, err := s.importArchive(ctx, openPackage(t, buf, nil), j, &ImportRequest{History: true}, setProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	projects := s.projectSvc.(*testProjects)
+	nodeSvc := s.nodeSvc.(*testNodes)
+
+	if len(projects.created) != 1 || projects.created[0].Name != "Study" || projects.created[0].Account != "u2" {
+		t.Fatalf("expected Study to be created, got %v", projects.created)
+	}
+	if len(states) != 1 || states[0] != id {
+		t.Errorf("expected the project to be set, got %v", states)
+	}
+
+	if len(projects.workflows) != 1 || projects.workflows[0].Source != testProject.Workflow.Source {
+		t.Errorf("expected the workflow to be restored, got %v", projects.workflows)
+	}
+
+	if len(nodeSvc.created) != 1 || nodeSvc.created[0].Id != "raw" || nodeSvc.created[0].Title != "Raw data" {
+		t.Errorf("expected the node to be created, got %v", nodeSvc.created)
+	}
+	if len(nodeSvc.notes) != 1 || nodeSvc.notes[0].Notes != "Collected in 2017." {
+		t.Errorf("expected the notes to be restored, got %v", nodeSvc.notes)
+	}
+
+	if len(nodeSvc.files) != 1 || len(nodeSvc.files[0].Files) != 1 {
+		t.Fatalf("expected a file to be added, got %v", nodeSvc.files)
+	}
+
+	f := nodeSvc.files[0].Files[0]
+	if f.Name != "values.csv" {
+		t.Errorf("expected values.csv, got %s", f.Name)
+	}
+	if b, _ := s.dataSvc.(*testData).object(f.Id); string(b) != testFile {
+		t.Errorf("expected the file contents to be uploaded, got %q", b)
+	}
+
+	replayed := s.commitlogSvc.(*testCommitlog).replayed
+	if len(replayed) != 1 || len(replayed[0].Commits) != 1 || replayed[0].Commits[0].Msg != "Add raw data" {
+		t.Errorf("expected the history to be replayed, got %v", replayed)
+	}
+
+	if len(projects.purged) != 0 {
+		t.Errorf("expected nothing to be purged, got %v", projects.purged)
+	}
+}
+
+func TestImportArchiveTampered(t *testing.T) {
+	s, cleanup := testService()
+	defer cleanup()
+
+	ctx := context.Background()
+	buf := testPackage(t, s)
+
+	projects := s.projectSvc.(*testProjects)
+	j := &job{ID: "j1", Account: "u2"}
+
+	// Payload files that do not match the manifest are rejected before
+	// anything is created.
+	for _, name := range []string{"values.csv", "workflow.yml"} {
+		zr := openPackage(t, buf, map[string]string{name: "tampered"})
+
+		_, err := s.importArchive(ctx, zr, j, &ImportRequest{}, func(string) {})
+		if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+			t.Errorf("%s: expected checksum mismatch, got %v", name, err)
+		}
+	}
+
+	if len(projects.created) != 0 {
+		t.Fatalf("expected no project to be created, got %v", projects.created)
+	}
+
+	// A file that is not stored as packaged is only noticed after the
+	// project is created, which is removed again.
+	s.dataSvc.(*testData).corrupt = true
+
+	var states []string
+	setProject := func(id string) {
+		states = append(states, id)
+	}
+
+	_, err := s.importArchive(ctx, openPackage(t, buf, nil), j, &ImportRequest{}, setProject)
+	if err == nil || !strings.Contains(err.Error(), "stored checksum mismatch") {
+		t.Fatalf("expected stored checksum mismatch, got %v", err)
+	}
+
+	if len(projects.created) != 1 {
+		t.Fatalf("expected a project to be created, got %v", projects.created)
+	}
+	if len(states) != 2 || states[0] == "" || states[1] != "" {
+		t.Errorf("expected the project to be set and unset, got %v", states)
+	}
+	if len(projects.purged) != 1 || projects.purged[0] != states[0] {
+		t.Errorf("expected %s to be purged, got %v", states[0], projects.purged)
+	}
+
+	// No files were added to the partial project.
+	if files := s.nodeSvc.(*testNodes).files; len(files) != 0 {
+		t.Errorf("expected no files to be added, got %v", files)
+	}
+}
@@ -0,0 +1,645 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rdm-academy/api/account"
+	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/internal/archive"
+	"github.com/rdm-academy/api/nodes"
+	"github.com/rdm-academy/api/project"
+)
+
+// The package is a BagIt bag (RFC 8493) whose payload is an RO-Crate.
+// The layout of the payload is:
+//
+//	data/ro-crate-metadata.json
+//	data/project.json
+//	data/workflow.yml
+//	data/history.json
+//	data/nodes/<title>/notes.md
+//	data/nodes/<title>/<file>
+const (
+	bagitVersion    = "1.0"
+	roCrateVersion  = "https://w3id.org/ro/crate/1.1"
+	roCrateContext  = "https://w3id.org/ro/crate/1.1/context"
+	roCrateMetadata = "ro-crate-metadata.json"
+	nodeNotes       = "notes.md"
+
+	// Authors with a verified ORCID iD are identified by its URL.
+	orcidURL = "https://orcid.org/"
+)
+
+type packageFile struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Hash      string `json:"hash"`
+	Mediatype string `json:"mediatype,omitempty"`
+	PutTime   int64  `json:"put_time,omitempty"`
+}
+
+type packageNode struct {
+	ID     string         `json:"id"`
+	Type   string         `json:"type"`
+	Title  string         `json:"title"`
+	Notes  string         `json:"notes,omitempty"`
+	Path   string         `json:"path"`
+	Input  []string       `json:"input,omitempty"`
+	Output []string       `json:"output,omitempty"`
+	Files  []*packageFile `json:"files"`
+}
+
+// packageProject is written to data/project.json.
+type packageProject struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Created     int64            `json:"created"`
+	Modified    int64            `json:"modified"`
+	Exported    int64            `json:"exported"`
+	Authors     []*packageAuthor `json:"authors"`
+	Nodes       []*packageNode   `json:"nodes"`
+}
+
+// packageAuthor is an author of commits in the history.
+type packageAuthor struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Orcid string `json:"orcid,omitempty"`
+}
+
+type packageEvent struct {
+	ID          string          `json:"id"`
+	Time        int64           `json:"time"`
+	Type        string          `json:"type"`
+	Author      string          `json:"author"`
+	AuthorOrcid string          `json:"author_orcid,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"`
+}
+
+// packageCommit is an entry of data/history.json.
+type packageCommit struct {
+	ID          string          `json:"id"`
+	Msg         string          `json:"msg"`
+	Author      string          `json:"author"`
+	AuthorOrcid string          `json:"author_orcid,omitempty"`
+	Time        int64           `json:"time"`
+	Parent      string          `json:"parent,omitempty"`
+	Events      []*packageEvent `json:"events"`
+}
+
+func isNotFound(err error) bool {
+	sts, ok := status.FromError(err)
+	return ok && sts.Code() == codes.NotFound
+}
+
+// describeProject collects the nodes and files of the project workflow.
+// Files that are not stored are skipped.
+func (s *service) describeProject(ctx context.Context, p *project.Project) (*packageProject, error) {
+	pp := &packageProject{
+		ID:          p.Id,
+		Name:        p.Name,
+		Description: p.Description,
+		Created:     p.Created,
+		Modified:    p.Modified,
+		Exported:    time.Now().Unix(),
+		Nodes:       []*packageNode{},
+	}
+
+	if p.Workflow == nil {
+		return pp, nil
+	}
+
+	ids := make([]string, 0, len(p.Workflow.Nodes))
+	for id := range p.Workflow.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	dirs := make(map[string]struct{})
+
+	for _, id := range ids {
+		wn := p.Workflow.Nodes[id]
+
+		n := &packageNode{
+			ID:     id,
+			Type:   wn.Type,
+			Title:  wn.Title,
+			Input:  wn.Input,
+			Output: wn.Output,
+			Files:  []*packageFile{},
+		}
+
+		rep, err := s.nodeSvc.Get(ctx, &nodes.GetRequest{
+			Project: p.Id,
+			Id:      id,
+		})
+		if err != nil && !isNotFound(err) {
+			return nil, err
+		}
+
+		// The node has data associated with it.
+		if rep != nil {
+			n.Title = rep.Title
+			n.Notes = rep.Notes
+		}
+
+		n.Path = path.Join("nodes", archive.UniqueName(dirs, archive.PathName(n.Title), id))
+
+		if rep != nil {
+			names := make(map[string]struct{})
+
+			// Reserved for the node notes.
+			if n.Notes != "" {
+				names[nodeNotes] = struct{}{}
+			}
+
+			for _, f := range rep.Files {
+				desc, err := s.dataSvc.Describe(ctx, &data.DescribeRequest{
+					Id: f.Id,
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				if desc.State != data.State_DONE {
+					continue
+				}
+
+				name := archive.UniqueName(names, archive.PathName(f.Name), f.Id)
+
+				n.Files = append(n.Files, &packageFile{
+					ID:        f.Id,
+					Name:      f.Name,
+					Path:      path.Join(n.Path, name),
+					Size:      desc.Size,
+					Hash:      desc.Hash,
+					Mediatype: desc.Mediatype,
+					PutTime:   desc.PutTime,
+				})
+			}
+		}
+
+		pp.Nodes = append(pp.Nodes, n)
+	}
+
+	return pp, nil
+}
+
+// history returns all commits of the project, latest first.
+func (s *service) history(ctx context.Context, projectId string) ([]*packageCommit, error) {
+	req := commitlog.HistoryRequest{
+		Project: projectId,
+	}
+
+	commits := make([]*packageCommit, 0)
+
+	for {
+		rep, err := s.commitlogSvc.History(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+
+		if rep.Commit == nil {
+			break
+		}
+
+		events := make([]*packageEvent, len(rep.Commit.Events))
+		for i, e := range rep.Commit.Events {
+			events[i] = &packageEvent{
+				ID:     e.Id,
+				Time:   e.Time,
+				Type:   e.Type,
+				Author: e.Author,
+				Data:   json.RawMessage(e.Data),
+			}
+		}
+
+		commits = append(commits, &packageCommit{
+			ID:     rep.Commit.Id,
+			Msg:    rep.Commit.Msg,
+			Author: rep.Commit.Author,
+			Time:   rep.Commit.Time,
+			Parent: rep.Commit.Parent,
+			Events: events,
+		})
+
+		// Any more?
+		if rep.Next == "" {
+			break
+		}
+
+		req.Commit = rep.Next
+	}
+
+	return commits, nil
+}
+
+// authors looks up the authors of the commits and sets the verified ORCID
+// iDs on the commits and events. Authors that no longer exist are skipped.
+func (s *service) authors(ctx context.Context, commits []*packageCommit) ([]*packageAuthor, error) {
+	seen := make(map[string]*packageAuthor)
+	var list []*packageAuthor
+
+	lookup := func(id string) (*packageAuthor, error) {
+		if a, ok := seen[id]; ok || id == "" {
+			return a, nil
+		}
+
+		rep, err := s.accountSvc.GetUser(ctx, &account.GetUserRequest{
+			Id: id,
+		})
+		if err != nil {
+			if isNotFound(err) {
+				seen[id] = nil
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		a := &packageAuthor{
+			ID:   rep.Id,
+			Name: rep.Name,
+		}
+		if rep.OrcidVerified {
+			a.Orcid = rep.Orcid
+		}
+
+		seen[id] = a
+		list = append(list, a)
+
+		return a, nil
+	}
+
+	for _, c := range commits {
+		a, err := lookup(c.Author)
+		if err != nil {
+			return nil, err
+		}
+		if a != nil {
+			c.AuthorOrcid = a.Orcid
+		}
+
+		for _, e := range c.Events {
+			a, err := lookup(e.Author)
+			if err != nil {
+				return nil, err
+			}
+			if a != nil {
+				e.AuthorOrcid = a.Orcid
+			}
+		}
+	}
+
+	return list, nil
+}
+
+// roCrate builds the RO-Crate metadata for the project.
+func roCrate(pp *packageProject) map[string]interface{} {
+	ref := func(id string) map[string]string {
+		return map[string]string{"@id": id}
+	}
+
+	var (
+		graph []interface{}
+		parts []interface{}
+	)
+
+	graph = append(graph, map[string]interface{}{
+		"@id":        roCrateMetadata,
+		"@type":      "CreativeWork",
+		"conformsTo": ref(roCrateVersion),
+		"about":      ref("./"),
+	})
+
+	for _, f := range []struct{ id, name, format string }{
+		{"project.json", "Project metadata", "application/json"},
+		{"workflow.yml", "Workflow", "application/x-yaml"},
+		{"history.json", "Commit history", "application/json"},
+	} {
+		parts = append(parts, ref(f.id))
+		graph = append(graph, map[string]interface{}{
+			"@id":            f.id,
+			"@type":          "File",
+			"name":           f.name,
+			"encodingFormat": f.format,
+		})
+	}
+
+	for _, n := range pp.Nodes {
+		dir := n.Path + "/"
+		parts = append(parts, ref(dir))
+
+		var files []interface{}
+		for _, f := range n.Files {
+			files = append(files, ref(f.Path))
+
+			e := map[string]interface{}{
+				"@id":          f.Path,
+				"@type":        "File",
+				"name":         f.Name,
+				"contentSize":  f.Size,
+				"sha256":       strings.TrimPrefix(f.Hash, "sha256:"),
+				"identifier":   f.ID,
+				"dateModified": time.Unix(f.PutTime, 0).UTC().Format(time.RFC3339),
+			}
+			if f.Mediatype != "" {
+				e["encodingFormat"] = f.Mediatype
+			}
+
+			graph = append(graph, e)
+		}
+
+		e := map[string]interface{}{
+			"@id":        dir,
+			"@type":      "Dataset",
+			"name":       n.Title,
+			"identifier": n.ID,
+			"hasPart":    files,
+		}
+		if n.Notes != "" {
+			e["description"] = n.Notes
+		}
+		if n.Type != "" {
+			e["additionalType"] = n.Type
+		}
+
+		graph = append(graph, e)
+	}
+
+	var authors []interface{}
+	for _, a := range pp.Authors {
+		id := "#author-" + a.ID
+		if a.Orcid != "" {
+			id = orcidURL + a.Orcid
+		}
+
+		authors = append(authors, ref(id))
+		graph = append(graph, map[string]interface{}{
+			"@id":   id,
+			"@type": "Person",
+			"name":  a.Name,
+		})
+	}
+
+	root := map[string]interface{}{
+		"@id":           "./",
+		"@type":         "Dataset",
+		"name":          pp.Name,
+		"identifier":    pp.ID,
+		"datePublished": time.Unix(pp.Exported, 0).UTC().Format(time.RFC3339),
+		"hasPart":       parts,
+	}
+	if pp.Description != "" {
+		root["description"] = pp.Description
+	}
+	if len(authors) > 0 {
+		root["author"] = authors
+	}
+
+	graph = append(graph, root)
+
+	return map[string]interface{}{
+		"@context": roCrateContext,
+		"@graph":   graph,
+	}
+}
+
+// bagWriter writes a BagIt bag to a zip archive and records the checksums
+// of the payload and tag files for the manifests.
+type bagWriter struct {
+	zw   *zip.Writer
+	root string
+
+	payload map[string]string
+	tags    map[string]string
+
+	octets int64
+	count  int
+}
+
+func (b *bagWriter) create(name string) (io.Writer, error) {
+	h := &zip.FileHeader{
+		Name:   path.Join(b.root, name),
+		Method: zip.Deflate,
+	}
+	h.SetModTime(time.Now())
+
+	return b.zw.CreateHeader(h)
+}
+
+// writePayload writes the contents to the payload directory.
+func (b *bagWriter) writePayload(name string, buf []byte) error {
+	w, err := b.create(path.Join("data", name))
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(buf)
+
+	b.payload[path.Join("data", name)] = hex.EncodeToString(sum[:])
+	b.octets += int64(len(buf))
+	b.count++
+
+	return nil
+}
+
+// copyPayload streams the reader to the payload directory and confirms
+// the contents match the stored hash.
+func (b *bagWriter) copyPayload(name string, r io.Reader, sum string) error {
+	w, err := b.create(path.Join("data", name))
+	if err != nil {
+		return err
+	}
+
+	h := sha256.New()
+
+	n, err := io.Copy(io.MultiWriter(w, h), r)
+	if err != nil {
+		return err
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if sum != "" && actual != strings.TrimPrefix(sum, "sha256:") {
+		return fmt.Errorf("%s: checksum mismatch", name)
+	}
+
+	b.payload[path.Join("data", name)] = actual
+	b.octets += n
+	b.count++
+
+	return nil
+}
+
+// writeTag writes a tag file at the root of the bag.
+func (b *bagWriter) writeTag(name string, buf []byte) error {
+	w, err := b.create(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(buf)
+	b.tags[name] = hex.EncodeToString(sum[:])
+
+	return nil
+}
+
+func manifestBytes(sums map[string]string) []byte {
+	names := make([]string, 0, len(sums))
+	for n := range sums {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, n := range names {
+		fmt.Fprintf(&buf, "%s  %s\n", sums[n], n)
+	}
+
+	return buf.Bytes()
+}
+
+// close writes the tag files and manifests and closes the archive.
+func (b *bagWriter) close(pp *packageProject) error {
+	err := b.writeTag("bagit.txt", []byte(fmt.Sprintf("BagIt-Version: %s\nTag-File-Character-Encoding: UTF-8\n", bagitVersion)))
+	if err != nil {
+		return err
+	}
+
+	var info bytes.Buffer
+	fmt.Fprintf(&info, "Bagging-Date: %s\n", time.Unix(pp.Exported, 0).UTC().Format("2006-01-02"))
+	fmt.Fprintf(&info, "Payload-Oxum: %d.%d\n", b.octets, b.count)
+	fmt.Fprintf(&info, "External-Identifier: %s\n", pp.ID)
+	if pp.Name != "" {
+		fmt.Fprintf(&info, "External-Description: %s\n", strings.Replace(pp.Name, "\n", " ", -1))
+	}
+
+	if err := b.writeTag("bag-info.txt", info.Bytes()); err != nil {
+		return err
+	}
+
+	if err := b.writeTag("manifest-sha256.txt", manifestBytes(b.payload)); err != nil {
+		return err
+	}
+
+	// The tag manifest is not listed in itself.
+	w, err := b.create("tagmanifest-sha256.txt")
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(manifestBytes(b.tags)); err != nil {
+		return err
+	}
+
+	return b.zw.Close()
+}
+
+// writePackage writes the project package as a zipped BagIt bag.
+func (s *service) writePackage(ctx context.Context, w io.Writer, p *project.Project) error {
+	pp, err := s.describeProject(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	commits, err := s.history(ctx, p.Id)
+	if err != nil {
+		return err
+	}
+
+	pp.Authors, err = s.authors(ctx, commits)
+	if err != nil {
+		return err
+	}
+
+	root := archive.PathName(p.Name)
+	if root == "" {
+		root = p.Id
+	}
+
+	b := &bagWriter{
+		zw:      zip.NewWriter(w),
+		root:    root,
+		payload: make(map[string]string),
+		tags:    make(map[string]string),
+	}
+
+	var source string
+	if p.Workflow != nil {
+		source = p.Workflow.Source
+	}
+
+	if err := b.writePayload("workflow.yml", []byte(source)); err != nil {
+		return err
+	}
+
+	for _, x := range []struct {
+		name string
+		v    interface{}
+	}{
+		{roCrateMetadata, roCrate(pp)},
+		{"project.json", pp},
+		{"history.json", commits},
+	} {
+		buf, err := json.MarshalIndent(x.v, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if err := b.writePayload(x.name, buf); err != nil {
+			return err
+		}
+	}
+
+	for _, n := range pp.Nodes {
+		if n.Notes != "" {
+			if err := b.writePayload(path.Join(n.Path, nodeNotes), []byte(n.Notes)); err != nil {
+				return err
+			}
+		}
+
+		for _, f := range n.Files {
+			if err := s.copyFile(ctx, b, f); err != nil {
+				return err
+			}
+		}
+	}
+
+	return b.close(pp)
+}
+
+func (s *service) copyFile(ctx context.Context, b *bagWriter, f *packageFile) error {
+	r, err := data.Open(ctx, s.dataSvc, s.storage, f.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %s", f.Path, err)
+	}
+	defer r.Close()
+
+	return b.copyPayload(f.Path, r, f.Hash)
+}
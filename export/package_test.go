@@ -0,0 +1,434 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rdm-academy/api/account"
+	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/nodes"
+	"github.com/rdm-academy/api/project"
+	"github.com/rdm-academy/api/storage"
+)
+
+const testFile = "id,value\n1,42\n"
+
+// testData keeps the objects in memory. Uploads are put to the server of
+// the data.
+type testData struct {
+	data.ServiceClient
+
+	srv *httptest.Server
+
+	// Changes what is stored, as if the upload was corrupted.
+	corrupt bool
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	ids     int
+}
+
+func newTestData() *testData {
+	d := &testData{
+		objects: map[string][]byte{
+			"f1": []byte(testFile),
+		},
+	}
+
+	d.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		if d.corrupt {
+			b = append(b, '!')
+		}
+		d.objects[strings.TrimPrefix(r.URL.Path, "/")] = b
+	}))
+
+	return d
+}
+
+func (d *testData) object(id string) ([]byte, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	b, ok := d.objects[id]
+	return b, ok
+}
+
+func (d *testData) Describe(ctx context.Context, req *data.DescribeRequest, opts ...transport.RequestOption) (*data.DescribeReply, error) {
+	b, ok := d.object(req.Id)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "object not found")
+	}
+
+	sum := sha256.Sum256(b)
+
+	return &data.DescribeReply{
+		Id:        req.Id,
+		State:     data.State_DONE,
+		Hash:      "sha256:" + hex.EncodeToString(sum[:]),
+		Size:      int64(len(b)),
+		Mediatype: "text/csv",
+		PutTime:   1500000000,
+		Bucket:    "test",
+		Key:       req.Id,
+	}, nil
+}
+
+func (d *testData) Upload(ctx context.Context, req *data.UploadRequest, opts ...transport.RequestOption) (*data.UploadReply, error) {
+	d.mu.Lock()
+	d.ids++
+	id := fmt.Sprintf("o%d", d.ids)
+	d.mu.Unlock()
+
+	return &data.UploadReply{
+		Id:        id,
+		SignedUrl: d.srv.URL + "/" + id,
+	}, nil
+}
+
+func (d *testData) Update(ctx context.Context, req *data.UpdateRequest, opts ...transport.RequestOption) (*data.UpdateReply, error) {
+	return &data.UpdateReply{}, nil
+}
+
+// testStorage reads the objects of the data.
+type testStorage struct {
+	storage.Storage
+
+	data *testData
+}
+
+func (s *testStorage) Bucket(name string) storage.Bucket {
+	return &testBucket{data: s.data}
+}
+
+type testBucket struct {
+	storage.Bucket
+
+	data *testData
+}
+
+func (b *testBucket) Object(name string) storage.Object {
+	return &testObject{data: b.data, name: name}
+}
+
+type testObject struct {
+	storage.Object
+
+	data *testData
+	name string
+}
+
+func (o *testObject) Reader(ctx context.Context) (io.ReadCloser, error) {
+	b, ok := o.data.object(o.name)
+	if !ok {
+		return nil, fmt.Errorf("%s: not found", o.name)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
+}
+
+type testNodes struct {
+	nodes.ServiceClient
+
+	created []*nodes.CreateRequest
+	notes   []*nodes.SetNotesRequest
+	files   []*nodes.AddFilesRequest
+}
+
+func (n *testNodes) Get(ctx context.Context, req *nodes.GetRequest, opts ...transport.RequestOption) (*nodes.GetReply, error) {
+	if req.Id != "raw" {
+		return nil, status.Error(codes.NotFound, "node not found")
+	}
+
+	return &nodes.GetReply{
+		Id:    req.Id,
+		Title: "Raw data",
+		Notes: "Collected in 2017.",
+		Files: []*nodes.File{
+			{Id: "f1", Name: "values.csv"},
+		},
+	}, nil
+}
+
+func (n *testNodes) Create(ctx context.Context, req *nodes.CreateRequest, opts ...transport.RequestOption) (*nodes.NoReply, error) {
+	n.created = append(n.created, req)
+	return &nodes.NoReply{}, nil
+}
+
+func (n *testNodes) SetNotes(ctx context.Context, req *nodes.SetNotesRequest, opts ...transport.RequestOption) (*nodes.NoReply, error) {
+	n.notes = append(n.notes, req)
+	return &nodes.NoReply{}, nil
+}
+
+func (n *testNodes) AddFiles(ctx context.Context, req *nodes.AddFilesRequest, opts ...transport.RequestOption) (*nodes.NoReply, error) {
+	n.files = append(n.files, req)
+	return &nodes.NoReply{}, nil
+}
+
+type testCommitlog struct {
+	commitlog.ServiceClient
+
+	replayed []*commitlog.ReplayRequest
+}
+
+func (c *testCommitlog) History(ctx context.Context, req *commitlog.HistoryRequest, opts ...transport.RequestOption) (*commitlog.HistoryReply, error) {
+	return &commitlog.HistoryReply{
+		Commit: &commitlog.Commit{
+			Id:     "c1",
+			Msg:    "Add raw data",
+			Author: "u1",
+			Time:   1500000000,
+			Events: []*commitlog.Event{
+				{Id: "e1", Type: "node.added", Author: "u1", Time: 1500000000, Data: []byte(`{"node":"raw"}`)},
+			},
+		},
+	}, nil
+}
+
+func (c *testCommitlog) Replay(ctx context.Context, req *commitlog.ReplayRequest, opts ...transport.RequestOption) (*commitlog.ReplayReply, error) {
+	c.replayed = append(c.replayed, req)
+	return &commitlog.ReplayReply{}, nil
+}
+
+type testAccounts struct {
+	account.ServiceClient
+}
+
+func (a *testAccounts) GetUser(ctx context.Context, req *account.GetUserRequest, opts ...transport.RequestOption) (*account.GetUserResponse, error) {
+	return &account.GetUserResponse{
+		Id:            req.Id,
+		Name:          "Joe",
+		Orcid:         "0000-0002-1825-0097",
+		OrcidVerified: true,
+	}, nil
+}
+
+type testProjects struct {
+	project.ServiceClient
+
+	created   []*project.CreateProjectRequest
+	workflows []*project.UpdateWorkflowRequest
+	purged    []string
+}
+
+func (p *testProjects) CreateProject(ctx context.Context, req *project.CreateProjectRequest, opts ...transport.RequestOption) (*project.CreateProjectResponse, error) {
+	p.created = append(p.created, req)
+
+	return &project.CreateProjectResponse{
+		Project: &project.Project{Id: fmt.Sprintf("p%d", len(p.created)+1), Account: req.Account, Name: req.Name},
+	}, nil
+}
+
+func (p *testProjects) UpdateWorkflow(ctx context.Context, req *project.UpdateWorkflowRequest, opts ...transport.RequestOption) (*project.UpdateWorkflowResponse, error) {
+	p.workflows = append(p.workflows, req)
+	return &project.UpdateWorkflowResponse{}, nil
+}
+
+func (p *testProjects) DeleteProject(ctx context.Context, req *project.DeleteProjectRequest, opts ...transport.RequestOption) (*project.DeleteProjectResponse, error) {
+	if req.Purge {
+		p.purged = append(p.purged, req.Id)
+	}
+	return &project.DeleteProjectResponse{}, nil
+}
+
+// testService returns a service with in-memory stand-ins of the other
+// services and a function that closes the upload server.
+func testService() (*service, func()) {
+	d := newTestData()
+
+	s := &service{
+		storage:      &testStorage{data: d},
+		projectSvc:   &testProjects{},
+		nodeSvc:      &testNodes{},
+		commitlogSvc: &testCommitlog{},
+		dataSvc:      d,
+		accountSvc:   &testAccounts{},
+	}
+
+	return s, d.srv.Close
+}
+
+var testProject = &project.Project{
+	Id:          "p1",
+	Account:     "u1",
+	Name:        "Study",
+	Description: "A study.",
+	Created:     1500000000,
+	Modified:    1500000000,
+	Workflow: &project.Workflow{
+		Source: "raw:\n  type: data\n  title: Raw data\n",
+		Nodes: map[string]*project.Node{
+			"raw": {Type: "data", Title: "Raw data"},
+		},
+	},
+}
+
+// testPackage exports the test project.
+func testPackage(t *testing.T, s *service) []byte {
+	var buf bytes.Buffer
+	if err := s.writePackage(context.Background(), &buf, testProject); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// readManifest returns the checksums of the manifest keyed by path.
+func readManifest(t *testing.T, f *zip.File) map[string]string {
+	rc, err := f.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+
+	sums := make(map[string]string)
+
+	sc := bufio.NewScanner(rc)
+	for sc.Scan() {
+		toks := strings.SplitN(sc.Text(), "  ", 2)
+		if len(toks) != 2 {
+			t.Fatalf("%s: invalid line %q", f.Name, sc.Text())
+		}
+		sums[toks[1]] = toks[0]
+	}
+
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return sums
+}
+
+func readZipFile(t *testing.T, f *zip.File) []byte {
+	rc, err := f.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestWritePackage(t *testing.T) {
+	s, cleanup := testService()
+	defer cleanup()
+
+	buf := testPackage(t, s)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		if !strings.HasPrefix(f.Name, "Study/") {
+			t.Errorf("%s: expected the bag to be in Study/", f.Name)
+		}
+		files[strings.TrimPrefix(f.Name, "Study/")] = f
+	}
+
+	for _, name := range []string{"bagit.txt", "bag-info.txt", "manifest-sha256.txt", "tagmanifest-sha256.txt"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("expected %s", name)
+		}
+	}
+
+	// The manifest lists every payload file with its checksum.
+	manifest := readManifest(t, files["manifest-sha256.txt"])
+
+	var payload int
+	for name, f := range files {
+		if !strings.HasPrefix(name, "data/") {
+			continue
+		}
+		payload++
+
+		sum := sha256.Sum256(readZipFile(t, f))
+		if manifest[name] != hex.EncodeToString(sum[:]) {
+			t.Errorf("%s: expected checksum %x, got %q", name, sum, manifest[name])
+		}
+	}
+
+	if payload != len(manifest) {
+		t.Errorf("expected %d manifest entries, got %d", payload, len(manifest))
+	}
+
+	// The tag files are listed in the tag manifest.
+	for name, sum := range readManifest(t, files["tagmanifest-sha256.txt"]) {
+		actual := sha256.Sum256(readZipFile(t, files[name]))
+		if sum != hex.EncodeToString(actual[:]) {
+			t.Errorf("%s: tag manifest checksum mismatch", name)
+		}
+	}
+
+	// The node files are described in the RO-Crate metadata.
+	var crate struct {
+		Graph []map[string]interface{} `json:"@graph"`
+	}
+	if err := json.Unmarshal(readZipFile(t, files["data/"+roCrateMetadata]), &crate); err != nil {
+		t.Fatal(err)
+	}
+
+	entities := make(map[string]map[string]interface{})
+	for _, e := range crate.Graph {
+		entities[e["@id"].(string)] = e
+	}
+
+	var found bool
+	for id, e := range entities {
+		if e["identifier"] != "f1" {
+			continue
+		}
+		found = true
+
+		sum := sha256.Sum256([]byte(testFile))
+		if e["sha256"] != hex.EncodeToString(sum[:]) || e["name"] != "values.csv" {
+			t.Errorf("unexpected file entity %v", e)
+		}
+		if manifest["data/"+id] == "" {
+			t.Errorf("%s: expected the file in the payload", id)
+		}
+	}
+	if !found {
+		t.Error("expected the file in the metadata")
+	}
+
+	if root := entities["./"]; root == nil || root["name"] != "Study" {
+		t.Errorf("unexpected root entity %v", root)
+	}
+	if a := entities[orcidURL+"0000-0002-1825-0097"]; a == nil || a["name"] != "Joe" {
+		t.Errorf("expected the author with the ORCID iD, got %v", a)
+	}
+}
@@ -0,0 +1,386 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/rdm-academy/api/account"
+	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/nodes"
+	"github.com/rdm-academy/api/project"
+	"github.com/rdm-academy/api/storage"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	jobsCol = "jobs"
+
+	packageMediatype = "application/zip"
+
+	// Maximum time a single export may run.
+	jobTimeout = 6 * time.Hour
+
+	// Time allowed to remove a project that failed to import.
+	cleanupTimeout = time.Minute
+
+	// Running jobs renew their lease. A job whose lease expired was
+	// interrupted, e.g. by a restart, and is failed.
+	jobLease = 5 * time.Minute
+
+	// ImportRetention is the time uploaded import packages are kept. The
+	// package outlives the job that imports it.
+	ImportRetention = 2 * jobTimeout
+)
+
+type job struct {
+	ID       string    `bson:"_id"`
+	Kind     Kind      `bson:"kind"`
+	Project  string    `bson:"project"`
+	Account  string    `bson:"account"`
+	State    State     `bson:"state"`
+	Error    string    `bson:"error"`
+	Created  time.Time `bson:"created"`
+	Finished time.Time `bson:"finished"`
+	File     string    `bson:"file"`
+	Size     int64     `bson:"size"`
+	Expires  time.Time `bson:"expires"`
+	Lease    time.Time `bson:"lease"`
+}
+
+func (j *job) proto() *Job {
+	x := &Job{
+		Id:      j.ID,
+		Kind:    j.Kind,
+		Project: j.Project,
+		Account: j.Account,
+		State:   j.State,
+		Error:   j.Error,
+		Created: j.Created.Unix(),
+		File:    j.File,
+		Size:    j.Size,
+	}
+
+	if !j.Finished.IsZero() {
+		x.Finished = j.Finished.Unix()
+	}
+
+	if !j.Expires.IsZero() {
+		x.Expires = j.Expires.Unix()
+	}
+
+	return x
+}
+
+// countWriter counts the number of bytes written.
+type countWriter struct {
+	w    io.Writer
+	size int64
+}
+
+func (w *countWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+type service struct {
+	db *mgo.Database
+
+	// Files are read from storage directly.
+	storage storage.Storage
+
+	projectSvc   project.ServiceClient
+	nodeSvc      nodes.ServiceClient
+	commitlogSvc commitlog.ServiceClient
+	dataSvc      data.ServiceClient
+	accountSvc   account.ServiceClient
+
+	// Time export packages are kept.
+	retention time.Duration
+}
+
+func (s *service) Create(ctx context.Context, req *CreateRequest) (*CreateReply, error) {
+	if req.Project == "" {
+		return nil, status.Error(codes.InvalidArgument, "project required")
+	}
+
+	if req.Account == "" {
+		return nil, status.Error(codes.InvalidArgument, "account required")
+	}
+
+	// Confirms the account has access to the project.
+	rep, err := s.projectSvc.GetProject(ctx, &project.GetProjectRequest{
+		Id:      req.Project,
+		Account: req.Account,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	j := job{
+		ID:      uuid.NewV4().String(),
+		Kind:    Kind_EXPORT,
+		Project: req.Project,
+		Account: req.Account,
+		State:   State_PENDING,
+		Created: time.Now(),
+		Lease:   time.Now().Add(jobLease),
+	}
+
+	if err := s.db.C(jobsCol).Insert(&j); err != nil {
+		return nil, err
+	}
+
+	go s.run(&j, func(ctx context.Context) (bson.M, error) {
+		expires := time.Now().Add(s.retention)
+
+		file, size, err := s.store(ctx, &j, rep.Project, expires)
+		if err != nil {
+			return nil, err
+		}
+
+		return bson.M{
+			"file":    file,
+			"size":    size,
+			"expires": expires,
+		}, nil
+	})
+
+	return &CreateReply{
+		Job: j.proto(),
+	}, nil
+}
+
+func (s *service) Get(ctx context.Context, req *GetRequest) (*GetReply, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id required")
+	}
+
+	q := bson.M{
+		"_id":     req.Id,
+		"account": req.Account,
+	}
+
+	var j job
+	if err := s.db.C(jobsCol).Find(q).One(&j); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "job not found")
+		}
+
+		return nil, err
+	}
+
+	return &GetReply{
+		Job: j.proto(),
+	}, nil
+}
+
+func (s *service) Import(ctx context.Context, req *ImportRequest) (*ImportReply, error) {
+	if req.Account == "" {
+		return nil, status.Error(codes.InvalidArgument, "account required")
+	}
+
+	if req.File == "" {
+		return nil, status.Error(codes.InvalidArgument, "file required")
+	}
+
+	desc, err := s.dataSvc.Describe(ctx, &data.DescribeRequest{
+		Id: req.File,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if desc.Account != req.Account {
+		return nil, status.Error(codes.NotFound, "file not found")
+	}
+
+	if desc.State != data.State_DONE {
+		return nil, status.Error(codes.FailedPrecondition, "file is not stored")
+	}
+
+	j := job{
+		ID:      uuid.NewV4().String(),
+		Kind:    Kind_IMPORT,
+		Account: req.Account,
+		State:   State_PENDING,
+		Created: time.Now(),
+		File:    req.File,
+		Size:    desc.Size,
+		Lease:   time.Now().Add(jobLease),
+	}
+
+	if err := s.db.C(jobsCol).Insert(&j); err != nil {
+		return nil, err
+	}
+
+	go s.run(&j, func(ctx context.Context) (bson.M, error) {
+		return s.importPackage(ctx, &j, req)
+	})
+
+	return &ImportReply{
+		Job: j.proto(),
+	}, nil
+}
+
+// run runs the job function in the background. The state of the job is
+// updated as it progresses and the fields returned by the function are
+// set when it is done. The lease of the job is renewed while it runs.
+func (s *service) run(j *job, fn func(context.Context) (bson.M, error)) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	s.setState(j.ID, bson.M{
+		"state": State_RUNNING,
+		"lease": time.Now().Add(jobLease),
+	})
+
+	go func() {
+		t := time.NewTicker(jobLease / 3)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-t.C:
+				s.setState(j.ID, bson.M{
+					"lease": time.Now().Add(jobLease),
+				})
+			}
+		}
+	}()
+
+	set, err := fn(ctx)
+	if err != nil {
+		log.Printf("export: job %s failed: %s", j.ID, err)
+
+		s.setState(j.ID, bson.M{
+			"state":    State_ERROR,
+			"error":    err.Error(),
+			"finished": time.Now(),
+		})
+		return
+	}
+
+	if set == nil {
+		set = bson.M{}
+	}
+
+	set["state"] = State_DONE
+	set["finished"] = time.Now()
+
+	s.setState(j.ID, set)
+}
+
+func (s *service) setState(id string, set bson.M) {
+	if err := s.db.C(jobsCol).UpdateId(id, bson.M{"$set": set}); err != nil {
+		log.Printf("export: failed to update job %s: %s", id, err)
+	}
+}
+
+// store streams the package into the data service and returns the id
+// and size of the stored object. The package is removed once it expires.
+func (s *service) store(ctx context.Context, j *job, p *project.Project, expires time.Time) (string, int64, error) {
+	pr, pw := io.Pipe()
+	cw := &countWriter{w: pw}
+
+	go func() {
+		pw.CloseWithError(s.writePackage(ctx, cw, p))
+	}()
+
+	id, err := data.Upload(ctx, s.dataSvc, &data.UploadRequest{
+		Account:   j.Account,
+		Project:   j.Project,
+		Mediatype: packageMediatype,
+		Artifact:  true,
+		Expires:   expires.Unix(),
+	}, packageMediatype, pr)
+
+	// Unblock the writer if the upload failed early.
+	pr.CloseWithError(err)
+
+	if err != nil {
+		return "", 0, fmt.Errorf("store package: %s", err)
+	}
+
+	return id, cw.size, nil
+}
+
+// failInterrupted fails the pending and running jobs whose lease expired.
+// Jobs still running on other instances keep renewing their lease.
+func (s *service) failInterrupted() error {
+	now := time.Now()
+
+	_, err := s.db.C(jobsCol).UpdateAll(bson.M{
+		"state": bson.M{
+			"$in": []State{State_PENDING, State_RUNNING},
+		},
+		"$or": []bson.M{
+			{"lease": bson.M{"$lt": now}},
+			{"lease": bson.M{"$exists": false}},
+		},
+	}, bson.M{
+		"$set": bson.M{
+			"state":    State_ERROR,
+			"error":    "job interrupted",
+			"finished": now,
+		},
+	})
+
+	return err
+}
+
+// NewService initializes a new export service. Export packages are kept
+// for the retention period. Jobs that were interrupted, e.g. by a restart,
+// are marked as failed once their lease expires.
+func NewService(tp transport.Transport, db *mgo.Database, stg storage.Storage, retention time.Duration) (Service, error) {
+	if retention <= 0 {
+		return nil, errors.New("retention must be positive")
+	}
+
+	err := db.C(jobsCol).EnsureIndex(mgo.Index{
+		Key: []string{"account"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &service{
+		db:           db,
+		storage:      stg,
+		projectSvc:   project.NewServiceClient(tp),
+		nodeSvc:      nodes.NewServiceClient(tp),
+		commitlogSvc: commitlog.NewServiceClient(tp),
+		dataSvc:      data.NewServiceClient(tp),
+		accountSvc:   account.NewServiceClient(tp),
+		retention:    retention,
+	}
+
+	if err := s.failInterrupted(); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for range time.Tick(jobLease) {
+			if err := s.failInterrupted(); err != nil {
+				log.Printf("export: failed to fail interrupted jobs: %s", err)
+			}
+		}
+	}()
+
+	return s, nil
+}
@@ -0,0 +1,374 @@
+// Code generated by protoc-gen-go.
+// source: service.proto
+// DO NOT EDIT!
+
+/*
+Package export is a generated protocol buffer package.
+
+It is generated from these files:
+	service.proto
+
+It has these top-level messages:
+	Job
+	CreateRequest
+	CreateReply
+	GetRequest
+	GetReply
+	ImportRequest
+	ImportReply
+*/
+package export
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Kind int32
+
+const (
+	Kind_EXPORT Kind = 0
+	Kind_IMPORT Kind = 1
+)
+
+var Kind_name = map[int32]string{
+	0: "EXPORT",
+	1: "IMPORT",
+}
+var Kind_value = map[string]int32{
+	"EXPORT": 0,
+	"IMPORT": 1,
+}
+
+func (x Kind) String() string {
+	return proto.EnumName(Kind_name, int32(x))
+}
+func (Kind) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type State int32
+
+const (
+	State_UNKNOWN State = 0
+	State_PENDING State = 1
+	State_RUNNING State = 2
+	State_DONE    State = 3
+	State_ERROR   State = 4
+)
+
+var State_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "PENDING",
+	2: "RUNNING",
+	3: "DONE",
+	4: "ERROR",
+}
+var State_value = map[string]int32{
+	"UNKNOWN": 0,
+	"PENDING": 1,
+	"RUNNING": 2,
+	"DONE":    3,
+	"ERROR":   4,
+}
+
+func (x State) String() string {
+	return proto.EnumName(State_name, int32(x))
+}
+func (State) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+type Job struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	// For imports, the project is set once it has been created.
+	Project string `protobuf:"bytes,2,opt,name=project" json:"project,omitempty"`
+	Account string `protobuf:"bytes,3,opt,name=account" json:"account,omitempty"`
+	State   State  `protobuf:"varint,4,opt,name=state,enum=export.State" json:"state,omitempty"`
+	// Error that caused the job to fail.
+	Error    string `protobuf:"bytes,5,opt,name=error" json:"error,omitempty"`
+	Created  int64  `protobuf:"varint,6,opt,name=created" json:"created,omitempty"`
+	Finished int64  `protobuf:"varint,7,opt,name=finished" json:"finished,omitempty"`
+	// ID of the package stored in the data service.
+	File string `protobuf:"bytes,8,opt,name=file" json:"file,omitempty"`
+	// Size of the package in bytes.
+	Size int64 `protobuf:"varint,9,opt,name=size" json:"size,omitempty"`
+	Kind Kind  `protobuf:"varint,10,opt,name=kind,enum=export.Kind" json:"kind,omitempty"`
+	// Time the package of an export is removed.
+	Expires int64 `protobuf:"varint,11,opt,name=expires" json:"expires,omitempty"`
+}
+
+func (m *Job) Reset()                    { *m = Job{} }
+func (m *Job) String() string            { return proto.CompactTextString(m) }
+func (*Job) ProtoMessage()               {}
+func (*Job) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *Job) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Job) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *Job) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *Job) GetState() State {
+	if m != nil {
+		return m.State
+	}
+	return State_UNKNOWN
+}
+
+func (m *Job) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *Job) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *Job) GetFinished() int64 {
+	if m != nil {
+		return m.Finished
+	}
+	return 0
+}
+
+func (m *Job) GetFile() string {
+	if m != nil {
+		return m.File
+	}
+	return ""
+}
+
+func (m *Job) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *Job) GetKind() Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return Kind_EXPORT
+}
+
+func (m *Job) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+type CreateRequest struct {
+	Project string `protobuf:"bytes,1,opt,name=project" json:"project,omitempty"`
+	Account string `protobuf:"bytes,2,opt,name=account" json:"account,omitempty"`
+}
+
+func (m *CreateRequest) Reset()                    { *m = CreateRequest{} }
+func (m *CreateRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateRequest) ProtoMessage()               {}
+func (*CreateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *CreateRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *CreateRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+type CreateReply struct {
+	Job *Job `protobuf:"bytes,1,opt,name=job" json:"job,omitempty"`
+}
+
+func (m *CreateReply) Reset()                    { *m = CreateReply{} }
+func (m *CreateReply) String() string            { return proto.CompactTextString(m) }
+func (*CreateReply) ProtoMessage()               {}
+func (*CreateReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *CreateReply) GetJob() *Job {
+	if m != nil {
+		return m.Job
+	}
+	return nil
+}
+
+type GetRequest struct {
+	Id      string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Account string `protobuf:"bytes,2,opt,name=account" json:"account,omitempty"`
+}
+
+func (m *GetRequest) Reset()                    { *m = GetRequest{} }
+func (m *GetRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetRequest) ProtoMessage()               {}
+func (*GetRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *GetRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *GetRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+type GetReply struct {
+	Job *Job `protobuf:"bytes,1,opt,name=job" json:"job,omitempty"`
+}
+
+func (m *GetReply) Reset()                    { *m = GetReply{} }
+func (m *GetReply) String() string            { return proto.CompactTextString(m) }
+func (*GetReply) ProtoMessage()               {}
+func (*GetReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *GetReply) GetJob() *Job {
+	if m != nil {
+		return m.Job
+	}
+	return nil
+}
+
+type ImportRequest struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	// ID of the package stored in the data service.
+	File string `protobuf:"bytes,2,opt,name=file" json:"file,omitempty"`
+	// Name of the new project. Defaults to the name in the package.
+	Name string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	// Replay the commit history of the package into the commitlog.
+	History bool `protobuf:"varint,4,opt,name=history" json:"history,omitempty"`
+}
+
+func (m *ImportRequest) Reset()                    { *m = ImportRequest{} }
+func (m *ImportRequest) String() string            { return proto.CompactTextString(m) }
+func (*ImportRequest) ProtoMessage()               {}
+func (*ImportRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *ImportRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *ImportRequest) GetFile() string {
+	if m != nil {
+		return m.File
+	}
+	return ""
+}
+
+func (m *ImportRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ImportRequest) GetHistory() bool {
+	if m != nil {
+		return m.History
+	}
+	return false
+}
+
+type ImportReply struct {
+	Job *Job `protobuf:"bytes,1,opt,name=job" json:"job,omitempty"`
+}
+
+func (m *ImportReply) Reset()                    { *m = ImportReply{} }
+func (m *ImportReply) String() string            { return proto.CompactTextString(m) }
+func (*ImportReply) ProtoMessage()               {}
+func (*ImportReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *ImportReply) GetJob() *Job {
+	if m != nil {
+		return m.Job
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Job)(nil), "export.Job")
+	proto.RegisterType((*CreateRequest)(nil), "export.CreateRequest")
+	proto.RegisterType((*CreateReply)(nil), "export.CreateReply")
+	proto.RegisterType((*GetRequest)(nil), "export.GetRequest")
+	proto.RegisterType((*GetReply)(nil), "export.GetReply")
+	proto.RegisterType((*ImportRequest)(nil), "export.ImportRequest")
+	proto.RegisterType((*ImportReply)(nil), "export.ImportReply")
+	proto.RegisterEnum("export.Kind", Kind_name, Kind_value)
+	proto.RegisterEnum("export.State", State_name, State_value)
+}
+
+func init() { proto.RegisterFile("service.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 478 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x53, 0x5f, 0x6f, 0xd3, 0x3e,
+	0x14, 0x9d, 0xf3, 0xaf, 0xe9, 0xcd, 0xaf, 0x53, 0xe4, 0xfd, 0x90, 0xac, 0x4a, 0xa0, 0xaa, 0xbc,
+	0x94, 0x81, 0xfa, 0x50, 0x26, 0xbe, 0x40, 0x57, 0x55, 0xdd, 0x44, 0x3a, 0x79, 0x4c, 0xf0, 0xda,
+	0x26, 0x77, 0x9a, 0xb7, 0x2e, 0x0e, 0x8e, 0x87, 0x56, 0x3e, 0x0c, 0x0f, 0x7c, 0x52, 0x64, 0xa7,
+	0x66, 0xcb, 0xc4, 0x04, 0x6f, 0xf7, 0x9c, 0x1b, 0xdf, 0x73, 0x7d, 0x4e, 0x0c, 0xbd, 0x1a, 0xd5,
+	0x37, 0x91, 0xe3, 0xb8, 0x52, 0x52, 0x4b, 0x1a, 0xe1, 0x7d, 0x25, 0x95, 0x1e, 0xfe, 0xf0, 0xc0,
+	0x3f, 0x91, 0x6b, 0xba, 0x0f, 0x9e, 0x28, 0x18, 0x19, 0x90, 0x51, 0x97, 0x7b, 0xa2, 0xa0, 0x0c,
+	0x3a, 0x95, 0x92, 0xd7, 0x98, 0x6b, 0xe6, 0x59, 0xd2, 0x41, 0xd3, 0x59, 0xe5, 0xb9, 0xbc, 0x2b,
+	0x35, 0xf3, 0x9b, 0xce, 0x0e, 0xd2, 0xd7, 0x10, 0xd6, 0x7a, 0xa5, 0x91, 0x05, 0x03, 0x32, 0xda,
+	0x9f, 0xf4, 0xc6, 0x8d, 0xc6, 0xf8, 0xdc, 0x90, 0xbc, 0xe9, 0xd1, 0xff, 0x21, 0x44, 0xa5, 0xa4,
+	0x62, 0xa1, 0x3d, 0xdc, 0x00, 0x33, 0x34, 0x57, 0xb8, 0xd2, 0x58, 0xb0, 0x68, 0x40, 0x46, 0x3e,
+	0x77, 0x90, 0xf6, 0x21, 0xbe, 0x14, 0xa5, 0xa8, 0xaf, 0xb0, 0x60, 0x1d, 0xdb, 0xfa, 0x8d, 0x29,
+	0x85, 0xe0, 0x52, 0x6c, 0x90, 0xc5, 0x76, 0x94, 0xad, 0x0d, 0x57, 0x8b, 0xef, 0xc8, 0xba, 0xf6,
+	0x5b, 0x5b, 0xd3, 0x01, 0x04, 0x37, 0xa2, 0x2c, 0x18, 0xd8, 0xbd, 0xfe, 0x73, 0x7b, 0x9d, 0x8a,
+	0xb2, 0xe0, 0xb6, 0x63, 0xf4, 0xf1, 0xbe, 0x12, 0x0a, 0x6b, 0x96, 0x34, 0xfa, 0x3b, 0x38, 0x9c,
+	0x42, 0x6f, 0x6a, 0x57, 0xe1, 0xf8, 0xf5, 0x0e, 0x6b, 0xfd, 0xd8, 0x19, 0xf2, 0xac, 0x33, 0x5e,
+	0xcb, 0x99, 0xe1, 0x3b, 0x48, 0xdc, 0x90, 0x6a, 0xb3, 0xa5, 0x2f, 0xc1, 0xbf, 0x96, 0x6b, 0x7b,
+	0x3c, 0x99, 0x24, 0x6e, 0x9d, 0x13, 0xb9, 0xe6, 0x86, 0x1f, 0x7e, 0x00, 0x98, 0xa3, 0x76, 0x7a,
+	0x7f, 0x48, 0xe6, 0x19, 0x95, 0x37, 0x10, 0xdb, 0x73, 0xff, 0x20, 0x71, 0x03, 0xbd, 0xc5, 0xad,
+	0xa1, 0x1e, 0xdd, 0xca, 0x4d, 0x25, 0xed, 0x54, 0x9d, 0xc9, 0x5e, 0xdb, 0xe4, 0x72, 0x75, 0x8b,
+	0xbb, 0x1f, 0xc0, 0xd6, 0x66, 0xc2, 0x95, 0xa8, 0xb5, 0x54, 0x5b, 0x9b, 0x7f, 0xcc, 0x1d, 0x34,
+	0xb7, 0x77, 0x62, 0x7f, 0x5f, 0xed, 0xf0, 0x15, 0x04, 0x26, 0x18, 0x0a, 0x10, 0xcd, 0xbe, 0x9c,
+	0x2d, 0xf9, 0xa7, 0x74, 0xcf, 0xd4, 0x8b, 0x8f, 0xb6, 0x26, 0x87, 0x53, 0x08, 0xed, 0x0f, 0x45,
+	0x13, 0xe8, 0x5c, 0x64, 0xa7, 0xd9, 0xf2, 0x73, 0x96, 0xee, 0x19, 0x70, 0x36, 0xcb, 0x8e, 0x17,
+	0xd9, 0x3c, 0x25, 0x06, 0xf0, 0x8b, 0x2c, 0x33, 0xc0, 0xa3, 0x31, 0x04, 0xc7, 0xcb, 0x6c, 0x96,
+	0xfa, 0xb4, 0x0b, 0xe1, 0x8c, 0xf3, 0x25, 0x4f, 0x83, 0xc9, 0x4f, 0x02, 0x9d, 0xf3, 0xe6, 0x41,
+	0xd0, 0x23, 0x88, 0x9a, 0x70, 0xe8, 0x0b, 0xb7, 0x4c, 0x2b, 0xf1, 0xfe, 0xc1, 0x53, 0xda, 0xdc,
+	0xe2, 0x2d, 0xf8, 0x73, 0xd4, 0x94, 0xba, 0xde, 0x43, 0x62, 0xfd, 0xb4, 0xc5, 0x99, 0x8f, 0x8f,
+	0x20, 0x6a, 0x1c, 0x78, 0x90, 0x68, 0xd9, 0xdf, 0x3f, 0x78, 0x4a, 0x57, 0x9b, 0xed, 0x3a, 0xb2,
+	0x4f, 0xf5, 0xfd, 0xaf, 0x01, 0x00, 0x7b, 0x90, 0x80, 0x7b, 0xbb, 0x03, 0x00, 0x00,
+}
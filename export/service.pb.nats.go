@@ -0,0 +1,138 @@
+package export
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	traceIdKey = struct{}{}
+)
+
+type Service interface {
+	Create(context.Context, *CreateRequest) (*CreateReply, error)
+	Get(context.Context, *GetRequest) (*GetReply, error)
+	Import(context.Context, *ImportRequest) (*ImportReply, error)
+}
+
+type ServiceClient interface {
+	Create(context.Context, *CreateRequest, ...transport.RequestOption) (*CreateReply, error)
+	Get(context.Context, *GetRequest, ...transport.RequestOption) (*GetReply, error)
+	Import(context.Context, *ImportRequest, ...transport.RequestOption) (*ImportReply, error)
+}
+
+// serviceClient an implementation of Service client.
+type serviceClient struct {
+	tp transport.Transport
+}
+
+func (c *serviceClient) Create(ctx context.Context, req *CreateRequest, opts ...transport.RequestOption) (*CreateReply, error) {
+	var rep CreateReply
+
+	_, err := c.tp.Request("export.Create", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) Get(ctx context.Context, req *GetRequest, opts ...transport.RequestOption) (*GetReply, error) {
+	var rep GetReply
+
+	_, err := c.tp.Request("export.Get", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) Import(ctx context.Context, req *ImportRequest, opts ...transport.RequestOption) (*ImportReply, error) {
+	var rep ImportReply
+
+	_, err := c.tp.Request("export.Import", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+// NewServiceClient creates a new Service client.
+func NewServiceClient(tp transport.Transport) ServiceClient {
+	return &serviceClient{tp}
+}
+
+type ServiceServer struct {
+	tp  transport.Transport
+	svc Service
+}
+
+func NewServiceServer(tp transport.Transport, svc Service) *ServiceServer {
+	return &ServiceServer{
+		tp:  tp,
+		svc: svc,
+	}
+}
+
+func (s *ServiceServer) Serve(ctx context.Context, opts ...transport.SubscribeOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+	}()
+
+	var err error
+
+	_, err = s.tp.Subscribe("export.Create", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req CreateRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Create(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("export.Get", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req GetRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Get(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("export.Import", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req ImportRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Import(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	sigchan := make(chan os.Signal)
+	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
+
+	<-sigchan
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/rdm-academy/api/account"
+	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/internal/archive"
+	"github.com/rdm-academy/api/nodes"
+	"github.com/rdm-academy/api/project"
+	"github.com/rdm-academy/api/storage"
+)
+
+// accountExport collects everything an account owns for the export.
+type accountExport struct {
+	projectSvc   project.ServiceClient
+	commitlogSvc commitlog.ServiceClient
+	dataSvc      data.ServiceClient
+	nodeSvc      nodes.ServiceClient
+	storage      storage.Storage
+}
+
+type accountExportInfo struct {
+	User     *account.GetUserResponse `json:"user"`
+	Usage    *data.UsageReply         `json:"usage"`
+	Exported int64                    `json:"exported"`
+}
+
+type accountExportEvent struct {
+	ID     string          `json:"id"`
+	Time   int64           `json:"time"`
+	Type   string          `json:"type"`
+	Author string          `json:"author"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+type accountExportCommit struct {
+	ID     string                `json:"id"`
+	Msg    string                `json:"msg"`
+	Author string                `json:"author"`
+	Time   int64                 `json:"time"`
+	Parent string                `json:"parent,omitempty"`
+	Events []*accountExportEvent `json:"events"`
+}
+
+type accountExportProject struct {
+	project  *project.Project
+	commits  []*accountExportCommit
+	contents *archiveBuilder
+}
+
+// projectHistory returns all commits of the project, latest first.
+func projectHistory(ctx context.Context, commitlogSvc commitlog.ServiceClient, id string) ([]*accountExportCommit, error) {
+	req := commitlog.HistoryRequest{
+		Project: id,
+	}
+
+	commits := make([]*accountExportCommit, 0)
+
+	for {
+		rep, err := commitlogSvc.History(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+
+		if rep.Commit == nil {
+			break
+		}
+
+		events := make([]*accountExportEvent, len(rep.Commit.Events))
+		for i, e := range rep.Commit.Events {
+			events[i] = &accountExportEvent{
+				ID:     e.Id,
+				Time:   e.Time,
+				Type:   e.Type,
+				Author: e.Author,
+				Data:   json.RawMessage(e.Data),
+			}
+		}
+
+		commits = append(commits, &accountExportCommit{
+			ID:     rep.Commit.Id,
+			Msg:    rep.Commit.Msg,
+			Author: rep.Commit.Author,
+			Time:   rep.Commit.Time,
+			Parent: rep.Commit.Parent,
+			Events: events,
+		})
+
+		// Any more?
+		if rep.Next == "" {
+			break
+		}
+
+		req.Commit = rep.Next
+	}
+
+	return commits, nil
+}
+
+// serve streams an archive of the account, its usage and all of its
+// projects, including the ones in the trash.
+func (x *accountExport) serve(c echo.Context, user *account.GetUserResponse) error {
+	ctx := c.Request().Context()
+
+	usage, err := x.dataSvc.Usage(ctx, &data.UsageRequest{
+		Account: user.Id,
+	})
+	if err != nil {
+		return err
+	}
+
+	lrep, err := x.projectSvc.ListProjects(ctx, &project.ListProjectsRequest{
+		Account: user.Id,
+	})
+	if err != nil {
+		return err
+	}
+
+	drep, err := x.projectSvc.ListDeletedProjects(ctx, &project.ListDeletedProjectsRequest{
+		Account: user.Id,
+	})
+	if err != nil {
+		return err
+	}
+
+	dirs := make(map[string]struct{})
+
+	var projects []*accountExportProject
+
+	for _, p := range append(lrep.Projects, drep.Projects...) {
+		commits, err := projectHistory(ctx, x.commitlogSvc, p.Id)
+		if err != nil {
+			return err
+		}
+
+		b := newArchiveBuilder(x.dataSvc, x.nodeSvc, x.storage)
+		b.prefix = path.Join("projects", archive.UniqueName(dirs, archive.PathName(p.Name), p.Id))
+
+		if err := b.addProject(ctx, p); err != nil {
+			return err
+		}
+
+		projects = append(projects, &accountExportProject{
+			project:  p,
+			commits:  commits,
+			contents: b,
+		})
+	}
+
+	info := &accountExportInfo{
+		User:     user,
+		Usage:    usage,
+		Exported: time.Now().Unix(),
+	}
+
+	return streamArchive(c, "account", func(ctx context.Context, a archiveWriter) error {
+		now := time.Now()
+
+		buf, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if err := writeBytes(a, "account.json", buf, now); err != nil {
+			return err
+		}
+
+		for _, p := range projects {
+			prefix := p.contents.prefix
+
+			buf, err := json.MarshalIndent(p.project, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			if err := writeBytes(a, path.Join(prefix, "project.json"), buf, now); err != nil {
+				return err
+			}
+
+			if p.project.Workflow != nil && p.project.Workflow.Source != "" {
+				err := writeBytes(a, path.Join(prefix, "workflow.yml"), []byte(p.project.Workflow.Source), now)
+				if err != nil {
+					return err
+				}
+			}
+
+			buf, err = json.MarshalIndent(p.commits, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			if err := writeBytes(a, path.Join(prefix, "history.json"), buf, now); err != nil {
+				return err
+			}
+
+			if err := p.contents.write(ctx, a); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/rdm-academy/api/commitlog"
+)
+
+// apiEvent is a log event with the author resolved.
+type apiEvent struct {
+	ID     string          `json:"id"`
+	Time   int64           `json:"time"`
+	Type   string          `json:"type"`
+	Author *author         `json:"author"`
+	Data   json.RawMessage `json:"data"`
+}
+
+func newAPIEvent(e *commitlog.Event, authors map[string]*author) *apiEvent {
+	return &apiEvent{
+		ID:     e.Id,
+		Time:   e.Time,
+		Type:   e.Type,
+		Author: authors[e.Author],
+		Data:   json.RawMessage(e.Data),
+	}
+}
+
+// apiCommit is a log commit with the authors resolved.
+type apiCommit struct {
+	ID     string      `json:"id"`
+	Msg    string      `json:"msg"`
+	Author *author     `json:"author"`
+	Time   int64       `json:"time"`
+	Events []*apiEvent `json:"events"`
+	Parent string      `json:"parent"`
+}
+
+// nodeData is an update of a node. Only the present fields are set.
+type nodeData struct {
+	Title *string
+	Notes *string
+}
+
+// Result of uploading a single file.
+type uploadResult struct {
+	Name  string `json:"name"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// A file declared by the client for a direct upload.
+type uploadFile struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	Mediatype string `json:"mediatype"`
+}
+
+type uploadTarget struct {
+	Name      string `json:"name"`
+	ID        string `json:"id"`
+	SignedURL string `json:"signed_url"`
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/internal/archive"
+	"github.com/rdm-academy/api/nodes"
+	"github.com/rdm-academy/api/project"
+	"github.com/rdm-academy/api/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	archiveManifest = "manifest.json"
+)
+
+// archiveFormats maps the supported archive formats to their media type.
+var archiveFormats = map[string]string{
+	"zip":    "application/zip",
+	"tar.gz": "application/gzip",
+}
+
+// manifestFile describes a file in an archive.
+type manifestFile struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Hash      string `json:"hash"`
+	Mediatype string `json:"mediatype,omitempty"`
+	PutTime   int64  `json:"put_time,omitempty"`
+
+	// Stored object the contents are read from.
+	object storage.Object
+}
+
+// manifestNode describes a node and its files in an archive.
+type manifestNode struct {
+	ID    string          `json:"id"`
+	Type  string          `json:"type"`
+	Title string          `json:"title"`
+	Notes string          `json:"notes,omitempty"`
+	Path  string          `json:"path"`
+	Files []*manifestFile `json:"files"`
+}
+
+// manifestProject describes the project an archive was built from.
+type manifestProject struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// manifest is written to the root of the archive and describes
+// the contents.
+type manifest struct {
+	Project *manifestProject `json:"project,omitempty"`
+	Created int64            `json:"created"`
+	Nodes   []*manifestNode  `json:"nodes"`
+}
+
+// archiveWriter abstracts the archive formats.
+type archiveWriter interface {
+	// Create adds a file to the archive and returns a writer for its contents.
+	Create(name string, size int64, modtime time.Time) (io.Writer, error)
+
+	// Close flushes the archive.
+	Close() error
+}
+
+type zipArchive struct {
+	w *zip.Writer
+}
+
+func (a *zipArchive) Create(name string, size int64, modtime time.Time) (io.Writer, error) {
+	h := &zip.FileHeader{
+		Name:   name,
+		Method: zip.Deflate,
+	}
+	h.SetModTime(modtime)
+
+	return a.w.CreateHeader(h)
+}
+
+func (a *zipArchive) Close() error {
+	return a.w.Close()
+}
+
+type tarArchive struct {
+	gw *gzip.Writer
+	tw *tar.Writer
+}
+
+func (a *tarArchive) Create(name string, size int64, modtime time.Time) (io.Writer, error) {
+	err := a.tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  modtime,
+		Typeflag: tar.TypeReg,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.tw, nil
+}
+
+func (a *tarArchive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+
+	return a.gw.Close()
+}
+
+func newArchiveWriter(format string, w io.Writer) archiveWriter {
+	if format == "tar.gz" {
+		gw := gzip.NewWriter(w)
+
+		return &tarArchive{
+			gw: gw,
+			tw: tar.NewWriter(gw),
+		}
+	}
+
+	return &zipArchive{
+		w: zip.NewWriter(w),
+	}
+}
+
+// archiveBuilder collects the nodes and file descriptions of an archive
+// prior to writing so errors can be returned before the response starts.
+type archiveBuilder struct {
+	dataSvc data.ServiceClient
+	nodeSvc nodes.ServiceClient
+	storage storage.Storage
+
+	// Prefix of the entries in the archive.
+	prefix string
+
+	manifest *manifest
+	dirs     map[string]struct{}
+}
+
+func newArchiveBuilder(dataSvc data.ServiceClient, nodeSvc nodes.ServiceClient, stg storage.Storage) *archiveBuilder {
+	return &archiveBuilder{
+		dataSvc: dataSvc,
+		nodeSvc: nodeSvc,
+		storage: stg,
+		manifest: &manifest{
+			Created: time.Now().Unix(),
+			Nodes:   []*manifestNode{},
+		},
+		dirs: make(map[string]struct{}),
+	}
+}
+
+// addNode adds the node and describes each of its files. Files that are
+// not stored yet are skipped.
+func (b *archiveBuilder) addNode(ctx context.Context, projectId, id string) error {
+	rep, err := b.nodeSvc.Get(ctx, &nodes.GetRequest{
+		Project: projectId,
+		Id:      id,
+	})
+	if err != nil {
+		return err
+	}
+
+	dir := archive.UniqueName(b.dirs, archive.PathName(rep.Title), rep.Id)
+
+	n := &manifestNode{
+		ID:    rep.Id,
+		Type:  strings.ToLower(rep.Type.String()),
+		Title: rep.Title,
+		Notes: rep.Notes,
+		Path:  dir,
+		Files: []*manifestFile{},
+	}
+
+	names := make(map[string]struct{})
+
+	for _, f := range rep.Files {
+		desc, err := b.dataSvc.Describe(ctx, &data.DescribeRequest{
+			Id: f.Id,
+		})
+		if err != nil {
+			return err
+		}
+
+		if desc.State != data.State_DONE {
+			continue
+		}
+
+		name := archive.UniqueName(names, archive.PathName(f.Name), f.Id)
+
+		n.Files = append(n.Files, &manifestFile{
+			ID:        f.Id,
+			Name:      f.Name,
+			Path:      path.Join(dir, name),
+			Size:      desc.Size,
+			Hash:      desc.Hash,
+			Mediatype: desc.Mediatype,
+			PutTime:   desc.PutTime,
+			object:    data.Object(b.storage, desc),
+		})
+	}
+
+	b.manifest.Nodes = append(b.manifest.Nodes, n)
+
+	return nil
+}
+
+// addProject adds all nodes of the project workflow. Nodes in the
+// workflow without any data are skipped.
+func (b *archiveBuilder) addProject(ctx context.Context, p *project.Project) error {
+	b.manifest.Project = &manifestProject{
+		ID:          p.Id,
+		Name:        p.Name,
+		Description: p.Description,
+	}
+
+	if p.Workflow == nil {
+		return nil
+	}
+
+	ids := make([]string, 0, len(p.Workflow.Nodes))
+	for id := range p.Workflow.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		err := b.addNode(ctx, p.Id, id)
+		if err == nil {
+			continue
+		}
+
+		if sts, ok := status.FromError(err); ok && sts.Code() == codes.NotFound {
+			continue
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// writeBytes adds a file with the contents to the archive.
+func writeBytes(a archiveWriter, name string, buf []byte, modtime time.Time) error {
+	w, err := a.Create(name, int64(len(buf)), modtime)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf)
+	return err
+}
+
+// write writes the manifest followed by the contents of each file.
+func (b *archiveBuilder) write(ctx context.Context, a archiveWriter) error {
+	buf, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = writeBytes(a, path.Join(b.prefix, archiveManifest), buf, time.Unix(b.manifest.Created, 0))
+	if err != nil {
+		return err
+	}
+
+	for _, n := range b.manifest.Nodes {
+		for _, f := range n.Files {
+			if err := b.writeFile(ctx, a, f); err != nil {
+				return fmt.Errorf("%s: %s", f.Path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *archiveBuilder) writeFile(ctx context.Context, a archiveWriter, f *manifestFile) error {
+	r, err := f.object.Reader(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := a.Create(path.Join(b.prefix, f.Path), f.Size, time.Unix(f.PutTime, 0))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// streamArchive streams an archive in the requested format to the client.
+// The function writes the entries of the archive.
+func streamArchive(c echo.Context, name string, fn func(context.Context, archiveWriter) error) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "zip"
+	}
+
+	contentType, ok := archiveFormats[format]
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "format must be zip or tar.gz")
+	}
+
+	name = archive.PathName(name)
+	if name == "" {
+		name = "archive"
+	}
+
+	h := c.Response().Header()
+	h.Set(echo.HeaderContentType, contentType)
+	h.Set(echo.HeaderContentDisposition, contentDisposition(name+"."+format))
+
+	c.Response().WriteHeader(http.StatusOK)
+
+	// Once the response has started, errors can only be logged and
+	// the archive will be truncated.
+	a := newArchiveWriter(format, c.Response())
+	if err := fn(c.Request().Context(), a); err != nil {
+		return err
+	}
+
+	return a.Close()
+}
+
+// serveArchive streams the archive in the requested format to the client.
+func serveArchive(c echo.Context, b *archiveBuilder, name string) error {
+	return streamArchive(c, name, b.write)
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/nodes"
+	"github.com/rdm-academy/api/project"
+	"github.com/rdm-academy/api/storage"
+)
+
+type archiveNodes struct {
+	nodes.ServiceClient
+
+	nodes map[string]*nodes.GetReply
+}
+
+func (f *archiveNodes) Get(ctx context.Context, req *nodes.GetRequest, opts ...transport.RequestOption) (*nodes.GetReply, error) {
+	n, ok := f.nodes[req.Id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "node not found")
+	}
+
+	return n, nil
+}
+
+type archiveData struct {
+	data.ServiceClient
+
+	objects map[string]*data.DescribeReply
+}
+
+func (f *archiveData) Describe(ctx context.Context, req *data.DescribeRequest, opts ...transport.RequestOption) (*data.DescribeReply, error) {
+	d, ok := f.objects[req.Id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "object not found")
+	}
+
+	return d, nil
+}
+
+// readZip returns the contents of the entries of a zip archive.
+func readZip(t *testing.T, b []byte) map[string][]byte {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := make(map[string][]byte)
+
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		buf, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entries[f.Name] = buf
+	}
+
+	return entries
+}
+
+// readTar returns the contents of the entries of a tar.gz archive.
+func readTar(t *testing.T, b []byte) map[string][]byte {
+	gr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := tar.NewReader(gr)
+	entries := make(map[string][]byte)
+
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		buf, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entries[h.Name] = buf
+	}
+
+	return entries
+}
+
+func TestArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+
+	stg, err := storage.New(ctx, storage.Config{Base: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents := map[string]string{
+		"f1": "a,b\n1,2\n",
+		"f2": "a,b\n3,4\n",
+		"f3": "result",
+	}
+
+	objects := map[string]*data.DescribeReply{
+		// Not stored yet.
+		"f4": {Id: "f4", State: data.State_INPROGRESS},
+	}
+
+	for id, c := range contents {
+		w, err := stg.Bucket("test").Object("k" + id).Writer(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, c)
+		w.Close()
+
+		objects[id] = &data.DescribeReply{
+			Id:     id,
+			State:  data.State_DONE,
+			Bucket: "test",
+			Key:    "k" + id,
+			Size:   int64(len(c)),
+			Hash:   fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(c))),
+		}
+	}
+
+	b := newArchiveBuilder(&archiveData{objects: objects}, &archiveNodes{nodes: map[string]*nodes.GetReply{
+		"a": {Id: "a", Type: nodes.NodeType_DATA, Title: "Raw data", Files: []*nodes.File{
+			{Id: "f1", Name: "data.csv"},
+			{Id: "f2", Name: "data.csv"},
+			{Id: "f4", Name: "pending.csv"},
+		}},
+		// Same title as a.
+		"b": {Id: "b", Type: nodes.NodeType_FINDING, Title: "Raw data", Files: []*nodes.File{
+			{Id: "f3", Name: "../result.txt"},
+		}},
+		"c": {Id: "c", Type: nodes.NodeType_MANUAL},
+	}}, stg)
+
+	b.prefix = "project"
+
+	err = b.addProject(ctx, &project.Project{
+		Id:   "p1",
+		Name: "Project",
+		Workflow: &project.Workflow{
+			Nodes: map[string]*project.Node{
+				"a": {}, "b": {}, "c": {},
+				// Removed from the nodes service.
+				"d": {},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries nested under the prefix and node directories.
+	paths := map[string]string{
+		"project/Raw data/data.csv":        "f1",
+		"project/Raw data/data-f2.csv":     "f2",
+		"project/Raw data-b/.._result.txt": "f3",
+	}
+
+	for _, format := range []string{"zip", "tar.gz"} {
+		var buf bytes.Buffer
+
+		a := newArchiveWriter(format, &buf)
+		if err := b.write(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		var entries map[string][]byte
+		if format == "zip" {
+			entries = readZip(t, buf.Bytes())
+		} else {
+			entries = readTar(t, buf.Bytes())
+		}
+
+		if len(entries) != len(paths)+1 {
+			t.Errorf("%s: expected %d entries, got %d", format, len(paths)+1, len(entries))
+		}
+
+		for p, id := range paths {
+			if string(entries[p]) != contents[id] {
+				t.Errorf("%s: expected %s at %s, got %q", format, id, p, entries[p])
+			}
+		}
+
+		var m manifest
+		if err := json.Unmarshal(entries["project/"+archiveManifest], &m); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+
+		if m.Project == nil || m.Project.ID != "p1" {
+			t.Errorf("%s: expected project in manifest", format)
+		}
+
+		if len(m.Nodes) != 3 {
+			t.Fatalf("%s: expected 3 nodes, got %d", format, len(m.Nodes))
+		}
+
+		var files int
+		for _, n := range m.Nodes {
+			for _, f := range n.Files {
+				files++
+
+				// The hashes in the manifest match the contents.
+				c, ok := entries["project/"+f.Path]
+				if !ok {
+					t.Errorf("%s: no entry for %s", format, f.Path)
+					continue
+				}
+
+				if hash := fmt.Sprintf("sha256:%x", sha256.Sum256(c)); hash != f.Hash {
+					t.Errorf("%s: expected hash %s for %s, got %s", format, f.Hash, f.Path, hash)
+				}
+
+				if f.Size != int64(len(c)) {
+					t.Errorf("%s: expected size %d for %s, got %d", format, f.Size, f.Path, len(c))
+				}
+			}
+		}
+
+		if files != len(paths) {
+			t.Errorf("%s: expected %d files in manifest, got %d", format, len(paths), files)
+		}
+	}
+}
@@ -0,0 +1,675 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+	"github.com/rdm-academy/api/account"
+	uuid "github.com/satori/go.uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// How long a login started with a provider can be completed.
+	authLoginTTL = 10 * time.Minute
+
+	authCookie = "auth_login"
+
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+)
+
+var errInvalidLogin = errors.New("invalid or expired login")
+
+var errInvalidRefresh = echo.NewHTTPError(http.StatusUnauthorized, "invalid refresh token")
+
+// authIdentity is the identity of the user returned by a provider.
+type authIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// authProvider performs the OAuth authorization code flow with PKCE with an
+// identity provider.
+type authProvider struct {
+	name         string
+	clientID     string
+	clientSecret string
+	scopes       []string
+
+	// Discovery document location of OpenID Connect providers. The
+	// endpoints are resolved on first use.
+	discoveryURL string
+
+	issuer      string
+	authURL     string
+	tokenURL    string
+	userinfoURL string
+
+	// userinfo returns the identity for the access token.
+	userinfo func(ctx context.Context, p *authProvider, token string) (*authIdentity, error)
+
+	client *http.Client
+	mu     sync.Mutex
+}
+
+func (p *authProvider) httpClient() *http.Client {
+	if p.client == nil {
+		return http.DefaultClient
+	}
+	return p.client
+}
+
+// getJSON performs a GET with the access token and decodes the reply.
+func (p *authProvider) getJSON(ctx context.Context, u, token string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rep, err := p.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer rep.Body.Close()
+
+	if rep.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s returned status %d", p.name, u, rep.StatusCode)
+	}
+
+	return json.NewDecoder(rep.Body).Decode(v)
+}
+
+// discover resolves the endpoints from the OpenID Connect discovery
+// document. Failures are retried on the next use.
+func (p *authProvider) discover(ctx context.Context) error {
+	if p.discoveryURL == "" {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.authURL != "" {
+		return nil
+	}
+
+	var doc struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+
+	if err := p.getJSON(ctx, p.discoveryURL, "", &doc); err != nil {
+		return err
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.UserinfoEndpoint == "" {
+		return fmt.Errorf("%s: incomplete discovery document", p.name)
+	}
+
+	// The document must describe the configured provider, otherwise
+	// identities of another issuer would be accepted as its own.
+	if doc.Issuer != p.issuer {
+		return fmt.Errorf("%s: discovery document issuer %q does not match %q", p.name, doc.Issuer, p.issuer)
+	}
+
+	p.tokenURL = doc.TokenEndpoint
+	p.userinfoURL = doc.UserinfoEndpoint
+	p.authURL = doc.AuthorizationEndpoint
+
+	return nil
+}
+
+// authorizeURL returns the URL the user is sent to in order to sign in.
+func (p *authProvider) authorizeURL(redirectURL, state, challenge string) string {
+	q := url.Values{}
+	q.Set("client_id", p.clientID)
+	q.Set("response_type", "code")
+	q.Set("redirect_uri", redirectURL)
+	q.Set("scope", strings.Join(p.scopes, " "))
+	q.Set("state", state)
+	q.Set("code_challenge", challenge)
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(p.authURL, "?") {
+		sep = "&"
+	}
+
+	return p.authURL + sep + q.Encode()
+}
+
+// exchange trades the authorization code for an access token.
+func (p *authProvider) exchange(ctx context.Context, redirectURL, code, verifier string) (string, error) {
+	form := url.Values{}
+	form.Set("client_id", p.clientID)
+	form.Set("client_secret", p.clientSecret)
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURL)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequest(http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	rep, err := p.httpClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer rep.Body.Close()
+
+	// GitHub reports errors with a 200 status.
+	var t struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+
+	if err := json.NewDecoder(rep.Body).Decode(&t); err != nil {
+		return "", fmt.Errorf("%s: token exchange failed with status %d", p.name, rep.StatusCode)
+	}
+
+	if t.Error != "" {
+		return "", fmt.Errorf("%s: %s: %s", p.name, t.Error, t.Description)
+	}
+
+	if rep.StatusCode != http.StatusOK || t.AccessToken == "" {
+		return "", fmt.Errorf("%s: token exchange failed with status %d", p.name, rep.StatusCode)
+	}
+
+	return t.AccessToken, nil
+}
+
+// oidcUserinfo reads the identity from the OpenID Connect userinfo endpoint.
+func oidcUserinfo(ctx context.Context, p *authProvider, token string) (*authIdentity, error) {
+	var info struct {
+		Subject       string      `json:"sub"`
+		Email         string      `json:"email"`
+		EmailVerified interface{} `json:"email_verified"`
+		Name          string      `json:"name"`
+	}
+
+	if err := p.getJSON(ctx, p.userinfoURL, token, &info); err != nil {
+		return nil, err
+	}
+
+	if info.Subject == "" {
+		return nil, fmt.Errorf("%s: userinfo without subject", p.name)
+	}
+
+	// Some providers encode the flag as a string.
+	var verified bool
+	switch v := info.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = v == "true"
+	}
+
+	return &authIdentity{
+		Issuer:        p.issuer,
+		Subject:       info.Subject,
+		Email:         info.Email,
+		EmailVerified: verified,
+		Name:          info.Name,
+	}, nil
+}
+
+// githubUserinfo reads the identity from the GitHub API. The primary email
+// is used if it is verified.
+func githubUserinfo(ctx context.Context, p *authProvider, token string) (*authIdentity, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+
+	if err := p.getJSON(ctx, p.userinfoURL, token, &user); err != nil {
+		return nil, err
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+
+	if err := p.getJSON(ctx, p.userinfoURL+"/emails", token, &emails); err != nil {
+		return nil, err
+	}
+
+	id := &authIdentity{
+		Issuer:  p.issuer,
+		Subject: strconv.FormatInt(user.ID, 10),
+		Name:    user.Name,
+	}
+
+	if id.Name == "" {
+		id.Name = user.Login
+	}
+
+	for _, e := range emails {
+		if e.Primary {
+			id.Email = e.Email
+			id.EmailVerified = e.Verified
+			break
+		}
+	}
+
+	return id, nil
+}
+
+// newGitHubProvider returns the GitHub provider.
+func newGitHubProvider(clientID, clientSecret string) *authProvider {
+	return &authProvider{
+		name:         "github",
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       []string{"read:user", "user:email"},
+		issuer:       "https://github.com",
+		authURL:      "https://github.com/login/oauth/authorize",
+		tokenURL:     "https://github.com/login/oauth/access_token",
+		userinfoURL:  "https://api.github.com/user",
+		userinfo:     githubUserinfo,
+	}
+}
+
+// newOIDCProvider returns an OpenID Connect provider for the issuer.
+func newOIDCProvider(name, issuer, clientID, clientSecret string) *authProvider {
+	return &authProvider{
+		name:         name,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       []string{"openid", "email", "profile"},
+		issuer:       issuer,
+		discoveryURL: strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration",
+		userinfo:     oidcUserinfo,
+	}
+}
+
+// authLogin is stored in a signed cookie between the login and callback.
+type authLogin struct {
+	Provider string `json:"p"`
+	State    string `json:"s"`
+	Verifier string `json:"v"`
+	Expires  int64  `json:"e"`
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// pkceChallenge returns the S256 challenge of the verifier.
+func pkceChallenge(verifier string) string {
+	h := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
+
+// authTokens is returned to the client after a login or refresh.
+type authTokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// tokenIssuer mints the access and refresh tokens of the gateway. The
+// subject of the tokens is the account id. The key is only used for these
+// tokens, so an issuer sharing the HMAC key of external tokens cannot mint
+// them. The id of a refresh token is its session in the account service,
+// which is rotated on each use.
+type tokenIssuer struct {
+	key        []byte
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	accountSvc account.ServiceClient
+}
+
+func (t *tokenIssuer) sign(user *account.GetUserResponse, typ, jti string, ttl time.Duration, now time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"iss":   t.issuer,
+		"sub":   user.Id,
+		"jti":   jti,
+		"iat":   now.Unix(),
+		"exp":   now.Add(ttl).Unix(),
+		"typ":   typ,
+		"email": user.Email,
+		"name":  user.Name,
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.key)
+}
+
+func (t *tokenIssuer) tokens(user *account.GetUserResponse, session string, now time.Time) (*authTokens, error) {
+	access, err := t.sign(user, accessTokenType, uuid.NewV4().String(), t.accessTTL, now)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := t.sign(user, refreshTokenType, session, t.refreshTTL, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authTokens{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(t.accessTTL / time.Second),
+	}, nil
+}
+
+// mint starts a session for the sign in with the identity and returns its
+// tokens.
+func (t *tokenIssuer) mint(ctx context.Context, user *account.GetUserResponse, issuer, subject string) (*authTokens, error) {
+	now := time.Now()
+
+	rep, err := t.accountSvc.CreateSession(ctx, &account.CreateSessionRequest{
+		Account: user.Id,
+		Issuer:  issuer,
+		Subject: subject,
+		Expires: now.Add(t.refreshTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t.tokens(user, rep.Id, now)
+}
+
+// refresh rotates the session of the refresh token and returns new tokens.
+// A refresh token can only be used once.
+func (t *tokenIssuer) refresh(ctx context.Context, s string) (*authTokens, error) {
+	userId, session, err := t.parseRefresh(s)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	rep, err := t.accountSvc.RotateSession(ctx, &account.RotateSessionRequest{
+		Id:      session,
+		Expires: now.Add(t.refreshTTL).Unix(),
+	})
+	if status.Code(err) == codes.Unauthenticated {
+		return nil, errInvalidRefresh
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if rep.Account != userId {
+		return nil, errInvalidRefresh
+	}
+
+	user, err := t.accountSvc.GetUser(ctx, &account.GetUserRequest{
+		Id: userId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t.tokens(user, rep.Id, now)
+}
+
+// parseRefresh verifies the refresh token and returns the account id and
+// the session.
+func (t *tokenIssuer) parseRefresh(s string) (string, string, error) {
+	token, err := jwt.Parse(s, func(tok *jwt.Token) (interface{}, error) {
+		if _, ok := tok.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", tok.Header["alg"])
+		}
+		return t.key, nil
+	})
+	if err != nil || !token.Valid {
+		return "", "", errInvalidRefresh
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+
+	iss, _ := claims["iss"].(string)
+	typ, _ := claims["typ"].(string)
+	sub, _ := claims["sub"].(string)
+	jti, _ := claims["jti"].(string)
+
+	if iss != t.issuer || typ != refreshTokenType || sub == "" || jti == "" {
+		return "", "", errInvalidRefresh
+	}
+
+	return sub, jti, nil
+}
+
+// accessOnly rejects refresh tokens on the routes protected by the JWT
+// middleware.
+func accessOnly(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if token, ok := c.Get("user").(*jwt.Token); ok {
+			if claims, ok := token.Claims.(jwt.MapClaims); ok {
+				if typ, _ := claims["typ"].(string); typ == refreshTokenType {
+					return echo.NewHTTPError(http.StatusUnauthorized, "refresh token cannot be used for access")
+				}
+			}
+		}
+
+		return next(c)
+	}
+}
+
+// authHandler serves the login routes of the providers.
+type authHandler struct {
+	providers map[string]*authProvider
+	tokens    *tokenIssuer
+
+	// Public base URL of the gateway used for the callback URLs.
+	baseURL string
+
+	// Client URL the user is redirected to with the tokens in the
+	// fragment. If empty, the tokens are returned as JSON.
+	successURL string
+
+	accountSvc account.ServiceClient
+}
+
+func (h *authHandler) callbackURL(provider string) string {
+	return strings.TrimSuffix(h.baseURL, "/") + "/auth/" + provider + "/callback"
+}
+
+func (h *authHandler) provider(c echo.Context) (*authProvider, error) {
+	p, ok := h.providers[c.Param("provider")]
+	if !ok {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "unknown provider")
+	}
+
+	if err := p.discover(c.Request().Context()); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadGateway, err.Error())
+	}
+
+	return p, nil
+}
+
+// login redirects to the provider. The state and PKCE verifier are kept in
+// a signed cookie.
+func (h *authHandler) login(c echo.Context) error {
+	p, err := h.provider(c)
+	if err != nil {
+		return err
+	}
+
+	state, err := randomString(16)
+	if err != nil {
+		return err
+	}
+
+	verifier, err := randomString(32)
+	if err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(&authLogin{
+		Provider: p.name,
+		State:    state,
+		Verifier: verifier,
+		Expires:  time.Now().Add(authLoginTTL).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     authCookie,
+		Value:    signPayload(h.tokens.key, buf),
+		Path:     "/auth/",
+		MaxAge:   int(authLoginTTL / time.Second),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.baseURL, "https://"),
+	})
+
+	return c.Redirect(http.StatusFound, p.authorizeURL(h.callbackURL(p.name), state, pkceChallenge(verifier)))
+}
+
+// callback completes the login, creates or links the account and issues the
+// tokens of the gateway.
+func (h *authHandler) callback(c echo.Context) error {
+	p, err := h.provider(c)
+	if err != nil {
+		return err
+	}
+
+	if e := c.QueryParam("error"); e != "" {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s: %s", p.name, e))
+	}
+
+	cookie, err := c.Cookie(authCookie)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, errInvalidLogin.Error())
+	}
+
+	// Single use.
+	c.SetCookie(&http.Cookie{
+		Name:   authCookie,
+		Path:   "/auth/",
+		MaxAge: -1,
+	})
+
+	payload, err := openPayload(h.tokens.key, cookie.Value)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, errInvalidLogin.Error())
+	}
+
+	var l authLogin
+	if err := json.Unmarshal(payload, &l); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, errInvalidLogin.Error())
+	}
+
+	if l.Provider != p.name || l.State != c.QueryParam("state") || time.Now().Unix() > l.Expires {
+		return echo.NewHTTPError(http.StatusBadRequest, errInvalidLogin.Error())
+	}
+
+	ctx := c.Request().Context()
+
+	token, err := p.exchange(ctx, h.callbackURL(p.name), c.QueryParam("code"), l.Verifier)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	id, err := p.userinfo(ctx, p, token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, err.Error())
+	}
+
+	userId, _, err := ensureAccount(ctx, h.accountSvc, id.EmailVerified, id.Issuer, id.Subject, id.Email, id.Name)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.accountSvc.GetUser(ctx, &account.GetUserRequest{
+		Id: userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	tokens, err := h.tokens.mint(ctx, user, id.Issuer, id.Subject)
+	if err != nil {
+		return err
+	}
+
+	if h.successURL == "" {
+		return c.JSON(http.StatusOK, tokens)
+	}
+
+	f := url.Values{}
+	f.Set("access_token", tokens.AccessToken)
+	f.Set("refresh_token", tokens.RefreshToken)
+	f.Set("token_type", tokens.TokenType)
+	f.Set("expires_in", strconv.FormatInt(tokens.ExpiresIn, 10))
+
+	return c.Redirect(http.StatusFound, h.successURL+"#"+f.Encode())
+}
+
+// refresh issues new tokens for a refresh token.
+func (h *authHandler) refresh(c echo.Context) error {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := c.Bind(&body); err != nil {
+		return err
+	}
+
+	tokens, err := h.tokens.refresh(c.Request().Context(), body.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// list returns the names of the configured providers.
+func (h *authHandler) list(c echo.Context) error {
+	names := make([]string, 0, len(h.providers))
+	for n := range h.providers {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	return c.JSON(http.StatusOK, names)
+}
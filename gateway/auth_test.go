@@ -0,0 +1,395 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/labstack/echo"
+	"github.com/rdm-academy/api/account"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// oidcStandIn serves the discovery, token and userinfo endpoints of an
+// OpenID Connect provider. The token endpoint checks the PKCE verifier.
+func oidcStandIn(t *testing.T) *httptest.Server {
+	var (
+		srv       *httptest.Server
+		challenge string
+	)
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"userinfo_endpoint":      srv.URL + "/userinfo",
+		})
+	})
+
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("code_challenge_method") != "S256" {
+			t.Errorf("expected S256 challenge, got %q", q.Get("code_challenge_method"))
+		}
+
+		challenge = q.Get("code_challenge")
+
+		u, _ := url.Parse(q.Get("redirect_uri"))
+		v := url.Values{}
+		v.Set("code", "abc")
+		v.Set("state", q.Get("state"))
+		u.RawQuery = v.Encode()
+
+		http.Redirect(w, r, u.String(), http.StatusFound)
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		if r.Form.Get("code") != "abc" || pkceChallenge(r.Form.Get("code_verifier")) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "invalid_grant",
+			})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "token",
+			"token_type":   "bearer",
+		})
+	})
+
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sub":            "123",
+			"email":          "joe@example.com",
+			"email_verified": true,
+			"name":           "Joe",
+		})
+	})
+
+	srv = httptest.NewServer(mux)
+
+	return srv
+}
+
+// fakeAccounts implements the account lookups used by the login.
+type fakeAccounts struct {
+	account.ServiceClient
+
+	users map[string]*account.GetUserResponse
+
+	// Accounts of the sessions.
+	sessions map[string]string
+}
+
+func (f *fakeAccounts) GetUser(ctx context.Context, req *account.GetUserRequest, opts ...transport.RequestOption) (*account.GetUserResponse, error) {
+	key := req.Id
+	if req.Issuer != "" {
+		key = req.Issuer + "|" + req.Subject
+	}
+
+	if req.Email != "" {
+		for _, u := range f.users {
+			if u.Email == req.Email {
+				return u, nil
+			}
+		}
+	}
+
+	if u, ok := f.users[key]; ok {
+		return u, nil
+	}
+
+	return nil, status.Error(codes.NotFound, "user does not exist")
+}
+
+func (f *fakeAccounts) CreateUser(ctx context.Context, req *account.CreateUserRequest, opts ...transport.RequestOption) (*account.CreateUserResponse, error) {
+	for _, u := range f.users {
+		if u.Email == req.Email {
+			return nil, status.Error(codes.AlreadyExists, "user already exists")
+		}
+	}
+
+	u := &account.GetUserResponse{
+		Id:            "u1",
+		Email:         req.Email,
+		Name:          req.Name,
+		EmailVerified: req.EmailVerified,
+	}
+
+	f.users[u.Id] = u
+	f.users[req.Issuer+"|"+req.Subject] = u
+
+	return &account.CreateUserResponse{
+		Id:    u.Id,
+		Email: u.Email,
+		Name:  u.Name,
+	}, nil
+}
+
+func (f *fakeAccounts) LinkIdentity(ctx context.Context, req *account.LinkIdentityRequest, opts ...transport.RequestOption) (*account.LinkIdentityResponse, error) {
+	f.users[req.Issuer+"|"+req.Subject] = f.users[req.Id]
+	return &account.LinkIdentityResponse{}, nil
+}
+
+func (f *fakeAccounts) CreateSession(ctx context.Context, req *account.CreateSessionRequest, opts ...transport.RequestOption) (*account.CreateSessionResponse, error) {
+	if f.sessions == nil {
+		f.sessions = make(map[string]string)
+	}
+
+	id := fmt.Sprintf("s%d", len(f.sessions)+1)
+	f.sessions[id] = req.Account
+
+	return &account.CreateSessionResponse{
+		Id: id,
+	}, nil
+}
+
+func (f *fakeAccounts) RotateSession(ctx context.Context, req *account.RotateSessionRequest, opts ...transport.RequestOption) (*account.RotateSessionResponse, error) {
+	a, ok := f.sessions[req.Id]
+	if !ok || a == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid session")
+	}
+
+	// Rotated sessions are kept empty so the ids are not reused.
+	f.sessions[req.Id] = ""
+
+	rep, err := f.CreateSession(ctx, &account.CreateSessionRequest{Account: a})
+	if err != nil {
+		return nil, err
+	}
+
+	return &account.RotateSessionResponse{
+		Id:      rep.Id,
+		Account: a,
+	}, nil
+}
+
+func TestEnsureAccountLink(t *testing.T) {
+	tests := []struct {
+		name            string
+		accountVerified bool
+		idVerified      bool
+		linked          bool
+	}{
+		{"both verified", true, true, true},
+		{"account unverified", false, true, false},
+		{"identity unverified", true, false, false},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+		accounts := &fakeAccounts{
+			users: make(map[string]*account.GetUserResponse),
+		}
+
+		// The account was created with another identity.
+		_, _, err := ensureAccount(ctx, accounts, test.accountVerified, "a", "1", "joe@example.com", "Joe")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id, created, err := ensureAccount(ctx, accounts, test.idVerified, "b", "1", "joe@example.com", "Joe")
+
+		if !test.linked {
+			if err != errLinkIdentity {
+				t.Errorf("%s: expected the link to be refused, got %v", test.name, err)
+			}
+			if _, ok := accounts.users["b|1"]; ok {
+				t.Errorf("%s: expected the identity not to be linked", test.name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+
+		if id != "u1" || created {
+			t.Errorf("%s: expected the identity to be linked to u1, got %s (created %v)", test.name, id, created)
+		}
+	}
+}
+
+func TestAuthLogin(t *testing.T) {
+	idp := oidcStandIn(t)
+	defer idp.Close()
+
+	accounts := &fakeAccounts{
+		users: make(map[string]*account.GetUserResponse),
+	}
+
+	h := &authHandler{
+		providers: map[string]*authProvider{
+			"oidc": newOIDCProvider("oidc", idp.URL, "client", "secret"),
+		},
+		tokens: &tokenIssuer{
+			key:        []byte("key"),
+			issuer:     "gateway",
+			accessTTL:  time.Minute,
+			refreshTTL: time.Hour,
+			accountSvc: accounts,
+		},
+		accountSvc: accounts,
+	}
+
+	e := echo.New()
+	e.GET("/auth/:provider/login", h.login)
+	e.GET("/auth/:provider/callback", h.callback)
+	e.POST("/auth/token", h.refresh)
+
+	gw := httptest.NewServer(e)
+	defer gw.Close()
+
+	h.baseURL = gw.URL
+
+	login, err := http.NewRequest(http.MethodGet, gw.URL+"/auth/oidc/login", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Capture the login response to get the cookie.
+	rep, err := (&http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}).Do(login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.Body.Close()
+
+	if rep.StatusCode != http.StatusFound {
+		t.Fatalf("expected redirect, got %d", rep.StatusCode)
+	}
+
+	cookies := rep.Cookies()
+
+	// Follows the redirects to the provider and back to the callback,
+	// passing on the login cookie.
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			for _, c := range cookies {
+				req.AddCookie(c)
+			}
+			return nil
+		},
+	}
+
+	authorize, _ := http.NewRequest(http.MethodGet, rep.Header.Get("Location"), nil)
+
+	rep, err = client.Do(authorize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rep.Body.Close()
+
+	if rep.StatusCode != http.StatusOK {
+		t.Fatalf("expected tokens, got %d", rep.StatusCode)
+	}
+
+	var tokens authTokens
+	if err := json.NewDecoder(rep.Body).Decode(&tokens); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := accounts.users[idp.URL+"|123"]; !ok {
+		t.Error("expected account to be created for the identity")
+	}
+
+	if id, session, err := h.tokens.parseRefresh(tokens.RefreshToken); err != nil || id != "u1" || session != "s1" {
+		t.Errorf("expected refresh token of session s1 for u1, got %q and %q (%v)", id, session, err)
+	}
+
+	// The access token is not a refresh token.
+	if _, _, err := h.tokens.parseRefresh(tokens.AccessToken); err == nil {
+		t.Error("expected access token to be rejected as refresh token")
+	}
+
+	refresh := func() int {
+		rep, err := http.Post(gw.URL+"/auth/token", "application/json", strings.NewReader(`{"refresh_token":"`+tokens.RefreshToken+`"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep.Body.Close()
+
+		return rep.StatusCode
+	}
+
+	if code := refresh(); code != http.StatusOK {
+		t.Errorf("expected refresh to succeed, got %d", code)
+	}
+
+	// The session was rotated.
+	if code := refresh(); code != http.StatusUnauthorized {
+		t.Errorf("expected a used refresh token to be rejected, got %d", code)
+	}
+}
+
+func TestAuthCallbackWithoutLogin(t *testing.T) {
+	idp := oidcStandIn(t)
+	defer idp.Close()
+
+	h := &authHandler{
+		providers: map[string]*authProvider{
+			"oidc": newOIDCProvider("oidc", idp.URL, "client", "secret"),
+		},
+		tokens: &tokenIssuer{
+			key: []byte("key"),
+		},
+	}
+
+	e := echo.New()
+	e.GET("/auth/:provider/callback", h.callback)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/oidc/callback?code=abc&state=x", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestOIDCDiscovery(t *testing.T) {
+	idp := oidcStandIn(t)
+	defer idp.Close()
+
+	ctx := context.Background()
+
+	p := newOIDCProvider("oidc", idp.URL, "client", "secret")
+	if err := p.discover(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if p.issuer != idp.URL || p.tokenURL != idp.URL+"/token" {
+		t.Errorf("unexpected endpoints: %s %s", p.issuer, p.tokenURL)
+	}
+
+	// The document is served for another issuer than the configured one.
+	p = newOIDCProvider("oidc", idp.URL+"/", "client", "secret")
+	if err := p.discover(ctx); err == nil {
+		t.Error("expected issuer mismatch to be rejected")
+	}
+	if p.authURL != "" {
+		t.Error("expected endpoints not to be set")
+	}
+}
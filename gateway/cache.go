@@ -0,0 +1,291 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/golang/protobuf/proto"
+	"github.com/rdm-academy/api/account"
+	"github.com/rdm-academy/api/commitlog"
+)
+
+type accountCacheEntry struct {
+	key     string
+	user    *account.GetUserResponse
+	expires time.Time
+}
+
+// accountCache is a TTL and LRU cache of account lookups. A user is cached
+// under each key it was looked up by, e.g. id, email and identity, so all
+// of them can be invalidated by the user id.
+type accountCache struct {
+	ttl  time.Duration
+	size int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	keys  map[string]map[string]struct{}
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+func newAccountCache(size int, ttl time.Duration) *accountCache {
+	return &accountCache{
+		ttl:   ttl,
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+		keys:  make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *accountCache) get(key string) (*account.GetUserResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+
+	e := el.Value.(*accountCacheEntry)
+
+	if time.Now().After(e.expires) {
+		c.remove(el)
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	atomic.AddUint64(&c.hits, 1)
+
+	return e.user, true
+}
+
+func (c *accountCache) set(key string, user *account.GetUserResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+
+	el := c.ll.PushFront(&accountCacheEntry{
+		key:     key,
+		user:    user,
+		expires: time.Now().Add(c.ttl),
+	})
+
+	c.items[key] = el
+
+	ks, ok := c.keys[user.Id]
+	if !ok {
+		ks = make(map[string]struct{})
+		c.keys[user.Id] = ks
+	}
+	ks[key] = struct{}{}
+
+	for c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+		atomic.AddUint64(&c.evictions, 1)
+	}
+}
+
+// remove must be called with the lock held.
+func (c *accountCache) remove(el *list.Element) {
+	e := c.ll.Remove(el).(*accountCacheEntry)
+	delete(c.items, e.key)
+
+	if ks, ok := c.keys[e.user.Id]; ok {
+		delete(ks, e.key)
+		if len(ks) == 0 {
+			delete(c.keys, e.user.Id)
+		}
+	}
+}
+
+// invalidate removes all entries of the user.
+func (c *accountCache) invalidate(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.keys[id] {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+func (c *accountCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// writeMetrics writes the counters in the Prometheus text format.
+func (c *accountCache) writeMetrics(w io.Writer) {
+	fmt.Fprintf(w, "# TYPE gateway_account_cache_hits_total counter\n")
+	fmt.Fprintf(w, "gateway_account_cache_hits_total %d\n", atomic.LoadUint64(&c.hits))
+	fmt.Fprintf(w, "# TYPE gateway_account_cache_misses_total counter\n")
+	fmt.Fprintf(w, "gateway_account_cache_misses_total %d\n", atomic.LoadUint64(&c.misses))
+	fmt.Fprintf(w, "# TYPE gateway_account_cache_evictions_total counter\n")
+	fmt.Fprintf(w, "gateway_account_cache_evictions_total %d\n", atomic.LoadUint64(&c.evictions))
+	fmt.Fprintf(w, "# TYPE gateway_account_cache_entries gauge\n")
+	fmt.Fprintf(w, "gateway_account_cache_entries %d\n", c.len())
+}
+
+// userCacheKey returns the key of the lookup, or an empty string if the
+// request is not cacheable.
+func userCacheKey(req *account.GetUserRequest) string {
+	switch {
+	case req.Id != "":
+		return "id:" + req.Id
+	case req.Email != "":
+		return "email:" + strings.ToLower(req.Email)
+	case req.Issuer != "" && req.Subject != "":
+		return "identity:" + req.Issuer + "|" + req.Subject
+	}
+	return ""
+}
+
+// cachedAccounts is an account client with cached user lookups. Changes
+// made through it invalidate the user immediately; changes by other
+// gateways are received as account events.
+type cachedAccounts struct {
+	account.ServiceClient
+
+	cache *accountCache
+}
+
+func (a *cachedAccounts) GetUser(ctx context.Context, req *account.GetUserRequest, opts ...transport.RequestOption) (*account.GetUserResponse, error) {
+	key := userCacheKey(req)
+	if key == "" {
+		return a.ServiceClient.GetUser(ctx, req, opts...)
+	}
+
+	if u, ok := a.cache.get(key); ok {
+		return u, nil
+	}
+
+	u, err := a.ServiceClient.GetUser(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	a.cache.set(key, u)
+	if id := "id:" + u.Id; id != key {
+		a.cache.set(id, u)
+	}
+
+	return u, nil
+}
+
+// GetUsers returns the cached users and looks up the others in one request.
+func (a *cachedAccounts) GetUsers(ctx context.Context, req *account.GetUsersRequest, opts ...transport.RequestOption) (*account.GetUsersResponse, error) {
+	found := make(map[string]*account.GetUserResponse, len(req.Ids))
+
+	var missing []string
+	for _, id := range req.Ids {
+		if _, ok := found[id]; ok {
+			continue
+		}
+
+		if u, ok := a.cache.get("id:" + id); ok {
+			found[id] = u
+		} else {
+			found[id] = nil
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) > 0 {
+		rep, err := a.ServiceClient.GetUsers(ctx, &account.GetUsersRequest{
+			Ids: missing,
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, u := range rep.Users {
+			a.cache.set("id:"+u.Id, u)
+			found[u.Id] = u
+		}
+	}
+
+	users := make([]*account.GetUserResponse, 0, len(found))
+	for _, id := range req.Ids {
+		if u := found[id]; u != nil {
+			users = append(users, u)
+			delete(found, id)
+		}
+	}
+
+	return &account.GetUsersResponse{
+		Users: users,
+	}, nil
+}
+
+func (a *cachedAccounts) UpdateUser(ctx context.Context, req *account.UpdateUserRequest, opts ...transport.RequestOption) (*account.UpdateUserResponse, error) {
+	defer a.cache.invalidate(req.Id)
+	return a.ServiceClient.UpdateUser(ctx, req, opts...)
+}
+
+func (a *cachedAccounts) DeleteUser(ctx context.Context, req *account.DeleteUserRequest, opts ...transport.RequestOption) (*account.DeleteUserResponse, error) {
+	defer a.cache.invalidate(req.Id)
+	return a.ServiceClient.DeleteUser(ctx, req, opts...)
+}
+
+func (a *cachedAccounts) LinkIdentity(ctx context.Context, req *account.LinkIdentityRequest, opts ...transport.RequestOption) (*account.LinkIdentityResponse, error) {
+	defer a.cache.invalidate(req.Id)
+	return a.ServiceClient.LinkIdentity(ctx, req, opts...)
+}
+
+func (a *cachedAccounts) UnlinkIdentity(ctx context.Context, req *account.UnlinkIdentityRequest, opts ...transport.RequestOption) (*account.UnlinkIdentityResponse, error) {
+	defer a.cache.invalidate(req.Id)
+	return a.ServiceClient.UnlinkIdentity(ctx, req, opts...)
+}
+
+func (a *cachedAccounts) RequestEmailChange(ctx context.Context, req *account.RequestEmailChangeRequest, opts ...transport.RequestOption) (*account.RequestEmailChangeResponse, error) {
+	defer a.cache.invalidate(req.Id)
+	return a.ServiceClient.RequestEmailChange(ctx, req, opts...)
+}
+
+func (a *cachedAccounts) ConfirmEmailChange(ctx context.Context, req *account.ConfirmEmailChangeRequest, opts ...transport.RequestOption) (*account.ConfirmEmailChangeResponse, error) {
+	defer a.cache.invalidate(req.Id)
+	return a.ServiceClient.ConfirmEmailChange(ctx, req, opts...)
+}
+
+func (a *cachedAccounts) VerifyOrcid(ctx context.Context, req *account.VerifyOrcidRequest, opts ...transport.RequestOption) (*account.VerifyOrcidResponse, error) {
+	defer a.cache.invalidate(req.Id)
+	return a.ServiceClient.VerifyOrcid(ctx, req, opts...)
+}
+
+// newAccountCacheHandler invalidates users on account events.
+func newAccountCacheHandler(c *accountCache) transport.Handler {
+	return func(msg *transport.Message) (proto.Message, error) {
+		var e commitlog.Event
+		if err := msg.Decode(&e); err != nil {
+			return nil, err
+		}
+
+		switch e.Type {
+		case "account.updated", "account.deleted":
+			if e.Author != "" {
+				c.invalidate(e.Author)
+			}
+		}
+
+		return nil, nil
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/rdm-academy/api/account"
+)
+
+// countingAccounts counts the user lookups reaching the service.
+type countingAccounts struct {
+	account.ServiceClient
+
+	user  *account.GetUserResponse
+	calls int
+}
+
+func (f *countingAccounts) GetUser(ctx context.Context, req *account.GetUserRequest, opts ...transport.RequestOption) (*account.GetUserResponse, error) {
+	f.calls++
+	return f.user, nil
+}
+
+func (f *countingAccounts) GetUsers(ctx context.Context, req *account.GetUsersRequest, opts ...transport.RequestOption) (*account.GetUsersResponse, error) {
+	f.calls++
+
+	rep := &account.GetUsersResponse{}
+	for _, id := range req.Ids {
+		if id == f.user.Id {
+			rep.Users = append(rep.Users, f.user)
+		}
+	}
+
+	return rep, nil
+}
+
+func (f *countingAccounts) UpdateUser(ctx context.Context, req *account.UpdateUserRequest, opts ...transport.RequestOption) (*account.UpdateUserResponse, error) {
+	return &account.UpdateUserResponse{}, nil
+}
+
+func TestCachedAccounts(t *testing.T) {
+	svc := &countingAccounts{
+		user: &account.GetUserResponse{Id: "u1", Email: "jane@example.com"},
+	}
+
+	c := newAccountCache(10, time.Minute)
+
+	a := &cachedAccounts{
+		ServiceClient: svc,
+		cache:         c,
+	}
+
+	ctx := context.Background()
+
+	a.GetUser(ctx, &account.GetUserRequest{Email: "Jane@example.com"})
+	a.GetUser(ctx, &account.GetUserRequest{Email: "jane@example.com"})
+	// Cached by id by the email lookup.
+	a.GetUser(ctx, &account.GetUserRequest{Id: "u1"})
+
+	if svc.calls != 1 {
+		t.Errorf("expected 1 lookup, got %d", svc.calls)
+	}
+
+	// Updates invalidate all keys of the user.
+	a.UpdateUser(ctx, &account.UpdateUserRequest{Id: "u1"})
+
+	if n := c.len(); n != 0 {
+		t.Errorf("expected empty cache, got %d entries", n)
+	}
+
+	a.GetUser(ctx, &account.GetUserRequest{Id: "u1"})
+
+	if svc.calls != 2 {
+		t.Errorf("expected 2 lookups, got %d", svc.calls)
+	}
+
+	if c.hits != 2 || c.misses != 2 {
+		t.Errorf("expected 2 hits and 2 misses, got %d and %d", c.hits, c.misses)
+	}
+}
+
+func TestAccountCacheEviction(t *testing.T) {
+	c := newAccountCache(2, time.Minute)
+
+	c.set("id:u1", &account.GetUserResponse{Id: "u1"})
+	c.set("id:u2", &account.GetUserResponse{Id: "u2"})
+
+	// Recently used entries are kept.
+	c.get("id:u1")
+	c.set("id:u3", &account.GetUserResponse{Id: "u3"})
+
+	if _, ok := c.get("id:u2"); ok {
+		t.Error("expected least recently used entry to be evicted")
+	}
+
+	if _, ok := c.get("id:u1"); !ok {
+		t.Error("expected recently used entry to be kept")
+	}
+
+	if c.evictions != 1 {
+		t.Errorf("expected 1 eviction, got %d", c.evictions)
+	}
+
+	c.ttl = -time.Second
+	c.set("id:u4", &account.GetUserResponse{Id: "u4"})
+
+	if _, ok := c.get("id:u4"); ok {
+		t.Error("expected expired entry to be missed")
+	}
+}
+
+func TestEnricherGetAuthors(t *testing.T) {
+	svc := &countingAccounts{
+		user: &account.GetUserResponse{
+			Id:            "u1",
+			Name:          "Jane",
+			Email:         " Jane@Example.com",
+			Orcid:         "0000-0002-1825-0097",
+			OrcidVerified: true,
+		},
+	}
+
+	e := &Enricher{
+		accountSvc: &cachedAccounts{
+			ServiceClient: svc,
+			cache:         newAccountCache(10, time.Minute),
+		},
+	}
+
+	ctx := context.Background()
+
+	authors, err := e.GetAuthors(ctx, []string{"u1", "", "u2", "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := authors["u1"]
+	if a == nil || a.Name != "Jane" || a.Orcid != "0000-0002-1825-0097" {
+		t.Fatalf("unexpected author: %+v", a)
+	}
+
+	// SHA-256 of the normalized "jane@example.com".
+	if a.EmailHash != "8c87b489ce35cf2e2f39f80e282cb2e804932a56a213983eeeb428407d43b52d" {
+		t.Errorf("unexpected email hash %q", a.EmailHash)
+	}
+
+	if a := authors["u2"]; a == nil || a.ID != "u2" || a.Name != "" {
+		t.Errorf("expected unknown author with id only, got %+v", a)
+	}
+
+	if _, ok := authors[""]; ok {
+		t.Error("expected empty id to be skipped")
+	}
+
+	if svc.calls != 1 {
+		t.Errorf("expected 1 batch lookup, got %d", svc.calls)
+	}
+
+	// The known user is cached, so only the unknown one is requested.
+	e.GetAuthors(ctx, []string{"u1", "u2"})
+
+	if svc.calls != 2 {
+		t.Errorf("expected 2 lookups, got %d", svc.calls)
+	}
+}
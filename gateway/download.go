@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/storage"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fileDownload serves the contents of project files. Files are proxied from
+// the signed URL or, in redirect mode, the client is redirected to it. The
+// mode can be chosen per request with the redirect query parameter. Storage
+// without signed URLs is always read directly.
+type fileDownload struct {
+	logger  *zap.Logger
+	dataSvc data.ServiceClient
+	storage storage.Storage
+
+	// Redirect to the signed URL unless the request says otherwise.
+	redirect bool
+}
+
+// serve downloads the file of the request as an attachment with the given
+// name. The caller checks the file is part of a project the user can view.
+func (d *fileDownload) serve(c echo.Context, name string) error {
+	ctx := c.Request().Context()
+
+	file := c.Param("file")
+
+	redirect := d.redirect
+	if v := c.QueryParam("redirect"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "redirect must be a boolean")
+		}
+		redirect = b
+	}
+
+	if redirect {
+		rep, err := d.dataSvc.Get(ctx, &data.GetRequest{
+			Id:                 file,
+			ContentDisposition: contentDisposition(name),
+		})
+		if err == nil {
+			return c.Redirect(http.StatusFound, rep.SignedUrl)
+		}
+
+		// Proxy the file if the storage cannot sign URLs.
+		if status.Code(err) != codes.Unimplemented {
+			return err
+		}
+	}
+
+	desc, err := d.dataSvc.Describe(ctx, &data.DescribeRequest{
+		Id: file,
+	})
+	if err != nil {
+		return err
+	}
+
+	rep, err := d.dataSvc.Get(ctx, &data.GetRequest{
+		Id: file,
+	})
+	if status.Code(err) == codes.Unimplemented {
+		return serveObject(c, d.logger, data.Object(d.storage, desc), desc, name)
+	}
+	if err != nil {
+		return err
+	}
+
+	return serveFile(c, d.logger, rep.SignedUrl, desc, name)
+}
+
+// fileETag returns a strong entity tag derived from the object hash.
+func fileETag(hash string) string {
+	if hash == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(`"%s"`, strings.TrimPrefix(hash, "sha256:"))
+}
+
+// notModified checks the conditional request headers against the entity
+// tag and modification time of the file. If-None-Match takes precedence
+// over If-Modified-Since.
+func notModified(req *http.Request, etag string, modtime time.Time) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		if etag == "" {
+			return false
+		}
+
+		for _, t := range strings.Split(inm, ",") {
+			t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+			if t == "*" || t == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if ims := req.Header.Get("If-Modified-Since"); ims != "" && !modtime.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err == nil && !modtime.After(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rangeApplies checks whether the Range header should be honored given
+// the If-Range header, if present.
+func rangeApplies(req *http.Request, etag string, modtime time.Time) bool {
+	ir := req.Header.Get("If-Range")
+	if ir == "" {
+		return true
+	}
+
+	if strings.HasPrefix(ir, `"`) {
+		return etag != "" && ir == etag
+	}
+
+	t, err := http.ParseTime(ir)
+	return err == nil && !modtime.IsZero() && modtime.Equal(t)
+}
+
+// contentDisposition returns the header value for downloading the
+// file as an attachment with the given name.
+func contentDisposition(name string) string {
+	if name == "" {
+		return "attachment"
+	}
+
+	v := mime.FormatMediaType("attachment", map[string]string{
+		"filename": name,
+	})
+	if v == "" {
+		return "attachment"
+	}
+
+	return v
+}
+
+// serveFile proxies the file at the signed URL to the client. Range and
+// conditional requests are supported using the object's hash and put time.
+// Storage errors are logged rather than passed on to the client.
+func serveFile(c echo.Context, logger *zap.Logger, url string, desc *data.DescribeReply, name string) error {
+	req := c.Request()
+	h := c.Response().Header()
+
+	etag := fileETag(desc.Hash)
+
+	var modtime time.Time
+	if desc.PutTime > 0 {
+		modtime = time.Unix(desc.PutTime, 0).UTC()
+	}
+
+	h.Set("Accept-Ranges", "bytes")
+
+	if etag != "" {
+		h.Set("ETag", etag)
+	}
+
+	if !modtime.IsZero() {
+		h.Set(echo.HeaderLastModified, modtime.Format(http.TimeFormat))
+	}
+
+	if notModified(req, etag, modtime) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	sreq, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	sreq = sreq.WithContext(req.Context())
+
+	// Prevent transparent decompression so the length is preserved.
+	sreq.Header.Set("Accept-Encoding", "identity")
+
+	if r := req.Header.Get("Range"); r != "" && rangeApplies(req, etag, modtime) {
+		sreq.Header.Set("Range", r)
+	}
+
+	resp, err := http.DefaultClient.Do(sreq)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, err.Error())
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		h.Set("Content-Range", fmt.Sprintf("bytes */%d", desc.Size))
+		return c.NoContent(http.StatusRequestedRangeNotSatisfiable)
+
+	default:
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		logger.Warn("storage error",
+			zap.String("storage.status", resp.Status),
+			zap.String("storage.body", string(b)),
+		)
+
+		return echo.NewHTTPError(http.StatusBadGateway, "storage error")
+	}
+
+	contentType := echo.MIMEOctetStream
+	if desc.Mediatype != "" {
+		contentType = desc.Mediatype
+	}
+
+	// The parts of a multi-range response are delimited by the boundary
+	// of the storage response.
+	if resp.StatusCode == http.StatusPartialContent {
+		if ct := resp.Header.Get(echo.HeaderContentType); strings.HasPrefix(ct, "multipart/") {
+			contentType = ct
+		}
+	}
+
+	if cr := resp.Header.Get("Content-Range"); cr != "" {
+		h.Set("Content-Range", cr)
+	}
+
+	if resp.ContentLength >= 0 {
+		h.Set(echo.HeaderContentLength, strconv.FormatInt(resp.ContentLength, 10))
+	} else if resp.StatusCode == http.StatusOK {
+		h.Set(echo.HeaderContentLength, strconv.FormatInt(desc.Size, 10))
+	}
+
+	h.Set(echo.HeaderContentDisposition, contentDisposition(name))
+
+	return c.Stream(resp.StatusCode, contentType, resp.Body)
+}
+
+// serveObject reads the file from storage directly, for storage that cannot
+// sign URLs. Range and conditional requests are supported if the object
+// reader can seek.
+func serveObject(c echo.Context, logger *zap.Logger, obj storage.Object, desc *data.DescribeReply, name string) error {
+	req := c.Request()
+	h := c.Response().Header()
+
+	var modtime time.Time
+	if desc.PutTime > 0 {
+		modtime = time.Unix(desc.PutTime, 0).UTC()
+	}
+
+	if etag := fileETag(desc.Hash); etag != "" {
+		h.Set("ETag", etag)
+	}
+
+	rc, err := obj.Reader(req.Context())
+	if err != nil {
+		logger.Warn("storage error",
+			zap.String("storage.error", err.Error()),
+		)
+
+		return echo.NewHTTPError(http.StatusBadGateway, "storage error")
+	}
+	defer rc.Close()
+
+	contentType := echo.MIMEOctetStream
+	if desc.Mediatype != "" {
+		contentType = desc.Mediatype
+	}
+
+	h.Set(echo.HeaderContentType, contentType)
+	h.Set(echo.HeaderContentDisposition, contentDisposition(name))
+
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		http.ServeContent(c.Response(), req, "", modtime, rs)
+		return nil
+	}
+
+	if notModified(req, h.Get("ETag"), modtime) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	if !modtime.IsZero() {
+		h.Set(echo.HeaderLastModified, modtime.Format(http.TimeFormat))
+	}
+
+	h.Set(echo.HeaderContentLength, strconv.FormatInt(desc.Size, 10))
+
+	return c.Stream(http.StatusOK, contentType, rc)
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/labstack/echo"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/storage"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFileETag(t *testing.T) {
+	for _, x := range []struct {
+		hash string
+		etag string
+	}{
+		{"", ""},
+		{"sha256:abc", `"abc"`},
+		{"abc", `"abc"`},
+	} {
+		if etag := fileETag(x.hash); etag != x.etag {
+			t.Errorf("%q: expected %s, got %s", x.hash, x.etag, etag)
+		}
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	modtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, x := range []struct {
+		name    string
+		headers map[string]string
+		etag    string
+		modtime time.Time
+		ok      bool
+	}{
+		{"no conditions", nil, `"a"`, modtime, false},
+		{"matching etag", map[string]string{"If-None-Match": `"a"`}, `"a"`, modtime, true},
+		{"etag in list", map[string]string{"If-None-Match": `"b", "a"`}, `"a"`, modtime, true},
+		{"weak etag", map[string]string{"If-None-Match": `W/"a"`}, `"a"`, modtime, true},
+		{"any etag", map[string]string{"If-None-Match": "*"}, `"a"`, modtime, true},
+		{"other etag", map[string]string{"If-None-Match": `"b"`}, `"a"`, modtime, false},
+		{"no etag", map[string]string{"If-None-Match": `"a"`}, "", modtime, false},
+		{"not modified since", map[string]string{"If-Modified-Since": modtime.Format(http.TimeFormat)}, `"a"`, modtime, true},
+		{"modified since", map[string]string{"If-Modified-Since": modtime.Add(-time.Second).Format(http.TimeFormat)}, `"a"`, modtime, false},
+		{"invalid date", map[string]string{"If-Modified-Since": "yesterday"}, `"a"`, modtime, false},
+		{"no modtime", map[string]string{"If-Modified-Since": modtime.Format(http.TimeFormat)}, `"a"`, time.Time{}, false},
+		{"etag takes precedence", map[string]string{
+			"If-None-Match":     `"b"`,
+			"If-Modified-Since": modtime.Format(http.TimeFormat),
+		}, `"a"`, modtime, false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range x.headers {
+			req.Header.Set(k, v)
+		}
+
+		if ok := notModified(req, x.etag, x.modtime); ok != x.ok {
+			t.Errorf("%s: expected %t, got %t", x.name, x.ok, ok)
+		}
+	}
+}
+
+func TestRangeApplies(t *testing.T) {
+	modtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, x := range []struct {
+		name    string
+		ifRange string
+		etag    string
+		ok      bool
+	}{
+		{"no if-range", "", `"a"`, true},
+		{"matching etag", `"a"`, `"a"`, true},
+		{"other etag", `"b"`, `"a"`, false},
+		{"no etag", `"a"`, "", false},
+		{"matching date", modtime.Format(http.TimeFormat), `"a"`, true},
+		{"other date", modtime.Add(time.Second).Format(http.TimeFormat), `"a"`, false},
+		{"invalid date", "yesterday", `"a"`, false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if x.ifRange != "" {
+			req.Header.Set("If-Range", x.ifRange)
+		}
+
+		if ok := rangeApplies(req, x.etag, modtime); ok != x.ok {
+			t.Errorf("%s: expected %t, got %t", x.name, x.ok, ok)
+		}
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	for _, x := range []struct {
+		name     string
+		filename string
+	}{
+		{"", ""},
+		{"data.csv", "data.csv"},
+		{"my data.csv", "my data.csv"},
+		{`quoted "name".csv`, `quoted "name".csv`},
+		{"données.csv", "données.csv"},
+	} {
+		v := contentDisposition(x.name)
+
+		typ, params, err := mime.ParseMediaType(v)
+		if err != nil {
+			t.Errorf("%q: %s", x.name, err)
+			continue
+		}
+
+		if typ != "attachment" {
+			t.Errorf("%q: expected attachment, got %s", x.name, typ)
+		}
+
+		if params["filename"] != x.filename {
+			t.Errorf("%q: expected filename %q, got %q", x.name, x.filename, params["filename"])
+		}
+	}
+}
+
+func TestServeFile(t *testing.T) {
+	content := "0123456789"
+	modtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// Storage serving ranges of the content.
+	stg := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "secret provider details", http.StatusForbidden)
+			return
+		}
+
+		http.ServeContent(w, r, "", modtime, strings.NewReader(content))
+	}))
+	defer stg.Close()
+
+	desc := &data.DescribeReply{
+		Hash:      "sha256:abc",
+		Size:      int64(len(content)),
+		Mediatype: "text/plain",
+		PutTime:   modtime.Unix(),
+	}
+
+	serve := func(path string, headers map[string]string) (*httptest.ResponseRecorder, error) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		return rec, serveFile(c, zap.NewNop(), stg.URL+path, desc, "file.txt")
+	}
+
+	t.Run("full", func(t *testing.T) {
+		rec, err := serve("/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.Code != http.StatusOK || rec.Body.String() != content {
+			t.Errorf("expected full content, got %d %q", rec.Code, rec.Body.String())
+		}
+		if ct := rec.Header().Get(echo.HeaderContentType); ct != "text/plain" {
+			t.Errorf("expected the mediatype, got %s", ct)
+		}
+		if etag := rec.Header().Get("ETag"); etag != `"abc"` {
+			t.Errorf("expected etag, got %s", etag)
+		}
+	})
+
+	t.Run("single range", func(t *testing.T) {
+		rec, err := serve("/", map[string]string{"Range": "bytes=2-4"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.Code != http.StatusPartialContent || rec.Body.String() != "234" {
+			t.Errorf("expected range, got %d %q", rec.Code, rec.Body.String())
+		}
+		if ct := rec.Header().Get(echo.HeaderContentType); ct != "text/plain" {
+			t.Errorf("expected the mediatype, got %s", ct)
+		}
+		if cr := rec.Header().Get("Content-Range"); cr != "bytes 2-4/10" {
+			t.Errorf("expected content range, got %s", cr)
+		}
+	})
+
+	t.Run("multiple ranges", func(t *testing.T) {
+		rec, err := serve("/", map[string]string{"Range": "bytes=0-1,5-6"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.Code != http.StatusPartialContent {
+			t.Fatalf("expected %d, got %d", http.StatusPartialContent, rec.Code)
+		}
+
+		typ, params, err := mime.ParseMediaType(rec.Header().Get(echo.HeaderContentType))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != "multipart/byteranges" {
+			t.Fatalf("expected multipart/byteranges, got %s", typ)
+		}
+
+		mr := multipart.NewReader(bytes.NewReader(rec.Body.Bytes()), params["boundary"])
+
+		var parts []string
+		for {
+			p, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			b, _ := ioutil.ReadAll(p)
+			parts = append(parts, string(b))
+		}
+
+		if strings.Join(parts, ",") != "01,56" {
+			t.Errorf("expected the two ranges, got %v", parts)
+		}
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		rec, err := serve("/", map[string]string{"If-None-Match": `"abc"`})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("expected %d, got %d", http.StatusNotModified, rec.Code)
+		}
+	})
+
+	t.Run("stale if-range", func(t *testing.T) {
+		rec, err := serve("/", map[string]string{"Range": "bytes=2-4", "If-Range": `"old"`})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.Code != http.StatusOK || rec.Body.String() != content {
+			t.Errorf("expected full content, got %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		_, err := serve("/fail", nil)
+
+		he, ok := err.(*echo.HTTPError)
+		if !ok || he.Code != http.StatusBadGateway {
+			t.Fatalf("expected bad gateway, got %v", err)
+		}
+
+		if strings.Contains(he.Error(), "secret") {
+			t.Errorf("expected storage body not to be passed on, got %s", he.Error())
+		}
+	})
+}
+
+// fakeDownloadData signs URLs to the storage server unless unsigned is set,
+// which behaves like local storage.
+type fakeDownloadData struct {
+	data.ServiceClient
+
+	url      string
+	unsigned bool
+	desc     *data.DescribeReply
+}
+
+func (f *fakeDownloadData) Describe(ctx context.Context, req *data.DescribeRequest, opts ...transport.RequestOption) (*data.DescribeReply, error) {
+	return f.desc, nil
+}
+
+func (f *fakeDownloadData) Get(ctx context.Context, req *data.GetRequest, opts ...transport.RequestOption) (*data.GetReply, error) {
+	if f.unsigned {
+		return nil, status.Error(codes.Unimplemented, "signed urls are not supported")
+	}
+
+	q := url.Values{}
+	if req.ContentDisposition != "" {
+		q.Set("response-content-disposition", req.ContentDisposition)
+	}
+
+	return &data.GetReply{
+		SignedUrl: f.url + "/" + req.Id + "?" + q.Encode(),
+	}, nil
+}
+
+func TestFileDownload(t *testing.T) {
+	ctx := context.Background()
+	content := "0123456789"
+
+	stg := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+	}))
+	defer stg.Close()
+
+	dir, err := ioutil.TempDir("", "download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	local, err := storage.New(ctx, storage.Config{
+		Base: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := local.Bucket("b1").Object("f1").Writer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, content); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dataSvc := &fakeDownloadData{
+		url: stg.URL,
+		desc: &data.DescribeReply{
+			Id:        "f1",
+			Bucket:    "b1",
+			Key:       "f1",
+			Hash:      "sha256:abc",
+			Size:      int64(len(content)),
+			Mediatype: "text/plain",
+		},
+	}
+
+	d := &fileDownload{
+		logger:  zap.NewNop(),
+		dataSvc: dataSvc,
+		storage: local,
+	}
+
+	serve := func(query string, headers map[string]string) (*httptest.ResponseRecorder, error) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("project", "file")
+		c.SetParamValues("p1", "f1")
+
+		return rec, d.serve(c, "report 1.txt")
+	}
+
+	expectProxied := func(t *testing.T, rec *httptest.ResponseRecorder, err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.Code != http.StatusOK || rec.Body.String() != content {
+			t.Errorf("expected the content, got %d %q", rec.Code, rec.Body.String())
+		}
+		if cd := rec.Header().Get(echo.HeaderContentDisposition); cd != contentDisposition("report 1.txt") {
+			t.Errorf("expected the content disposition, got %s", cd)
+		}
+	}
+
+	t.Run("proxy", func(t *testing.T) {
+		rec, err := serve("", nil)
+		expectProxied(t, rec, err)
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		rec, err := serve("redirect=true", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.Code != http.StatusFound {
+			t.Fatalf("expected redirect, got %d", rec.Code)
+		}
+
+		loc, err := url.Parse(rec.Header().Get(echo.HeaderLocation))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(loc.String(), stg.URL+"/f1") {
+			t.Errorf("expected the signed url, got %s", loc)
+		}
+		if cd := loc.Query().Get("response-content-disposition"); cd != contentDisposition("report 1.txt") {
+			t.Errorf("expected the content disposition to be signed, got %q", cd)
+		}
+	})
+
+	t.Run("redirect default", func(t *testing.T) {
+		d.redirect = true
+		defer func() { d.redirect = false }()
+
+		rec, err := serve("", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != http.StatusFound {
+			t.Errorf("expected redirect, got %d", rec.Code)
+		}
+
+		rec, err = serve("redirect=false", nil)
+		expectProxied(t, rec, err)
+	})
+
+	t.Run("invalid redirect", func(t *testing.T) {
+		_, err := serve("redirect=maybe", nil)
+		if he, ok := err.(*echo.HTTPError); !ok || he.Code != http.StatusBadRequest {
+			t.Errorf("expected bad request, got %v", err)
+		}
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		dataSvc.unsigned = true
+		defer func() { dataSvc.unsigned = false }()
+
+		// The redirect falls back to reading from storage.
+		rec, err := serve("redirect=true", nil)
+		expectProxied(t, rec, err)
+
+		if etag := rec.Header().Get("ETag"); etag != `"abc"` {
+			t.Errorf("expected etag, got %s", etag)
+		}
+
+		rec, err = serve("", map[string]string{"Range": "bytes=2-4"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != http.StatusPartialContent || rec.Body.String() != "234" {
+			t.Errorf("expected range, got %d %q", rec.Code, rec.Body.String())
+		}
+
+		rec, err = serve("", map[string]string{"If-None-Match": `"abc"`})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("expected not modified, got %d", rec.Code)
+		}
+	})
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -15,20 +18,72 @@ type Enricher struct {
 	projectSvc project.ServiceClient
 }
 
-func (e *Enricher) GetUserName(ctx context.Context, id string) (string, error) {
-	if id == "" {
-		return "", nil
+// Maximum number of ids per GetUsers request.
+const authorBatchSize = 1000
+
+// author is the public information of a commit or event author. The email
+// is only exposed as a hash, e.g. for Gravatar images.
+type author struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	EmailHash string `json:"email_hash,omitempty"`
+	Orcid     string `json:"orcid,omitempty"`
+}
+
+func newAuthor(u *account.GetUserResponse) *author {
+	a := &author{
+		ID:   u.Id,
+		Name: u.Name,
 	}
 
-	rep, err := e.accountSvc.GetUser(ctx, &account.GetUserRequest{
-		Id: id,
-	})
+	if u.Email != "" {
+		h := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(u.Email))))
+		a.EmailHash = hex.EncodeToString(h[:])
+	}
 
-	if err != nil {
-		return "", err
+	if u.OrcidVerified {
+		a.Orcid = u.Orcid
+	}
+
+	return a
+}
+
+// GetAuthors resolves the authors of the ids in batches. Users that no
+// longer exist are returned with the id only.
+func (e *Enricher) GetAuthors(ctx context.Context, ids []string) (map[string]*author, error) {
+	authors := make(map[string]*author, len(ids))
+
+	var batch []string
+	for _, id := range ids {
+		if _, ok := authors[id]; ok || id == "" {
+			continue
+		}
+
+		authors[id] = &author{ID: id}
+		batch = append(batch, id)
+	}
+
+	for len(batch) > 0 {
+		n := len(batch)
+		if n > authorBatchSize {
+			n = authorBatchSize
+		}
+
+		rep, err := e.accountSvc.GetUsers(ctx, &account.GetUsersRequest{
+			Ids: batch[:n],
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, u := range rep.Users {
+			authors[u.Id] = newAuthor(u)
+		}
+
+		batch = batch[n:]
 	}
 
-	return rep.Name, nil
+	return authors, nil
 }
 
 func (e *Enricher) CanViewProject(ctx context.Context, id, account string) (bool, error) {
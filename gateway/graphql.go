@@ -0,0 +1,756 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/labstack/echo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rdm-academy/api/account"
+	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/nodes"
+	"github.com/rdm-academy/api/project"
+)
+
+// Maximum nesting of the fields of a query.
+const graphqlMaxDepth = 10
+
+const graphqlSchema = `
+schema {
+	query: Query
+}
+
+# Timestamps in seconds since the epoch and sizes in bytes.
+scalar Int64
+
+type Query {
+	# Account of the requesting user.
+	viewer: Account!
+
+	# Projects of the requesting user.
+	projects: [Project!]!
+
+	# Project by id or null if it does not exist or cannot be viewed.
+	project(id: ID!): Project
+}
+
+type Account {
+	id: ID!
+	name: String!
+	email: String!
+	affiliation: String!
+	orcid: String!
+	orcidVerified: Boolean!
+	avatarUrl: String!
+	pendingEmail: String!
+	created: Int64!
+	modified: Int64!
+}
+
+# Public information of a user.
+type Author {
+	id: ID!
+	name: String!
+	emailHash: String
+	orcid: String
+}
+
+type Project {
+	id: ID!
+	name: String!
+	description: String!
+	owner: Author
+	created: Int64!
+	modified: Int64!
+	workflow: Workflow
+	node(id: ID!): Node
+	log: [Commit!]!
+	pending: [Event!]!
+}
+
+type Workflow {
+	source: String!
+	modified: Int64!
+	nodes: [Node!]!
+}
+
+enum NodeType {
+	UNKNOWN
+	DATA
+	COMPUTE
+	MANUAL
+	FINDING
+}
+
+type Node {
+	id: ID!
+	type: NodeType!
+	title: String!
+	notes: String!
+	input: [ID!]!
+	output: [ID!]!
+	files: [File!]!
+}
+
+enum FileState {
+	UNKNOWN
+	CREATED
+	INPROGRESS
+	ERROR
+	DONE
+}
+
+# A file of a node. The fields other than the id and name are null if the
+# data record does not exist.
+type File {
+	id: ID!
+	name: String!
+	state: FileState!
+	error: String
+	size: Int64
+	hash: String
+	mediatype: String
+	created: Int64
+	stored: Int64
+}
+
+type Commit {
+	id: ID!
+	msg: String!
+	author: Author
+	time: Int64!
+	parent: ID
+	events: [Event!]!
+}
+
+type Event {
+	id: ID!
+	type: String!
+	author: Author
+	time: Int64!
+
+	# Data of the event as JSON.
+	data: String!
+}
+`
+
+// int64Scalar is a 64-bit integer. GraphQL integers are limited to 32 bits.
+type int64Scalar int64
+
+func (int64Scalar) ImplementsGraphQLType(name string) bool {
+	return name == "Int64"
+}
+
+func (x *int64Scalar) UnmarshalGraphQL(input interface{}) error {
+	switch v := input.(type) {
+	case int32:
+		*x = int64Scalar(v)
+	case int64:
+		*x = int64Scalar(v)
+	case float64:
+		*x = int64Scalar(v)
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		*x = int64Scalar(n)
+	default:
+		return fmt.Errorf("invalid Int64 %v", input)
+	}
+
+	return nil
+}
+
+func optionalInt64(x int64) *int64Scalar {
+	if x == 0 {
+		return nil
+	}
+	v := int64Scalar(x)
+	return &v
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// isHidden returns true if the error denies the existence of the object to
+// the user, as the REST routes do with a 404.
+func isHidden(err error) bool {
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.NotFound, codes.PermissionDenied, codes.Unauthenticated:
+			return true
+		}
+	}
+	return false
+}
+
+// graphqlHandler serves the GraphQL endpoint.
+type graphqlHandler struct {
+	schema *graphql.Schema
+
+	accountSvc   account.ServiceClient
+	projectSvc   project.ServiceClient
+	commitlogSvc commitlog.ServiceClient
+	dataSvc      data.ServiceClient
+	nodeSvc      nodes.ServiceClient
+	enricher     *Enricher
+}
+
+func newGraphQLHandler(h *graphqlHandler) *graphqlHandler {
+	h.schema = graphql.MustParseSchema(
+		graphqlSchema,
+		&graphqlResolver{},
+		graphql.MaxDepth(graphqlMaxDepth),
+	)
+	return h
+}
+
+type graphqlParams struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (h *graphqlHandler) Serve(c echo.Context) error {
+	var params graphqlParams
+	if err := c.Bind(&params); err != nil {
+		return err
+	}
+
+	if params.Query == "" {
+		return c.String(http.StatusBadRequest, "query required")
+	}
+
+	ctx := c.Request().Context()
+	ctx = context.WithValue(ctx, graphqlRequestKey{}, h.newRequest(ctx, c.Get("user.id").(string)))
+
+	rep := h.schema.Exec(ctx, params.Query, params.OperationName, params.Variables)
+
+	return c.JSON(http.StatusOK, rep)
+}
+
+type graphqlRequestKey struct{}
+
+// graphqlRequest is the state of a single query. The loaders cache the
+// lookups for the duration of the query only, so every query sees the
+// current state and permissions.
+type graphqlRequest struct {
+	*graphqlHandler
+
+	user string
+
+	projects *loader
+	nodes    *loader
+	files    *loader
+	authors  *loader
+}
+
+func (h *graphqlHandler) newRequest(ctx context.Context, user string) *graphqlRequest {
+	r := &graphqlRequest{
+		graphqlHandler: h,
+		user:           user,
+	}
+
+	// The project service checks the user can view the project.
+	r.projects = newLoader(ctx, fetchEach(func(ctx context.Context, id string) (interface{}, error) {
+		rep, err := h.projectSvc.GetProject(ctx, &project.GetProjectRequest{
+			Id:      id,
+			Account: user,
+		})
+		if err != nil {
+			if isHidden(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return rep.Project, nil
+	}))
+
+	// Keys are the project and node id separated by a slash.
+	r.nodes = newLoader(ctx, fetchEach(func(ctx context.Context, key string) (interface{}, error) {
+		p, n := splitNodeKey(key)
+
+		rep, err := h.nodeSvc.Get(ctx, &nodes.GetRequest{
+			Project: p,
+			Id:      n,
+		})
+		if err != nil {
+			if isHidden(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return rep, nil
+	}))
+
+	r.files = newLoader(ctx, fetchEach(func(ctx context.Context, id string) (interface{}, error) {
+		rep, err := h.dataSvc.Describe(ctx, &data.DescribeRequest{
+			Id: id,
+		})
+		if err != nil {
+			if isHidden(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return rep, nil
+	}))
+
+	r.authors = newLoader(ctx, func(ctx context.Context, ids []string) (map[string]interface{}, error) {
+		authors, err := h.enricher.GetAuthors(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]interface{}, len(authors))
+		for id, a := range authors {
+			values[id] = a
+		}
+		return values, nil
+	})
+
+	return r
+}
+
+func graphqlRequestFrom(ctx context.Context) *graphqlRequest {
+	return ctx.Value(graphqlRequestKey{}).(*graphqlRequest)
+}
+
+func nodeKey(project, node string) string {
+	return project + "/" + node
+}
+
+func splitNodeKey(key string) (string, string) {
+	toks := strings.SplitN(key, "/", 2)
+	if len(toks) == 1 {
+		return toks[0], ""
+	}
+	return toks[0], toks[1]
+}
+
+func (r *graphqlRequest) project(id string) (*project.Project, error) {
+	v, err := r.projects.load(id)
+	if v == nil || err != nil {
+		return nil, err
+	}
+	return v.(*project.Project), nil
+}
+
+func (r *graphqlRequest) node(project, id string) (*nodes.GetReply, error) {
+	v, err := r.nodes.load(nodeKey(project, id))
+	if v == nil || err != nil {
+		return nil, err
+	}
+	return v.(*nodes.GetReply), nil
+}
+
+func (r *graphqlRequest) file(id string) (*data.DescribeReply, error) {
+	v, err := r.files.load(id)
+	if v == nil || err != nil {
+		return nil, err
+	}
+	return v.(*data.DescribeReply), nil
+}
+
+func (r *graphqlRequest) author(id string) (*authorResolver, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	v, err := r.authors.load(id)
+	if v == nil || err != nil {
+		return nil, err
+	}
+	return &authorResolver{v.(*author)}, nil
+}
+
+// graphqlResolver resolves the query fields. The state of the query is
+// taken from the context.
+type graphqlResolver struct{}
+
+func (*graphqlResolver) Viewer(ctx context.Context) (*accountResolver, error) {
+	r := graphqlRequestFrom(ctx)
+
+	rep, err := r.accountSvc.GetUser(ctx, &account.GetUserRequest{
+		Id: r.user,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &accountResolver{rep}, nil
+}
+
+func (*graphqlResolver) Projects(ctx context.Context) ([]*projectResolver, error) {
+	r := graphqlRequestFrom(ctx)
+
+	rep, err := r.projectSvc.ListProjects(ctx, &project.ListProjectsRequest{
+		Account: r.user,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]*projectResolver, len(rep.Projects))
+	for i, p := range rep.Projects {
+		r.projects.prime(p.Id, p)
+		projects[i] = &projectResolver{p}
+	}
+
+	return projects, nil
+}
+
+func (*graphqlResolver) Project(ctx context.Context, args struct{ ID graphql.ID }) (*projectResolver, error) {
+	p, err := graphqlRequestFrom(ctx).project(string(args.ID))
+	if p == nil || err != nil {
+		return nil, err
+	}
+
+	return &projectResolver{p}, nil
+}
+
+type accountResolver struct {
+	u *account.GetUserResponse
+}
+
+func (r *accountResolver) ID() graphql.ID        { return graphql.ID(r.u.Id) }
+func (r *accountResolver) Name() string          { return r.u.Name }
+func (r *accountResolver) Email() string         { return r.u.Email }
+func (r *accountResolver) Affiliation() string   { return r.u.Affiliation }
+func (r *accountResolver) Orcid() string         { return r.u.Orcid }
+func (r *accountResolver) OrcidVerified() bool   { return r.u.OrcidVerified }
+func (r *accountResolver) AvatarURL() string     { return r.u.AvatarUrl }
+func (r *accountResolver) PendingEmail() string  { return r.u.PendingEmail }
+func (r *accountResolver) Created() int64Scalar  { return int64Scalar(r.u.Created) }
+func (r *accountResolver) Modified() int64Scalar { return int64Scalar(r.u.Modified) }
+
+type authorResolver struct {
+	a *author
+}
+
+func (r *authorResolver) ID() graphql.ID     { return graphql.ID(r.a.ID) }
+func (r *authorResolver) Name() string       { return r.a.Name }
+func (r *authorResolver) EmailHash() *string { return optionalString(r.a.EmailHash) }
+func (r *authorResolver) Orcid() *string     { return optionalString(r.a.Orcid) }
+
+type projectResolver struct {
+	p *project.Project
+}
+
+func (r *projectResolver) ID() graphql.ID        { return graphql.ID(r.p.Id) }
+func (r *projectResolver) Name() string          { return r.p.Name }
+func (r *projectResolver) Description() string   { return r.p.Description }
+func (r *projectResolver) Created() int64Scalar  { return int64Scalar(r.p.Created) }
+func (r *projectResolver) Modified() int64Scalar { return int64Scalar(r.p.Modified) }
+
+func (r *projectResolver) Owner(ctx context.Context) (*authorResolver, error) {
+	return graphqlRequestFrom(ctx).author(r.p.Account)
+}
+
+func (r *projectResolver) Workflow() *workflowResolver {
+	if r.p.Workflow == nil {
+		return nil
+	}
+	return &workflowResolver{r.p}
+}
+
+func (r *projectResolver) Node(ctx context.Context, args struct{ ID graphql.ID }) (*nodeResolver, error) {
+	id := string(args.ID)
+
+	var wf *project.Node
+	if r.p.Workflow != nil {
+		wf = r.p.Workflow.Nodes[id]
+	}
+
+	// Nodes that are not in the workflow may still have data.
+	if wf == nil {
+		n, err := graphqlRequestFrom(ctx).node(r.p.Id, id)
+		if n == nil || err != nil {
+			return nil, err
+		}
+	}
+
+	return &nodeResolver{
+		project: r.p.Id,
+		id:      id,
+		wf:      wf,
+	}, nil
+}
+
+func (r *projectResolver) Log(ctx context.Context) ([]*commitResolver, error) {
+	req := graphqlRequestFrom(ctx)
+
+	hreq := commitlog.HistoryRequest{
+		Project: r.p.Id,
+	}
+
+	var commits []*commitResolver
+
+	for {
+		rep, err := req.commitlogSvc.History(ctx, &hreq)
+		if err != nil {
+			return nil, err
+		}
+
+		if rep.Commit == nil {
+			break
+		}
+
+		commits = append(commits, &commitResolver{rep.Commit})
+
+		// Any more?
+		if rep.Next == "" {
+			break
+		}
+
+		hreq.Commit = rep.Next
+	}
+
+	return commits, nil
+}
+
+func (r *projectResolver) Pending(ctx context.Context) ([]*eventResolver, error) {
+	rep, err := graphqlRequestFrom(ctx).commitlogSvc.Pending(ctx, &commitlog.PendingRequest{
+		Project: r.p.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newEventResolvers(rep.Events), nil
+}
+
+type workflowResolver struct {
+	p *project.Project
+}
+
+func (r *workflowResolver) Source() string        { return r.p.Workflow.Source }
+func (r *workflowResolver) Modified() int64Scalar { return int64Scalar(r.p.Workflow.Modified) }
+
+// Nodes returns the nodes of the workflow ordered by id.
+func (r *workflowResolver) Nodes() []*nodeResolver {
+	ids := make([]string, 0, len(r.p.Workflow.Nodes))
+	for id := range r.p.Workflow.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	nodes := make([]*nodeResolver, len(ids))
+	for i, id := range ids {
+		nodes[i] = &nodeResolver{
+			project: r.p.Id,
+			id:      id,
+			wf:      r.p.Workflow.Nodes[id],
+		}
+	}
+
+	return nodes
+}
+
+// nodeResolver combines the node of the workflow, if any, with the data of
+// the node service.
+type nodeResolver struct {
+	project string
+	id      string
+	wf      *project.Node
+}
+
+func (r *nodeResolver) data(ctx context.Context) (*nodes.GetReply, error) {
+	return graphqlRequestFrom(ctx).node(r.project, r.id)
+}
+
+func (r *nodeResolver) ID() graphql.ID { return graphql.ID(r.id) }
+
+func (r *nodeResolver) Type(ctx context.Context) (string, error) {
+	n, err := r.data(ctx)
+	if n == nil || err != nil {
+		return nodes.NodeType_UNKNOWN.String(), err
+	}
+	return n.Type.String(), nil
+}
+
+// Title returns the title set on the node or else the one of the workflow.
+func (r *nodeResolver) Title(ctx context.Context) (string, error) {
+	n, err := r.data(ctx)
+	if err != nil {
+		return "", err
+	}
+	if n != nil && n.Title != "" {
+		return n.Title, nil
+	}
+	if r.wf != nil {
+		return r.wf.Title, nil
+	}
+	return "", nil
+}
+
+func (r *nodeResolver) Notes(ctx context.Context) (string, error) {
+	n, err := r.data(ctx)
+	if n == nil || err != nil {
+		return "", err
+	}
+	return n.Notes, nil
+}
+
+func (r *nodeResolver) Input() []graphql.ID {
+	if r.wf == nil {
+		return []graphql.ID{}
+	}
+	return toGraphQLIDs(r.wf.Input)
+}
+
+func (r *nodeResolver) Output() []graphql.ID {
+	if r.wf == nil {
+		return []graphql.ID{}
+	}
+	return toGraphQLIDs(r.wf.Output)
+}
+
+func (r *nodeResolver) Files(ctx context.Context) ([]*fileResolver, error) {
+	n, err := r.data(ctx)
+	if n == nil || err != nil {
+		return []*fileResolver{}, err
+	}
+
+	files := make([]*fileResolver, len(n.Files))
+	for i, f := range n.Files {
+		files[i] = &fileResolver{f}
+	}
+
+	return files, nil
+}
+
+func toGraphQLIDs(ids []string) []graphql.ID {
+	out := make([]graphql.ID, len(ids))
+	for i, id := range ids {
+		out[i] = graphql.ID(id)
+	}
+	return out
+}
+
+// fileResolver resolves a file of a node with the data record describing it.
+type fileResolver struct {
+	f *nodes.File
+}
+
+func (r *fileResolver) describe(ctx context.Context) (*data.DescribeReply, error) {
+	return graphqlRequestFrom(ctx).file(r.f.Id)
+}
+
+func (r *fileResolver) ID() graphql.ID { return graphql.ID(r.f.Id) }
+func (r *fileResolver) Name() string   { return r.f.Name }
+
+func (r *fileResolver) State(ctx context.Context) (string, error) {
+	d, err := r.describe(ctx)
+	if d == nil || err != nil {
+		return data.State_UNKNOWN.String(), err
+	}
+	return d.State.String(), nil
+}
+
+func (r *fileResolver) Error(ctx context.Context) (*string, error) {
+	d, err := r.describe(ctx)
+	if d == nil || err != nil {
+		return nil, err
+	}
+	return optionalString(d.Error), nil
+}
+
+func (r *fileResolver) Size(ctx context.Context) (*int64Scalar, error) {
+	d, err := r.describe(ctx)
+	if d == nil || err != nil {
+		return nil, err
+	}
+	v := int64Scalar(d.Size)
+	return &v, nil
+}
+
+func (r *fileResolver) Hash(ctx context.Context) (*string, error) {
+	d, err := r.describe(ctx)
+	if d == nil || err != nil {
+		return nil, err
+	}
+	return optionalString(d.Hash), nil
+}
+
+func (r *fileResolver) Mediatype(ctx context.Context) (*string, error) {
+	d, err := r.describe(ctx)
+	if d == nil || err != nil {
+		return nil, err
+	}
+	return optionalString(d.Mediatype), nil
+}
+
+func (r *fileResolver) Created(ctx context.Context) (*int64Scalar, error) {
+	d, err := r.describe(ctx)
+	if d == nil || err != nil {
+		return nil, err
+	}
+	return optionalInt64(d.CreateTime), nil
+}
+
+// Stored is the time the content was stored.
+func (r *fileResolver) Stored(ctx context.Context) (*int64Scalar, error) {
+	d, err := r.describe(ctx)
+	if d == nil || err != nil {
+		return nil, err
+	}
+	return optionalInt64(d.PutTime), nil
+}
+
+type commitResolver struct {
+	c *commitlog.Commit
+}
+
+func (r *commitResolver) ID() graphql.ID    { return graphql.ID(r.c.Id) }
+func (r *commitResolver) Msg() string       { return r.c.Msg }
+func (r *commitResolver) Time() int64Scalar { return int64Scalar(r.c.Time) }
+func (r *commitResolver) Events() []*eventResolver {
+	return newEventResolvers(r.c.Events)
+}
+
+func (r *commitResolver) Author(ctx context.Context) (*authorResolver, error) {
+	return graphqlRequestFrom(ctx).author(r.c.Author)
+}
+
+func (r *commitResolver) Parent() *graphql.ID {
+	if r.c.Parent == "" {
+		return nil
+	}
+	id := graphql.ID(r.c.Parent)
+	return &id
+}
+
+type eventResolver struct {
+	e *commitlog.Event
+}
+
+func newEventResolvers(events []*commitlog.Event) []*eventResolver {
+	out := make([]*eventResolver, len(events))
+	for i, e := range events {
+		out[i] = &eventResolver{e}
+	}
+	return out
+}
+
+func (r *eventResolver) ID() graphql.ID    { return graphql.ID(r.e.Id) }
+func (r *eventResolver) Type() string      { return r.e.Type }
+func (r *eventResolver) Time() int64Scalar { return int64Scalar(r.e.Time) }
+func (r *eventResolver) Data() string      { return string(r.e.Data) }
+
+func (r *eventResolver) Author(ctx context.Context) (*authorResolver, error) {
+	return graphqlRequestFrom(ctx).author(r.e.Author)
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rdm-academy/api/account"
+	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/nodes"
+	"github.com/rdm-academy/api/project"
+)
+
+// fakeProjects has a single project owned by u1.
+type fakeProjects struct {
+	project.ServiceClient
+}
+
+var testProject = &project.Project{
+	Id:      "p1",
+	Account: "u1",
+	Name:    "Test",
+	Workflow: &project.Workflow{
+		Nodes: map[string]*project.Node{
+			"a": {Title: "Raw data", Output: []string{"b"}},
+			"b": {Title: "Analysis", Input: []string{"a"}},
+		},
+	},
+}
+
+func (f *fakeProjects) GetProject(ctx context.Context, req *project.GetProjectRequest, opts ...transport.RequestOption) (*project.GetProjectResponse, error) {
+	if req.Id != testProject.Id {
+		return nil, status.Error(codes.NotFound, "project not found")
+	}
+	if req.Account != testProject.Account {
+		return nil, status.Error(codes.PermissionDenied, "not allowed")
+	}
+
+	return &project.GetProjectResponse{Project: testProject}, nil
+}
+
+func (f *fakeProjects) ListProjects(ctx context.Context, req *project.ListProjectsRequest, opts ...transport.RequestOption) (*project.ListProjectsResponse, error) {
+	rep := &project.ListProjectsResponse{}
+	if req.Account == testProject.Account {
+		rep.Projects = []*project.Project{testProject}
+	}
+	return rep, nil
+}
+
+type fakeCommitlog struct {
+	commitlog.ServiceClient
+}
+
+func (f *fakeCommitlog) History(ctx context.Context, req *commitlog.HistoryRequest, opts ...transport.RequestOption) (*commitlog.HistoryReply, error) {
+	if req.Commit == "" {
+		return &commitlog.HistoryReply{
+			Commit: &commitlog.Commit{Id: "c2", Author: "u1", Parent: "c1", Events: []*commitlog.Event{
+				{Id: "e1", Author: "u1", Type: "node.titleSet", Data: []byte(`{}`)},
+			}},
+			Next: "c1",
+		}, nil
+	}
+
+	return &commitlog.HistoryReply{
+		Commit: &commitlog.Commit{Id: "c1", Author: "u2"},
+	}, nil
+}
+
+type fakeNodes struct {
+	nodes.ServiceClient
+}
+
+func (f *fakeNodes) Get(ctx context.Context, req *nodes.GetRequest, opts ...transport.RequestOption) (*nodes.GetReply, error) {
+	if req.Id != "a" {
+		return nil, status.Error(codes.NotFound, "node not found")
+	}
+
+	return &nodes.GetReply{
+		Id:    "a",
+		Type:  nodes.NodeType_DATA,
+		Files: []*nodes.File{{Id: "f1", Name: "data.csv"}},
+	}, nil
+}
+
+type fakeData struct {
+	data.ServiceClient
+}
+
+func (f *fakeData) Describe(ctx context.Context, req *data.DescribeRequest, opts ...transport.RequestOption) (*data.DescribeReply, error) {
+	return &data.DescribeReply{
+		Id:    req.Id,
+		State: data.State_DONE,
+		Size:  1 << 33,
+	}, nil
+}
+
+func execGraphQL(t *testing.T, h *graphqlHandler, user, query string) map[string]interface{} {
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, graphqlRequestKey{}, h.newRequest(ctx, user))
+
+	rep := h.schema.Exec(ctx, query, "", nil)
+	if len(rep.Errors) > 0 {
+		t.Fatalf("query failed: %v", rep.Errors)
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(rep.Data, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	return out
+}
+
+func newTestGraphQLHandler() (*graphqlHandler, *countingAccounts) {
+	accounts := &countingAccounts{
+		user: &account.GetUserResponse{Id: "u1", Name: "Jane", Email: "jane@example.com"},
+	}
+	projects := &fakeProjects{}
+
+	h := newGraphQLHandler(&graphqlHandler{
+		accountSvc:   accounts,
+		projectSvc:   projects,
+		commitlogSvc: &fakeCommitlog{},
+		dataSvc:      &fakeData{},
+		nodeSvc:      &fakeNodes{},
+		enricher: &Enricher{
+			accountSvc: accounts,
+			projectSvc: projects,
+		},
+	})
+
+	return h, accounts
+}
+
+func TestGraphQLBatchesAuthors(t *testing.T) {
+	h, accounts := newTestGraphQLHandler()
+
+	out := execGraphQL(t, h, "u1", `{
+		projects {
+			id
+			owner { name }
+			log {
+				id
+				author { id name }
+				events { author { name } }
+			}
+		}
+	}`)
+
+	// All authors are resolved with a single GetUsers.
+	if accounts.calls != 1 {
+		t.Errorf("expected 1 account lookup, got %d", accounts.calls)
+	}
+
+	log := out["projects"].([]interface{})[0].(map[string]interface{})["log"].([]interface{})
+	if len(log) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(log))
+	}
+
+	// Authors that no longer exist have the id only.
+	a := log[1].(map[string]interface{})["author"].(map[string]interface{})
+	if a["id"] != "u2" || a["name"] != "" {
+		t.Errorf("unexpected author %v", a)
+	}
+}
+
+func TestGraphQLProjectAccess(t *testing.T) {
+	h, _ := newTestGraphQLHandler()
+
+	query := `{ project(id: "p1") { name } missing: project(id: "p2") { name } }`
+
+	out := execGraphQL(t, h, "u2", query)
+	if out["project"] != nil || out["missing"] != nil {
+		t.Errorf("expected no projects, got %v", out)
+	}
+
+	out = execGraphQL(t, h, "u1", query)
+	if out["project"] == nil {
+		t.Error("expected the project")
+	}
+}
+
+func TestGraphQLNodes(t *testing.T) {
+	h, _ := newTestGraphQLHandler()
+
+	out := execGraphQL(t, h, "u1", `{
+		project(id: "p1") {
+			workflow { nodes { id type title output } }
+			node(id: "a") { files { name state size } }
+			missing: node(id: "c") { id }
+		}
+	}`)
+
+	p := out["project"].(map[string]interface{})
+
+	ns := p["workflow"].(map[string]interface{})["nodes"].([]interface{})
+	if len(ns) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(ns))
+	}
+
+	a := ns[0].(map[string]interface{})
+	if a["id"] != "a" || a["type"] != "DATA" || a["title"] != "Raw data" {
+		t.Errorf("unexpected node %v", a)
+	}
+
+	// Nodes without data have the defaults.
+	b := ns[1].(map[string]interface{})
+	if b["type"] != "UNKNOWN" || b["title"] != "Analysis" {
+		t.Errorf("unexpected node %v", b)
+	}
+
+	f := p["node"].(map[string]interface{})["files"].([]interface{})[0].(map[string]interface{})
+	if f["state"] != "DONE" || f["size"] != float64(1<<33) {
+		t.Errorf("unexpected file %v", f)
+	}
+
+	if p["missing"] != nil {
+		t.Errorf("expected no node, got %v", p["missing"])
+	}
+}
+
+func TestLoader(t *testing.T) {
+	var batches [][]string
+
+	l := newLoader(context.Background(), func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		batches = append(batches, keys)
+
+		values := make(map[string]interface{})
+		for _, k := range keys {
+			if k == "bad" {
+				values[k] = status.Error(codes.Internal, "failed")
+			} else {
+				values[k] = k + "!"
+			}
+		}
+		return values, nil
+	})
+
+	l.prime("c", "primed")
+
+	var wg sync.WaitGroup
+	for _, k := range []string{"a", "b", "a", "c", "bad"} {
+		wg.Add(1)
+		go func(k string) {
+			defer wg.Done()
+
+			v, err := l.load(k)
+			switch k {
+			case "bad":
+				if err == nil {
+					t.Error("expected an error")
+				}
+			case "c":
+				if v != "primed" {
+					t.Errorf("expected the primed value, got %v", v)
+				}
+			default:
+				if v != k+"!" {
+					t.Errorf("unexpected value %v", v)
+				}
+			}
+		}(k)
+	}
+	wg.Wait()
+
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Errorf("expected a single batch of 3 keys, got %v", batches)
+	}
+}
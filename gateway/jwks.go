@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// Minimum time between refreshes triggered by an unknown key id.
+	jwksMinRefresh = time.Minute
+
+	// Time allowed to load the keys of all sources.
+	jwksTimeout = 10 * time.Second
+)
+
+var jwksClient = &http.Client{
+	Timeout: jwksTimeout,
+}
+
+var errUnknownKey = errors.New("unknown signing key")
+
+// jwk is a JSON Web Key as defined in RFC 7517. Only the public key
+// members of the supported key types are read.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey is a parsed key with the algorithm it is restricted to, if any.
+type publicKey struct {
+	alg string
+	key interface{}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// parseJWK returns the public key of the JWK.
+func parseJWK(k *jwk) (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			N: n,
+			E: int(e.Int64()),
+		}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     x,
+			Y:     y,
+		}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// parseJWKS parses the signing keys of a JWKS document by key id. Keys
+// that cannot be parsed or are meant for encryption are skipped.
+func parseJWKS(b []byte) (map[string]*publicKey, error) {
+	var doc struct {
+		Keys []*jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*publicKey, len(doc.Keys))
+
+	for _, k := range doc.Keys {
+		if k.Use == "enc" {
+			continue
+		}
+
+		key, err := parseJWK(k)
+		if err != nil {
+			continue
+		}
+
+		keys[k.Kid] = &publicKey{
+			alg: k.Alg,
+			key: key,
+		}
+	}
+
+	return keys, nil
+}
+
+// jwksSource is a URL or file the keys are loaded from.
+type jwksSource struct {
+	url  string
+	file string
+
+	client *http.Client
+}
+
+func (s *jwksSource) String() string {
+	if s.file != "" {
+		return s.file
+	}
+	return s.url
+}
+
+func (s *jwksSource) load(ctx context.Context) (map[string]*publicKey, error) {
+	if s.file != "" {
+		b, err := ioutil.ReadFile(s.file)
+		if err != nil {
+			return nil, err
+		}
+		return parseJWKS(b)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+
+	client := s.client
+	if client == nil {
+		client = jwksClient
+	}
+
+	rep, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rep.Body.Close()
+
+	if rep.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks: %s returned status %d", s.url, rep.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(rep.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseJWKS(b)
+}
+
+// keySet caches the keys of the sources by key id. The keys are reloaded
+// after the refresh interval, or when a token refers to an unknown key id so
+// rotated keys are picked up. The sources are loaded without holding the
+// lock and concurrent lookups wait for the same load.
+type keySet struct {
+	sources []*jwksSource
+	refresh time.Duration
+
+	mu      sync.Mutex
+	keys    map[string]*publicKey
+	loaded  time.Time
+	tried   time.Time
+	lastErr error
+
+	// Closed when the load in progress, if any, is done.
+	loading chan struct{}
+}
+
+func (s *keySet) load() (map[string]*publicKey, error) {
+	// The load is shared, so it is not bound to the context of a request.
+	ctx, cancel := context.WithTimeout(context.Background(), jwksTimeout)
+	defer cancel()
+
+	keys := make(map[string]*publicKey)
+
+	for _, src := range s.sources {
+		k, err := src.load(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("jwks: %s: %s", src, err)
+		}
+
+		for id, key := range k {
+			keys[id] = key
+		}
+	}
+
+	return keys, nil
+}
+
+// cached returns the cached key for the key id or the error of the last
+// load. The lock must be held.
+func (s *keySet) cached(kid string) (*publicKey, error) {
+	if k, ok := s.keys[kid]; ok {
+		return k, nil
+	}
+
+	if s.lastErr != nil {
+		return nil, s.lastErr
+	}
+
+	return nil, errUnknownKey
+}
+
+// key returns the key for the key id.
+func (s *keySet) key(ctx context.Context, kid string) (*publicKey, error) {
+	s.mu.Lock()
+
+	for s.loading != nil {
+		ch := s.loading
+		s.mu.Unlock()
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		s.mu.Lock()
+	}
+
+	now := time.Now()
+
+	stale := s.keys == nil || now.Sub(s.loaded) > s.refresh
+
+	if !stale {
+		if k, ok := s.keys[kid]; ok {
+			s.mu.Unlock()
+			return k, nil
+		}
+	}
+
+	// Limit reloads for unknown key ids and failing sources.
+	if now.Sub(s.tried) < jwksMinRefresh {
+		defer s.mu.Unlock()
+		return s.cached(kid)
+	}
+
+	s.tried = now
+
+	ch := make(chan struct{})
+	s.loading = ch
+	s.mu.Unlock()
+
+	keys, err := s.load()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Keep using the cached keys if the sources are unavailable.
+	if err == nil {
+		s.keys = keys
+		s.loaded = now
+	}
+
+	s.lastErr = err
+	s.loading = nil
+	close(ch)
+
+	return s.cached(kid)
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+)
+
+// signingMethodEdDSA implements the EdDSA algorithm of RFC 8037 with
+// Ed25519 keys, which jwt-go does not provide.
+type signingMethodEdDSA struct{}
+
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+func (m *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	k, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(k, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	k, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	return jwt.EncodeSegment(ed25519.Sign(k, []byte(signingString))), nil
+}
+
+// jwtVerifier verifies the tokens of the gateway, signed with its own HMAC
+// key, and the tokens of external providers, signed with the shared HMAC
+// key or the keys of the key set.
+type jwtVerifier struct {
+	// Shared key of external HS256 tokens. If empty, they are rejected.
+	hmacKey []byte
+
+	// Key of the tokens minted by the gateway. If empty, they are rejected.
+	ownKey []byte
+
+	// Keys of asymmetric tokens. If nil, they are rejected.
+	keys *keySet
+
+	// Issuer of the tokens minted by the gateway. They are not subject to
+	// the issuer and audience checks.
+	ownIssuer string
+
+	// Accepted issuers and audience. Empty means any.
+	issuers  []string
+	audience string
+
+	// Allowed clock skew for the time based claims.
+	skew time.Duration
+}
+
+func (v *jwtVerifier) keyFunc(t *jwt.Token) (interface{}, error) {
+	switch t.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		key := v.hmacKey
+
+		if claims, ok := t.Claims.(jwt.MapClaims); ok {
+			if iss, _ := claims["iss"].(string); iss != "" && iss == v.ownIssuer {
+				key = v.ownKey
+			}
+		}
+
+		if len(key) == 0 || t.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return key, nil
+
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA, *signingMethodEdDSA:
+	default:
+		return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+	}
+
+	if v.keys == nil {
+		return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+	}
+
+	kid, _ := t.Header["kid"].(string)
+
+	k, err := v.keys.key(context.Background(), kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.alg != "" && k.alg != t.Method.Alg() {
+		return nil, errors.New("signing method does not match key")
+	}
+
+	// The key type must match the method so a key cannot be used with
+	// another algorithm.
+	switch t.Method.(type) {
+	case *jwt.SigningMethodRSA:
+		if _, ok := k.key.(*rsa.PublicKey); ok {
+			return k.key, nil
+		}
+	case *jwt.SigningMethodECDSA:
+		if _, ok := k.key.(*ecdsa.PublicKey); ok {
+			return k.key, nil
+		}
+	case *signingMethodEdDSA:
+		if _, ok := k.key.(ed25519.PublicKey); ok {
+			return k.key, nil
+		}
+	}
+
+	return nil, errors.New("signing method does not match key")
+}
+
+// hasAudience checks the aud claim, which is a string or a list.
+func hasAudience(claims jwt.MapClaims, aud string) bool {
+	switch x := claims["aud"].(type) {
+	case string:
+		return x == aud
+	case []interface{}:
+		for _, a := range x {
+			if s, ok := a.(string); ok && s == aud {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// numericClaim returns a NumericDate claim and whether it is present.
+func numericClaim(claims jwt.MapClaims, name string) (int64, bool, error) {
+	v, ok := claims[name]
+	if !ok {
+		return 0, false, nil
+	}
+
+	switch x := v.(type) {
+	case float64:
+		return int64(x), true, nil
+	case json.Number:
+		n, err := x.Int64()
+		return n, true, err
+	}
+
+	return 0, true, fmt.Errorf("invalid %s claim", name)
+}
+
+func (v *jwtVerifier) validate(claims jwt.MapClaims, now time.Time) error {
+	skew := int64(v.skew / time.Second)
+
+	iss, _ := claims["iss"].(string)
+
+	// Minted by the gateway.
+	own := iss != "" && iss == v.ownIssuer
+
+	exp, ok, err := numericClaim(claims, "exp")
+	if err != nil {
+		return err
+	}
+	// External tokens cannot be revoked, so they must expire.
+	if !ok && !own {
+		return errors.New("token has no expiry")
+	}
+	if ok && now.Unix() > exp+skew {
+		return errors.New("token is expired")
+	}
+
+	nbf, ok, err := numericClaim(claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Unix() < nbf-skew {
+		return errors.New("token is not valid yet")
+	}
+
+	if own {
+		return nil
+	}
+
+	if len(v.issuers) > 0 {
+		var found bool
+		for _, x := range v.issuers {
+			if x == iss {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return errors.New("token issuer is not accepted")
+		}
+	}
+
+	if v.audience != "" && !hasAudience(claims, v.audience) {
+		return errors.New("token audience is not accepted")
+	}
+
+	return nil
+}
+
+// parse verifies the signature and claims of the token.
+func (v *jwtVerifier) parse(s string) (*jwt.Token, error) {
+	p := &jwt.Parser{
+		UseJSONNumber: true,
+		// The time based claims are validated with the clock skew.
+		SkipClaimsValidation: true,
+	}
+
+	token, err := p.Parse(s, v.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid claims")
+	}
+
+	// Tokens minted by the gateway are HS256 only, so an external token
+	// cannot claim to be one.
+	if iss, _ := claims["iss"].(string); iss != "" && iss == v.ownIssuer && token.Method != jwt.SigningMethodHS256 {
+		return nil, errors.New("token issuer is not accepted")
+	}
+
+	if err := v.validate(claims, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// ownAccessToken returns true if the token is a valid access token minted
+// by the gateway.
+func (v *jwtVerifier) ownAccessToken(s string) bool {
+	token, err := v.parse(s)
+	if err != nil {
+		return false
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+
+	iss, _ := claims["iss"].(string)
+	typ, _ := claims["typ"].(string)
+
+	return iss != "" && iss == v.ownIssuer && typ == accessTokenType
+}
+
+// JWTMiddleware ensures a valid bearer token is present and stores it in
+// the context under "user".
+func JWTMiddleware(v *jwtVerifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth := c.Request().Header.Get(echo.HeaderAuthorization)
+
+			if len(auth) <= len("Bearer ") || !strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+				return echo.NewHTTPError(http.StatusBadRequest, "missing or malformed jwt")
+			}
+
+			token, err := v.parse(auth[len("Bearer "):])
+			if err != nil {
+				return &echo.HTTPError{
+					Code:     http.StatusUnauthorized,
+					Message:  "invalid or expired jwt",
+					Internal: err,
+				}
+			}
+
+			c.Set("user", token)
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, k *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"alg": "RS256",
+		"n":   b64(k.N.Bytes()),
+		"e":   b64(big.NewInt(int64(k.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, k *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   b64(k.X.Bytes()),
+		"y":   b64(k.Y.Bytes()),
+	}
+}
+
+func edJWK(kid string, k ed25519.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "OKP",
+		"kid": kid,
+		"crv": "Ed25519",
+		"x":   b64(k),
+	}
+}
+
+// jwksStandIn serves a JWKS document whose keys can be replaced.
+type jwksStandIn struct {
+	mu   sync.Mutex
+	keys []map[string]string
+	hits int
+}
+
+func (s *jwksStandIn) set(keys ...map[string]string) {
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+}
+
+func (s *jwksStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hits++
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": s.keys,
+	})
+}
+
+func signToken(t *testing.T, m jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	tok := jwt.NewWithClaims(m, claims)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+
+	s, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestJWTVerifierKeyTypes(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	jwks := &jwksStandIn{}
+	jwks.set(
+		rsaJWK("rsa", &rsaKey.PublicKey),
+		ecJWK("ec", &ecKey.PublicKey),
+		edJWK("ed", edPub),
+	)
+
+	srv := httptest.NewServer(jwks)
+	defer srv.Close()
+
+	v := &jwtVerifier{
+		hmacKey: []byte("secret"),
+		ownKey:  []byte("own"),
+		keys: &keySet{
+			sources: []*jwksSource{{url: srv.URL}},
+			refresh: time.Hour,
+		},
+		ownIssuer: "gateway",
+		issuers:   []string{"https://idp.example.com"},
+		audience:  "api",
+		skew:      time.Minute,
+	}
+
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": "https://idp.example.com",
+			"aud": "api",
+			"sub": "123",
+			"exp": time.Now().Add(time.Minute).Unix(),
+		}
+	}
+
+	for _, x := range []struct {
+		name string
+		tok  string
+	}{
+		{"RS256", signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims())},
+		{"ES256", signToken(t, jwt.SigningMethodES256, "ec", ecKey, claims())},
+		{"EdDSA", signToken(t, SigningMethodEdDSA, "ed", edKey, claims())},
+		{"HS256", signToken(t, jwt.SigningMethodHS256, "", []byte("secret"), claims())},
+	} {
+		if _, err := v.parse(x.tok); err != nil {
+			t.Errorf("%s: expected valid token: %s", x.name, err)
+		}
+	}
+
+	// Key of another type for the key id.
+	if _, err := v.parse(signToken(t, jwt.SigningMethodES256, "rsa", ecKey, claims())); err == nil {
+		t.Error("expected error for key type mismatch")
+	}
+
+	// The gateway issuer is reserved for HS256 tokens.
+	c := claims()
+	c["iss"] = "gateway"
+	if _, err := v.parse(signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, c)); err == nil {
+		t.Error("expected error for gateway issuer with asymmetric key")
+	}
+
+	// Gateway tokens are signed with its own key, not the shared one.
+	if _, err := v.parse(signToken(t, jwt.SigningMethodHS256, "", []byte("secret"), c)); err == nil {
+		t.Error("expected error for gateway issuer with the shared key")
+	}
+
+	if _, err := v.parse(signToken(t, jwt.SigningMethodHS256, "", []byte("own"), c)); err != nil {
+		t.Errorf("expected valid gateway token: %s", err)
+	}
+
+	// Unsigned.
+	if _, err := v.parse(signToken(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, claims())); err == nil {
+		t.Error("expected error for unsigned token")
+	}
+}
+
+func TestJWTVerifierClaims(t *testing.T) {
+	v := &jwtVerifier{
+		ownIssuer: "gateway",
+		issuers:   []string{"https://idp.example.com"},
+		audience:  "api",
+		skew:      time.Minute,
+	}
+
+	now := time.Now()
+	exp := float64(now.Unix() + 60)
+
+	for _, x := range []struct {
+		name   string
+		claims jwt.MapClaims
+		valid  bool
+	}{
+		{"valid", jwt.MapClaims{"iss": "https://idp.example.com", "aud": "api", "exp": exp}, true},
+		{"audience list", jwt.MapClaims{"iss": "https://idp.example.com", "aud": []interface{}{"other", "api"}, "exp": exp}, true},
+		{"expired within skew", jwt.MapClaims{"iss": "https://idp.example.com", "aud": "api", "exp": float64(now.Unix() - 30)}, true},
+		{"expired", jwt.MapClaims{"iss": "https://idp.example.com", "aud": "api", "exp": float64(now.Unix() - 120)}, false},
+		{"no expiry", jwt.MapClaims{"iss": "https://idp.example.com", "aud": "api"}, false},
+		{"not before within skew", jwt.MapClaims{"iss": "https://idp.example.com", "aud": "api", "exp": exp, "nbf": float64(now.Unix() + 30)}, true},
+		{"not before", jwt.MapClaims{"iss": "https://idp.example.com", "aud": "api", "exp": exp, "nbf": float64(now.Unix() + 120)}, false},
+		{"issuer", jwt.MapClaims{"iss": "https://other.example.com", "aud": "api", "exp": exp}, false},
+		{"audience", jwt.MapClaims{"iss": "https://idp.example.com", "aud": "other", "exp": exp}, false},
+		{"gateway", jwt.MapClaims{"iss": "gateway"}, true},
+		{"invalid exp", jwt.MapClaims{"iss": "https://idp.example.com", "aud": "api", "exp": "soon"}, false},
+	} {
+		err := v.validate(x.claims, now)
+		if x.valid && err != nil {
+			t.Errorf("%s: expected valid: %s", x.name, err)
+		} else if !x.valid && err == nil {
+			t.Errorf("%s: expected error", x.name)
+		}
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	k1, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	k2, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	jwks := &jwksStandIn{}
+	jwks.set(ecJWK("k1", &k1.PublicKey))
+
+	srv := httptest.NewServer(jwks)
+	defer srv.Close()
+
+	v := &jwtVerifier{
+		keys: &keySet{
+			sources: []*jwksSource{{url: srv.URL}},
+			refresh: time.Hour,
+		},
+	}
+
+	claims := jwt.MapClaims{"sub": "123", "exp": time.Now().Add(time.Minute).Unix()}
+
+	if _, err := v.parse(signToken(t, jwt.SigningMethodES256, "k1", k1, claims)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cached.
+	if _, err := v.parse(signToken(t, jwt.SigningMethodES256, "k1", k1, claims)); err != nil {
+		t.Fatal(err)
+	}
+
+	if jwks.hits != 1 {
+		t.Errorf("expected keys to be cached, got %d fetches", jwks.hits)
+	}
+
+	jwks.set(ecJWK("k2", &k2.PublicKey))
+
+	// Unknown key ids reload at most once per minimum interval.
+	if _, err := v.parse(signToken(t, jwt.SigningMethodES256, "k2", k2, claims)); err == nil {
+		t.Error("expected error for unknown key within the minimum interval")
+	}
+
+	v.keys.tried = time.Now().Add(-jwksMinRefresh)
+
+	if _, err := v.parse(signToken(t, jwt.SigningMethodES256, "k2", k2, claims)); err != nil {
+		t.Errorf("expected rotated key to be loaded: %s", err)
+	}
+
+	if _, err := v.parse(signToken(t, jwt.SigningMethodES256, "k1", k1, claims)); err == nil {
+		t.Error("expected error for removed key")
+	}
+}
+
+func TestKeySetConcurrentLoad(t *testing.T) {
+	k1, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	jwks := &jwksStandIn{}
+	jwks.set(ecJWK("k1", &k1.PublicKey))
+
+	release := make(chan struct{})
+
+	// Holds the response until the lookups are waiting.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		jwks.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	keys := &keySet{
+		sources: []*jwksSource{{url: srv.URL}},
+		refresh: time.Hour,
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := keys.key(context.Background(), "k1")
+			errs <- err
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	if jwks.hits != 1 {
+		t.Errorf("expected one fetch, got %d", jwks.hits)
+	}
+}
+
+func TestKeySetFile(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	b, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			edJWK("ed", edKey.Public().(ed25519.PublicKey)),
+			// Encryption keys are skipped.
+			{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		},
+	})
+
+	f, err := ioutil.TempFile("", "jwks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	f.Write(b)
+	f.Close()
+
+	v := &jwtVerifier{
+		keys: &keySet{
+			sources: []*jwksSource{{file: f.Name()}},
+			refresh: time.Hour,
+		},
+	}
+
+	if _, err := v.parse(signToken(t, SigningMethodEdDSA, "ed", edKey, jwt.MapClaims{"sub": "123", "exp": time.Now().Add(time.Minute).Unix()})); err != nil {
+		t.Error(err)
+	}
+
+	if _, ok := v.keys.keys["enc"]; ok {
+		t.Error("expected encryption key to be skipped")
+	}
+}
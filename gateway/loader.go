@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Time a loader waits for more keys before fetching a batch.
+const loaderWait = 2 * time.Millisecond
+
+// Maximum number of concurrent requests of loaders without a batch RPC.
+const loaderConcurrency = 8
+
+type loaderResult struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// loader batches and caches the lookups of a request, as done by
+// dataloader. Keys requested within the wait time are fetched together and
+// every key is fetched at most once. The fetch returns the values by key;
+// an error value fails the key only.
+type loader struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, keys []string) (map[string]interface{}, error)
+
+	mu      sync.Mutex
+	results map[string]*loaderResult
+	batch   []string
+}
+
+func newLoader(ctx context.Context, fetch func(context.Context, []string) (map[string]interface{}, error)) *loader {
+	return &loader{
+		ctx:     ctx,
+		fetch:   fetch,
+		results: make(map[string]*loaderResult),
+	}
+}
+
+// load returns the value of the key. Keys without a value return nil.
+func (l *loader) load(key string) (interface{}, error) {
+	l.mu.Lock()
+
+	r, ok := l.results[key]
+	if !ok {
+		r = &loaderResult{
+			done: make(chan struct{}),
+		}
+
+		l.results[key] = r
+		l.batch = append(l.batch, key)
+
+		if len(l.batch) == 1 {
+			time.AfterFunc(loaderWait, l.dispatch)
+		}
+	}
+
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-l.ctx.Done():
+		return nil, l.ctx.Err()
+	}
+}
+
+// prime caches a value fetched by other means.
+func (l *loader) prime(key string, value interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.results[key]; ok {
+		return
+	}
+
+	r := &loaderResult{
+		done:  make(chan struct{}),
+		value: value,
+	}
+	close(r.done)
+
+	l.results[key] = r
+}
+
+func (l *loader) dispatch() {
+	l.mu.Lock()
+	keys := l.batch
+	l.batch = nil
+	l.mu.Unlock()
+
+	values, err := l.fetch(l.ctx, keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, k := range keys {
+		r := l.results[k]
+
+		if err != nil {
+			r.err = err
+		} else if e, ok := values[k].(error); ok {
+			r.err = e
+		} else {
+			r.value = values[k]
+		}
+
+		close(r.done)
+	}
+}
+
+// fetchEach adapts a lookup of a single key to a loader fetch. The keys
+// are looked up concurrently.
+func fetchEach(fn func(ctx context.Context, key string) (interface{}, error)) func(context.Context, []string) (map[string]interface{}, error) {
+	return func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		var (
+			mu     sync.Mutex
+			wg     sync.WaitGroup
+			values = make(map[string]interface{}, len(keys))
+		)
+
+		sem := make(chan struct{}, loaderConcurrency)
+
+		for _, k := range keys {
+			sem <- struct{}{}
+			wg.Add(1)
+
+			go func(k string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				v, err := fn(ctx, k)
+				if err != nil {
+					v = err
+				}
+
+				mu.Lock()
+				values[k] = v
+				mu.Unlock()
+			}(k)
+		}
+
+		wg.Wait()
+
+		return values, nil
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// mailer sends the emails of the gateway. Without an SMTP address the
+// messages are logged, which is useful for development.
+type mailer struct {
+	addr       string
+	username   string
+	password   string
+	from       string
+	confirmURL string
+
+	logger *zap.Logger
+}
+
+func (m *mailer) send(to, subject, body string) error {
+	if m.addr == "" {
+		m.logger.Info("email",
+			zap.String("email.to", to),
+			zap.String("email.subject", subject),
+			zap.String("email.body", body),
+		)
+		return nil
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		host, _, _ := net.SplitHostPort(m.addr)
+		auth = smtp.PlainAuth("", m.username, m.password, host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + m.from,
+		"To: " + to,
+		"Subject: " + subject,
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		body,
+	}, "\r\n")
+
+	return smtp.SendMail(m.addr, auth, m.from, []string{to}, []byte(msg))
+}
+
+// sendEmailChange sends the link to confirm a change of the email address.
+func (m *mailer) sendEmailChange(to, token string) error {
+	u, err := url.Parse(m.confirmURL)
+	if err != nil {
+		return err
+	}
+
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+
+	body := fmt.Sprintf("Confirm your new email address by opening the link below. The link expires in 24 hours.\r\n\r\n%s\r\n\r\nIf you did not request this change, ignore this email.", u)
+
+	return m.send(to, "Confirm your email address", body)
+}
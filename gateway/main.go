@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,8 +23,12 @@ import (
 	"github.com/rdm-academy/api/account"
 	"github.com/rdm-academy/api/commitlog"
 	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/export"
 	"github.com/rdm-academy/api/nodes"
 	"github.com/rdm-academy/api/project"
+	"github.com/rdm-academy/api/storage"
+	"github.com/rdm-academy/api/storage/flags"
+	"github.com/rdm-academy/api/webhook"
 	"github.com/tylerb/graceful"
 )
 
@@ -41,12 +47,93 @@ func main() {
 		natsAddr     string
 		jwtKey       string
 		printVersion bool
+
+		uploadConcurrency int
+		downloadRedirect  bool
+
+		mail  mailer
+		orcid orcidClient
+
+		authBaseURL    string
+		authSuccessURL string
+		authIssuer     string
+		authKey        string
+		accessTTL      time.Duration
+		refreshTTL     time.Duration
+
+		githubClientID, githubClientSecret string
+		googleClientID, googleClientSecret string
+		oidcIssuer, oidcClientID           string
+		oidcClientSecret                   string
+
+		jwksURLs    string
+		jwksFile    string
+		jwksRefresh time.Duration
+		jwtIssuers  string
+		jwtAudience string
+		jwtSkew     time.Duration
+
+		accountCacheSize int
+		accountCacheTTL  time.Duration
+
+		rateLimitStoreName string
+		rateLimitBucket    string
+		trustedProxies     string
 	)
 
 	flag.StringVar(&httpAddr, "http.addr", "127.0.0.1:8080", "HTTP bind address.")
 	flag.StringVar(&corsHosts, "cors.hosts", "", "List of CORS allowed hosts.")
 	flag.StringVar(&natsAddr, "nats.addr", "nats://127.0.0.1:4222", "NATS address.")
 	flag.StringVar(&jwtKey, "jwt.key", "", "JWT key.")
+	flag.StringVar(&jwksURLs, "jwt.jwks-url", "", "Comma-separated list of JWKS URLs with the keys of RS256, ES256 and EdDSA tokens.")
+	flag.StringVar(&jwksFile, "jwt.jwks-file", "", "Local JWKS file with the keys of RS256, ES256 and EdDSA tokens.")
+	flag.DurationVar(&jwksRefresh, "jwt.jwks-refresh", time.Hour, "Interval the JWKS keys are reloaded. Unknown key ids trigger a reload as well.")
+	flag.StringVar(&jwtIssuers, "jwt.issuers", "", "Comma-separated list of accepted token issuers. If empty, any issuer is accepted.")
+	flag.StringVar(&jwtAudience, "jwt.audience", "", "Required token audience. If empty, the audience is not checked.")
+	flag.DurationVar(&jwtSkew, "jwt.skew", time.Minute, "Allowed clock skew when validating the exp and nbf claims.")
+	flag.IntVar(&uploadConcurrency, "upload.concurrency", 4, "Maximum number of concurrent file uploads per request.")
+	flag.BoolVar(&downloadRedirect, "download.redirect", false, "Redirect file downloads to the storage signed URL rather than proxying them.")
+	flag.StringVar(&mail.addr, "smtp.addr", "", "SMTP server address. If empty, emails are logged instead.")
+	flag.StringVar(&mail.username, "smtp.username", "", "SMTP username.")
+	flag.StringVar(&mail.password, "smtp.password", "", "SMTP password.")
+	flag.StringVar(&mail.from, "smtp.from", "noreply@localhost", "Sender address of emails.")
+	flag.StringVar(&mail.confirmURL, "email.confirm-url", "http://localhost:3000/account/email/confirm", "URL of the email change confirmation page. The token is added as a query parameter.")
+	flag.StringVar(&orcid.url, "orcid.url", "https://orcid.org", "ORCID base URL. Use https://sandbox.orcid.org for testing.")
+	flag.StringVar(&orcid.clientID, "orcid.client-id", "", "ORCID API client ID. ORCID verification is disabled if empty.")
+	flag.StringVar(&orcid.clientSecret, "orcid.client-secret", "", "ORCID API client secret.")
+	flag.StringVar(&orcid.redirectURL, "orcid.redirect-url", "http://localhost:3000/account/orcid", "Registered ORCID redirect URL. It receives the code and state to post to the gateway.")
+	flag.StringVar(&authBaseURL, "auth.base-url", "http://localhost:8080", "Public URL of the gateway used for the login callback URLs.")
+	flag.StringVar(&authSuccessURL, "auth.success-url", "", "Client URL to redirect to after a login with the tokens in the fragment. If empty, the tokens are returned as JSON.")
+	flag.StringVar(&authIssuer, "auth.issuer", "rdm-academy", "Issuer of the tokens minted by the gateway.")
+	flag.StringVar(&authKey, "auth.key", "", "HMAC key of the tokens minted by the gateway. It must differ from -jwt.key. Required by the login providers.")
+	flag.DurationVar(&accessTTL, "auth.access-ttl", 15*time.Minute, "Lifetime of access tokens minted by the gateway.")
+	flag.DurationVar(&refreshTTL, "auth.refresh-ttl", 30*24*time.Hour, "Lifetime of refresh tokens minted by the gateway.")
+	flag.StringVar(&githubClientID, "auth.github.client-id", "", "GitHub OAuth client ID. GitHub login is disabled if empty.")
+	flag.StringVar(&githubClientSecret, "auth.github.client-secret", "", "GitHub OAuth client secret.")
+	flag.StringVar(&googleClientID, "auth.google.client-id", "", "Google OAuth client ID. Google login is disabled if empty.")
+	flag.StringVar(&googleClientSecret, "auth.google.client-secret", "", "Google OAuth client secret.")
+	flag.StringVar(&oidcIssuer, "auth.oidc.issuer", "", "Issuer URL of a generic OpenID Connect provider, as it appears in its discovery document. OIDC login is disabled if empty.")
+	flag.StringVar(&oidcClientID, "auth.oidc.client-id", "", "OpenID Connect client ID.")
+	flag.StringVar(&oidcClientSecret, "auth.oidc.client-secret", "", "OpenID Connect client secret.")
+	flag.IntVar(&accountCacheSize, "account.cache-size", 10000, "Maximum number of cached account lookups.")
+	flag.DurationVar(&accountCacheTTL, "account.cache-ttl", time.Minute, "Lifetime of cached account lookups. Account events invalidate them earlier.")
+	flag.StringVar(&rateLimitStoreName, "ratelimit.store", "memory", "Store of the rate limit buckets, memory or nats. Use nats to share the budgets between gateway replicas.")
+	flag.StringVar(&rateLimitBucket, "ratelimit.nats-bucket", "gateway_ratelimit", "NATS key-value bucket of the nats rate limit store.")
+	flag.StringVar(&trustedProxies, "ratelimit.trusted-proxies", "", "Comma-separated IPs and CIDR ranges of proxies whose X-Forwarded-For and X-Real-IP headers are trusted. Otherwise the client IP is the remote address.")
+
+	// Rate limits per user or client IP by route class.
+	rateLimitFlags := map[string]*string{
+		rateLimitDefault:  flag.String("ratelimit.default", "600/1m", "Rate limit of requests not in another class as <requests>/<period>. Empty or 0 disables the limit."),
+		rateLimitUpload:   flag.String("ratelimit.upload", "60/1m", "Rate limit of file uploads."),
+		rateLimitDownload: flag.String("ratelimit.download", "300/1m", "Rate limit of file, archive and export downloads."),
+		rateLimitImport:   flag.String("ratelimit.import", "10/1h", "Rate limit of project imports."),
+		rateLimitLog:      flag.String("ratelimit.log", "120/1m", "Rate limit of log requests."),
+		rateLimitIP:       flag.String("ratelimit.ip", "1200/1m", "Rate limit of requests per client IP before authentication."),
+	}
+
+	// Archives read the files from storage.
+	storageOpts := flags.Register(flag.CommandLine)
+
 	flag.BoolVar(&printVersion, "version", false, "Print version.")
 
 	flag.Parse()
@@ -80,8 +167,237 @@ func main() {
 
 	tp.SetLogger(logger)
 
+	stg, err := storageOpts.Open(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if stg == nil {
+		log.Fatal("storage options must be set")
+	}
+	defer stg.Close()
+
+	mail.logger = logger
+
+	// Rate limits.
+	limiter := &rateLimiter{
+		limits: make(map[string]*rateLimit),
+		logger: logger,
+	}
+
+	limiter.proxies, err = parseTrustedProxies(trustedProxies)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var maxPeriod time.Duration
+	for class, v := range rateLimitFlags {
+		l, err := parseRateLimit(*v)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if l != nil {
+			limiter.limits[class] = l
+
+			if l.period > maxPeriod {
+				maxPeriod = l.period
+			}
+		}
+	}
+
+	switch rateLimitStoreName {
+	case "memory":
+		limiter.store = newMemoryRateLimitStore()
+
+	case "nats":
+		// The buckets are kept in JetStream using the transport connection.
+		limiter.store, err = newNATSRateLimitStore(tp.Conn(), rateLimitBucket, maxPeriod)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+	default:
+		log.Fatal(fmt.Errorf("unknown rate limit store %q", rateLimitStoreName))
+	}
+
+	// Account lookups are cached since every authenticated request resolves
+	// the user. Changes made by other gateways are received as events.
+	accountCache := newAccountCache(accountCacheSize, accountCacheTTL)
+
+	if _, err := tp.Subscribe("events.account", newAccountCacheHandler(accountCache)); err != nil {
+		log.Fatal(err)
+	}
+
+	// Project events are streamed to the clients viewing the project.
+	eventHub := newEventHub()
+
+	if _, err := tp.Subscribe("events.>", newEventHubHandler(eventHub)); err != nil {
+		log.Fatal(err)
+	}
+
+	// Service clients.
+	accountSvc := &cachedAccounts{
+		ServiceClient: account.NewServiceClient(tp),
+		cache:         accountCache,
+	}
+	projectSvc := project.NewServiceClient(tp)
+	commitlogSvc := commitlog.NewServiceClient(tp)
+	dataSvc := data.NewServiceClient(tp)
+	nodeSvc := nodes.NewServiceClient(tp)
+	exportSvc := export.NewServiceClient(tp)
+	webhookSvc := webhook.NewServiceClient(tp)
+
+	// Verifies the JWTs of the requests.
+	verifier := &jwtVerifier{
+		hmacKey:   []byte(jwtKey),
+		ownKey:    []byte(authKey),
+		ownIssuer: authIssuer,
+		audience:  jwtAudience,
+		skew:      jwtSkew,
+	}
+
+	if jwtIssuers != "" {
+		verifier.issuers = strings.Split(jwtIssuers, ",")
+	}
+
+	if jwksURLs != "" || jwksFile != "" {
+		keys := &keySet{
+			refresh: jwksRefresh,
+		}
+
+		if jwksURLs != "" {
+			for _, u := range strings.Split(jwksURLs, ",") {
+				keys.sources = append(keys.sources, &jwksSource{url: u})
+			}
+		}
+
+		if jwksFile != "" {
+			keys.sources = append(keys.sources, &jwksSource{file: jwksFile})
+		}
+
+		verifier.keys = keys
+	}
+
+	// Login with the configured identity providers.
+	auth := &authHandler{
+		providers: make(map[string]*authProvider),
+		tokens: &tokenIssuer{
+			key:        []byte(authKey),
+			issuer:     authIssuer,
+			accessTTL:  accessTTL,
+			refreshTTL: refreshTTL,
+			accountSvc: accountSvc,
+		},
+		baseURL:    authBaseURL,
+		successURL: authSuccessURL,
+		accountSvc: accountSvc,
+	}
+
+	if githubClientID != "" {
+		auth.providers["github"] = newGitHubProvider(githubClientID, githubClientSecret)
+	}
+	if googleClientID != "" {
+		auth.providers["google"] = newOIDCProvider("google", "https://accounts.google.com", googleClientID, googleClientSecret)
+	}
+	if oidcIssuer != "" {
+		auth.providers["oidc"] = newOIDCProvider("oidc", oidcIssuer, oidcClientID, oidcClientSecret)
+	}
+
+	if len(auth.providers) > 0 && authKey == "" {
+		log.Fatal("-auth.key is required by the login providers")
+	}
+
+	if authKey != "" && authKey == jwtKey {
+		log.Fatal("-auth.key must differ from -jwt.key")
+	}
+
+	e, _, err := newRouter(&router{
+		tp:                tp,
+		logger:            logger,
+		storage:           stg,
+		limiter:           limiter,
+		accountCache:      accountCache,
+		eventHub:          eventHub,
+		accountSvc:        accountSvc,
+		projectSvc:        projectSvc,
+		commitlogSvc:      commitlogSvc,
+		dataSvc:           dataSvc,
+		nodeSvc:           nodeSvc,
+		exportSvc:         exportSvc,
+		webhookSvc:        webhookSvc,
+		verifier:          verifier,
+		auth:              auth,
+		mail:              &mail,
+		orcid:             &orcid,
+		corsHosts:         corsHosts,
+		jwtKey:            jwtKey,
+		authIssuer:        authIssuer,
+		downloadRedirect:  downloadRedirect,
+		uploadConcurrency: uploadConcurrency,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Gracefully serve.
+	logger.Info("listening",
+		zap.String("http.addr", httpAddr),
+	)
+
+	e.Server.Addr = httpAddr
+	if err := graceful.ListenAndServe(e.Server, 5*time.Second); err != nil {
+		logger.Error("http error",
+			zap.Error(err),
+		)
+	}
+}
+
+// router holds the dependencies of the routes.
+type router struct {
+	tp      transport.Transport
+	logger  *zap.Logger
+	storage storage.Storage
+	limiter *rateLimiter
+
+	accountCache *accountCache
+	eventHub     *eventHub
+
+	accountSvc   account.ServiceClient
+	projectSvc   project.ServiceClient
+	commitlogSvc commitlog.ServiceClient
+	dataSvc      data.ServiceClient
+	nodeSvc      nodes.ServiceClient
+	exportSvc    export.ServiceClient
+	webhookSvc   webhook.ServiceClient
+
+	verifier *jwtVerifier
+	auth     *authHandler
+	mail     *mailer
+	orcid    *orcidClient
+
+	corsHosts        string
+	jwtKey           string
+	authIssuer       string
+	downloadRedirect bool
+
+	// Maximum number of concurrent file uploads per request.
+	uploadConcurrency int
+}
+
+// newRouter registers the routes. It returns the scopes required by each
+// authenticated route keyed by method and path. Routes that are missing
+// are public.
+func newRouter(r *router) (*echo.Echo, routeTable, error) {
+	uploadConcurrency := r.uploadConcurrency
+	if uploadConcurrency < 1 {
+		uploadConcurrency = 1
+	}
+	rateLimit := r.limiter.Middleware()
+	ipLimit := r.limiter.IPMiddleware()
+
 	// Setup HTTP mux.
 	e := echo.New()
+	routes := make(routeTable)
 
 	// Disable internal logger.
 	e.Logger.SetOutput(ioutil.Discard)
@@ -91,37 +407,38 @@ func main() {
 
 	// Setup middleware.
 	e.Pre(TraceMiddleware())
-	e.Use(LoggingMiddlware(logger))
+	e.Use(LoggingMiddlware(r.logger))
 	e.Pre(middleware.RemoveTrailingSlash())
 	e.Use(middleware.Recover())
-	e.Use(middleware.Gzip())
+
+	// Downloads are not compressed to preserve the length for range requests
+	// and archives are already compressed.
+	e.Use(middleware.GzipWithConfig(middleware.GzipConfig{
+		Skipper: func(c echo.Context) bool {
+			p := c.Path()
+			return strings.HasSuffix(p, "/download") || strings.HasSuffix(p, "/archive") || p == "/account/export"
+		},
+	}))
 
 	// If CORS hosts are specified, add middleware to restrict access.
-	if corsHosts != "" {
+	if r.corsHosts != "" {
 		config := middleware.CORSConfig{
-			AllowOrigins:     strings.Split(corsHosts, ","),
+			AllowOrigins:     strings.Split(r.corsHosts, ","),
 			AllowCredentials: true,
 			AllowHeaders: []string{
 				"Authorization",
 				"Content-Type",
+				"Last-Event-ID",
 			},
 		}
 
 		e.Use(middleware.CORSWithConfig(config))
 	}
 
-	// Service clients.
-	accountSvc := account.NewServiceClient(tp)
-	projectSvc := project.NewServiceClient(tp)
-	commitlogSvc := commitlog.NewServiceClient(tp)
-	dataSvc := data.NewServiceClient(tp)
-	nodeSvc := nodes.NewServiceClient(tp)
-
 	// Used to enrich objects prior to get them to the client.
-	// A poor mans GraphQL..
 	enricher := &Enricher{
-		accountSvc: accountSvc,
-		projectSvc: projectSvc,
+		accountSvc: r.accountSvc,
+		projectSvc: r.projectSvc,
 	}
 
 	//
@@ -135,266 +452,635 @@ func main() {
 
 	// Endpoint to check if the service is ready (e.g. liveness probe).
 	e.GET("/readyz", func(c echo.Context) error {
-		if tp.Conn().IsConnected() {
+		if r.tp.Conn().IsConnected() {
 			return c.NoContent(http.StatusOK)
 		}
 		return c.NoContent(http.StatusServiceUnavailable)
 	})
 
+	// Metrics in the Prometheus text format.
+	e.GET("/metrics", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, "text/plain; version=0.0.4")
+		c.Response().WriteHeader(http.StatusOK)
+		r.accountCache.writeMetrics(c.Response())
+		r.eventHub.writeMetrics(c.Response())
+		return nil
+	})
+
+	// OpenAPI document of the routes.
+	openAPI, err := json.Marshal(openAPIDocument(buildVersion, apiRoutes))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e.GET("/openapi.json", func(c echo.Context) error {
+		return c.JSONBlob(http.StatusOK, openAPI)
+	})
+
 	//
 	// Protected routes.
 	//
 
-	// Ensure a valid JWT is present.
-	authMiddleware := middleware.JWTWithConfig(middleware.JWTConfig{
-		SigningKey: []byte(jwtKey),
-	})
+	// Ensure a valid JWT or personal access token is present. Refresh
+	// tokens are only accepted by the token endpoint.
+	jwtMiddleware := JWTMiddleware(r.verifier)
+
+	// Personal access tokens are accepted as well.
+	credentialMiddleware := TokenMiddleware(r.accountSvc, jwtMiddleware)
+
+	authMiddleware := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return credentialMiddleware(accessOnly(next))
+	}
+
+	// Login with the configured identity providers.
+	e.GET("/auth/providers", r.auth.list, ipLimit, rateLimit)
+	e.GET("/auth/:provider/login", r.auth.login, ipLimit, rateLimit)
+	e.GET("/auth/:provider/callback", r.auth.callback, ipLimit, rateLimit)
+	e.POST("/auth/token", r.auth.refresh, ipLimit, rateLimit)
+
+	// Registration accepts JWTs of identities without an account.
+	register := newRouteGroup(e, routes, ipLimit, authMiddleware, rateLimit)
+
+	// Ensure the user account is created and optionally update the profile.
+	// This requires a valid JWT token and extracts the identity out.
+	// If an account already exists for the identity, only the profile is
+	// updated. An identity with a verified email of an existing account is
+	// linked to it.
+	register.PUT("/account", func(c echo.Context) error {
+		token, ok := c.Get("user").(*jwt.Token)
+		if !ok {
+			return echo.NewHTTPError(http.StatusForbidden, "registration requires a JWT")
+		}
 
-	// Ensure the user account is created. This requires a valid JWT token
-	// and extracts the profile information out.
-	// If the account already exists for the email address, this is a no-op.
-	e.PUT("/account", func(c echo.Context) error {
-		token, _ := c.Get("user").(*jwt.Token)
 		claims, _ := token.Claims.(jwt.MapClaims)
 
 		// Get values.
+		subject, _ := claims["sub"].(string)
+		issuer, _ := claims["iss"].(string)
 		email, _ := claims["email"].(string)
+		name, _ := claims["name"].(string)
+
+		if issuer == "" || subject == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "token must include iss and sub")
+		}
+
+		var body profileUpdate
+		if c.Request().ContentLength != 0 {
+			if err := c.Bind(&body); err != nil {
+				return err
+			}
+		}
 
 		ctx := c.Request().Context()
 
-		req := &account.GetUserRequest{
-			Email: email,
+		var (
+			id      string
+			created bool
+			err     error
+		)
+
+		// Tokens minted by the gateway are for existing accounts.
+		if issuer == r.authIssuer {
+			id = subject
+		} else {
+			verified, _ := claims["email_verified"].(bool)
+
+			id, created, err = ensureAccount(ctx, r.accountSvc, verified, issuer, subject, email, name)
+			if err != nil {
+				return err
+			}
 		}
 
-		if rep, err := accountSvc.GetUser(ctx, req); err == nil {
-			return c.JSON(http.StatusOK, rep)
+		code := http.StatusOK
+		if created {
+			code = http.StatusCreated
 		}
 
-		subject, _ := claims["sub"].(string)
-		issuer, _ := claims["iss"].(string)
-		name, _ := claims["name"].(string)
+		// Signing in with ORCID verifies the iD.
+		if issuer == r.orcid.issuer() {
+			_, err := r.accountSvc.VerifyOrcid(ctx, &account.VerifyOrcidRequest{
+				Id:    id,
+				Orcid: subject,
+			})
+			if err != nil {
+				return err
+			}
+		}
 
-		req2 := &account.CreateUserRequest{
-			Issuer:  issuer,
-			Subject: subject,
-			Name:    name,
-			Email:   email,
+		rep, err := r.accountSvc.GetUser(ctx, &account.GetUserRequest{
+			Id: id,
+		})
+		if err != nil {
+			return err
+		}
+
+		if body.empty() {
+			return c.JSON(code, rep)
 		}
 
-		rep, err := accountSvc.CreateUser(ctx, req2)
+		rep2, err := r.accountSvc.UpdateUser(ctx, body.apply(rep))
 		if err != nil {
 			return err
 		}
 
-		return c.JSON(http.StatusCreated, rep)
-	}, authMiddleware)
+		return c.JSON(code, rep2.User)
+	})
 
 	// Adds information of an authenticated user to the request context.
 	// This must come after the authMiddleware.
-	userMiddleware := AccountMiddleware(accountSvc)
+	userMiddleware := AccountMiddleware(r.accountSvc, r.authIssuer)
+
+	// Authenticated routes grouped by the scope they require. Personal
+	// access tokens and scoped JWTs must grant the scope.
+	authed := newRouteGroup(e, routes, ipLimit, authMiddleware, userMiddleware, rateLimit)
+
+	accountAdmin := authed.Scopes(account.ScopeAccountAdmin)
+	projectsRead := authed.Scopes(account.ScopeProjectsRead)
+	projectsWrite := authed.Scopes(account.ScopeProjectsWrite)
+	filesRead := authed.Scopes(account.ScopeFilesRead)
+	filesWrite := authed.Scopes(account.ScopeFilesWrite)
+	logCommit := authed.Scopes(account.ScopeLogCommit)
+
+	// Imports create a project with files.
+	importsWrite := authed.Scopes(account.ScopeProjectsWrite, account.ScopeFilesWrite)
 
 	// Account of the requesting user.
-	e.GET("/account", func(c echo.Context) error {
+	authed.GET("/account", func(c echo.Context) error {
 		req := account.GetUserRequest{
 			Id: c.Get("user.id").(string),
 		}
 
-		rep, err := accountSvc.GetUser(c.Request().Context(), &req)
+		rep, err := r.accountSvc.GetUser(c.Request().Context(), &req)
 		if err != nil {
 			return err
 		}
 
 		return c.JSON(http.StatusOK, rep)
-	}, authMiddleware, userMiddleware)
+	})
 
-	// Account of the requesting user.
-	e.DELETE("/account", func(c echo.Context) error {
+	// Delete the account of the requesting user along with the owned
+	// projects and files.
+	accountAdmin.DELETE("/account", func(c echo.Context) error {
 		req := account.DeleteUserRequest{
 			Id: c.Get("user.id").(string),
 		}
 
-		_, err := accountSvc.DeleteUser(c.Request().Context(), &req)
+		_, err := r.accountSvc.DeleteUser(c.Request().Context(), &req)
 		if err != nil {
 			return err
 		}
 
 		return c.NoContent(http.StatusOK)
-	}, authMiddleware, userMiddleware)
+	})
 
-	// Project endpoints.
-	e.POST("/projects", func(c echo.Context) error {
-		var req project.CreateProjectRequest
-		if err := c.Bind(&req); err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, err)
+	// Start a change of the email address. A confirmation link is sent to
+	// the new address.
+	accountAdmin.POST("/account/email", func(c echo.Context) error {
+		var body struct {
+			Email string `json:"email"`
 		}
 
-		req.Account = c.Get("user.id").(string)
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
 
-		rep, err := projectSvc.CreateProject(c.Request().Context(), &req)
+		rep, err := r.accountSvc.RequestEmailChange(c.Request().Context(), &account.RequestEmailChangeRequest{
+			Id:    c.Get("user.id").(string),
+			Email: body.Email,
+		})
 		if err != nil {
 			return err
 		}
 
-		return c.JSON(http.StatusCreated, rep.Project)
-	}, authMiddleware, userMiddleware)
+		if err := r.mail.sendEmailChange(strings.ToLower(strings.TrimSpace(body.Email)), rep.Token); err != nil {
+			return err
+		}
 
-	e.PUT("/projects/:id", func(c echo.Context) error {
-		var req project.UpdateProjectRequest
-		if err := c.Bind(&req); err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, err)
+		return c.JSON(http.StatusAccepted, map[string]interface{}{
+			"expires": rep.Expires,
+		})
+	})
+
+	// Confirm the change of the email address with the token that was sent.
+	accountAdmin.POST("/account/email/confirm", func(c echo.Context) error {
+		var body struct {
+			Token string `json:"token"`
 		}
 
-		req.Id = c.Param("id")
-		req.Account = c.Get("user.id").(string)
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
 
-		_, err := projectSvc.UpdateProject(c.Request().Context(), &req)
+		rep, err := r.accountSvc.ConfirmEmailChange(c.Request().Context(), &account.ConfirmEmailChangeRequest{
+			Id:    c.Get("user.id").(string),
+			Token: body.Token,
+		})
 		if err != nil {
 			return err
 		}
 
-		return c.NoContent(http.StatusOK)
-	}, authMiddleware, userMiddleware)
+		return c.JSON(http.StatusOK, rep)
+	})
 
-	e.PUT("/projects/:id/workflow", func(c echo.Context) error {
-		var req project.UpdateWorkflowRequest
-		if err := c.Bind(&req); err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, err)
+	// Start the verification of the ORCID iD. The client sends the user to
+	// the returned URL, and posts the code and state it receives on the
+	// redirect URL back.
+	accountAdmin.GET("/account/orcid/authorize", func(c echo.Context) error {
+		if !r.orcid.enabled() {
+			return echo.NewHTTPError(http.StatusNotImplemented, "ORCID verification is not configured")
 		}
 
-		req.Id = c.Param("id")
-		req.Account = c.Get("user.id").(string)
+		state := signState([]byte(r.jwtKey), c.Get("user.id").(string), time.Now())
 
-		_, err := projectSvc.UpdateWorkflow(c.Request().Context(), &req)
-		if err != nil {
+		return c.JSON(http.StatusOK, map[string]string{
+			"url": r.orcid.authorizeURL(state),
+		})
+	})
+
+	// Complete the verification of the ORCID iD.
+	accountAdmin.POST("/account/orcid", func(c echo.Context) error {
+		if !r.orcid.enabled() {
+			return echo.NewHTTPError(http.StatusNotImplemented, "ORCID verification is not configured")
+		}
+
+		var body struct {
+			Code  string `json:"code"`
+			State string `json:"state"`
+		}
+
+		if err := c.Bind(&body); err != nil {
 			return err
 		}
 
-		return c.NoContent(http.StatusOK)
-	}, authMiddleware, userMiddleware)
+		id := c.Get("user.id").(string)
 
-	e.DELETE("/projects/:id", func(c echo.Context) error {
-		req := &project.DeleteProjectRequest{
-			Id:      c.Param("id"),
-			Account: c.Get("user.id").(string),
+		if err := verifyState([]byte(r.jwtKey), body.State, id, time.Now()); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		ctx := c.Request().Context()
+
+		tok, err := r.orcid.exchange(ctx, body.Code)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
 
-		_, err := projectSvc.DeleteProject(c.Request().Context(), req)
+		rep, err := r.accountSvc.VerifyOrcid(ctx, &account.VerifyOrcidRequest{
+			Id:    id,
+			Orcid: tok.Orcid,
+		})
 		if err != nil {
 			return err
 		}
 
-		return c.NoContent(http.StatusOK)
-	}, authMiddleware, userMiddleware)
+		return c.JSON(http.StatusOK, rep.User)
+	})
 
-	e.GET("/projects", func(c echo.Context) error {
-		req := project.ListProjectsRequest{
+	// Personal access tokens of the account for scripts and pipelines.
+	accountAdmin.GET("/account/tokens", func(c echo.Context) error {
+		rep, err := r.accountSvc.ListTokens(c.Request().Context(), &account.ListTokensRequest{
 			Account: c.Get("user.id").(string),
+		})
+		if err != nil {
+			return err
 		}
 
-		rep, err := projectSvc.ListProjects(c.Request().Context(), &req)
-		if err != nil {
+		return c.JSON(http.StatusOK, rep.Tokens)
+	})
+
+	// Create a personal access token. The secret is only returned in this
+	// response.
+	accountAdmin.POST("/account/tokens", func(c echo.Context) error {
+		var body struct {
+			Name      string   `json:"name"`
+			Scopes    []string `json:"scopes"`
+			ExpiresIn int64    `json:"expires_in"`
+		}
+
+		if err := c.Bind(&body); err != nil {
 			return err
 		}
 
-		return c.JSON(http.StatusOK, rep.Projects)
-	}, authMiddleware, userMiddleware)
+		if body.ExpiresIn < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "expires_in must not be negative")
+		}
 
-	e.GET("/projects/:id", func(c echo.Context) error {
-		req := project.GetProjectRequest{
-			Id:      c.Param("id"),
+		req := account.CreateTokenRequest{
 			Account: c.Get("user.id").(string),
+			Name:    body.Name,
+			Scopes:  body.Scopes,
+		}
+
+		if body.ExpiresIn > 0 {
+			req.Expires = time.Now().Unix() + body.ExpiresIn
 		}
 
-		rep, err := projectSvc.GetProject(c.Request().Context(), &req)
+		rep, err := r.accountSvc.CreateToken(c.Request().Context(), &req)
 		if err != nil {
 			return err
 		}
 
-		return c.JSON(http.StatusOK, rep.Project)
-	}, authMiddleware, userMiddleware)
-
-	type apiEvent struct {
-		ID     string          `json:"id"`
-		Time   int64           `json:"time"`
-		Type   string          `json:"type"`
-		Author string          `json:"author"`
-		Data   json.RawMessage `json:"data"`
-	}
+		return c.JSON(http.StatusCreated, rep)
+	}, jwtOnly)
 
-	type apiCommit struct {
-		ID     string      `json:"id"`
-		Msg    string      `json:"msg"`
-		Author string      `json:"author"`
-		Time   int64       `json:"time"`
-		Events []*apiEvent `json:"events"`
-		Parent string      `json:"parent"`
-	}
+	accountAdmin.DELETE("/account/tokens/:id", func(c echo.Context) error {
+		_, err := r.accountSvc.RevokeToken(c.Request().Context(), &account.RevokeTokenRequest{
+			Account: c.Get("user.id").(string),
+			Id:      c.Param("id"),
+		})
+		if err != nil {
+			return err
+		}
 
-	e.GET("/projects/:id/log", func(c echo.Context) error {
-		ctx := c.Request().Context()
-		account := c.Get("user.id").(string)
+		return c.NoContent(http.StatusNoContent)
+	})
 
-		canView, err := enricher.CanViewProject(ctx, c.Param("id"), account)
+	// Identities linked to the account of the requesting user.
+	accountAdmin.GET("/account/identities", func(c echo.Context) error {
+		rep, err := r.accountSvc.ListIdentities(c.Request().Context(), &account.ListIdentitiesRequest{
+			Id: c.Get("user.id").(string),
+		})
 		if err != nil {
-			return c.String(http.StatusServiceUnavailable, err.Error())
+			return err
 		}
 
-		if !canView {
-			return c.NoContent(http.StatusNotFound)
-		}
+		return c.JSON(http.StatusOK, rep.Identities)
+	})
 
-		req := commitlog.HistoryRequest{
-			Project: c.Param("id"),
+	// Link another identity to the account. The body contains a token
+	// issued for the identity to prove the user controls it.
+	accountAdmin.POST("/account/identities", func(c echo.Context) error {
+		var body struct {
+			Token string `json:"token"`
 		}
 
-		commits := make([]*apiCommit, 0)
-		authors := make(map[string]string)
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
 
-		for {
-			rep, err := commitlogSvc.History(ctx, &req)
-			if err != nil {
-				return err
-			}
+		if body.Token == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "token required")
+		}
 
-			if rep.Commit == nil {
-				break
-			}
+		token, err := r.verifier.parse(body.Token)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid token")
+		}
 
-			events := make([]*apiEvent, len(rep.Commit.Events))
-			for i, e := range rep.Commit.Events {
-				var author string
-				if x, ok := authors[e.Author]; ok {
-					author = x
-				} else {
-					author, _ = enricher.GetUserName(ctx, e.Author)
-					authors[e.Author] = author
-				}
-
-				events[i] = &apiEvent{
-					ID:     e.Id,
-					Time:   e.Time,
-					Type:   e.Type,
-					Author: author,
-					Data:   json.RawMessage(e.Data),
-				}
-			}
+		claims, _ := token.Claims.(jwt.MapClaims)
 
-			var author string
-			if x, ok := authors[rep.Commit.Author]; ok {
-				author = x
-			} else {
-				author, _ = enricher.GetUserName(ctx, rep.Commit.Author)
-				authors[rep.Commit.Author] = author
-			}
+		subject, _ := claims["sub"].(string)
+		issuer, _ := claims["iss"].(string)
+		email, _ := claims["email"].(string)
+		name, _ := claims["name"].(string)
 
-			commits = append(commits, &apiCommit{
-				ID:     rep.Commit.Id,
-				Msg:    rep.Commit.Msg,
-				Author: author,
-				Time:   rep.Commit.Time,
-				Parent: rep.Commit.Parent,
-				Events: events,
-			})
+		if issuer == r.authIssuer {
+			return echo.NewHTTPError(http.StatusBadRequest, "token must be issued by an identity provider")
+		}
+
+		_, err = r.accountSvc.LinkIdentity(c.Request().Context(), &account.LinkIdentityRequest{
+			Id:      c.Get("user.id").(string),
+			Issuer:  issuer,
+			Subject: subject,
+			Email:   email,
+			Name:    name,
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}, jwtOnly)
+
+	// Unlink an identity from the account. The last identity cannot be
+	// unlinked.
+	accountAdmin.DELETE("/account/identities", func(c echo.Context) error {
+		_, err := r.accountSvc.UnlinkIdentity(c.Request().Context(), &account.UnlinkIdentityRequest{
+			Id:      c.Get("user.id").(string),
+			Issuer:  c.QueryParam("issuer"),
+			Subject: c.QueryParam("subject"),
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}, jwtOnly)
+
+	accountExporter := &accountExport{
+		projectSvc:   r.projectSvc,
+		commitlogSvc: r.commitlogSvc,
+		dataSvc:      r.dataSvc,
+		nodeSvc:      r.nodeSvc,
+		storage:      r.storage,
+	}
+
+	// Archive of everything the requesting user owns.
+	accountAdmin.GET("/account/export", func(c echo.Context) error {
+		rep, err := r.accountSvc.GetUser(c.Request().Context(), &account.GetUserRequest{
+			Id: c.Get("user.id").(string),
+		})
+		if err != nil {
+			return err
+		}
+
+		return accountExporter.serve(c, rep)
+	})
+
+	// Storage usage of the requesting user.
+	authed.GET("/account/usage", func(c echo.Context) error {
+		req := data.UsageRequest{
+			Account: c.Get("user.id").(string),
+		}
+
+		rep, err := r.dataSvc.Usage(c.Request().Context(), &req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, rep)
+	})
+
+	// Project endpoints.
+	projectsWrite.POST("/projects", func(c echo.Context) error {
+		var req project.CreateProjectRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusUnprocessableEntity, err)
+		}
+
+		req.Account = c.Get("user.id").(string)
+
+		rep, err := r.projectSvc.CreateProject(c.Request().Context(), &req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, rep.Project)
+	})
+
+	projectsWrite.PUT("/projects/:id", func(c echo.Context) error {
+		var req project.UpdateProjectRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusUnprocessableEntity, err)
+		}
+
+		req.Id = c.Param("id")
+		req.Account = c.Get("user.id").(string)
+
+		_, err := r.projectSvc.UpdateProject(c.Request().Context(), &req)
+		if err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusOK)
+	})
+
+	projectsWrite.PUT("/projects/:id/workflow", func(c echo.Context) error {
+		var req project.UpdateWorkflowRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusUnprocessableEntity, err)
+		}
+
+		req.Id = c.Param("id")
+		req.Account = c.Get("user.id").(string)
+
+		_, err := r.projectSvc.UpdateWorkflow(c.Request().Context(), &req)
+		if err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusOK)
+	})
+
+	// List the projects in the trash.
+	projectsRead.GET("/projects/trash", func(c echo.Context) error {
+		req := project.ListDeletedProjectsRequest{
+			Account: c.Get("user.id").(string),
+		}
+
+		rep, err := r.projectSvc.ListDeletedProjects(c.Request().Context(), &req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, rep.Projects)
+	})
+
+	// Restore a project from the trash.
+	projectsWrite.POST("/projects/:id/restore", func(c echo.Context) error {
+		req := project.RestoreProjectRequest{
+			Id:      c.Param("id"),
+			Account: c.Get("user.id").(string),
+		}
+
+		_, err := r.projectSvc.RestoreProject(c.Request().Context(), &req)
+		if err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusOK)
+	})
+
+	// Fork the project into a new project for the account. The body
+	// optionally specifies the name of the new project.
+	projectsWrite.POST("/projects/:id/fork", func(c echo.Context) error {
+		var req project.ForkProjectRequest
+		if c.Request().ContentLength != 0 {
+			if err := c.Bind(&req); err != nil {
+				return c.JSON(http.StatusUnprocessableEntity, err)
+			}
+		}
+
+		req.Id = c.Param("id")
+		req.Account = c.Get("user.id").(string)
+
+		rep, err := r.projectSvc.ForkProject(c.Request().Context(), &req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, rep.Project)
+	})
+
+	projectsWrite.DELETE("/projects/:id", func(c echo.Context) error {
+		req := &project.DeleteProjectRequest{
+			Id:      c.Param("id"),
+			Account: c.Get("user.id").(string),
+		}
+
+		_, err := r.projectSvc.DeleteProject(c.Request().Context(), req)
+		if err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusOK)
+	})
+
+	projectsRead.GET("/projects", func(c echo.Context) error {
+		req := project.ListProjectsRequest{
+			Account: c.Get("user.id").(string),
+		}
+
+		rep, err := r.projectSvc.ListProjects(c.Request().Context(), &req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, rep.Projects)
+	})
+
+	projectsRead.GET("/projects/:id", func(c echo.Context) error {
+		req := project.GetProjectRequest{
+			Id:      c.Param("id"),
+			Account: c.Get("user.id").(string),
+		}
+
+		rep, err := r.projectSvc.GetProject(c.Request().Context(), &req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, rep.Project)
+	})
+
+	projectsRead.GET("/projects/:id/log", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		account := c.Get("user.id").(string)
+
+		canView, err := enricher.CanViewProject(ctx, c.Param("id"), account)
+		if err != nil {
+			return c.String(http.StatusServiceUnavailable, err.Error())
+		}
+
+		if !canView {
+			return c.NoContent(http.StatusNotFound)
+		}
+
+		req := commitlog.HistoryRequest{
+			Project: c.Param("id"),
+		}
+
+		var (
+			history []*commitlog.Commit
+			ids     []string
+		)
+
+		for {
+			rep, err := r.commitlogSvc.History(ctx, &req)
+			if err != nil {
+				return err
+			}
+
+			if rep.Commit == nil {
+				break
+			}
+
+			history = append(history, rep.Commit)
+
+			ids = append(ids, rep.Commit.Author)
+			for _, e := range rep.Commit.Events {
+				ids = append(ids, e.Author)
+			}
 
 			// Any more?
 			if rep.Next == "" {
@@ -404,10 +1090,33 @@ func main() {
 			req.Commit = rep.Next
 		}
 
+		// Resolve the authors of all commits at once.
+		authors, err := enricher.GetAuthors(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		commits := make([]*apiCommit, len(history))
+		for i, x := range history {
+			events := make([]*apiEvent, len(x.Events))
+			for j, e := range x.Events {
+				events[j] = newAPIEvent(e, authors)
+			}
+
+			commits[i] = &apiCommit{
+				ID:     x.Id,
+				Msg:    x.Msg,
+				Author: authors[x.Author],
+				Time:   x.Time,
+				Parent: x.Parent,
+				Events: events,
+			}
+		}
+
 		return c.JSON(http.StatusOK, commits)
-	}, authMiddleware, userMiddleware)
+	})
 
-	e.GET("/projects/:id/log/pending", func(c echo.Context) error {
+	projectsRead.GET("/projects/:id/log/pending", func(c echo.Context) error {
 		ctx := c.Request().Context()
 		account := c.Get("user.id").(string)
 
@@ -423,36 +1132,58 @@ func main() {
 			Project: c.Param("id"),
 		}
 
-		rep, err := commitlogSvc.Pending(ctx, &req)
+		rep, err := r.commitlogSvc.Pending(ctx, &req)
 		if err != nil {
 			return err
 		}
 
-		authors := make(map[string]string)
-		events := make([]*apiEvent, len(rep.Events))
-
+		ids := make([]string, len(rep.Events))
 		for i, e := range rep.Events {
-			var author string
-			if x, ok := authors[e.Author]; ok {
-				author = x
-			} else {
-				author, _ = enricher.GetUserName(ctx, e.Author)
-				authors[e.Author] = author
-			}
+			ids[i] = e.Author
+		}
 
-			events[i] = &apiEvent{
-				ID:     e.Id,
-				Time:   e.Time,
-				Type:   e.Type,
-				Author: author,
-				Data:   json.RawMessage(e.Data),
-			}
+		authors, err := enricher.GetAuthors(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		events := make([]*apiEvent, len(rep.Events))
+		for i, e := range rep.Events {
+			events[i] = newAPIEvent(e, authors)
 		}
 
 		return c.JSON(http.StatusOK, events)
-	}, authMiddleware, userMiddleware)
+	})
+
+	// GraphQL queries over the account and the projects. The resolvers
+	// make the same checks as the routes above.
+	graphqlSvc := newGraphQLHandler(&graphqlHandler{
+		accountSvc:   r.accountSvc,
+		projectSvc:   r.projectSvc,
+		commitlogSvc: r.commitlogSvc,
+		dataSvc:      r.dataSvc,
+		nodeSvc:      r.nodeSvc,
+		enricher:     enricher,
+	})
 
-	e.POST("/projects/:id/log", func(c echo.Context) error {
+	projectsRead.POST("/graphql", graphqlSvc.Serve)
+
+	// Live events of a project as server-sent events or over a WebSocket.
+	// The token may be passed in the query since browsers cannot set the
+	// header of these requests.
+	streamer := &eventStreamer{
+		hub:          r.eventHub,
+		commitlogSvc: r.commitlogSvc,
+		enricher:     enricher,
+		logger:       r.logger,
+	}
+
+	eventsRead := newRouteGroup(e, routes, ipLimit, queryToken(r.verifier), authMiddleware, userMiddleware, rateLimit).
+		Scopes(account.ScopeProjectsRead)
+
+	eventsRead.GET("/projects/:id/events", streamer.Serve)
+
+	logCommit.POST("/projects/:id/log", func(c echo.Context) error {
 		ctx := c.Request().Context()
 		account := c.Get("user.id").(string)
 
@@ -473,22 +1204,22 @@ func main() {
 		req.Project = c.Param("id")
 		req.Author = account
 
-		_, err = commitlogSvc.Commit(ctx, &req)
+		_, err = r.commitlogSvc.Commit(ctx, &req)
 		if err != nil {
 			return err
 		}
 
 		return c.NoContent(http.StatusOK)
-	}, authMiddleware, userMiddleware)
+	})
 
 	// Get data about a node.
-	e.GET("/projects/:project/nodes/:node", func(c echo.Context) error {
+	projectsRead.GET("/projects/:project/nodes/:node", func(c echo.Context) error {
 		ctx := c.Request().Context()
 
 		project := c.Param("project")
 		node := c.Param("node")
 
-		rep, err := nodeSvc.Get(ctx, &nodes.GetRequest{
+		rep, err := r.nodeSvc.Get(ctx, &nodes.GetRequest{
 			Project: project,
 			Id:      node,
 		})
@@ -497,15 +1228,10 @@ func main() {
 		}
 
 		return c.JSON(http.StatusOK, rep)
-	}, authMiddleware, userMiddleware)
-
-	type nodeData struct {
-		Title *string
-		Notes *string
-	}
+	})
 
 	// Update data about a node.
-	e.PUT("/projects/:project/nodes/:node", func(c echo.Context) error {
+	projectsWrite.PUT("/projects/:project/nodes/:node", func(c echo.Context) error {
 		var data nodeData
 		if err := c.Bind(&data); err != nil {
 			return err
@@ -518,7 +1244,7 @@ func main() {
 		node := c.Param("node")
 
 		if data.Title != nil {
-			_, err := nodeSvc.SetTitle(ctx, &nodes.SetTitleRequest{
+			_, err := r.nodeSvc.SetTitle(ctx, &nodes.SetTitleRequest{
 				Project: project,
 				Id:      node,
 				Title:   *data.Title,
@@ -530,7 +1256,7 @@ func main() {
 		}
 
 		if data.Notes != nil {
-			_, err := nodeSvc.SetNotes(ctx, &nodes.SetNotesRequest{
+			_, err := r.nodeSvc.SetNotes(ctx, &nodes.SetNotesRequest{
 				Project: project,
 				Id:      node,
 				Notes:   *data.Notes,
@@ -542,72 +1268,183 @@ func main() {
 		}
 
 		return c.NoContent(http.StatusOK)
-	}, authMiddleware, userMiddleware)
+	})
+
+	// Upload files and associate them to the node.
+	uploader := &multipartUpload{
+		enricher:    enricher,
+		dataSvc:     r.dataSvc,
+		nodeSvc:     r.nodeSvc,
+		concurrency: uploadConcurrency,
+	}
+
+	filesWrite.POST("/projects/:project/nodes/:node/upload", uploader.serve)
+
+	// Start direct uploads. A signed URL is returned for each declared file
+	// which the client PUTs the file contents to.
+	filesWrite.POST("/projects/:project/nodes/:node/uploads", func(c echo.Context) error {
+		var body struct {
+			Files []*uploadFile `json:"files"`
+		}
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
+
+		if len(body.Files) == 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "no files specified")
+		}
+
+		for _, f := range body.Files {
+			if f.Name == "" {
+				return echo.NewHTTPError(http.StatusBadRequest, "file name required")
+			}
+		}
 
-	// Upload files and associate it to the node.
-	e.POST("/projects/:project/nodes/:node/upload", func(c echo.Context) error {
 		ctx := c.Request().Context()
 
 		account := c.Get("user.id").(string)
 		project := c.Param("project")
 		node := c.Param("node")
 
-		_, err := nodeSvc.Get(ctx, &nodes.GetRequest{
-			Project: project,
-			Id:      node,
-		})
+		canView, err := enricher.CanViewProject(ctx, project, account)
 		if err != nil {
-			return err
+			return c.String(http.StatusServiceUnavailable, err.Error())
+		}
+		if !canView {
+			return c.NoContent(http.StatusNotFound)
 		}
 
-		form, err := c.MultipartForm()
+		_, err = r.nodeSvc.Get(ctx, &nodes.GetRequest{
+			Project: project,
+			Id:      node,
+		})
 		if err != nil {
 			return err
 		}
 
-		var files []*nodes.File
+		targets := make([]*uploadTarget, len(body.Files))
 
-		// TODO: parallelize.
-		for _, f := range form.File["files"] {
-			src, err := f.Open()
+		for i, f := range body.Files {
+			rep, err := r.dataSvc.Upload(ctx, &data.UploadRequest{
+				Account:   account,
+				Project:   project,
+				Size:      f.Size,
+				Mediatype: f.Mediatype,
+				Name:      f.Name,
+			})
 			if err != nil {
 				return err
 			}
-			defer src.Close()
 
-			// Use client helper function to upload.
-			id, err := data.Upload(ctx, dataSvc, "", src)
-			if err != nil {
-				return err
+			targets[i] = &uploadTarget{
+				Name:      f.Name,
+				ID:        rep.Id,
+				SignedURL: rep.SignedUrl,
 			}
+		}
 
-			files = append(files, &nodes.File{
-				Id:   id,
-				Name: f.Filename,
-			})
+		return c.JSON(http.StatusCreated, targets)
+	})
+
+	// Complete a direct upload under the name it was declared with. The
+	// file is verified in storage asynchronously and added to the node
+	// once its state is DONE. A file that fails the verification is never
+	// added.
+	filesWrite.POST("/projects/:project/nodes/:node/uploads/:id/complete", func(c echo.Context) error {
+		var body struct {
+			Name string `json:"name"`
+			Hash string `json:"hash"`
+		}
+		if err := c.Bind(&body); err != nil {
+			return err
 		}
 
-		_, err = nodeSvc.AddFiles(ctx, &nodes.AddFilesRequest{
+		if body.Name == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "name required")
+		}
+
+		ctx := c.Request().Context()
+
+		account := c.Get("user.id").(string)
+		project := c.Param("project")
+		node := c.Param("node")
+		id := c.Param("id")
+
+		canView, err := enricher.CanViewProject(ctx, project, account)
+		if err != nil {
+			return c.String(http.StatusServiceUnavailable, err.Error())
+		}
+		if !canView {
+			return c.NoContent(http.StatusNotFound)
+		}
+
+		_, err = r.nodeSvc.Get(ctx, &nodes.GetRequest{
 			Project: project,
 			Id:      node,
-			Files:   files,
+		})
+		if err != nil {
+			return err
+		}
+
+		rep, err := r.dataSvc.Complete(ctx, &data.CompleteRequest{
+			Id:      id,
 			Account: account,
+			Project: project,
+			Hash:    body.Hash,
+			Node:    node,
+			Name:    body.Name,
 		})
 		if err != nil {
 			return err
 		}
 
-		return c.NoContent(http.StatusOK)
-	}, authMiddleware, userMiddleware)
+		return c.JSON(http.StatusAccepted, map[string]interface{}{
+			"id":    id,
+			"name":  body.Name,
+			"state": rep.State,
+		})
+	})
+
+	downloader := &fileDownload{
+		logger:   r.logger,
+		dataSvc:  r.dataSvc,
+		storage:  r.storage,
+		redirect: r.downloadRedirect,
+	}
+
+	// projectFile checks the user can view the project and confirms the
+	// file is associated with it. The reply provides the local name of
+	// the file.
+	projectFile := func(c echo.Context) (*nodes.GetFileReply, error) {
+		ctx := c.Request().Context()
+
+		project := c.Param("project")
+
+		canView, err := enricher.CanViewProject(ctx, project, c.Get("user.id").(string))
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+		}
+		if !canView {
+			return nil, echo.NewHTTPError(http.StatusNotFound)
+		}
+
+		return r.nodeSvc.GetFile(ctx, &nodes.GetFileRequest{
+			Project: project,
+			Id:      c.Param("file"),
+		})
+	}
 
 	// Get details about a file.
-	e.GET("/projects/:project/files/:file", func(c echo.Context) error {
+	projectsRead.GET("/projects/:project/files/:file", func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		//project := c.Param("project")
 		file := c.Param("file")
 
-		rep, err := dataSvc.Describe(ctx, &data.DescribeRequest{
+		if _, err := projectFile(c); err != nil {
+			return err
+		}
+
+		rep, err := r.dataSvc.Describe(ctx, &data.DescribeRequest{
 			Id: file,
 		})
 		if err != nil {
@@ -615,16 +1452,19 @@ func main() {
 		}
 
 		return c.JSON(http.StatusOK, rep)
-	}, authMiddleware, userMiddleware)
+	})
 
 	// Get a signed url for a client requested download.
-	e.GET("/projects/:project/files/:file/url", func(c echo.Context) error {
+	filesRead.GET("/projects/:project/files/:file/url", func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		//project := c.Param("project")
 		file := c.Param("file")
 
-		rep, err := dataSvc.Get(ctx, &data.GetRequest{
+		if _, err := projectFile(c); err != nil {
+			return err
+		}
+
+		rep, err := r.dataSvc.Get(ctx, &data.GetRequest{
 			Id: file,
 		})
 		if err != nil {
@@ -632,41 +1472,302 @@ func main() {
 		}
 
 		return c.JSON(http.StatusOK, rep)
-	}, authMiddleware, userMiddleware)
+	})
 
-	// Download a file.
-	e.GET("/projects/:project/files/:file/download", func(c echo.Context) error {
+	// Download a file. Range and conditional requests are supported. If
+	// redirect is enabled, the client is redirected to the signed URL instead.
+	filesRead.GET("/projects/:project/files/:file/download", func(c echo.Context) error {
+		frep, err := projectFile(c)
+		if err != nil {
+			return err
+		}
+
+		return downloader.serve(c, frep.File.Name)
+	})
+
+	// Download all files of a node as a zip or tar.gz archive.
+	filesRead.GET("/projects/:project/nodes/:node/archive", func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		// TODO: check association to project/permission
-		//project := c.Param("project")
-		file := c.Param("file")
+		project := c.Param("project")
 
-		rep, err := dataSvc.Get(ctx, &data.GetRequest{
-			Id: file,
+		canView, err := enricher.CanViewProject(ctx, project, c.Get("user.id").(string))
+		if err != nil {
+			return c.String(http.StatusServiceUnavailable, err.Error())
+		}
+		if !canView {
+			return c.NoContent(http.StatusNotFound)
+		}
+
+		b := newArchiveBuilder(r.dataSvc, r.nodeSvc, r.storage)
+		if err := b.addNode(ctx, project, c.Param("node")); err != nil {
+			return err
+		}
+
+		return serveArchive(c, b, b.manifest.Nodes[0].Path)
+	})
+
+	// Download all files of the project workflow as a zip or tar.gz archive.
+	filesRead.GET("/projects/:id/archive", func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		rep, err := r.projectSvc.GetProject(ctx, &project.GetProjectRequest{
+			Id:      c.Param("id"),
+			Account: c.Get("user.id").(string),
+		})
+		if err != nil {
+			return err
+		}
+
+		b := newArchiveBuilder(r.dataSvc, r.nodeSvc, r.storage)
+		if err := b.addProject(ctx, rep.Project); err != nil {
+			return err
+		}
+
+		return serveArchive(c, b, rep.Project.Name)
+	})
+
+	// Start an export of the project as a BagIt bag containing an RO-Crate.
+	projectsWrite.POST("/projects/:id/exports", func(c echo.Context) error {
+		rep, err := r.exportSvc.Create(c.Request().Context(), &export.CreateRequest{
+			Project: c.Param("id"),
+			Account: c.Get("user.id").(string),
 		})
 		if err != nil {
 			return err
 		}
 
-		resp, err := http.Get(rep.SignedUrl)
+		return c.JSON(http.StatusAccepted, rep.Job)
+	})
+
+	// Get the status of an export.
+	projectsRead.GET("/projects/:id/exports/:export", func(c echo.Context) error {
+		rep, err := r.exportSvc.Get(c.Request().Context(), &export.GetRequest{
+			Id:      c.Param("export"),
+			Account: c.Get("user.id").(string),
+		})
+		if err != nil {
+			return err
+		}
+
+		if rep.Job.Kind != export.Kind_EXPORT || rep.Job.Project != c.Param("id") {
+			return c.NoContent(http.StatusNotFound)
+		}
+
+		return c.JSON(http.StatusOK, rep.Job)
+	})
+
+	// Download a completed export.
+	filesRead.GET("/projects/:id/exports/:export/download", func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		rep, err := r.exportSvc.Get(ctx, &export.GetRequest{
+			Id:      c.Param("export"),
+			Account: c.Get("user.id").(string),
+		})
 		if err != nil {
 			return err
 		}
-		defer resp.Body.Close()
 
-		contentType := echo.MIMEOctetStream
-		if rep.Mediatype != "" {
-			contentType = rep.Mediatype
+		job := rep.Job
+		if job.Kind != export.Kind_EXPORT || job.Project != c.Param("id") {
+			return c.NoContent(http.StatusNotFound)
 		}
 
-		c.Response().Header().Set("content-disposition", fmt.Sprintf(`attachment; filename="%s"`, file))
+		if job.State != export.State_DONE {
+			return echo.NewHTTPError(http.StatusConflict, "export is not done")
+		}
 
-		return c.Stream(http.StatusOK, contentType, resp.Body)
+		grep, err := r.dataSvc.Get(ctx, &data.GetRequest{
+			Id:                 job.File,
+			ContentDisposition: contentDisposition(fmt.Sprintf("export-%s.zip", job.Id)),
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.Redirect(http.StatusFound, grep.SignedUrl)
+	})
+
+	// Import a project from a package produced by an export. The body
+	// is the zipped package. The package is stored as an artifact, so it
+	// does not count towards the quotas the imported files count towards,
+	// and is removed once the import has finished.
+	importsWrite.POST("/imports", func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		account := c.Get("user.id").(string)
+
+		history := false
+		if v := c.QueryParam("history"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "history must be a boolean")
+			}
+			history = b
+		}
+
+		var size int64
+		if c.Request().ContentLength > 0 {
+			size = c.Request().ContentLength
+		}
+
+		file, err := data.Upload(ctx, r.dataSvc, &data.UploadRequest{
+			Account:   account,
+			Size:      size,
+			Mediatype: "application/zip",
+			Artifact:  true,
+			Expires:   time.Now().Add(export.ImportRetention).Unix(),
+		}, "application/zip", c.Request().Body)
+		if err != nil {
+			return err
+		}
+
+		rep, err := r.exportSvc.Import(ctx, &export.ImportRequest{
+			Account: account,
+			File:    file,
+			Name:    c.QueryParam("name"),
+			History: history,
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusAccepted, rep.Job)
+	})
+
+	// Get the status of an import.
+	projectsRead.GET("/imports/:import", func(c echo.Context) error {
+		rep, err := r.exportSvc.Get(c.Request().Context(), &export.GetRequest{
+			Id:      c.Param("import"),
+			Account: c.Get("user.id").(string),
+		})
+		if err != nil {
+			return err
+		}
+
+		if rep.Job.Kind != export.Kind_IMPORT {
+			return c.NoContent(http.StatusNotFound)
+		}
+
+		return c.JSON(http.StatusOK, rep.Job)
+	})
+
+	// Webhooks of a project. Only the owner of the project can manage them.
+	projectsRead.GET("/projects/:id/webhooks", func(c echo.Context) error {
+		rep, err := r.webhookSvc.List(c.Request().Context(), &webhook.ListRequest{
+			Project: c.Param("id"),
+			Account: c.Get("user.id").(string),
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, rep.Webhooks)
+	})
+
+	// Register a webhook. The secret of the signatures is only returned
+	// in this response.
+	projectsWrite.POST("/projects/:id/webhooks", func(c echo.Context) error {
+		var req webhook.CreateRequest
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+
+		req.Project = c.Param("id")
+		req.Account = c.Get("user.id").(string)
+
+		rep, err := r.webhookSvc.Create(c.Request().Context(), &req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, rep)
+	})
+
+	projectsRead.GET("/projects/:id/webhooks/:webhook", func(c echo.Context) error {
+		rep, err := r.webhookSvc.Get(c.Request().Context(), &webhook.GetRequest{
+			Id:      c.Param("webhook"),
+			Project: c.Param("id"),
+			Account: c.Get("user.id").(string),
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, rep.Webhook)
+	})
+
+	// Update a webhook. Only the fields present are changed. The secret is
+	// returned if it was rotated.
+	projectsWrite.PUT("/projects/:id/webhooks/:webhook", func(c echo.Context) error {
+		var body webhookUpdate
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
+
+		ctx := c.Request().Context()
+		account := c.Get("user.id").(string)
+
+		cur, err := r.webhookSvc.Get(ctx, &webhook.GetRequest{
+			Id:      c.Param("webhook"),
+			Project: c.Param("id"),
+			Account: account,
+		})
+		if err != nil {
+			return err
+		}
+
+		req := body.apply(cur.Webhook)
+		req.Account = account
+
+		rep, err := r.webhookSvc.Update(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, rep)
+	})
+
+	projectsWrite.DELETE("/projects/:id/webhooks/:webhook", func(c echo.Context) error {
+		_, err := r.webhookSvc.Delete(c.Request().Context(), &webhook.DeleteRequest{
+			Id:      c.Param("webhook"),
+			Project: c.Param("id"),
+			Account: c.Get("user.id").(string),
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	// Latest deliveries of a webhook.
+	projectsRead.GET("/projects/:id/webhooks/:webhook/deliveries", func(c echo.Context) error {
+		req := webhook.DeliveriesRequest{
+			Webhook: c.Param("webhook"),
+			Project: c.Param("id"),
+			Account: c.Get("user.id").(string),
+		}
+
+		if v := c.QueryParam("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return c.String(http.StatusBadRequest, "invalid limit")
+			}
+			req.Limit = int32(n)
+		}
+
+		rep, err := r.webhookSvc.Deliveries(c.Request().Context(), &req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, rep.Deliveries)
 	})
 
 	// Remove a file from a node.
-	e.DELETE("/projects/:project/nodes/:node/files/:file", func(c echo.Context) error {
+	filesWrite.DELETE("/projects/:project/nodes/:node/files/:file", func(c echo.Context) error {
 		ctx := c.Request().Context()
 
 		account := c.Get("user.id").(string)
@@ -674,7 +1775,7 @@ func main() {
 		node := c.Param("node")
 		file := c.Param("file")
 
-		_, err := nodeSvc.RemoveFiles(ctx, &nodes.RemoveFilesRequest{
+		_, err := r.nodeSvc.RemoveFiles(ctx, &nodes.RemoveFilesRequest{
 			Project: project,
 			Id:      node,
 			Account: account,
@@ -687,17 +1788,7 @@ func main() {
 		}
 
 		return c.NoContent(http.StatusOK)
-	}, authMiddleware, userMiddleware)
-
-	// Gracefully serve.
-	logger.Info("listening",
-		zap.String("http.addr", httpAddr),
-	)
+	})
 
-	e.Server.Addr = httpAddr
-	if err := graceful.ListenAndServe(e.Server, 5*time.Second); err != nil {
-		logger.Error("http error",
-			zap.Error(err),
-		)
-	}
+	return e, routes, nil
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"net/http"
 	"strconv"
 	"time"
 
@@ -15,6 +16,9 @@ var (
 	traceIdKey   = "trace.id"
 	userIdKey    = "user.id"
 	userEmailKey = "user.email"
+
+	tokenIdKey     = "token.id"
+	tokenScopesKey = "token.scopes"
 )
 
 func TraceMiddleware() echo.MiddlewareFunc {
@@ -26,28 +30,43 @@ func TraceMiddleware() echo.MiddlewareFunc {
 	}
 }
 
-func AccountMiddleware(accounts account.ServiceClient) echo.MiddlewareFunc {
+// AccountMiddleware resolves the account of the token. Tokens minted by the
+// gateway identify the account by its id, others by the linked identity.
+func AccountMiddleware(accounts account.ServiceClient, issuer string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			if token, ok := c.Get("user").(*jwt.Token); ok {
-				// Get the user's email address from the JWT
-				var email string
+				// Get the user's identity from the JWT
+				var iss, subject string
 
 				if claims, ok := token.Claims.(jwt.MapClaims); ok {
-					email, _ = claims["email"].(string)
+					iss, _ = claims["iss"].(string)
+					subject, _ = claims["sub"].(string)
+				}
+
+				// The handlers rely on the account being set.
+				if iss == "" || subject == "" {
+					return echo.NewHTTPError(http.StatusUnauthorized, "token must include iss and sub")
 				}
 
-				if email != "" {
-					rep, err := accounts.GetUser(c.Request().Context(), &account.GetUserRequest{
-						Email: email,
-					})
-					if err != nil {
-						return err
+				req := &account.GetUserRequest{
+					Issuer:  iss,
+					Subject: subject,
+				}
+
+				if iss == issuer {
+					req = &account.GetUserRequest{
+						Id: subject,
 					}
+				}
 
-					c.Set(userIdKey, rep.Id)
-					c.Set(userEmailKey, rep.Email)
+				rep, err := accounts.GetUser(c.Request().Context(), req)
+				if err != nil {
+					return err
 				}
+
+				c.Set(userIdKey, rep.Id)
+				c.Set(userEmailKey, rep.Email)
 			}
 
 			return next(c)
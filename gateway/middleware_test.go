@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+	"github.com/rdm-academy/api/account"
+)
+
+func TestAccountMiddleware(t *testing.T) {
+	accounts := &fakeAccounts{
+		users: map[string]*account.GetUserResponse{
+			"u1":                         {Id: "u1"},
+			"https://idp.example.com|s1": {Id: "u2"},
+		},
+	}
+
+	mw := AccountMiddleware(accounts, "rdm-academy")
+
+	for _, x := range []struct {
+		name   string
+		claims jwt.MapClaims
+		code   int
+		user   string
+	}{
+		{"gateway token", jwt.MapClaims{"iss": "rdm-academy", "sub": "u1"}, http.StatusOK, "u1"},
+		{"identity", jwt.MapClaims{"iss": "https://idp.example.com", "sub": "s1"}, http.StatusOK, "u2"},
+		{"no issuer", jwt.MapClaims{"sub": "u1"}, http.StatusUnauthorized, ""},
+		{"no subject", jwt.MapClaims{"iss": "rdm-academy"}, http.StatusUnauthorized, ""},
+		{"empty subject", jwt.MapClaims{"iss": "rdm-academy", "sub": ""}, http.StatusUnauthorized, ""},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+		c.Set("user", &jwt.Token{Claims: x.claims})
+
+		var called bool
+		err := mw(func(c echo.Context) error {
+			called = true
+			return nil
+		})(c)
+
+		code := http.StatusOK
+		if he, ok := err.(*echo.HTTPError); ok {
+			code = he.Code
+		} else if err != nil {
+			t.Fatalf("%s: %s", x.name, err)
+		}
+
+		if code != x.code {
+			t.Errorf("%s: expected %d, got %d", x.name, x.code, code)
+		}
+		if ok := x.code == http.StatusOK; called != ok {
+			t.Errorf("%s: expected called to be %t", x.name, ok)
+		}
+
+		user, _ := c.Get(userIdKey).(string)
+		if user != x.user {
+			t.Errorf("%s: expected user %q, got %q", x.name, x.user, user)
+		}
+	}
+}
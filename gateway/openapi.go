@@ -0,0 +1,518 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/rdm-academy/api/account"
+	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/export"
+	"github.com/rdm-academy/api/nodes"
+	"github.com/rdm-academy/api/project"
+	"github.com/rdm-academy/api/webhook"
+)
+
+// apiQuery is a query parameter of a route.
+type apiQuery struct {
+	name        string
+	typ         string
+	description string
+}
+
+// apiRoute describes a route for the OpenAPI document. The body and
+// response are values of the types that are bound and returned, so the
+// schemas are derived from the protobuf messages.
+type apiRoute struct {
+	method  string
+	path    string
+	tag     string
+	summary string
+
+	// Public routes do not require authentication. Otherwise the scopes
+	// a restricted token must grant, if any.
+	public bool
+	scopes []string
+
+	query []apiQuery
+
+	// JSON body, or a raw body of the body type.
+	body     interface{}
+	bodyType string
+
+	// Body fields that are set from the path or the requesting user and
+	// are not part of the request.
+	ignore []string
+
+	status       int
+	response     interface{}
+	responseType string
+}
+
+var (
+	archiveQuery = apiQuery{"format", "string", "Archive format, zip (default) or tar.gz."}
+)
+
+// apiRoutes lists the routes registered in main. The test ensures every
+// registered route is listed.
+var apiRoutes = []*apiRoute{
+	{method: "GET", path: "/healthz", tag: "service", summary: "Check if the gateway is healthy.", public: true, status: http.StatusOK},
+	{method: "GET", path: "/readyz", tag: "service", summary: "Check if the gateway is connected to NATS.", public: true, status: http.StatusOK},
+	{method: "GET", path: "/metrics", tag: "service", summary: "Metrics in the Prometheus text format.", public: true, status: http.StatusOK, responseType: "text/plain"},
+	{method: "GET", path: "/openapi.json", tag: "service", summary: "This document.", public: true, status: http.StatusOK, response: map[string]interface{}{}},
+
+	{method: "GET", path: "/auth/providers", tag: "auth", summary: "List the configured login providers.", public: true, status: http.StatusOK, response: []string{}},
+	{method: "GET", path: "/auth/:provider/login", tag: "auth", summary: "Redirect to the login of the provider.", public: true, status: http.StatusFound},
+	{method: "GET", path: "/auth/:provider/callback", tag: "auth", summary: "Complete the login and issue tokens. Redirects to the success URL if configured.", public: true, status: http.StatusOK, response: &authTokens{}},
+	{method: "POST", path: "/auth/token", tag: "auth", summary: "Issue new tokens for a refresh token.", public: true, body: &struct {
+		RefreshToken string `json:"refresh_token"`
+	}{}, status: http.StatusOK, response: &authTokens{}},
+
+	{method: "PUT", path: "/account", tag: "account", summary: "Register the account of the identity of the JWT and optionally update the profile. Returns 201 if the account was created.", body: &profileUpdate{}, status: http.StatusOK, response: &account.GetUserResponse{}},
+	{method: "GET", path: "/account", tag: "account", summary: "Get the account.", status: http.StatusOK, response: &account.GetUserResponse{}},
+	{method: "DELETE", path: "/account", tag: "account", summary: "Delete the account along with the owned projects and files.", scopes: []string{account.ScopeAccountAdmin}, status: http.StatusOK},
+	{method: "POST", path: "/account/email", tag: "account", summary: "Start a change of the email address.", scopes: []string{account.ScopeAccountAdmin}, body: &struct {
+		Email string `json:"email"`
+	}{}, status: http.StatusAccepted, response: &struct {
+		Expires int64 `json:"expires"`
+	}{}},
+	{method: "POST", path: "/account/email/confirm", tag: "account", summary: "Confirm a change of the email address.", scopes: []string{account.ScopeAccountAdmin}, body: &struct {
+		Token string `json:"token"`
+	}{}, status: http.StatusOK, response: &account.ConfirmEmailChangeResponse{}},
+	{method: "GET", path: "/account/orcid/authorize", tag: "account", summary: "Get the ORCID authorization URL to verify the ORCID iD.", scopes: []string{account.ScopeAccountAdmin}, status: http.StatusOK, response: &struct {
+		URL string `json:"url"`
+	}{}},
+	{method: "POST", path: "/account/orcid", tag: "account", summary: "Complete the verification of the ORCID iD.", scopes: []string{account.ScopeAccountAdmin}, body: &struct {
+		Code  string `json:"code"`
+		State string `json:"state"`
+	}{}, status: http.StatusOK, response: &account.GetUserResponse{}},
+	{method: "GET", path: "/account/tokens", tag: "account", summary: "List the personal access tokens.", scopes: []string{account.ScopeAccountAdmin}, status: http.StatusOK, response: []*account.Token{}},
+	{method: "POST", path: "/account/tokens", tag: "account", summary: "Create a personal access token. Requires a JWT.", scopes: []string{account.ScopeAccountAdmin}, body: &struct {
+		Name      string   `json:"name"`
+		Scopes    []string `json:"scopes"`
+		ExpiresIn int64    `json:"expires_in"`
+	}{}, status: http.StatusCreated, response: &account.CreateTokenResponse{}},
+	{method: "DELETE", path: "/account/tokens/:id", tag: "account", summary: "Revoke a personal access token.", scopes: []string{account.ScopeAccountAdmin}, status: http.StatusNoContent},
+	{method: "GET", path: "/account/identities", tag: "account", summary: "List the linked identities.", scopes: []string{account.ScopeAccountAdmin}, status: http.StatusOK, response: []*account.Identity{}},
+	{method: "POST", path: "/account/identities", tag: "account", summary: "Link the identity of a token issued by an identity provider. Requires a JWT.", scopes: []string{account.ScopeAccountAdmin}, body: &struct {
+		Token string `json:"token"`
+	}{}, status: http.StatusNoContent},
+	{method: "DELETE", path: "/account/identities", tag: "account", summary: "Unlink an identity. Requires a JWT.", scopes: []string{account.ScopeAccountAdmin}, query: []apiQuery{
+		{"issuer", "string", "Issuer of the identity."},
+		{"subject", "string", "Subject of the identity."},
+	}, status: http.StatusNoContent},
+	{method: "GET", path: "/account/export", tag: "account", summary: "Download an archive of everything the account owns.", scopes: []string{account.ScopeAccountAdmin}, status: http.StatusOK, responseType: "application/zip"},
+	{method: "GET", path: "/account/usage", tag: "account", summary: "Get the storage usage of the account.", status: http.StatusOK, response: &data.UsageReply{}},
+
+	{method: "POST", path: "/graphql", tag: "graphql", summary: "Run a GraphQL query over the account and the projects.", scopes: []string{account.ScopeProjectsRead}, body: &graphqlParams{}, status: http.StatusOK, response: &struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors,omitempty"`
+	}{}},
+
+	{method: "POST", path: "/projects", tag: "projects", summary: "Create a project.", scopes: []string{account.ScopeProjectsWrite}, body: &project.CreateProjectRequest{}, ignore: []string{"account"}, status: http.StatusCreated, response: &project.Project{}},
+	{method: "PUT", path: "/projects/:id", tag: "projects", summary: "Update a project.", scopes: []string{account.ScopeProjectsWrite}, body: &project.UpdateProjectRequest{}, ignore: []string{"id", "account"}, status: http.StatusOK},
+	{method: "PUT", path: "/projects/:id/workflow", tag: "projects", summary: "Update the workflow of a project.", scopes: []string{account.ScopeProjectsWrite}, body: &project.UpdateWorkflowRequest{}, ignore: []string{"id", "account"}, status: http.StatusOK},
+	{method: "GET", path: "/projects/trash", tag: "projects", summary: "List the projects in the trash.", scopes: []string{account.ScopeProjectsRead}, status: http.StatusOK, response: []*project.Project{}},
+	{method: "POST", path: "/projects/:id/restore", tag: "projects", summary: "Restore a project from the trash.", scopes: []string{account.ScopeProjectsWrite}, status: http.StatusOK},
+	{method: "POST", path: "/projects/:id/fork", tag: "projects", summary: "Fork a project.", scopes: []string{account.ScopeProjectsWrite}, body: &project.ForkProjectRequest{}, ignore: []string{"id", "account"}, status: http.StatusCreated, response: &project.Project{}},
+	{method: "DELETE", path: "/projects/:id", tag: "projects", summary: "Move a project to the trash.", scopes: []string{account.ScopeProjectsWrite}, status: http.StatusOK},
+	{method: "GET", path: "/projects", tag: "projects", summary: "List the projects.", scopes: []string{account.ScopeProjectsRead}, status: http.StatusOK, response: []*project.Project{}},
+	{method: "GET", path: "/projects/:id", tag: "projects", summary: "Get a project.", scopes: []string{account.ScopeProjectsRead}, status: http.StatusOK, response: &project.Project{}},
+	{method: "GET", path: "/projects/:id/log", tag: "log", summary: "Get the commits of a project.", scopes: []string{account.ScopeProjectsRead}, status: http.StatusOK, response: []*apiCommit{}},
+	{method: "GET", path: "/projects/:id/log/pending", tag: "log", summary: "Get the events pending the next commit.", scopes: []string{account.ScopeProjectsRead}, status: http.StatusOK, response: []*apiEvent{}},
+	{method: "GET", path: "/projects/:id/events", tag: "log", summary: "Stream the events of a project as server-sent events, or over a WebSocket if the connection is upgraded. Resumes after the Last-Event-ID header.", scopes: []string{account.ScopeProjectsRead}, query: []apiQuery{
+		{"last_event_id", "string", "Id of the last event received, if the Last-Event-ID header cannot be set."},
+		{"access_token", "string", "Access token, if the Authorization header cannot be set."},
+	}, status: http.StatusOK, responseType: "text/event-stream"},
+	{method: "POST", path: "/projects/:id/log", tag: "log", summary: "Commit the pending events.", scopes: []string{account.ScopeLogCommit}, body: &commitlog.CommitRequest{}, ignore: []string{"project", "author"}, status: http.StatusOK},
+
+	{method: "GET", path: "/projects/:project/nodes/:node", tag: "nodes", summary: "Get a node.", scopes: []string{account.ScopeProjectsRead}, status: http.StatusOK, response: &nodes.GetReply{}},
+	{method: "PUT", path: "/projects/:project/nodes/:node", tag: "nodes", summary: "Update the title and notes of a node.", scopes: []string{account.ScopeProjectsWrite}, body: &nodeData{}, status: http.StatusOK},
+	{method: "POST", path: "/projects/:project/nodes/:node/upload", tag: "files", summary: "Upload the files of the multipart form field files to a node.", scopes: []string{account.ScopeFilesWrite}, bodyType: "multipart/form-data", status: http.StatusOK, response: []*uploadResult{}},
+	{method: "POST", path: "/projects/:project/nodes/:node/uploads", tag: "files", summary: "Start direct uploads to signed URLs.", scopes: []string{account.ScopeFilesWrite}, body: &struct {
+		Files []*uploadFile `json:"files"`
+	}{}, status: http.StatusCreated, response: []*uploadTarget{}},
+	{method: "POST", path: "/projects/:project/nodes/:node/uploads/:id/complete", tag: "files", summary: "Complete a direct upload with the name it was declared with. The file is verified asynchronously and added to the node once it is done.", scopes: []string{account.ScopeFilesWrite}, body: &struct {
+		Name string `json:"name"`
+		Hash string `json:"hash"`
+	}{}, status: http.StatusAccepted, response: &struct {
+		ID    string     `json:"id"`
+		Name  string     `json:"name"`
+		State data.State `json:"state"`
+	}{}},
+	{method: "DELETE", path: "/projects/:project/nodes/:node/files/:file", tag: "files", summary: "Remove a file from a node.", scopes: []string{account.ScopeFilesWrite}, status: http.StatusOK},
+	{method: "GET", path: "/projects/:project/nodes/:node/archive", tag: "files", summary: "Download the files of a node as an archive.", scopes: []string{account.ScopeFilesRead}, query: []apiQuery{archiveQuery}, status: http.StatusOK, responseType: "application/octet-stream"},
+	{method: "GET", path: "/projects/:project/files/:file", tag: "files", summary: "Describe a file.", scopes: []string{account.ScopeProjectsRead}, status: http.StatusOK, response: &data.DescribeReply{}},
+	{method: "GET", path: "/projects/:project/files/:file/url", tag: "files", summary: "Get a signed download URL of a file.", scopes: []string{account.ScopeFilesRead}, status: http.StatusOK, response: &data.GetReply{}},
+	{method: "GET", path: "/projects/:project/files/:file/download", tag: "files", summary: "Download a file. Range and conditional requests are supported.", scopes: []string{account.ScopeFilesRead}, query: []apiQuery{
+		{"redirect", "boolean", "Redirect to the signed URL instead of proxying the file."},
+	}, status: http.StatusOK, responseType: "application/octet-stream"},
+	{method: "GET", path: "/projects/:id/archive", tag: "files", summary: "Download the files of a project as an archive.", scopes: []string{account.ScopeFilesRead}, query: []apiQuery{archiveQuery}, status: http.StatusOK, responseType: "application/octet-stream"},
+
+	{method: "POST", path: "/projects/:id/exports", tag: "exports", summary: "Start an export of a project.", scopes: []string{account.ScopeProjectsWrite}, status: http.StatusAccepted, response: &export.Job{}},
+	{method: "GET", path: "/projects/:id/exports/:export", tag: "exports", summary: "Get an export.", scopes: []string{account.ScopeProjectsRead}, status: http.StatusOK, response: &export.Job{}},
+	{method: "GET", path: "/projects/:id/exports/:export/download", tag: "exports", summary: "Redirect to the package of a completed export.", scopes: []string{account.ScopeFilesRead}, status: http.StatusFound},
+	{method: "POST", path: "/imports", tag: "exports", summary: "Import a project from an export package.", scopes: []string{account.ScopeProjectsWrite, account.ScopeFilesWrite}, query: []apiQuery{
+		{"name", "string", "Name of the project. Defaults to the name in the package."},
+		{"history", "boolean", "Replay the commit history."},
+	}, bodyType: "application/zip", status: http.StatusAccepted, response: &export.Job{}},
+	{method: "GET", path: "/imports/:import", tag: "exports", summary: "Get an import.", scopes: []string{account.ScopeProjectsRead}, status: http.StatusOK, response: &export.Job{}},
+
+	{method: "GET", path: "/projects/:id/webhooks", tag: "webhooks", summary: "List the webhooks of a project. Requires ownership of the project.", scopes: []string{account.ScopeProjectsRead}, status: http.StatusOK, response: []*webhook.Webhook{}},
+	{method: "POST", path: "/projects/:id/webhooks", tag: "webhooks", summary: "Register a webhook. The response has the secret of the signatures.", scopes: []string{account.ScopeProjectsWrite}, body: &webhook.CreateRequest{}, ignore: []string{"account", "project"}, status: http.StatusCreated, response: &webhook.CreateReply{}},
+	{method: "GET", path: "/projects/:id/webhooks/:webhook", tag: "webhooks", summary: "Get a webhook.", scopes: []string{account.ScopeProjectsRead}, status: http.StatusOK, response: &webhook.Webhook{}},
+	{method: "PUT", path: "/projects/:id/webhooks/:webhook", tag: "webhooks", summary: "Update the fields of a webhook that are present and optionally rotate its secret.", scopes: []string{account.ScopeProjectsWrite}, body: &webhookUpdate{}, status: http.StatusOK, response: &webhook.UpdateReply{}},
+	{method: "DELETE", path: "/projects/:id/webhooks/:webhook", tag: "webhooks", summary: "Remove a webhook and its deliveries.", scopes: []string{account.ScopeProjectsWrite}, status: http.StatusNoContent},
+	{method: "GET", path: "/projects/:id/webhooks/:webhook/deliveries", tag: "webhooks", summary: "List the latest deliveries of a webhook.", scopes: []string{account.ScopeProjectsRead}, query: []apiQuery{
+		{"limit", "integer", "Maximum number of deliveries. Defaults to 50."},
+	}, status: http.StatusOK, response: []*webhook.Delivery{}},
+}
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// schemaBuilder derives JSON schemas from Go types by their JSON encoding.
+// Named struct types are added to the components and referenced.
+type schemaBuilder struct {
+	schemas map[string]interface{}
+}
+
+// schemaName returns the component name of a named type, e.g.
+// project.Project. Types of the gateway are prefixed with gateway.
+func schemaName(t reflect.Type) string {
+	pkg := path.Base(t.PkgPath())
+	if pkg == "main" || pkg == "." {
+		pkg = "gateway"
+	}
+
+	name := []rune(t.Name())
+	name[0] = unicode.ToUpper(name[0])
+
+	return pkg + "." + string(name)
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	if t == rawMessageType {
+		return map[string]interface{}{}
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.schema(t.Elem())
+
+	case reflect.Interface:
+		return map[string]interface{}{}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+
+		return map[string]interface{}{
+			"type":  "array",
+			"items": b.schema(t.Elem()),
+		}
+
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": b.schema(t.Elem()),
+		}
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t, nil)
+		}
+
+		name := schemaName(t)
+		if _, ok := b.schemas[name]; !ok {
+			// Set first for recursive types.
+			b.schemas[name] = nil
+			b.schemas[name] = b.object(t, nil)
+		}
+
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+
+	case reflect.Int32:
+		// Protobuf enums are encoded as numbers.
+		if t.Name() != "" {
+			if m := proto.EnumValueMap(schemaName(t)); m != nil {
+				return enumSchema(m)
+			}
+		}
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+
+	case reflect.Int, reflect.Int64, reflect.Uint32, reflect.Uint64, reflect.Int8, reflect.Int16, reflect.Uint8, reflect.Uint16, reflect.Uint:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+// enumSchema lists the values of a protobuf enum and their names.
+func enumSchema(m map[string]int32) map[string]interface{} {
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return m[names[i]] < m[names[j]]
+	})
+
+	values := make([]int32, len(names))
+	for i, n := range names {
+		values[i] = m[n]
+	}
+
+	return map[string]interface{}{
+		"type":            "integer",
+		"format":          "int32",
+		"enum":            values,
+		"x-enum-varnames": names,
+	}
+}
+
+// object returns the schema of the struct without the ignored properties.
+func (b *schemaBuilder) object(t reflect.Type, ignore []string) map[string]interface{} {
+	props := make(map[string]interface{})
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		// Unexported and protobuf internal fields.
+		if f.PkgPath != "" || strings.HasPrefix(f.Name, "XXX_") {
+			continue
+		}
+
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+
+		skip := false
+		for _, x := range ignore {
+			if x == name {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+
+		props[name] = b.schema(f.Type)
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+	}
+}
+
+// body returns the schema of a request body.
+func (b *schemaBuilder) body(v interface{}, ignore []string) map[string]interface{} {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Inline since the schema differs from the message.
+	if len(ignore) > 0 {
+		return b.object(t, ignore)
+	}
+
+	return b.schema(t)
+}
+
+// openAPIPath converts the echo path parameters, e.g. :id, to {id} and
+// returns the parameter names.
+func openAPIPath(p string) (string, []string) {
+	var params []string
+
+	toks := strings.Split(p, "/")
+	for i, t := range toks {
+		if strings.HasPrefix(t, ":") {
+			params = append(params, t[1:])
+			toks[i] = "{" + t[1:] + "}"
+		}
+	}
+
+	return strings.Join(toks, "/"), params
+}
+
+func (r *apiRoute) operation(b *schemaBuilder) map[string]interface{} {
+	op := map[string]interface{}{
+		"summary": r.summary,
+		"tags":    []string{r.tag},
+	}
+
+	_, pathParams := openAPIPath(r.path)
+
+	var params []interface{}
+	for _, p := range pathParams {
+		params = append(params, map[string]interface{}{
+			"name":     p,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+
+	for _, q := range r.query {
+		params = append(params, map[string]interface{}{
+			"name":        q.name,
+			"in":          "query",
+			"description": q.description,
+			"schema":      map[string]interface{}{"type": q.typ},
+		})
+	}
+
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if r.body != nil {
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": b.body(r.body, r.ignore),
+				},
+			},
+		}
+	} else if r.bodyType != "" {
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				r.bodyType: map[string]interface{}{
+					"schema": map[string]interface{}{"type": "string", "format": "binary"},
+				},
+			},
+		}
+	}
+
+	rep := map[string]interface{}{
+		"description": http.StatusText(r.status),
+	}
+
+	if r.response != nil {
+		rep["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": b.schema(reflect.TypeOf(r.response)),
+			},
+		}
+	} else if r.responseType != "" {
+		rep["content"] = map[string]interface{}{
+			r.responseType: map[string]interface{}{
+				"schema": map[string]interface{}{"type": "string", "format": "binary"},
+			},
+		}
+	}
+
+	op["responses"] = map[string]interface{}{
+		fmt.Sprint(r.status): rep,
+		"default": map[string]interface{}{
+			"$ref": "#/components/responses/Error",
+		},
+	}
+
+	if r.public {
+		op["security"] = []interface{}{}
+	} else if len(r.scopes) > 0 {
+		op["description"] = fmt.Sprintf("Personal access tokens and scoped JWTs require the %s scope.", strings.Join(r.scopes, " and "))
+		op["x-scopes"] = r.scopes
+	}
+
+	return op
+}
+
+// openAPIDocument returns the OpenAPI 3 document of the routes.
+func openAPIDocument(version string, routes []*apiRoute) map[string]interface{} {
+	b := &schemaBuilder{
+		schemas: make(map[string]interface{}),
+	}
+
+	paths := make(map[string]map[string]interface{})
+
+	for _, r := range routes {
+		p, _ := openAPIPath(r.path)
+
+		if paths[p] == nil {
+			paths[p] = make(map[string]interface{})
+		}
+
+		paths[p][strings.ToLower(r.method)] = r.operation(b)
+	}
+
+	if version == "" {
+		version = "dev"
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "RDM Academy API",
+			"version": version,
+		},
+		"paths": paths,
+		"security": []interface{}{
+			map[string]interface{}{"bearerAuth": []string{}},
+		},
+		"components": map[string]interface{}{
+			"schemas": b.schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "A JWT or a personal access token.",
+				},
+			},
+			"responses": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"description": "Error",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": map[string]interface{}{
+								"type": "object",
+								"properties": map[string]interface{}{
+									"message": map[string]interface{}{"type": "string"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
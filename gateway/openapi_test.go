@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"go.uber.org/zap"
+)
+
+// testRouter builds the router with stand-in dependencies. The handlers are
+// not called.
+func testRouter(t *testing.T) (*echo.Echo, routeTable) {
+	e, routes, err := newRouter(&router{
+		logger: zap.NewNop(),
+		limiter: &rateLimiter{
+			store:  newMemoryRateLimitStore(),
+			limits: make(map[string]*rateLimit),
+			logger: zap.NewNop(),
+		},
+		accountCache: newAccountCache(1, time.Minute),
+		eventHub:     newEventHub(),
+		verifier:     &jwtVerifier{},
+		auth:         &authHandler{},
+		mail:         &mailer{},
+		orcid:        &orcidClient{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e, routes
+}
+
+func sameScopes(a, b []string) bool {
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+
+	return strings.Join(a, " ") == strings.Join(b, " ")
+}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	e, routes := testRouter(t)
+
+	registered := make(map[string]bool)
+	for _, r := range e.Routes() {
+		registered[r.Method+" "+r.Path] = true
+	}
+
+	if len(registered) == 0 {
+		t.Fatal("no routes registered")
+	}
+
+	documented := make(map[string]*apiRoute)
+	for _, r := range apiRoutes {
+		k := r.method + " " + r.path
+		if documented[k] != nil {
+			t.Errorf("%s is documented twice", k)
+		}
+		documented[k] = r
+	}
+
+	for k := range registered {
+		r := documented[k]
+		if r == nil {
+			t.Errorf("%s is registered but missing from apiRoutes", k)
+			continue
+		}
+
+		scopes, authed := routes[k]
+
+		if r.public && authed {
+			t.Errorf("%s is documented as public but requires authentication", k)
+		} else if !r.public && !authed {
+			t.Errorf("%s is public but documented as authenticated", k)
+		}
+
+		if authed && !sameScopes(r.scopes, scopes) {
+			t.Errorf("%s: documented scopes %v, route requires %v", k, r.scopes, scopes)
+		}
+	}
+
+	for k := range documented {
+		if !registered[k] {
+			t.Errorf("%s is documented but not registered", k)
+		}
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	doc := openAPIDocument("1.0.0", apiRoutes)
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var x struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+
+	if err := json.Unmarshal(b, &x); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := x.Paths["/projects/{project}/nodes/{node}"]["put"]; !ok {
+		t.Error("expected path parameters to be converted")
+	}
+
+	for _, name := range []string{"project.Project", "nodes.GetReply", "data.DescribeReply", "export.Job", "gateway.ApiCommit", "gateway.Author"} {
+		if _, ok := x.Components.Schemas[name]; !ok {
+			t.Errorf("expected schema %s", name)
+		}
+	}
+
+	// Every reference must resolve.
+	s := string(b)
+	for {
+		i := strings.Index(s, `"#/components/schemas/`)
+		if i < 0 {
+			break
+		}
+		s = s[i+len(`"#/components/schemas/`):]
+
+		name := s[:strings.Index(s, `"`)]
+		if _, ok := x.Components.Schemas[name]; !ok {
+			t.Errorf("unresolved schema reference %s", name)
+		}
+	}
+
+	var node struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	json.Unmarshal(x.Components.Schemas["nodes.GetReply"], &node)
+
+	if v := node.Properties["type"]["x-enum-varnames"]; v == nil {
+		t.Error("expected enum names of the node type")
+	}
+
+	// Fields set by the gateway are not part of the request.
+	var commit struct {
+		Post struct {
+			RequestBody struct {
+				Content map[string]struct {
+					Schema struct {
+						Properties map[string]interface{} `json:"properties"`
+					} `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+		} `json:"post"`
+	}
+
+	b, _ = json.Marshal(doc["paths"].(map[string]map[string]interface{})["/projects/{id}/log"])
+	json.Unmarshal(b, &commit)
+
+	props := commit.Post.RequestBody.Content["application/json"].Schema.Properties
+	if _, ok := props["author"]; ok {
+		t.Error("expected author to be ignored in the commit request")
+	}
+	if _, ok := props["msg"]; !ok {
+		t.Error("expected msg in the commit request")
+	}
+}
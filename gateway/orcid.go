@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// How long an authorization started with ORCID can be completed.
+	orcidStateTTL = 10 * time.Minute
+)
+
+var errInvalidState = errors.New("invalid state")
+
+// orcidToken is the reply of the ORCID token endpoint. It includes the
+// authenticated iD and name.
+type orcidToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+	Name        string `json:"name"`
+	Orcid       string `json:"orcid"`
+}
+
+// orcidClient performs the OAuth authorization code exchange with ORCID
+// to verify the iD of a user.
+type orcidClient struct {
+	// Base URL of ORCID, e.g. https://orcid.org or https://sandbox.orcid.org.
+	url          string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+
+	client *http.Client
+}
+
+// issuer is the OpenID issuer of ORCID tokens. The subject is the iD.
+func (o *orcidClient) issuer() string {
+	return strings.TrimSuffix(o.url, "/")
+}
+
+func (o *orcidClient) enabled() bool {
+	return o.clientID != "" && o.clientSecret != ""
+}
+
+// authorizeURL returns the URL the user is sent to in order to sign in to
+// ORCID.
+func (o *orcidClient) authorizeURL(state string) string {
+	q := url.Values{}
+	q.Set("client_id", o.clientID)
+	q.Set("response_type", "code")
+	q.Set("scope", "/authenticate")
+	q.Set("redirect_uri", o.redirectURL)
+	q.Set("state", state)
+
+	return o.issuer() + "/oauth/authorize?" + q.Encode()
+}
+
+// exchange trades the authorization code for a token.
+func (o *orcidClient) exchange(ctx context.Context, code string) (*orcidToken, error) {
+	form := url.Values{}
+	form.Set("client_id", o.clientID)
+	form.Set("client_secret", o.clientSecret)
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", o.redirectURL)
+
+	req, err := http.NewRequest(http.MethodPost, o.issuer()+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	client := o.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	rep, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rep.Body.Close()
+
+	if rep.StatusCode != http.StatusOK {
+		var e struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		json.NewDecoder(rep.Body).Decode(&e)
+
+		if e.Description != "" {
+			return nil, fmt.Errorf("orcid: %s: %s", e.Error, e.Description)
+		}
+
+		return nil, fmt.Errorf("orcid: token exchange failed with status %d", rep.StatusCode)
+	}
+
+	var t orcidToken
+	if err := json.NewDecoder(rep.Body).Decode(&t); err != nil {
+		return nil, err
+	}
+
+	if t.Orcid == "" {
+		return nil, errors.New("orcid: token reply without iD")
+	}
+
+	return &t, nil
+}
+
+// signState returns an opaque state binding the authorization to the
+// user. It expires after orcidStateTTL.
+func signState(key []byte, user string, now time.Time) string {
+	payload := user + "." + strconv.FormatInt(now.Add(orcidStateTTL).Unix(), 10)
+	return signPayload(key, []byte(payload))
+}
+
+// verifyState checks the state was issued for the user and has not
+// expired.
+func verifyState(key []byte, state, user string, now time.Time) error {
+	payload, err := openPayload(key, state)
+	if err != nil {
+		return errInvalidState
+	}
+
+	j := strings.LastIndexByte(string(payload), '.')
+	if j < 0 || string(payload[:j]) != user {
+		return errInvalidState
+	}
+
+	exp, err := strconv.ParseInt(string(payload[j+1:]), 10, 64)
+	if err != nil || now.Unix() > exp {
+		return errInvalidState
+	}
+
+	return nil
+}
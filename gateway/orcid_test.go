@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// orcidStandIn serves the ORCID token endpoint for a single code.
+func orcidStandIn(t *testing.T, code string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/token" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Form.Get("client_id") != "client" || r.Form.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error":             "invalid_client",
+				"error_description": "Client not found",
+			})
+			return
+		}
+
+		if r.Form.Get("grant_type") != "authorization_code" || r.Form.Get("code") != code {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error":             "invalid_grant",
+				"error_description": "Invalid authorization code",
+			})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "token",
+			"token_type":   "bearer",
+			"expires_in":   631138518,
+			"scope":        "/authenticate",
+			"name":         "Josiah Carberry",
+			"orcid":        "0000-0002-1825-0097",
+		})
+	}))
+}
+
+func TestOrcidExchange(t *testing.T) {
+	srv := orcidStandIn(t, "abc")
+	defer srv.Close()
+
+	o := &orcidClient{
+		url:          srv.URL,
+		clientID:     "client",
+		clientSecret: "secret",
+		redirectURL:  "http://localhost/orcid",
+	}
+
+	tok, err := o.exchange(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tok.Orcid != "0000-0002-1825-0097" {
+		t.Errorf("expected iD, got %q", tok.Orcid)
+	}
+
+	if tok.Name != "Josiah Carberry" {
+		t.Errorf("expected name, got %q", tok.Name)
+	}
+
+	if _, err := o.exchange(context.Background(), "wrong"); err == nil {
+		t.Error("expected error for invalid code")
+	}
+
+	o.clientSecret = "other"
+	if _, err := o.exchange(context.Background(), "abc"); err == nil {
+		t.Error("expected error for invalid client")
+	}
+}
+
+func TestOrcidAuthorizeURL(t *testing.T) {
+	o := &orcidClient{
+		url:         "https://sandbox.orcid.org/",
+		clientID:    "client",
+		redirectURL: "http://localhost/orcid",
+	}
+
+	u, err := url.Parse(o.authorizeURL("state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Host != "sandbox.orcid.org" || u.Path != "/oauth/authorize" {
+		t.Errorf("unexpected url %s", u)
+	}
+
+	q := u.Query()
+	for k, v := range map[string]string{
+		"client_id":     "client",
+		"response_type": "code",
+		"scope":         "/authenticate",
+		"redirect_uri":  "http://localhost/orcid",
+		"state":         "state",
+	} {
+		if q.Get(k) != v {
+			t.Errorf("expected %s=%s, got %q", k, v, q.Get(k))
+		}
+	}
+}
+
+func TestOrcidState(t *testing.T) {
+	key := []byte("key")
+	now := time.Now()
+
+	state := signState(key, "user", now)
+
+	if err := verifyState(key, state, "user", now); err != nil {
+		t.Errorf("expected valid state: %s", err)
+	}
+
+	if err := verifyState(key, state, "other", now); err == nil {
+		t.Error("expected error for other user")
+	}
+
+	if err := verifyState([]byte("other"), state, "user", now); err == nil {
+		t.Error("expected error for other key")
+	}
+
+	if err := verifyState(key, state, "user", now.Add(orcidStateTTL+time.Second)); err == nil {
+		t.Error("expected error for expired state")
+	}
+
+	if err := verifyState(key, state+"x", "user", now); err == nil {
+		t.Error("expected error for tampered state")
+	}
+}
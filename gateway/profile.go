@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+
+	"github.com/rdm-academy/api/account"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// profileUpdate is a partial update of the account profile. Fields that
+// are not present are left unchanged.
+type profileUpdate struct {
+	Name        *string `json:"name"`
+	Affiliation *string `json:"affiliation"`
+	Orcid       *string `json:"orcid"`
+	AvatarURL   *string `json:"avatar_url"`
+}
+
+func (p *profileUpdate) empty() bool {
+	return p.Name == nil && p.Affiliation == nil && p.Orcid == nil && p.AvatarURL == nil
+}
+
+// apply returns the request to update the current profile with the
+// present fields.
+func (p *profileUpdate) apply(u *account.GetUserResponse) *account.UpdateUserRequest {
+	req := &account.UpdateUserRequest{
+		Id:          u.Id,
+		Name:        u.Name,
+		Affiliation: u.Affiliation,
+		Orcid:       u.Orcid,
+		AvatarUrl:   u.AvatarUrl,
+	}
+
+	if p.Name != nil {
+		req.Name = *p.Name
+	}
+	if p.Affiliation != nil {
+		req.Affiliation = *p.Affiliation
+	}
+	if p.Orcid != nil {
+		req.Orcid = *p.Orcid
+	}
+	if p.AvatarURL != nil {
+		req.AvatarUrl = *p.AvatarURL
+	}
+
+	return req
+}
+
+// errLinkIdentity is returned when an identity has the email of an existing
+// account it cannot be linked to automatically.
+var errLinkIdentity = status.Error(codes.AlreadyExists, "an account with this email already exists; sign in to it and link this identity from the account")
+
+// ensureAccount returns the id of the account linked to the identity,
+// creating the account if it does not exist. An identity is only linked to
+// an existing account with the same email if the email was verified by
+// both, otherwise anyone could create an account with the email of another
+// person and gain access once that person signs in.
+func ensureAccount(ctx context.Context, accountSvc account.ServiceClient, emailVerified bool, issuer, subject, email, name string) (string, bool, error) {
+	rep, err := accountSvc.GetUser(ctx, &account.GetUserRequest{
+		Issuer:  issuer,
+		Subject: subject,
+	})
+	if err == nil {
+		return rep.Id, false, nil
+	}
+
+	if status.Code(err) != codes.NotFound {
+		return "", false, err
+	}
+
+	rep2, err := accountSvc.CreateUser(ctx, &account.CreateUserRequest{
+		Issuer:        issuer,
+		Subject:       subject,
+		Name:          name,
+		Email:         email,
+		EmailVerified: emailVerified,
+	})
+	if err == nil {
+		return rep2.Id, true, nil
+	}
+
+	if status.Code(err) != codes.AlreadyExists {
+		return "", false, err
+	}
+
+	if !emailVerified {
+		return "", false, errLinkIdentity
+	}
+
+	rep, err = accountSvc.GetUser(ctx, &account.GetUserRequest{
+		Email: email,
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	if !rep.EmailVerified {
+		return "", false, errLinkIdentity
+	}
+
+	_, err = accountSvc.LinkIdentity(ctx, &account.LinkIdentityRequest{
+		Id:      rep.Id,
+		Issuer:  issuer,
+		Subject: subject,
+		Email:   email,
+		Name:    name,
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	return rep.Id, false, nil
+}
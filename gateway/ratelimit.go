@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Rate limit classes. Expensive routes have their own budget so they do
+// not exhaust the budget of the rest of the API.
+const (
+	rateLimitDefault  = "default"
+	rateLimitUpload   = "upload"
+	rateLimitDownload = "download"
+	rateLimitImport   = "import"
+	rateLimitLog      = "log"
+
+	// Requests of a client IP before authentication.
+	rateLimitIP = "ip"
+)
+
+// Interval stale buckets are removed from the memory store.
+const rateLimitSweepInterval = time.Minute
+
+// rateLimitClass returns the class of the route.
+func rateLimitClass(method, path string) string {
+	switch {
+	case strings.HasSuffix(path, "/upload") || strings.Contains(path, "/uploads"):
+		return rateLimitUpload
+	case strings.HasSuffix(path, "/download") || strings.HasSuffix(path, "/archive") || path == "/account/export":
+		return rateLimitDownload
+	case method == http.MethodPost && path == "/imports":
+		return rateLimitImport
+	case strings.HasSuffix(path, "/log") || strings.HasSuffix(path, "/log/pending"):
+		return rateLimitLog
+	}
+
+	return rateLimitDefault
+}
+
+// rateLimit is a token bucket holding up to limit tokens which are
+// refilled over the period.
+type rateLimit struct {
+	limit  int
+	period time.Duration
+}
+
+// parseRateLimit parses a limit of the form <requests>/<period>, e.g.
+// 100/1m. An empty string or zero requests disable the limit.
+func parseRateLimit(s string) (*rateLimit, error) {
+	if s == "" || s == "0" {
+		return nil, nil
+	}
+
+	toks := strings.SplitN(s, "/", 2)
+	if len(toks) != 2 {
+		return nil, fmt.Errorf("invalid rate limit %q: expected <requests>/<period>", s)
+	}
+
+	n, err := strconv.Atoi(toks[0])
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid rate limit %q: invalid number of requests", s)
+	}
+
+	d, err := time.ParseDuration(toks[1])
+	if err != nil || d <= 0 {
+		return nil, fmt.Errorf("invalid rate limit %q: invalid period", s)
+	}
+
+	if n == 0 {
+		return nil, nil
+	}
+
+	return &rateLimit{
+		limit:  n,
+		period: d,
+	}, nil
+}
+
+// rate returns the refill rate in tokens per second.
+func (l *rateLimit) rate() float64 {
+	return float64(l.limit) / l.period.Seconds()
+}
+
+// bucketState is the stored state of a token bucket.
+type bucketState struct {
+	Tokens  float64 `json:"t"`
+	Updated int64   `json:"u"`
+}
+
+// rateLimitResult is the outcome of taking a token.
+type rateLimitResult struct {
+	allowed   bool
+	remaining int
+
+	// Time until the bucket is full again.
+	reset time.Duration
+
+	// Time until a token is available, if none was.
+	retryAfter time.Duration
+}
+
+// take refills the bucket for the elapsed time and takes a token if one is
+// available. A nil state is a new, full bucket.
+func (l *rateLimit) take(b *bucketState, now time.Time) (*bucketState, *rateLimitResult) {
+	limit := float64(l.limit)
+	rate := l.rate()
+
+	tokens := limit
+	if b != nil {
+		elapsed := time.Duration(now.UnixNano() - b.Updated).Seconds()
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		tokens = math.Min(limit, b.Tokens+elapsed*rate)
+	}
+
+	r := &rateLimitResult{}
+
+	if tokens >= 1 {
+		tokens--
+		r.allowed = true
+	} else {
+		r.retryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+
+	r.remaining = int(tokens)
+	r.reset = time.Duration((limit - tokens) / rate * float64(time.Second))
+
+	return &bucketState{
+		Tokens:  tokens,
+		Updated: now.UnixNano(),
+	}, r
+}
+
+// rateLimitStore holds the token buckets. The take must be atomic so
+// concurrent requests cannot take the same token.
+type rateLimitStore interface {
+	take(ctx context.Context, key string, l *rateLimit, now time.Time) (*rateLimitResult, error)
+}
+
+// memoryRateLimitStore keeps the buckets in memory. It is only suitable for
+// a single gateway since every replica would have its own budget.
+type memoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	swept   time.Time
+}
+
+type memoryBucket struct {
+	state *bucketState
+	full  time.Time
+}
+
+func newMemoryRateLimitStore() *memoryRateLimitStore {
+	return &memoryRateLimitStore{
+		buckets: make(map[string]*memoryBucket),
+	}
+}
+
+func (s *memoryRateLimitStore) take(ctx context.Context, key string, l *rateLimit, now time.Time) (*rateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Buckets that are full again are the same as missing ones.
+	if now.Sub(s.swept) > rateLimitSweepInterval {
+		for k, b := range s.buckets {
+			if now.After(b.full) {
+				delete(s.buckets, k)
+			}
+		}
+		s.swept = now
+	}
+
+	var state *bucketState
+	if b, ok := s.buckets[key]; ok {
+		state = b.state
+	}
+
+	state, r := l.take(state, now)
+
+	s.buckets[key] = &memoryBucket{
+		state: state,
+		full:  now.Add(r.reset),
+	}
+
+	return r, nil
+}
+
+// parseTrustedProxies parses a comma-separated list of IP addresses and
+// CIDR ranges.
+func parseTrustedProxies(s string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+
+	for _, tok := range strings.Split(s, ",") {
+		tok = strings.TrimSpace(tok)
+		if tok == "" {
+			continue
+		}
+
+		if !strings.Contains(tok, "/") {
+			ip := net.ParseIP(tok)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", tok)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(tok)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", tok)
+		}
+
+		nets = append(nets, n)
+	}
+
+	return nets, nil
+}
+
+// rateLimiter limits requests by user, or by client IP for unauthenticated
+// requests, with a budget per class.
+type rateLimiter struct {
+	store  rateLimitStore
+	limits map[string]*rateLimit
+	logger *zap.Logger
+
+	// Proxies whose forwarded headers are trusted.
+	proxies []*net.IPNet
+}
+
+func (rl *rateLimiter) trusted(s string) bool {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range rl.proxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clientIP returns the IP of the client. The forwarded headers can be set
+// by anyone, so they are only used if the request comes from a trusted
+// proxy. The X-Forwarded-For hops are walked from the nearest one and the
+// first address that is not a trusted proxy is the client.
+func (rl *rateLimiter) clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	if !rl.trusted(ip) {
+		return ip
+	}
+
+	if xff := r.Header.Get(echo.HeaderXForwardedFor); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+
+			ip = hop
+			if !rl.trusted(hop) {
+				break
+			}
+		}
+
+		return ip
+	}
+
+	if x := strings.TrimSpace(r.Header.Get(echo.HeaderXRealIP)); net.ParseIP(x) != nil {
+		return x
+	}
+
+	return ip
+}
+
+// key returns the client the request is accounted to.
+func (rl *rateLimiter) key(c echo.Context) string {
+	if id, ok := c.Get(userIdKey).(string); ok && id != "" {
+		return "user:" + id
+	}
+	return "ip:" + rl.clientIP(c.Request())
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// limit takes a token from the bucket of the key and sets the rate limit
+// headers.
+func (rl *rateLimiter) limit(c echo.Context, class, key string, next echo.HandlerFunc) error {
+	l := rl.limits[class]
+	if l == nil {
+		return next(c)
+	}
+
+	ctx := c.Request().Context()
+
+	r, err := rl.store.take(ctx, class+":"+key, l, time.Now())
+	if err != nil {
+		// An unavailable store must not take down the API.
+		rl.logger.Warn("rate limit store failed",
+			zap.Error(err),
+			zap.String("ratelimit.class", class),
+		)
+		return next(c)
+	}
+
+	h := c.Response().Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(l.limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(r.remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(r.reset)))
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", l.limit, ceilSeconds(l.period)))
+
+	if !r.allowed {
+		h.Set("Retry-After", strconv.Itoa(ceilSeconds(r.retryAfter)))
+		return status.Errorf(codes.ResourceExhausted, "rate limit of %s requests exceeded", class)
+	}
+
+	return next(c)
+}
+
+// Middleware limits the requests of the route. It must come after the
+// AccountMiddleware, if any, to account requests to the user.
+func (rl *rateLimiter) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return rl.limit(c, rateLimitClass(c.Request().Method, c.Path()), rl.key(c), next)
+		}
+	}
+}
+
+// IPMiddleware limits the requests of the client IP. It comes before the
+// authentication so requests with invalid credentials are limited too.
+func (rl *rateLimiter) IPMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return rl.limit(c, rateLimitIP, rl.clientIP(c.Request()), next)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/nats-io/go-nats"
+)
+
+// Attempts to update a bucket modified concurrently by another request.
+const natsRateLimitRetries = 5
+
+// natsRateLimitStore keeps the buckets in a NATS JetStream key-value bucket
+// so the budgets are shared by all gateway replicas. Concurrent takes are
+// serialized with the revision of the key.
+type natsRateLimitStore struct {
+	kv nats.KeyValue
+}
+
+// newNATSRateLimitStore opens the key-value bucket, creating it if it does
+// not exist. Keys expire after the ttl, which must be at least the longest
+// limit period, so idle buckets are removed.
+func newNATSRateLimitStore(nc *nats.Conn, bucket string, ttl time.Duration) (*natsRateLimitStore, error) {
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, err
+	}
+
+	kv, err := js.KeyValue(bucket)
+	if err == nats.ErrBucketNotFound {
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{
+			Bucket:      bucket,
+			Description: "Gateway rate limit buckets.",
+			History:     1,
+			TTL:         ttl,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &natsRateLimitStore{
+		kv: kv,
+	}, nil
+}
+
+func (s *natsRateLimitStore) take(ctx context.Context, key string, l *rateLimit, now time.Time) (*rateLimitResult, error) {
+	// Keys are restricted to a small character set.
+	key = base64.RawURLEncoding.EncodeToString([]byte(key))
+
+	var err error
+
+	for i := 0; i < natsRateLimitRetries; i++ {
+		var (
+			state *bucketState
+			rev   uint64
+		)
+
+		entry, gerr := s.kv.Get(key)
+		switch gerr {
+		case nil:
+			state = &bucketState{}
+			if err := json.Unmarshal(entry.Value(), state); err != nil {
+				// Start over with a full bucket.
+				state = nil
+			}
+			rev = entry.Revision()
+
+		case nats.ErrKeyNotFound:
+
+		default:
+			return nil, gerr
+		}
+
+		next, r := l.take(state, now)
+
+		b, merr := json.Marshal(next)
+		if merr != nil {
+			return nil, merr
+		}
+
+		if rev == 0 {
+			_, err = s.kv.Create(key, b)
+		} else {
+			_, err = s.kv.Update(key, b, rev)
+		}
+
+		// Otherwise the key was changed since it was read.
+		if err == nil {
+			return r, nil
+		}
+	}
+
+	return nil, err
+}
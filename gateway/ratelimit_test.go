@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"go.uber.org/zap"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	l, err := parseRateLimit("100/1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l.limit != 100 || l.period != time.Minute {
+		t.Errorf("unexpected limit %+v", l)
+	}
+
+	for _, s := range []string{"", "0", "0/1m"} {
+		if l, err := parseRateLimit(s); err != nil || l != nil {
+			t.Errorf("%q: expected disabled limit", s)
+		}
+	}
+
+	for _, s := range []string{"100", "x/1m", "-1/1m", "100/x", "100/0s"} {
+		if _, err := parseRateLimit(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestRateLimitClass(t *testing.T) {
+	for _, x := range []struct {
+		method string
+		path   string
+		class  string
+	}{
+		{"POST", "/projects/:project/nodes/:node/upload", rateLimitUpload},
+		{"POST", "/projects/:project/nodes/:node/uploads/:id/complete", rateLimitUpload},
+		{"GET", "/projects/:project/files/:file/download", rateLimitDownload},
+		{"GET", "/projects/:id/archive", rateLimitDownload},
+		{"GET", "/account/export", rateLimitDownload},
+		{"POST", "/imports", rateLimitImport},
+		{"GET", "/imports/:import", rateLimitDefault},
+		{"GET", "/projects/:id/log/pending", rateLimitLog},
+		{"POST", "/projects/:id/log", rateLimitLog},
+		{"GET", "/projects", rateLimitDefault},
+	} {
+		if c := rateLimitClass(x.method, x.path); c != x.class {
+			t.Errorf("%s %s: expected %s, got %s", x.method, x.path, x.class, c)
+		}
+	}
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	s := newMemoryRateLimitStore()
+	l := &rateLimit{limit: 2, period: 2 * time.Second}
+
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, allowed := range []bool{true, true, false} {
+		r, _ := s.take(ctx, "k", l, now)
+		if r.allowed != allowed {
+			t.Fatalf("take %d: expected allowed=%v", i, allowed)
+		}
+	}
+
+	// Other keys have their own bucket.
+	if r, _ := s.take(ctx, "other", l, now); !r.allowed {
+		t.Error("expected other key to be allowed")
+	}
+
+	// One token is refilled per second.
+	r, _ := s.take(ctx, "k", l, now.Add(time.Second))
+	if !r.allowed || r.remaining != 0 {
+		t.Errorf("expected refilled token, got %+v", r)
+	}
+
+	r, _ = s.take(ctx, "k", l, now.Add(time.Second))
+	if r.allowed || r.retryAfter != time.Second || r.reset != 2*time.Second {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	// Full buckets are removed.
+	s.take(ctx, "k", l, now.Add(time.Hour))
+	if _, ok := s.buckets["other"]; ok {
+		t.Error("expected full bucket to be removed")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	rl := &rateLimiter{
+		store: newMemoryRateLimitStore(),
+		limits: map[string]*rateLimit{
+			rateLimitDefault: {limit: 1, period: time.Minute},
+		},
+		logger: zap.NewNop(),
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = grpcHTTPErrorHandler(e)
+
+	ok := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	e.GET("/projects", ok, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if u := c.Request().Header.Get("X-User"); u != "" {
+				c.Set(userIdKey, u)
+			}
+			return next(c)
+		}
+	}, rl.Middleware())
+
+	// Unlimited class.
+	e.POST("/imports", ok, rl.Middleware())
+
+	do := func(method, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/projects", nil)
+		if method == http.MethodPost {
+			req = httptest.NewRequest(method, "/imports", nil)
+		}
+		req.Header.Set("X-User", user)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := do(http.MethodGet, "u1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	h := rec.Header()
+	if h.Get("RateLimit-Limit") != "1" || h.Get("RateLimit-Remaining") != "0" || h.Get("RateLimit-Reset") != "60" || h.Get("RateLimit-Policy") != "1;w=60" {
+		t.Errorf("unexpected headers %v", h)
+	}
+
+	rec = do(http.MethodGet, "u1")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rec.Code)
+	}
+
+	if rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("unexpected Retry-After %q", rec.Header().Get("Retry-After"))
+	}
+
+	// Users and unauthenticated clients have their own budgets.
+	if rec := do(http.MethodGet, "u2"); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 for other user, got %d", rec.Code)
+	}
+
+	if rec := do(http.MethodGet, ""); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 for client IP, got %d", rec.Code)
+	}
+
+	for i := 0; i < 3; i++ {
+		if rec := do(http.MethodPost, "u1"); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "" {
+			t.Errorf("expected unlimited class, got %d", rec.Code)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := parseTrustedProxies("10.0.0.0/8, 192.168.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rl := &rateLimiter{proxies: proxies}
+
+	for _, x := range []struct {
+		name   string
+		remote string
+		xff    string
+		realIP string
+		ip     string
+	}{
+		{"direct", "203.0.113.1:1234", "", "", "203.0.113.1"},
+		{"untrusted forwarded", "203.0.113.1:1234", "198.51.100.1", "198.51.100.2", "203.0.113.1"},
+		{"trusted forwarded", "10.0.0.1:1234", "198.51.100.1", "", "198.51.100.1"},
+		{"spoofed hop", "10.0.0.1:1234", "198.51.100.9, 198.51.100.1, 10.0.0.2", "", "198.51.100.1"},
+		{"single address", "192.168.1.1:1234", "198.51.100.1", "", "198.51.100.1"},
+		{"all trusted", "10.0.0.1:1234", "10.0.0.3, 10.0.0.2", "", "10.0.0.3"},
+		{"invalid hop", "10.0.0.1:1234", "junk, 10.0.0.2", "", "10.0.0.2"},
+		{"real ip", "10.0.0.1:1234", "", "198.51.100.2", "198.51.100.2"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = x.remote
+		if x.xff != "" {
+			req.Header.Set(echo.HeaderXForwardedFor, x.xff)
+		}
+		if x.realIP != "" {
+			req.Header.Set(echo.HeaderXRealIP, x.realIP)
+		}
+
+		if ip := rl.clientIP(req); ip != x.ip {
+			t.Errorf("%s: expected %s, got %s", x.name, x.ip, ip)
+		}
+	}
+
+	if _, err := parseTrustedProxies("10.0.0.0/33"); err == nil {
+		t.Error("expected error for invalid range")
+	}
+}
+
+func TestIPRateLimitMiddleware(t *testing.T) {
+	rl := &rateLimiter{
+		store: newMemoryRateLimitStore(),
+		limits: map[string]*rateLimit{
+			rateLimitIP: {limit: 1, period: time.Minute},
+		},
+		logger: zap.NewNop(),
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = grpcHTTPErrorHandler(e)
+
+	// Stands in for an authentication that always fails.
+	e.GET("/account", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusUnauthorized)
+	}, rl.IPMiddleware())
+
+	do := func(remote, xff string) int {
+		req := httptest.NewRequest(http.MethodGet, "/account", nil)
+		req.RemoteAddr = remote
+		req.Header.Set(echo.HeaderXForwardedFor, xff)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	if code := do("203.0.113.1:1234", "198.51.100.1"); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", code)
+	}
+
+	// A forged header from an untrusted client does not get a new budget.
+	if code := do("203.0.113.1:1234", "198.51.100.2"); code != http.StatusTooManyRequests {
+		t.Errorf("expected 429, got %d", code)
+	}
+
+	if code := do("203.0.113.2:1234", ""); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for other client, got %d", code)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+	"github.com/rdm-academy/api/account"
+)
+
+// routeTable records the scopes required by the authenticated routes keyed
+// by method and path.
+type routeTable map[string][]string
+
+// routeGroup registers routes with a common middleware chain. Unlike
+// echo.Group, it does not add catch-all routes, so several groups can share
+// the root path. The routes of a group require authentication and are
+// recorded in the route table.
+type routeGroup struct {
+	echo       *echo.Echo
+	middleware []echo.MiddlewareFunc
+
+	routes routeTable
+	scopes []string
+}
+
+func newRouteGroup(e *echo.Echo, routes routeTable, m ...echo.MiddlewareFunc) *routeGroup {
+	return &routeGroup{
+		echo:       e,
+		middleware: m,
+		routes:     routes,
+		scopes:     []string{},
+	}
+}
+
+// With returns a group with the middleware appended.
+func (g *routeGroup) With(m ...echo.MiddlewareFunc) *routeGroup {
+	mw := make([]echo.MiddlewareFunc, 0, len(g.middleware)+len(m))
+	mw = append(mw, g.middleware...)
+	mw = append(mw, m...)
+
+	ng := newRouteGroup(g.echo, g.routes, mw...)
+	ng.scopes = g.scopes
+
+	return ng
+}
+
+// Scopes returns a group whose routes require the scopes in addition to
+// those of the group.
+func (g *routeGroup) Scopes(scopes ...string) *routeGroup {
+	ng := g.With(requireScopes(scopes...))
+	ng.scopes = append(append([]string{}, g.scopes...), scopes...)
+
+	return ng
+}
+
+func (g *routeGroup) chain(m []echo.MiddlewareFunc) []echo.MiddlewareFunc {
+	return append(append([]echo.MiddlewareFunc{}, g.middleware...), m...)
+}
+
+func (g *routeGroup) add(method, path string, h echo.HandlerFunc, m []echo.MiddlewareFunc) *echo.Route {
+	g.routes[method+" "+path] = g.scopes
+
+	return g.echo.Add(method, path, h, g.chain(m)...)
+}
+
+func (g *routeGroup) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.add(echo.GET, path, h, m)
+}
+
+func (g *routeGroup) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.add(echo.POST, path, h, m)
+}
+
+func (g *routeGroup) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.add(echo.PUT, path, h, m)
+}
+
+func (g *routeGroup) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.add(echo.DELETE, path, h, m)
+}
+
+// grantedScopes returns the scopes of the credential of the request and
+// whether the credential is restricted to them. Personal access tokens are
+// always restricted. JWTs are restricted if their scope claim, a space
+// separated string or a list, includes any of the API scopes; otherwise
+// they are user sessions with full access.
+func grantedScopes(c echo.Context) (map[string]bool, bool) {
+	if scopes, ok := c.Get(tokenScopesKey).([]string); ok {
+		m := make(map[string]bool, len(scopes))
+		for _, s := range scopes {
+			m[s] = true
+		}
+		return m, true
+	}
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return nil, false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, false
+	}
+
+	var values []string
+
+	switch x := claims["scope"].(type) {
+	case string:
+		values = strings.Fields(x)
+	case []interface{}:
+		for _, v := range x {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	if x, ok := claims["scp"].([]interface{}); ok {
+		for _, v := range x {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	m := make(map[string]bool)
+	for _, s := range values {
+		if account.ValidScope(s) {
+			m[s] = true
+		}
+	}
+
+	if len(m) == 0 {
+		return nil, false
+	}
+
+	return m, true
+}
+
+// requireScopes ensures the credential of the request grants all of the
+// scopes. This must come after the authMiddleware.
+func requireScopes(scopes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			granted, restricted := grantedScopes(c)
+
+			if restricted {
+				for _, s := range scopes {
+					if !granted[s] {
+						return echo.NewHTTPError(http.StatusForbidden, "missing scope: "+s)
+					}
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+	"github.com/rdm-academy/api/account"
+)
+
+func TestRequireScopes(t *testing.T) {
+	e := echo.New()
+
+	h := requireScopes(account.ScopeProjectsWrite)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, x := range []struct {
+		name string
+		set  func(echo.Context)
+		code int
+	}{
+		{"token with scope", func(c echo.Context) {
+			c.Set(tokenScopesKey, []string{account.ScopeProjectsRead, account.ScopeProjectsWrite})
+		}, http.StatusOK},
+		{"token without scope", func(c echo.Context) {
+			c.Set(tokenScopesKey, []string{account.ScopeProjectsRead})
+		}, http.StatusForbidden},
+		{"session", func(c echo.Context) {
+			c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"sub": "1"}})
+		}, http.StatusOK},
+		{"session with provider scopes", func(c echo.Context) {
+			c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"scope": "openid email"}})
+		}, http.StatusOK},
+		{"jwt with scope", func(c echo.Context) {
+			c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"scope": "openid projects:write"}})
+		}, http.StatusOK},
+		{"jwt without scope", func(c echo.Context) {
+			c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"scope": "openid projects:read"}})
+		}, http.StatusForbidden},
+		{"jwt with scp list", func(c echo.Context) {
+			c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"scp": []interface{}{"projects:write"}}})
+		}, http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		x.set(c)
+
+		err := h(c)
+
+		code := rec.Code
+		if he, ok := err.(*echo.HTTPError); ok {
+			code = he.Code
+		}
+
+		if code != x.code {
+			t.Errorf("%s: expected %d, got %d", x.name, x.code, code)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var errInvalidSignature = errors.New("invalid signature")
+
+// signPayload returns the payload with an HMAC so it can be handed to the
+// client and verified when it comes back.
+func signPayload(key, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// openPayload verifies the signed value and returns the payload.
+func openPayload(key []byte, s string) ([]byte, error) {
+	i := strings.IndexByte(s, '.')
+	if i < 0 {
+		return nil, errInvalidSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(s[:i])
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+
+	sum, err := base64.RawURLEncoding.DecodeString(s[i+1:])
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+
+	if !hmac.Equal(sum, mac.Sum(nil)) {
+		return nil, errInvalidSignature
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,533 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/golang/protobuf/proto"
+	"github.com/labstack/echo"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rdm-academy/api/commitlog"
+)
+
+// Events buffered per stream. A stream that falls further behind is closed
+// and the client resumes from the last event it got.
+const streamBuffer = 64
+
+// Interval of keep-alive messages so idle streams are not closed by proxies.
+const streamKeepAlive = 30 * time.Second
+
+// Time allowed to write a message to a WebSocket.
+const streamWriteTimeout = 10 * time.Second
+
+// Events fetched per request when a stream is resumed.
+const streamResumeBatch = 500
+
+// Attempts to look up the last event of a resuming client. An event sent
+// live may not be recorded by the commit log yet.
+const streamResumeAttempts = 3
+
+// Delay between the attempts to look up the last event.
+const streamResumeDelay = 200 * time.Millisecond
+
+// Events replayed per connection. A client further behind gets the rest
+// once it reconnects with the last event it got.
+const streamReplayMax = 5000
+
+// Events after which the viewer may no longer be allowed to see the project.
+var streamAccessEvents = map[string]bool{
+	"project.deleted": true,
+	"project.trashed": true,
+}
+
+// eventSub receives the events of a project.
+type eventSub struct {
+	project string
+	events  chan *commitlog.Event
+
+	// Closed if the subscriber fell behind and was dropped.
+	dropped chan struct{}
+}
+
+// eventHub fans out the events of a single NATS subscription to the
+// streams of the projects.
+type eventHub struct {
+	mu   sync.Mutex
+	subs map[string]map[*eventSub]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{
+		subs: make(map[string]map[*eventSub]struct{}),
+	}
+}
+
+func (h *eventHub) subscribe(project string) *eventSub {
+	s := &eventSub{
+		project: project,
+		events:  make(chan *commitlog.Event, streamBuffer),
+		dropped: make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.subs[project]
+	if !ok {
+		subs = make(map[*eventSub]struct{})
+		h.subs[project] = subs
+	}
+	subs[s] = struct{}{}
+
+	return s
+}
+
+func (h *eventHub) unsubscribe(s *eventSub) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(s)
+}
+
+func (h *eventHub) remove(s *eventSub) {
+	subs := h.subs[s.project]
+	delete(subs, s)
+	if len(subs) == 0 {
+		delete(h.subs, s.project)
+	}
+}
+
+// publish passes the event to the subscribers of its project. It does not
+// block; subscribers with a full buffer are dropped.
+func (h *eventHub) publish(e *commitlog.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.subs[e.Project] {
+		select {
+		case s.events <- e:
+		default:
+			h.remove(s)
+			close(s.dropped)
+		}
+	}
+}
+
+// len returns the number of subscribers.
+func (h *eventHub) len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := 0
+	for _, subs := range h.subs {
+		n += len(subs)
+	}
+	return n
+}
+
+func (h *eventHub) writeMetrics(w io.Writer) {
+	fmt.Fprintf(w, "# TYPE gateway_event_streams gauge\n")
+	fmt.Fprintf(w, "gateway_event_streams %d\n", h.len())
+}
+
+// newEventHubHandler passes the project events to the hub.
+func newEventHubHandler(h *eventHub) transport.Handler {
+	return func(msg *transport.Message) (proto.Message, error) {
+		var e commitlog.Event
+		if err := msg.Decode(&e); err != nil {
+			return nil, err
+		}
+
+		if e.Project != "" {
+			h.publish(&e)
+		}
+
+		return nil, nil
+	}
+}
+
+// eventStreamer serves the event streams of projects.
+type eventStreamer struct {
+	hub          *eventHub
+	commitlogSvc commitlog.ServiceClient
+	enricher     *Enricher
+	logger       *zap.Logger
+}
+
+// lastEventID returns the id of the last event the client got, if it is
+// resuming the stream.
+func lastEventID(c echo.Context) string {
+	if id := c.Request().Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return c.QueryParam("last_event_id")
+}
+
+// stream is an open stream of a project.
+type stream struct {
+	*eventStreamer
+
+	project string
+	account string
+	sub     *eventSub
+
+	// First batch of the events missed by the client, whether there are
+	// more and the ids sent already.
+	replay []*commitlog.Event
+	more   bool
+	sent   map[string]struct{}
+}
+
+// open subscribes to the events of the project and fetches the first
+// batch of the events after the last one the client got, so errors occur
+// before the response starts. If the last event is unknown, the stream
+// resumes with the live events. The stream must be closed.
+func (s *eventStreamer) open(ctx context.Context, project, account, last string) (*stream, error) {
+	st := &stream{
+		eventStreamer: s,
+		project:       project,
+		account:       account,
+		sent:          make(map[string]struct{}),
+	}
+
+	// Subscribe first so no event is missed between the replay and the
+	// live events.
+	st.sub = s.hub.subscribe(project)
+
+	if last == "" {
+		return st, nil
+	}
+
+	req := commitlog.EventsRequest{
+		Project: project,
+		After:   last,
+		Limit:   streamResumeBatch,
+	}
+
+	var (
+		rep *commitlog.EventsReply
+		err error
+	)
+
+	for i := 1; ; i++ {
+		rep, err = s.commitlogSvc.Events(ctx, &req)
+		if status.Code(err) != codes.NotFound || i == streamResumeAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			st.close()
+			return nil, ctx.Err()
+		case <-time.After(streamResumeDelay):
+		}
+	}
+
+	if status.Code(err) == codes.NotFound {
+		s.logger.Info("event stream resumed from unknown event",
+			zap.String("project", project),
+			zap.String("event", last),
+		)
+		return st, nil
+	}
+
+	if err != nil {
+		st.close()
+		return nil, err
+	}
+
+	st.replay = rep.Events
+	st.more = rep.More && len(rep.Events) > 0
+
+	return st, nil
+}
+
+func (st *stream) close() {
+	st.hub.unsubscribe(st.sub)
+}
+
+// authors resolves the authors of the events.
+func (st *stream) authors(ctx context.Context, events []*commitlog.Event) (map[string]*author, error) {
+	ids := make([]string, len(events))
+	for i, e := range events {
+		ids[i] = e.Author
+	}
+	return st.enricher.GetAuthors(ctx, ids)
+}
+
+// sendReplay sends the missed events a batch at a time, up to
+// streamReplayMax. It returns whether events were left over.
+func (st *stream) sendReplay(ctx context.Context, send func(*apiEvent) error) (bool, error) {
+	events, more := st.replay, st.more
+	st.replay = nil
+
+	for n := 0; len(events) > 0; {
+		authors, err := st.authors(ctx, events)
+		if err != nil {
+			return false, err
+		}
+
+		for _, e := range events {
+			if err := send(newAPIEvent(e, authors)); err != nil {
+				return false, err
+			}
+			st.sent[e.Id] = struct{}{}
+		}
+
+		n += len(events)
+
+		if !more {
+			return false, nil
+		}
+
+		if n >= streamReplayMax {
+			return true, nil
+		}
+
+		rep, err := st.commitlogSvc.Events(ctx, &commitlog.EventsRequest{
+			Project: st.project,
+			After:   events[len(events)-1].Id,
+			Limit:   streamResumeBatch,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		events, more = rep.Events, rep.More
+	}
+
+	return false, nil
+}
+
+// run sends the missed events and then the live events until the context
+// is done, the client falls behind or can no longer view the project.
+func (st *stream) run(ctx context.Context, send func(*apiEvent) error, keepAlive func() error) error {
+	more, err := st.sendReplay(ctx, send)
+	if err != nil {
+		return err
+	}
+
+	// The client resumes from the last replayed event.
+	if more {
+		st.logger.Info("event stream replay truncated",
+			zap.String("project", st.project),
+		)
+		return nil
+	}
+
+	ticker := time.NewTicker(streamKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-st.sub.dropped:
+			st.logger.Info("event stream fell behind",
+				zap.String("project", st.project),
+			)
+			return nil
+
+		case <-ticker.C:
+			if err := keepAlive(); err != nil {
+				return err
+			}
+
+		case e := <-st.sub.events:
+			// Sent with the replay.
+			if _, ok := st.sent[e.Id]; ok {
+				delete(st.sent, e.Id)
+				continue
+			}
+
+			authors, err := st.authors(ctx, []*commitlog.Event{e})
+			if err != nil {
+				return err
+			}
+
+			if err := send(newAPIEvent(e, authors)); err != nil {
+				return err
+			}
+
+			if streamAccessEvents[e.Type] {
+				canView, err := st.enricher.CanViewProject(ctx, st.project, st.account)
+				if err != nil {
+					return err
+				}
+				if !canView {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// Serve serves the events of the project as server-sent events, or over a
+// WebSocket if the client asks to upgrade the connection.
+func (s *eventStreamer) Serve(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	project := c.Param("id")
+	account := c.Get("user.id").(string)
+
+	canView, err := s.enricher.CanViewProject(ctx, project, account)
+	if err != nil {
+		return c.String(http.StatusServiceUnavailable, err.Error())
+	}
+	if !canView {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	st, err := s.open(ctx, project, account, lastEventID(c))
+	if err != nil {
+		return err
+	}
+	defer st.close()
+
+	if c.IsWebSocket() {
+		return st.serveWebSocket(c)
+	}
+
+	return st.serveSSE(c)
+}
+
+func (st *stream) serveSSE(c echo.Context) error {
+	w := c.Response()
+
+	h := w.Header()
+	h.Set(echo.HeaderContentType, "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	// Disable response buffering of nginx.
+	h.Set("X-Accel-Buffering", "no")
+
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	send := func(e *apiEvent) error {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		// Events without an id would reset the id of the last event.
+		if e.ID != "" {
+			if _, err := fmt.Fprintf(w, "id: %s\n", e.ID); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", b); err != nil {
+			return err
+		}
+
+		w.Flush()
+		return nil
+	}
+
+	keepAlive := func() error {
+		if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+			return err
+		}
+
+		w.Flush()
+		return nil
+	}
+
+	// The response is committed so errors can only be logged.
+	if err := st.run(c.Request().Context(), send, keepAlive); err != nil {
+		st.logger.Warn("event stream failed",
+			zap.Error(err),
+			zap.String("project", st.project),
+		)
+	}
+
+	return nil
+}
+
+func (st *stream) serveWebSocket(c echo.Context) error {
+	server := websocket.Server{
+		// Any origin is accepted since the connection is authenticated
+		// with a bearer token rather than cookies.
+		Handshake: func(*websocket.Config, *http.Request) error {
+			return nil
+		},
+
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+
+			ctx, cancel := context.WithCancel(c.Request().Context())
+			defer cancel()
+
+			// Messages of the client are ignored. Reading handles the
+			// control frames and notices when the client goes away.
+			go func() {
+				io.Copy(ioutil.Discard, ws)
+				cancel()
+			}()
+
+			send := func(e *apiEvent) error {
+				ws.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+				return websocket.JSON.Send(ws, e)
+			}
+
+			keepAlive := func() error {
+				ws.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+				ws.PayloadType = websocket.PingFrame
+				_, err := ws.Write(nil)
+				return err
+			}
+
+			if err := st.run(ctx, send, keepAlive); err != nil {
+				st.logger.Warn("event stream failed",
+					zap.Error(err),
+					zap.String("project", st.project),
+				)
+			}
+		},
+	}
+
+	server.ServeHTTP(c.Response(), c.Request())
+
+	return nil
+}
+
+// queryToken passes the access_token query parameter on as a bearer token
+// if the request has no Authorization header. Browsers cannot set headers
+// on EventSource and WebSocket requests. URLs end up in logs and browser
+// history, so only the short-lived access tokens minted by the gateway are
+// accepted, not personal access tokens or external tokens.
+func queryToken(v *jwtVerifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+
+			if r.Header.Get(echo.HeaderAuthorization) != "" {
+				return next(c)
+			}
+
+			token := c.QueryParam("access_token")
+			if token == "" {
+				return next(c)
+			}
+
+			if !v.ownAccessToken(token) {
+				return echo.NewHTTPError(http.StatusUnauthorized, "access_token must be an access token issued by the gateway")
+			}
+
+			r.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+
+			return next(c)
+		}
+	}
+}
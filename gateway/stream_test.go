@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rdm-academy/api/account"
+	"github.com/rdm-academy/api/commitlog"
+)
+
+// fakeEventLog has the events e1 and e2 after e0, and n1 to nN after n0.
+// The events in missing are not found for the given number of requests.
+type fakeEventLog struct {
+	commitlog.ServiceClient
+	n       int
+	missing map[string]int
+}
+
+func (f *fakeEventLog) Events(ctx context.Context, req *commitlog.EventsRequest, opts ...transport.RequestOption) (*commitlog.EventsReply, error) {
+	rep := &commitlog.EventsReply{}
+
+	if f.missing[req.After] > 0 {
+		f.missing[req.After]--
+		return nil, status.Error(codes.NotFound, "event not found")
+	}
+
+	if req.After == "e0" {
+		rep.Events = []*commitlog.Event{
+			{Project: "p1", Id: "e1", Author: "u1", Type: "node.added"},
+			{Project: "p1", Id: "e2", Author: "u1", Type: "node.added"},
+		}
+		return rep, nil
+	}
+
+	var i int
+	if _, err := fmt.Sscanf(req.After, "n%d", &i); err != nil {
+		return rep, nil
+	}
+
+	for i++; i <= f.n && len(rep.Events) < int(req.Limit); i++ {
+		rep.Events = append(rep.Events, &commitlog.Event{Project: "p1", Id: fmt.Sprintf("n%d", i), Author: "u1"})
+	}
+	rep.More = i <= f.n
+
+	return rep, nil
+}
+
+func newTestStreamer() *eventStreamer {
+	accounts := &countingAccounts{
+		user: &account.GetUserResponse{Id: "u1", Name: "Jane"},
+	}
+
+	return &eventStreamer{
+		hub:          newEventHub(),
+		commitlogSvc: &fakeEventLog{},
+		enricher: &Enricher{
+			accountSvc: accounts,
+			projectSvc: &fakeProjects{},
+		},
+		logger: zap.NewNop(),
+	}
+}
+
+func TestEventHub(t *testing.T) {
+	h := newEventHub()
+
+	s1 := h.subscribe("p1")
+	s2 := h.subscribe("p2")
+
+	h.publish(&commitlog.Event{Project: "p1", Id: "e1"})
+
+	if len(s1.events) != 1 || len(s2.events) != 0 {
+		t.Errorf("expected the event in p1 only, got %d and %d", len(s1.events), len(s2.events))
+	}
+
+	// Subscribers that fall behind are dropped.
+	for i := 0; i < streamBuffer; i++ {
+		h.publish(&commitlog.Event{Project: "p1"})
+	}
+
+	select {
+	case <-s1.dropped:
+	default:
+		t.Error("expected the subscriber to be dropped")
+	}
+
+	if n := h.len(); n != 1 {
+		t.Errorf("expected 1 subscriber, got %d", n)
+	}
+
+	h.unsubscribe(s2)
+
+	if n := h.len(); n != 0 {
+		t.Errorf("expected no subscribers, got %d", n)
+	}
+}
+
+func TestEventStreamResume(t *testing.T) {
+	s := newTestStreamer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	st, err := s.open(ctx, "p1", "u1", "e0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.close()
+
+	// Delivered live as well as with the replay.
+	s.hub.publish(&commitlog.Event{Project: "p1", Id: "e2", Author: "u1"})
+	s.hub.publish(&commitlog.Event{Project: "p1", Id: "e3", Author: "u1"})
+
+	events := make(chan *apiEvent, 10)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- st.run(ctx, func(e *apiEvent) error {
+			events <- e
+			return nil
+		}, func() error { return nil })
+	}()
+
+	for _, id := range []string{"e1", "e2", "e3"} {
+		select {
+		case e := <-events:
+			if e.ID != id {
+				t.Fatalf("expected %s, got %s", id, e.ID)
+			}
+			if e.Author == nil || e.Author.Name != "Jane" {
+				t.Errorf("expected the author to be resolved, got %v", e.Author)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", id)
+		}
+	}
+
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEventStreamReplayLimit(t *testing.T) {
+	s := newTestStreamer()
+	s.commitlogSvc = &fakeEventLog{n: streamReplayMax + streamResumeBatch + 1}
+
+	ctx := context.Background()
+
+	st, err := s.open(ctx, "p1", "u1", "n0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.close()
+
+	var last string
+	n := 0
+
+	err = st.run(ctx, func(e *apiEvent) error {
+		n++
+		last = e.ID
+		return nil
+	}, func() error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The stream ends after the limit and resumes from the last event.
+	if n != streamReplayMax {
+		t.Fatalf("expected %d events, got %d", streamReplayMax, n)
+	}
+
+	st, err = s.open(ctx, "p1", "u1", last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.close()
+
+	rctx, cancel := context.WithCancel(ctx)
+	n = 0
+
+	err = st.run(rctx, func(e *apiEvent) error {
+		n++
+		if e.ID == fmt.Sprintf("n%d", streamReplayMax+streamResumeBatch+1) {
+			cancel()
+		}
+		return nil
+	}, func() error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != streamResumeBatch+1 {
+		t.Errorf("expected %d events, got %d", streamResumeBatch+1, n)
+	}
+}
+
+func TestEventStreamAccessLost(t *testing.T) {
+	s := newTestStreamer()
+
+	ctx := context.Background()
+
+	// u2 cannot view p1, e.g. after the project was trashed.
+	st, err := s.open(ctx, "p1", "u2", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.close()
+
+	s.hub.publish(&commitlog.Event{Project: "p1", Id: "e1", Type: "project.trashed"})
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- st.run(ctx, func(*apiEvent) error { return nil }, func() error { return nil })
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the stream to end")
+	}
+}
+
+func TestEventStreamUnknownEvent(t *testing.T) {
+	s := newTestStreamer()
+
+	// e0 is recorded after the first lookup, x0 never.
+	s.commitlogSvc = &fakeEventLog{missing: map[string]int{
+		"e0": 1,
+		"x0": streamResumeAttempts,
+	}}
+
+	ctx := context.Background()
+
+	st, err := s.open(ctx, "p1", "u1", "e0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.close()
+
+	if len(st.replay) != 2 {
+		t.Errorf("expected the missed events after a retry, got %d", len(st.replay))
+	}
+
+	st, err = s.open(ctx, "p1", "u1", "x0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.close()
+
+	if len(st.replay) != 0 || st.more {
+		t.Errorf("expected no missed events, got %d", len(st.replay))
+	}
+
+	// The stream continues with the live events.
+	s.hub.publish(&commitlog.Event{Project: "p1", Id: "x1", Author: "u1"})
+
+	rctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var got string
+	err = st.run(rctx, func(e *apiEvent) error {
+		got = e.ID
+		cancel()
+		return nil
+	}, func() error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "x1" {
+		t.Errorf("expected x1, got %s", got)
+	}
+}
+
+func TestEventStreamNotFound(t *testing.T) {
+	s := newTestStreamer()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/projects/p1/events", nil)
+	rec := httptest.NewRecorder()
+
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("p1")
+	c.Set("user.id", "u2")
+
+	if err := s.Serve(c); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+
+	if n := s.hub.len(); n != 0 {
+		t.Errorf("expected no subscribers, got %d", n)
+	}
+}
+
+func TestQueryToken(t *testing.T) {
+	v := &jwtVerifier{
+		hmacKey:   []byte("shared"),
+		ownKey:    []byte("own"),
+		ownIssuer: "gateway",
+	}
+
+	issuer := &tokenIssuer{
+		key:       []byte("own"),
+		issuer:    "gateway",
+		accessTTL: time.Minute,
+	}
+
+	user := &account.GetUserResponse{Id: "u1"}
+	now := time.Now()
+
+	access, _ := issuer.sign(user, accessTokenType, "a1", time.Minute, now)
+	refresh, _ := issuer.sign(user, refreshTokenType, "s1", time.Hour, now)
+	external := signToken(t, jwt.SigningMethodHS256, "", []byte("shared"), jwt.MapClaims{
+		"sub": "u1",
+		"exp": now.Add(time.Minute).Unix(),
+	})
+
+	for _, x := range []struct {
+		name  string
+		token string
+		code  int
+	}{
+		{"access token", access, http.StatusOK},
+		{"refresh token", refresh, http.StatusUnauthorized},
+		{"external token", external, http.StatusUnauthorized},
+		{"personal access token", account.TokenPrefix + "secret", http.StatusUnauthorized},
+	} {
+		e := echo.New()
+		e.GET("/", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		}, queryToken(v))
+
+		req := httptest.NewRequest(http.MethodGet, "/?access_token="+x.token, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != x.code {
+			t.Errorf("%s: expected %d, got %d", x.name, x.code, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/rdm-academy/api/account"
+)
+
+// TokenMiddleware authenticates requests with a personal access token and
+// passes other bearer tokens on to the JWT middleware. The account and the
+// scopes of the token are added to the context.
+func TokenMiddleware(accounts account.ServiceClient, jwtMiddleware echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withJWT := jwtMiddleware(next)
+
+		return func(c echo.Context) error {
+			auth := c.Request().Header.Get(echo.HeaderAuthorization)
+
+			if len(auth) <= len("Bearer ") || !strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+				return withJWT(c)
+			}
+
+			secret := auth[len("Bearer "):]
+			if !strings.HasPrefix(secret, account.TokenPrefix) {
+				return withJWT(c)
+			}
+
+			rep, err := accounts.VerifyToken(c.Request().Context(), &account.VerifyTokenRequest{
+				Secret: secret,
+			})
+			if err != nil {
+				return err
+			}
+
+			c.Set(tokenIdKey, rep.Token.Id)
+			c.Set(tokenScopesKey, rep.Token.Scopes)
+			c.Set(userIdKey, rep.User.Id)
+			c.Set(userEmailKey, rep.User.Email)
+
+			return next(c)
+		}
+	}
+}
+
+// jwtOnly rejects requests authenticated with a personal access token, so
+// a token cannot be used to manage the credentials of the account.
+func jwtOnly(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if _, ok := c.Get(tokenIdKey).(string); ok {
+			return echo.NewHTTPError(http.StatusForbidden, "personal access tokens cannot be used for this request")
+		}
+
+		return next(c)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/internal/rpcerr"
+	"github.com/rdm-academy/api/nodes"
+)
+
+// multipartUpload uploads the files of a multipart form and associates them
+// to the node. The parts are streamed to storage as they are read from the
+// request. A multipart body can only be read in order, but a file is
+// finalized while the next one is read, so up to concurrency uploads are in
+// flight. The data service checks the quotas with the stored size of each
+// file.
+type multipartUpload struct {
+	enricher *Enricher
+	dataSvc  data.ServiceClient
+	nodeSvc  nodes.ServiceClient
+
+	// Maximum number of concurrent file uploads per request.
+	concurrency int
+}
+
+func (u *multipartUpload) serve(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	account := c.Get("user.id").(string)
+	project := c.Param("project")
+	node := c.Param("node")
+
+	canView, err := u.enricher.CanViewProject(ctx, project, account)
+	if err != nil {
+		return c.String(http.StatusServiceUnavailable, err.Error())
+	}
+	if !canView {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	_, err = u.nodeSvc.Get(ctx, &nodes.GetRequest{
+		Project: project,
+		Id:      node,
+	})
+	if err != nil {
+		return err
+	}
+
+	mr, err := c.Request().MultipartReader()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	var (
+		wg      sync.WaitGroup
+		results []*uploadResult
+
+		// Error reading the body. The files uploaded before it are
+		// still added to the node.
+		readErr error
+	)
+
+	// Bounds the number of in-flight uploads.
+	sem := make(chan struct{}, u.concurrency)
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+
+		if part.FormName() != "files" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		res := &uploadResult{
+			Name: part.FileName(),
+		}
+		results = append(results, res)
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		// The part is copied into the pipe while the upload reads from it.
+		pr, pw := io.Pipe()
+
+		go func(mediatype string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// Use client helper function to upload.
+			id, err := data.Upload(ctx, u.dataSvc, &data.UploadRequest{
+				Account: account,
+				Project: project,
+			}, mediatype, pr)
+
+			// Unblock the copy if the upload stopped reading early.
+			pr.CloseWithError(err)
+
+			if err != nil {
+				res.Error = rpcerr.Message(err)
+				return
+			}
+
+			res.ID = id
+		}(part.Header.Get(echo.HeaderContentType))
+
+		_, err = io.Copy(pw, part)
+		pw.CloseWithError(err)
+		part.Close()
+	}
+
+	wg.Wait()
+
+	var files []*nodes.File
+	for _, res := range results {
+		if res.Error != "" {
+			continue
+		}
+
+		files = append(files, &nodes.File{
+			Id:   res.ID,
+			Name: res.Name,
+		})
+	}
+
+	if len(files) > 0 {
+		_, err = u.nodeSvc.AddFiles(ctx, &nodes.AddFilesRequest{
+			Project: project,
+			Id:      node,
+			Files:   files,
+			Account: account,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// The remaining parts could not be read. The results report the
+	// files that were added.
+	if readErr != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": readErr.Error(),
+			"files": results,
+		})
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
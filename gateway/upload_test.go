@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/labstack/echo"
+	"github.com/rdm-academy/api/data"
+	"github.com/rdm-academy/api/nodes"
+)
+
+// fakeUploadNodes records the files added to the node.
+type fakeUploadNodes struct {
+	nodes.ServiceClient
+
+	files []*nodes.File
+}
+
+func (n *fakeUploadNodes) Get(ctx context.Context, req *nodes.GetRequest, opts ...transport.RequestOption) (*nodes.GetReply, error) {
+	return &nodes.GetReply{}, nil
+}
+
+func (n *fakeUploadNodes) AddFiles(ctx context.Context, req *nodes.AddFilesRequest, opts ...transport.RequestOption) (*nodes.NoReply, error) {
+	n.files = append(n.files, req.Files...)
+	return &nodes.NoReply{}, nil
+}
+
+// fakeUploadData hands out signed URLs of the storage server.
+type fakeUploadData struct {
+	data.ServiceClient
+
+	url string
+
+	mu  sync.Mutex
+	ids int
+}
+
+func (d *fakeUploadData) Upload(ctx context.Context, req *data.UploadRequest, opts ...transport.RequestOption) (*data.UploadReply, error) {
+	d.mu.Lock()
+	d.ids++
+	id := fmt.Sprintf("o%d", d.ids)
+	d.mu.Unlock()
+
+	return &data.UploadReply{
+		Id:        id,
+		SignedUrl: d.url + "/" + id,
+	}, nil
+}
+
+func (d *fakeUploadData) Update(ctx context.Context, req *data.UpdateRequest, opts ...transport.RequestOption) (*data.UpdateReply, error) {
+	return &data.UpdateReply{}, nil
+}
+
+// uploadStorage fails the uploads of files with the content fail and
+// records the maximum number of concurrent uploads.
+type uploadStorage struct {
+	mu       sync.Mutex
+	inflight int
+	max      int
+}
+
+func (s *uploadStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.inflight++
+	if s.inflight > s.max {
+		s.max = s.inflight
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.inflight--
+		s.mu.Unlock()
+	}()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Keeps the upload in flight while the next part is read.
+	time.Sleep(20 * time.Millisecond)
+
+	if string(b) == "fail" {
+		http.Error(w, "storage error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// multipartBody returns a body with a part of the files field for each
+// name and content.
+func multipartBody(t *testing.T, files [][2]string) (*bytes.Buffer, string) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	for _, f := range files {
+		w, err := mw.CreateFormFile("files", f[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(f[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &buf, mw.FormDataContentType()
+}
+
+type uploadResponse struct {
+	Error string          `json:"error"`
+	Files []*uploadResult `json:"files"`
+}
+
+func TestMultipartUpload(t *testing.T) {
+	stg := &uploadStorage{}
+	srv := httptest.NewServer(stg)
+	defer srv.Close()
+
+	nodeSvc := &fakeUploadNodes{}
+
+	u := &multipartUpload{
+		enricher:    &Enricher{projectSvc: &fakeProjects{}},
+		dataSvc:     &fakeUploadData{url: srv.URL},
+		nodeSvc:     nodeSvc,
+		concurrency: 2,
+	}
+
+	post := func(project string, body *bytes.Buffer, contentType string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set(echo.HeaderContentType, contentType)
+		rec := httptest.NewRecorder()
+
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("project", "node")
+		c.SetParamValues(project, "n1")
+		c.Set("user.id", "u1")
+
+		if err := u.serve(c); err != nil {
+			t.Fatal(err)
+		}
+
+		return rec
+	}
+
+	// The project is checked before the body is read.
+	body, ct := multipartBody(t, [][2]string{{"a.txt", "a"}})
+	if rec := post("p2", body, ct); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another project, got %d", rec.Code)
+	}
+	if body.Len() == 0 {
+		t.Error("expected the body not to be read")
+	}
+
+	// The parts are uploaded with at most the bound in flight, and one
+	// failing upload does not affect the others.
+	files := [][2]string{
+		{"a.txt", "a"},
+		{"b.txt", "b"},
+		{"c.txt", "fail"},
+		{"d.txt", "d"},
+		{"e.txt", "e"},
+		{"f.txt", "f"},
+	}
+
+	body, ct = multipartBody(t, files)
+	rec := post("p1", body, ct)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	var results []*uploadResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != len(files) {
+		t.Fatalf("expected %d results, got %d", len(files), len(results))
+	}
+
+	for i, res := range results {
+		failed := files[i][1] == "fail"
+
+		if res.Name != files[i][0] {
+			t.Errorf("expected %s, got %s", files[i][0], res.Name)
+		}
+		if failed && (res.Error == "" || res.ID != "") {
+			t.Errorf("%s: expected an error, got %v", res.Name, res)
+		}
+		if !failed && (res.Error != "" || res.ID == "") {
+			t.Errorf("%s: expected an id, got %v", res.Name, res)
+		}
+	}
+
+	if stg.max > u.concurrency {
+		t.Errorf("expected at most %d concurrent uploads, got %d", u.concurrency, stg.max)
+	}
+
+	// Only the uploaded files are added to the node.
+	if len(nodeSvc.files) != len(files)-1 {
+		t.Errorf("expected %d files to be added, got %d", len(files)-1, len(nodeSvc.files))
+	}
+	for _, f := range nodeSvc.files {
+		if f.Name == "c.txt" {
+			t.Error("expected the failed file not to be added")
+		}
+	}
+
+	// The files read before the body was cut off are still added.
+	nodeSvc.files = nil
+
+	body, ct = multipartBody(t, [][2]string{{"a.txt", "a"}, {"b.txt", strings.Repeat("b", 1024)}})
+	body.Truncate(body.Len() - 512)
+
+	rec = post("p1", body, ct)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body)
+	}
+
+	var partial uploadResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &partial); err != nil {
+		t.Fatal(err)
+	}
+
+	if partial.Error == "" {
+		t.Error("expected the read error")
+	}
+	if len(partial.Files) != 2 || partial.Files[0].ID == "" || partial.Files[1].Error == "" {
+		t.Errorf("expected the first file to be uploaded, got %v", partial.Files)
+	}
+	if len(nodeSvc.files) != 1 || nodeSvc.files[0].Name != "a.txt" {
+		t.Errorf("expected a.txt to be added, got %v", nodeSvc.files)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import "github.com/rdm-academy/api/webhook"
+
+// webhookUpdate is a partial update of a webhook. Fields that are not
+// present are left unchanged.
+type webhookUpdate struct {
+	URL          *string   `json:"url"`
+	Events       *[]string `json:"events"`
+	Active       *bool     `json:"active"`
+	RotateSecret bool      `json:"rotate_secret"`
+}
+
+// apply returns the request to update the current webhook with the
+// present fields.
+func (u *webhookUpdate) apply(w *webhook.Webhook) *webhook.UpdateRequest {
+	req := &webhook.UpdateRequest{
+		Project:      w.Project,
+		Id:           w.Id,
+		Url:          w.Url,
+		Events:       w.Events,
+		Active:       w.Active,
+		RotateSecret: u.RotateSecret,
+	}
+
+	if u.URL != nil {
+		req.Url = *u.URL
+	}
+	if u.Events != nil {
+		req.Events = *u.Events
+	}
+	if u.Active != nil {
+		req.Active = *u.Active
+	}
+
+	return req
+}
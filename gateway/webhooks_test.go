@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rdm-academy/api/webhook"
+)
+
+func TestWebhookUpdate(t *testing.T) {
+	cur := &webhook.Webhook{
+		Id:      "w1",
+		Project: "p1",
+		Url:     "https://example.com/hook",
+		Events:  []string{"*"},
+		Active:  true,
+	}
+
+	var u webhookUpdate
+	if err := json.Unmarshal([]byte(`{"rotate_secret": true}`), &u); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only rotates the secret.
+	req := u.apply(cur)
+	if req.Url != cur.Url || len(req.Events) != 1 || !req.Active || !req.RotateSecret {
+		t.Errorf("expected the webhook to be unchanged, got %v", req)
+	}
+
+	u = webhookUpdate{}
+	if err := json.Unmarshal([]byte(`{"active": false, "events": ["node.added"]}`), &u); err != nil {
+		t.Fatal(err)
+	}
+
+	req = u.apply(cur)
+	if req.Url != cur.Url || req.Active || req.RotateSecret || len(req.Events) != 1 || req.Events[0] != "node.added" {
+		t.Errorf("unexpected update %v", req)
+	}
+}
@@ -0,0 +1,48 @@
+// Package archive names the entries of the archives and packages built
+// from projects.
+package archive
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PathName cleans a title or file name so it can be used as a single path
+// segment in an archive.
+func PathName(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 32 {
+			return -1
+		}
+		return r
+	}, s)
+
+	if s == "." || s == ".." {
+		return ""
+	}
+
+	return s
+}
+
+// UniqueName returns a unique name within the set. The id is used to
+// disambiguate names that are empty or already taken.
+func UniqueName(set map[string]struct{}, name, id string) string {
+	if name == "" {
+		name = id
+	}
+
+	if _, ok := set[name]; ok {
+		ext := path.Ext(name)
+		name = fmt.Sprintf("%s-%s%s", strings.TrimSuffix(name, ext), id, ext)
+	}
+
+	set[name] = struct{}{}
+
+	return name
+}
@@ -0,0 +1,38 @@
+package archive
+
+import "testing"
+
+func TestPathName(t *testing.T) {
+	tests := map[string]string{
+		" Raw data ":   "Raw data",
+		"a/b\\c:d":     "a_b_c_d",
+		"tab\tbed":     "tabbed",
+		"..":           "",
+		"results.csv":  "results.csv",
+		"what?<>|*\"x": "what______x",
+	}
+
+	for in, exp := range tests {
+		if out := PathName(in); out != exp {
+			t.Errorf("%q: expected %q, got %q", in, exp, out)
+		}
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	set := make(map[string]struct{})
+
+	names := []string{
+		UniqueName(set, "data.csv", "f1"),
+		UniqueName(set, "data.csv", "f2"),
+		UniqueName(set, "", "f3"),
+	}
+
+	exp := []string{"data.csv", "data-f2.csv", "f3"}
+
+	for i, n := range names {
+		if n != exp[i] {
+			t.Errorf("expected %q, got %q", exp[i], n)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Package mongotest provides scratch MongoDB databases for tests.
+package mongotest
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Addr is the environment variable with the address of the MongoDB used by
+// tests. Tests that need it are skipped if it is not set.
+const Addr = "MONGO_TEST_ADDR"
+
+// DB returns a database with a unique name starting with prefix, and a
+// function that drops it and closes the session.
+func DB(t testing.TB, prefix string) (*mgo.Database, func()) {
+	addr := os.Getenv(Addr)
+	if addr == "" {
+		t.Skip(Addr + " required")
+	}
+
+	sess, err := mgo.DialWithTimeout(addr, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := sess.DB(prefix + "_test_" + bson.NewObjectId().Hex())
+
+	return db, func() {
+		db.DropDatabase()
+		sess.Close()
+	}
+}
@@ -0,0 +1,16 @@
+// Package rpcerr inspects the errors returned by the service clients.
+package rpcerr
+
+import (
+	"google.golang.org/grpc/status"
+)
+
+// Message returns the message of a gRPC status error or the error string
+// otherwise.
+func Message(err error) string {
+	if sts, ok := status.FromError(err); ok {
+		return sts.Message()
+	}
+
+	return err.Error()
+}
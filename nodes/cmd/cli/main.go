@@ -141,6 +141,30 @@ func main() {
 		}
 		rep, err = client.Get(ctx, &req)
 
+	case "GetFile":
+		client := nodes.NewServiceClient(tp)
+		var req nodes.GetFileRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.GetFile(ctx, &req)
+
+	case "Copy":
+		client := nodes.NewServiceClient(tp)
+		var req nodes.CopyRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.Copy(ctx, &req)
+
+	case "FileUsage":
+		client := nodes.NewServiceClient(tp)
+		var req nodes.FileUsageRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.FileUsage(ctx, &req)
+
 	default:
 		log.Fatalf("unknown method %s", meth)
 	}
@@ -0,0 +1,154 @@
+package nodes
+
+import (
+	"log"
+	"strings"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Collection of the nodes each file is associated with, so the usage of a
+// file is found without scanning the node collections of all projects.
+// Project collections are named by UUID so the name does not conflict.
+const fileRefsCol = "file_refs"
+
+// Key of the unique index of the references.
+var fileRefsKey = []string{"file", "project", "node"}
+
+type fileRef struct {
+	File    string `bson:"file"`
+	Project string `bson:"project"`
+	Node    string `bson:"node"`
+}
+
+// addFileRefs records that the files are associated with the node. It is
+// called before the node is changed since a missing reference could let
+// the data service remove a file in use.
+func addFileRefs(db *mgo.Database, project, node string, ids []string) error {
+	for _, id := range ids {
+		ref := &fileRef{
+			File:    id,
+			Project: project,
+			Node:    node,
+		}
+
+		if _, err := db.C(fileRefsCol).Upsert(ref, ref); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeFileRefs removes the references of the files to the node.
+func removeFileRefs(db *mgo.Database, project, node string, ids []string) error {
+	q := bson.M{
+		"file": bson.M{
+			"$in": ids,
+		},
+		"project": project,
+		"node":    node,
+	}
+
+	_, err := db.C(fileRefsCol).RemoveAll(q)
+	return err
+}
+
+// dropFileRefs removes the references of all nodes of the project.
+func dropFileRefs(db *mgo.Database, project string) error {
+	_, err := db.C(fileRefsCol).RemoveAll(bson.M{"project": project})
+	return err
+}
+
+// hasFileRefsIndex checks whether the unique index of the references
+// exists.
+func hasFileRefsIndex(db *mgo.Database) (bool, error) {
+	cols, err := db.CollectionNames()
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	for _, col := range cols {
+		if col == fileRefsCol {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return false, nil
+	}
+
+	idxs, err := db.C(fileRefsCol).Indexes()
+	if err != nil {
+		return false, err
+	}
+
+	for _, idx := range idxs {
+		if strings.Join(idx.Key, ",") == strings.Join(fileRefsKey, ",") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// indexFileRefs records the references of the nodes created before they
+// were kept. The unique index is created once all nodes are indexed, so
+// an interrupted run is started over.
+func indexFileRefs(db *mgo.Database) error {
+	ok, err := hasFileRefsIndex(db)
+	if err != nil || ok {
+		return err
+	}
+
+	cols, err := db.CollectionNames()
+	if err != nil {
+		return err
+	}
+
+	var projects int
+
+	for _, col := range cols {
+		if col == fileRefsCol || strings.HasPrefix(col, "system.") {
+			continue
+		}
+
+		projects++
+
+		var ns []*node
+		if err := db.C(col).Find(nil).Select(bson.M{"files": 1}).All(&ns); err != nil {
+			return err
+		}
+
+		for _, n := range ns {
+			ids := make([]string, len(n.Files))
+			for i, f := range n.Files {
+				ids[i] = f.ID
+			}
+
+			if err := addFileRefs(db, col, n.ID, ids); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Printf("nodes: indexed the file references of %d projects", projects)
+
+	return db.C(fileRefsCol).EnsureIndex(mgo.Index{
+		Key:    fileRefsKey,
+		Unique: true,
+	})
+}
+
+// ensureIndexes creates the file references and the indexes the lookups
+// rely on.
+func ensureIndexes(db *mgo.Database) error {
+	if err := indexFileRefs(db); err != nil {
+		return err
+	}
+
+	return db.C(fileRefsCol).EnsureIndexKey("project")
+}
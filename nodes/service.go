@@ -56,7 +56,7 @@ func decodeType(s string) NodeType {
 
 // newEventHandler initializes a handler taking events from a stream
 // and recording them into project-specific collections.
-func newEventHandler(s Service) transport.Handler {
+func newEventHandler(s *service) transport.Handler {
 	return func(msg *transport.Message) (proto.Message, error) {
 		ctx := context.Background()
 
@@ -94,6 +94,13 @@ func newEventHandler(s Service) transport.Handler {
 			})
 
 			return nil, err
+
+		// The project was purged so its nodes are removed.
+		case "project.deleted":
+			if err := s.dropProject(e.Project); err != nil {
+				return nil, err
+			}
+			return nil, commitlog.Ack(s.tp, "nodes", &e)
 		}
 
 		return nil, nil
@@ -133,6 +140,7 @@ func (s *service) logEvent(t string, e *logEvent) {
 	}
 
 	_, err = s.tp.Publish(t, &commitlog.Event{
+		Id:      commitlog.NewEventID(),
 		Project: e.Project,
 		Time:    time.Now().Unix(),
 		Type:    e.Type,
@@ -255,12 +263,18 @@ func (s *service) AddFiles(ctx context.Context, req *AddFilesRequest) (*NoReply,
 	}
 
 	files := make([]*file, len(req.Files))
+	ids := make([]string, len(req.Files))
 
 	for i, f := range req.Files {
 		files[i] = &file{
 			ID:   f.Id,
 			Name: f.Name,
 		}
+		ids[i] = f.Id
+	}
+
+	if err := addFileRefs(s.db, req.Project, req.Id, ids); err != nil {
+		return nil, err
 	}
 
 	u := bson.M{
@@ -276,6 +290,9 @@ func (s *service) AddFiles(ctx context.Context, req *AddFilesRequest) (*NoReply,
 
 	if err := s.db.C(req.Project).UpdateId(req.Id, u); err != nil {
 		if err == mgo.ErrNotFound {
+			if err := removeFileRefs(s.db, req.Project, req.Id, ids); err != nil {
+				return nil, err
+			}
 			return nil, status.Error(codes.NotFound, "node does not exist")
 		}
 		return nil, err
@@ -350,6 +367,10 @@ func (s *service) RemoveFiles(ctx context.Context, req *RemoveFilesRequest) (*No
 		return nil, err
 	}
 
+	if err := removeFileRefs(s.db, req.Project, req.Id, req.FileIds); err != nil {
+		return nil, err
+	}
+
 	// Log the event.
 	s.logEvent("events.project", &logEvent{
 		Type:    "node.removed-files",
@@ -396,7 +417,147 @@ func (s *service) Get(ctx context.Context, req *GetRequest) (*GetReply, error) {
 	return &r, nil
 }
 
+func (s *service) GetFile(ctx context.Context, req *GetFileRequest) (*GetFileReply, error) {
+	if strings.TrimSpace(req.Project) == "" || strings.TrimSpace(req.Id) == "" {
+		return nil, status.Error(codes.InvalidArgument, "project and id required")
+	}
+
+	q := bson.M{
+		"files.id": req.Id,
+	}
+
+	// Only select the matching file.
+	p := bson.M{
+		"files.$": 1,
+	}
+
+	var n node
+	if err := s.db.C(req.Project).Find(q).Select(p).One(&n); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "file does not exist")
+		}
+		return nil, err
+	}
+
+	f := n.Files[0]
+
+	return &GetFileReply{
+		Node: n.ID,
+		File: &File{
+			Id:   f.ID,
+			Name: f.Name,
+		},
+	}, nil
+}
+
+func (s *service) Copy(ctx context.Context, req *CopyRequest) (*NoReply, error) {
+	if strings.TrimSpace(req.Project) == "" || strings.TrimSpace(req.ToProject) == "" {
+		return nil, status.Error(codes.InvalidArgument, "project and to project required")
+	}
+
+	if req.Project == req.ToProject {
+		return nil, status.Error(codes.InvalidArgument, "cannot copy nodes to the same project")
+	}
+
+	var ns []*node
+	if err := s.db.C(req.Project).Find(nil).All(&ns); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	for _, n := range ns {
+		// File references are shared since the objects are immutable.
+		n.Project = req.ToProject
+		n.Created = now
+		n.Modified = now
+
+		ids := make([]string, len(n.Files))
+		for i, f := range n.Files {
+			ids[i] = f.ID
+		}
+
+		if err := addFileRefs(s.db, req.ToProject, n.ID, ids); err != nil {
+			return nil, err
+		}
+
+		if err := s.db.C(req.ToProject).Insert(n); err != nil {
+			// Node was already created.
+			if mgo.IsDup(err) {
+				continue
+			}
+
+			return nil, err
+		}
+	}
+
+	return &NoReply{}, nil
+}
+
+// dropProject drops the node collection of the project and the file
+// references of its nodes.
+func (s *service) dropProject(project string) error {
+	if strings.TrimSpace(project) == "" {
+		return nil
+	}
+
+	// The collection does not exist if no nodes were created.
+	if err := s.db.C(project).DropCollection(); err != nil && err.Error() != "ns not found" {
+		return err
+	}
+
+	return dropFileRefs(s.db, project)
+}
+
+func (s *service) FileUsage(ctx context.Context, req *FileUsageRequest) (*FileUsageReply, error) {
+	if len(req.Ids) == 0 {
+		return &FileUsageReply{}, nil
+	}
+
+	q := bson.M{
+		"file": bson.M{
+			"$in": req.Ids,
+		},
+	}
+
+	if req.ExcludeProject != "" {
+		q["project"] = bson.M{
+			"$ne": req.ExcludeProject,
+		}
+	}
+
+	var refs []*fileRef
+	if err := s.db.C(fileRefsCol).Find(q).Select(bson.M{"file": 1, "project": 1}).All(&refs); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(refs))
+
+	var usages []*FileUsage
+
+	for _, r := range refs {
+		if _, ok := seen[r.File]; ok {
+			continue
+		}
+
+		seen[r.File] = struct{}{}
+
+		usages = append(usages, &FileUsage{
+			Id:      r.File,
+			Project: r.Project,
+		})
+	}
+
+	return &FileUsageReply{
+		Usages: usages,
+	}, nil
+}
+
 func NewService(tp transport.Transport, db *mgo.Database) (Service, error) {
+	if err := ensureIndexes(db); err != nil {
+		return nil, err
+	}
+
 	s := &service{
 		tp: tp,
 		db: db,
@@ -18,6 +18,12 @@ It has these top-level messages:
 	RemoveFilesRequest
 	GetRequest
 	GetReply
+	GetFileRequest
+	GetFileReply
+	CopyRequest
+	FileUsageRequest
+	FileUsage
+	FileUsageReply
 */
 package nodes
 
@@ -385,6 +391,153 @@ func (m *GetReply) GetFiles() []*File {
 	return nil
 }
 
+type GetFileRequest struct {
+	Project string `protobuf:"bytes,1,opt,name=project" json:"project,omitempty"`
+	// ID of the file stored in the data service.
+	Id string `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *GetFileRequest) Reset()                    { *m = GetFileRequest{} }
+func (m *GetFileRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetFileRequest) ProtoMessage()               {}
+func (*GetFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *GetFileRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *GetFileRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type GetFileReply struct {
+	// ID of the node the file is associated with.
+	Node string `protobuf:"bytes,1,opt,name=node" json:"node,omitempty"`
+	File *File  `protobuf:"bytes,2,opt,name=file" json:"file,omitempty"`
+}
+
+func (m *GetFileReply) Reset()                    { *m = GetFileReply{} }
+func (m *GetFileReply) String() string            { return proto.CompactTextString(m) }
+func (*GetFileReply) ProtoMessage()               {}
+func (*GetFileReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *GetFileReply) GetNode() string {
+	if m != nil {
+		return m.Node
+	}
+	return ""
+}
+
+func (m *GetFileReply) GetFile() *File {
+	if m != nil {
+		return m.File
+	}
+	return nil
+}
+
+// CopyRequest copies all nodes of a project, including their titles,
+// notes and file references, to another project.
+type CopyRequest struct {
+	// Project the nodes are copied from.
+	Project string `protobuf:"bytes,1,opt,name=project" json:"project,omitempty"`
+	// Project the nodes are copied to.
+	ToProject string `protobuf:"bytes,2,opt,name=to_project,json=toProject" json:"to_project,omitempty"`
+}
+
+func (m *CopyRequest) Reset()                    { *m = CopyRequest{} }
+func (m *CopyRequest) String() string            { return proto.CompactTextString(m) }
+func (*CopyRequest) ProtoMessage()               {}
+func (*CopyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *CopyRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *CopyRequest) GetToProject() string {
+	if m != nil {
+		return m.ToProject
+	}
+	return ""
+}
+
+// FileUsageRequest finds the projects the files are associated with.
+type FileUsageRequest struct {
+	// IDs of the files stored in the data service.
+	Ids []string `protobuf:"bytes,1,rep,name=ids" json:"ids,omitempty"`
+	// Project excluded from the search.
+	ExcludeProject string `protobuf:"bytes,2,opt,name=exclude_project,json=excludeProject" json:"exclude_project,omitempty"`
+}
+
+func (m *FileUsageRequest) Reset()                    { *m = FileUsageRequest{} }
+func (m *FileUsageRequest) String() string            { return proto.CompactTextString(m) }
+func (*FileUsageRequest) ProtoMessage()               {}
+func (*FileUsageRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *FileUsageRequest) GetIds() []string {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+func (m *FileUsageRequest) GetExcludeProject() string {
+	if m != nil {
+		return m.ExcludeProject
+	}
+	return ""
+}
+
+type FileUsage struct {
+	Id      string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Project string `protobuf:"bytes,2,opt,name=project" json:"project,omitempty"`
+}
+
+func (m *FileUsage) Reset()                    { *m = FileUsage{} }
+func (m *FileUsage) String() string            { return proto.CompactTextString(m) }
+func (*FileUsage) ProtoMessage()               {}
+func (*FileUsage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *FileUsage) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *FileUsage) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+type FileUsageReply struct {
+	// The first project found for each file in use. Files not in use
+	// are omitted.
+	Usages []*FileUsage `protobuf:"bytes,1,rep,name=usages" json:"usages,omitempty"`
+}
+
+func (m *FileUsageReply) Reset()                    { *m = FileUsageReply{} }
+func (m *FileUsageReply) String() string            { return proto.CompactTextString(m) }
+func (*FileUsageReply) ProtoMessage()               {}
+func (*FileUsageReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *FileUsageReply) GetUsages() []*FileUsage {
+	if m != nil {
+		return m.Usages
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*File)(nil), "nodes.File")
 	proto.RegisterType((*NoReply)(nil), "nodes.NoReply")
@@ -395,41 +548,57 @@ func init() {
 	proto.RegisterType((*RemoveFilesRequest)(nil), "nodes.RemoveFilesRequest")
 	proto.RegisterType((*GetRequest)(nil), "nodes.GetRequest")
 	proto.RegisterType((*GetReply)(nil), "nodes.GetReply")
+	proto.RegisterType((*GetFileRequest)(nil), "nodes.GetFileRequest")
+	proto.RegisterType((*GetFileReply)(nil), "nodes.GetFileReply")
+	proto.RegisterType((*CopyRequest)(nil), "nodes.CopyRequest")
+	proto.RegisterType((*FileUsageRequest)(nil), "nodes.FileUsageRequest")
+	proto.RegisterType((*FileUsage)(nil), "nodes.FileUsage")
+	proto.RegisterType((*FileUsageReply)(nil), "nodes.FileUsageReply")
 	proto.RegisterEnum("nodes.NodeType", NodeType_name, NodeType_value)
 }
 
 func init() { proto.RegisterFile("service.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 466 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x94, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xc7, 0xf1, 0x57, 0xec, 0x4c, 0xd4, 0xc4, 0xac, 0x2a, 0xe4, 0xf6, 0x14, 0xc2, 0x25, 0xf4,
-	0x90, 0x43, 0x90, 0x10, 0x57, 0xab, 0xa5, 0x51, 0x04, 0xdd, 0x22, 0x37, 0x11, 0x47, 0x14, 0xbc,
-	0x83, 0x64, 0x70, 0xbd, 0xc6, 0xde, 0x56, 0xf8, 0x25, 0x78, 0x20, 0x9e, 0x0e, 0xed, 0xfa, 0x03,
-	0xb7, 0x26, 0x51, 0x93, 0x9b, 0x67, 0xc6, 0xff, 0xf9, 0xad, 0x67, 0xff, 0x63, 0x38, 0xca, 0x31,
-	0xbb, 0x8f, 0x42, 0x9c, 0xa5, 0x19, 0x17, 0x9c, 0x58, 0x09, 0x67, 0x98, 0x4f, 0xce, 0xc0, 0xbc,
-	0x8c, 0x62, 0x24, 0x43, 0xd0, 0x23, 0xe6, 0x69, 0x63, 0x6d, 0xda, 0x0f, 0xf4, 0x88, 0x11, 0x02,
-	0x66, 0xb2, 0xb9, 0x45, 0x4f, 0x57, 0x19, 0xf5, 0x3c, 0xe9, 0x83, 0x4d, 0x79, 0x80, 0x69, 0x5c,
-	0x4c, 0x7e, 0x6b, 0x70, 0x74, 0x9e, 0xe1, 0x46, 0x60, 0x80, 0x3f, 0xef, 0x30, 0x17, 0x9d, 0x06,
-	0x1e, 0xd8, 0x69, 0xc6, 0xbf, 0x63, 0x28, 0xaa, 0x1e, 0x75, 0x48, 0x5e, 0x81, 0x29, 0x8a, 0x14,
-	0x3d, 0x63, 0xac, 0x4d, 0x87, 0xf3, 0xd1, 0x4c, 0x1d, 0x64, 0x46, 0x39, 0xc3, 0x55, 0x91, 0x62,
-	0xa0, 0x8a, 0xe4, 0x18, 0x2c, 0x11, 0x89, 0x18, 0x3d, 0x53, 0x89, 0xcb, 0x40, 0x36, 0xdd, 0x84,
-	0x21, 0xbf, 0x4b, 0x84, 0x67, 0x95, 0x4d, 0xab, 0x70, 0xf2, 0x03, 0x46, 0x37, 0x28, 0x56, 0xf2,
-	0xad, 0xfd, 0x4f, 0xd4, 0xc0, 0x8c, 0x7d, 0x60, 0x94, 0x0b, 0xcc, 0x0f, 0x82, 0x25, 0x52, 0x59,
-	0xc3, 0x54, 0xb0, 0x03, 0xf6, 0x0b, 0x46, 0x3e, 0x63, 0xf2, 0x92, 0x0e, 0x80, 0xbd, 0x04, 0xeb,
-	0x9b, 0x54, 0x7a, 0xc6, 0xd8, 0x98, 0x0e, 0xe6, 0x83, 0x6a, 0xd8, 0xb2, 0x5b, 0x50, 0x56, 0x76,
-	0x90, 0x73, 0x20, 0x01, 0xde, 0xf2, 0x7b, 0x3c, 0x10, 0x7e, 0x02, 0x8e, 0x44, 0x7c, 0x89, 0x58,
-	0xc9, 0xef, 0x07, 0xb6, 0x8c, 0x97, 0x6c, 0x17, 0xf4, 0x2d, 0xc0, 0x02, 0xc5, 0xde, 0x30, 0xe9,
-	0x48, 0x47, 0x09, 0xd3, 0xb8, 0xe8, 0xc8, 0x6a, 0xcb, 0xe9, 0x4f, 0xb2, 0xdc, 0x03, 0x17, 0x34,
-	0xd7, 0x65, 0xb6, 0xaf, 0xab, 0x99, 0xab, 0xb5, 0x6d, 0xae, 0x67, 0x0b, 0x70, 0x6a, 0x00, 0x19,
-	0x80, 0xbd, 0xa6, 0x1f, 0xe8, 0xf5, 0x67, 0xea, 0x3e, 0x23, 0x0e, 0x98, 0x17, 0xfe, 0xca, 0x77,
-	0x35, 0x99, 0x3e, 0xbf, 0xbe, 0xfa, 0xb4, 0x5e, 0xbd, 0x77, 0x75, 0x02, 0xd0, 0xbb, 0xf2, 0xe9,
-	0xda, 0xff, 0xe8, 0x1a, 0xb2, 0x70, 0xb9, 0xa4, 0x17, 0x4b, 0xba, 0x70, 0xcd, 0xf9, 0x1f, 0x1d,
-	0xec, 0x9b, 0x72, 0x77, 0xc9, 0x0c, 0x7a, 0xe5, 0xda, 0x91, 0xe3, 0x0a, 0xf9, 0x60, 0x0b, 0x4f,
-	0x87, 0xcd, 0xa7, 0x95, 0x83, 0x98, 0x83, 0x53, 0xaf, 0x05, 0x79, 0x51, 0xd5, 0x1e, 0xed, 0xc9,
-	0x16, 0x8d, 0x72, 0x77, 0x5b, 0xd3, 0xb6, 0xfb, 0xff, 0x34, 0xb5, 0x49, 0x1b, 0xcd, 0x23, 0xd7,
-	0x76, 0x34, 0xef, 0x60, 0xd0, 0xb2, 0x17, 0x39, 0xa9, 0xca, 0x5d, 0xcb, 0x75, 0x94, 0xaf, 0xc1,
-	0x58, 0xa0, 0x20, 0xcf, 0xab, 0xf4, 0x3f, 0xbf, 0x9c, 0x8e, 0xda, 0xa9, 0x34, 0x2e, 0xbe, 0xf6,
-	0xd4, 0xdf, 0xee, 0xcd, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x74, 0x1a, 0x2b, 0x33, 0xfe, 0x04,
-	0x00, 0x00,
+	// 635 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0xc6, 0x3f, 0x89, 0x93, 0x31, 0x75, 0xcc, 0xd2, 0x82, 0x5b, 0x09, 0x51, 0xcc, 0x81, 0xd0,
+	0x43, 0x0e, 0x41, 0x45, 0xa8, 0x9c, 0xa2, 0x94, 0x46, 0x15, 0xd4, 0xad, 0xdc, 0x44, 0x1c, 0xab,
+	0x10, 0x0f, 0xc8, 0xe0, 0x66, 0x4d, 0xbc, 0xa9, 0xea, 0x97, 0xe0, 0x01, 0x79, 0x1a, 0xb4, 0xeb,
+	0xb5, 0xeb, 0xc4, 0x34, 0x90, 0xdc, 0x3c, 0x33, 0xfb, 0xcd, 0x37, 0x3b, 0xf3, 0xed, 0x18, 0xb6,
+	0x12, 0x9c, 0xdd, 0x84, 0x13, 0xec, 0xc4, 0x33, 0xca, 0x28, 0xa9, 0x4d, 0x69, 0x80, 0x89, 0x7b,
+	0x00, 0xfa, 0x49, 0x18, 0x21, 0xb1, 0x40, 0x0d, 0x03, 0x47, 0xd9, 0x57, 0xda, 0x4d, 0x5f, 0x0d,
+	0x03, 0x42, 0x40, 0x9f, 0x8e, 0xaf, 0xd1, 0x51, 0x85, 0x47, 0x7c, 0xbb, 0x4d, 0x30, 0x3c, 0xea,
+	0x63, 0x1c, 0xa5, 0xee, 0x2f, 0x05, 0xb6, 0xfa, 0x33, 0x1c, 0x33, 0xf4, 0xf1, 0xe7, 0x1c, 0x13,
+	0x56, 0x49, 0xe0, 0x80, 0x11, 0xcf, 0xe8, 0x77, 0x9c, 0x30, 0x99, 0x23, 0x37, 0xc9, 0x4b, 0xd0,
+	0x59, 0x1a, 0xa3, 0xa3, 0xed, 0x2b, 0x6d, 0xab, 0xdb, 0xea, 0x88, 0x42, 0x3a, 0x1e, 0x0d, 0x70,
+	0x98, 0xc6, 0xe8, 0x8b, 0x20, 0xd9, 0x86, 0x1a, 0x0b, 0x59, 0x84, 0x8e, 0x2e, 0xc0, 0x99, 0xc1,
+	0x93, 0x8e, 0x27, 0x13, 0x3a, 0x9f, 0x32, 0xa7, 0x96, 0x25, 0x95, 0xa6, 0xfb, 0x03, 0x5a, 0x97,
+	0xc8, 0x86, 0xfc, 0xd4, 0xfa, 0x15, 0x15, 0x64, 0xda, 0x3a, 0x64, 0x1e, 0x65, 0x98, 0x6c, 0x44,
+	0x36, 0xe5, 0xc8, 0x9c, 0x4c, 0x18, 0x2b, 0xc8, 0x6e, 0xa1, 0xd5, 0x0b, 0x02, 0x3e, 0xa4, 0x0d,
+	0xc8, 0x5e, 0x40, 0xed, 0x2b, 0x47, 0x3a, 0xda, 0xbe, 0xd6, 0x36, 0xbb, 0xa6, 0x6c, 0x36, 0xcf,
+	0xe6, 0x67, 0x91, 0x15, 0xcc, 0x09, 0x10, 0x1f, 0xaf, 0xe9, 0x0d, 0x6e, 0x48, 0xbe, 0x0b, 0x0d,
+	0x4e, 0x71, 0x15, 0x06, 0x19, 0x7f, 0xd3, 0x37, 0xb8, 0x7d, 0x1a, 0xac, 0x22, 0x7d, 0x0b, 0x30,
+	0x40, 0xb6, 0x36, 0x19, 0x57, 0x64, 0x43, 0x00, 0xe3, 0x28, 0xad, 0xc0, 0x72, 0xc9, 0xa9, 0xff,
+	0x25, 0xb9, 0x05, 0x15, 0x14, 0xe3, 0xd2, 0xcb, 0xe3, 0x2a, 0xfa, 0x5a, 0xbb, 0xaf, 0xaf, 0xee,
+	0x11, 0x58, 0x03, 0x64, 0xc2, 0x23, 0x2f, 0x53, 0x2a, 0x5e, 0x59, 0xec, 0x54, 0x56, 0xaf, 0x9a,
+	0xd7, 0xeb, 0xf6, 0xe1, 0x61, 0x81, 0xe5, 0xf7, 0xe1, 0xaf, 0x91, 0x06, 0x28, 0x61, 0xe2, 0x9b,
+	0x3c, 0x07, 0x9d, 0x13, 0x09, 0xd4, 0x52, 0x05, 0x22, 0xe0, 0x9e, 0x80, 0xd9, 0xa7, 0x71, 0xfa,
+	0x6f, 0xf6, 0x67, 0x00, 0x8c, 0x5e, 0x2d, 0xf6, 0xb5, 0xc9, 0xe8, 0x85, 0xec, 0xec, 0x19, 0xd8,
+	0x3c, 0xeb, 0x28, 0x19, 0x7f, 0x2b, 0xae, 0x62, 0x83, 0xc6, 0xa7, 0xaa, 0x88, 0xa9, 0xf2, 0x4f,
+	0xf2, 0x0a, 0x5a, 0x78, 0x3b, 0x89, 0xe6, 0x01, 0x2e, 0x65, 0xb2, 0xa4, 0x3b, 0x4f, 0x77, 0x08,
+	0xcd, 0x22, 0xdd, 0x1a, 0xf3, 0x3d, 0x02, 0xab, 0x54, 0x05, 0x6f, 0x4a, 0x1b, 0xea, 0x73, 0x6e,
+	0x65, 0x65, 0x98, 0x5d, 0xbb, 0xd4, 0x82, 0xec, 0x98, 0x8c, 0x1f, 0x0c, 0xa0, 0x91, 0xcf, 0x9a,
+	0x98, 0x60, 0x8c, 0xbc, 0x8f, 0xde, 0xf9, 0x67, 0xcf, 0x7e, 0x40, 0x1a, 0xa0, 0x1f, 0xf7, 0x86,
+	0x3d, 0x5b, 0xe1, 0xee, 0xfe, 0xf9, 0xd9, 0xc5, 0x68, 0xf8, 0xc1, 0x56, 0x09, 0x40, 0xfd, 0xac,
+	0xe7, 0x8d, 0x7a, 0x9f, 0x6c, 0x8d, 0x07, 0x4e, 0x4e, 0xbd, 0xe3, 0x53, 0x6f, 0x60, 0xeb, 0xdd,
+	0xdf, 0x1a, 0x18, 0x97, 0xd9, 0x1a, 0x25, 0x1d, 0xa8, 0x67, 0x1b, 0x90, 0x6c, 0x4b, 0xe2, 0x85,
+	0x85, 0xb8, 0x67, 0x15, 0x2a, 0xcb, 0xca, 0xed, 0x42, 0x23, 0xdf, 0x50, 0xe4, 0x89, 0x8c, 0x2d,
+	0xad, 0xac, 0x7b, 0x30, 0x62, 0xd1, 0x94, 0x31, 0xe5, 0xcd, 0xf3, 0x37, 0x4c, 0xbe, 0x2f, 0x0a,
+	0xcc, 0xd2, 0x02, 0xa9, 0x60, 0xde, 0x81, 0x59, 0x7a, 0xe9, 0x64, 0x57, 0x86, 0xab, 0xaf, 0xbf,
+	0x82, 0x7c, 0x0d, 0xda, 0x00, 0x19, 0x79, 0x24, 0xdd, 0x77, 0x4f, 0x77, 0xaf, 0x55, 0x76, 0xf1,
+	0xa3, 0x87, 0x60, 0x48, 0x51, 0x93, 0x9d, 0xbb, 0x58, 0xe9, 0x81, 0xec, 0x3d, 0x5e, 0x76, 0x73,
+	0xd8, 0x01, 0xe8, 0x5c, 0xc6, 0x84, 0xe4, 0x5d, 0xbe, 0xd3, 0x74, 0xa5, 0x9a, 0xf7, 0x65, 0x6d,
+	0x3d, 0xad, 0xe8, 0x41, 0xa2, 0x76, 0xaa, 0x81, 0x38, 0x4a, 0xbf, 0xd4, 0xc5, 0x8f, 0xf1, 0xcd,
+	0x9f, 0x01, 0x00, 0x28, 0xf2, 0xa9, 0x4e, 0x29, 0x07, 0x00, 0x00,
 }
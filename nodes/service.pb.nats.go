@@ -21,6 +21,9 @@ type Service interface {
 	AddFiles(context.Context, *AddFilesRequest) (*NoReply, error)
 	RemoveFiles(context.Context, *RemoveFilesRequest) (*NoReply, error)
 	Get(context.Context, *GetRequest) (*GetReply, error)
+	GetFile(context.Context, *GetFileRequest) (*GetFileReply, error)
+	Copy(context.Context, *CopyRequest) (*NoReply, error)
+	FileUsage(context.Context, *FileUsageRequest) (*FileUsageReply, error)
 }
 
 type ServiceClient interface {
@@ -30,6 +33,9 @@ type ServiceClient interface {
 	AddFiles(context.Context, *AddFilesRequest, ...transport.RequestOption) (*NoReply, error)
 	RemoveFiles(context.Context, *RemoveFilesRequest, ...transport.RequestOption) (*NoReply, error)
 	Get(context.Context, *GetRequest, ...transport.RequestOption) (*GetReply, error)
+	GetFile(context.Context, *GetFileRequest, ...transport.RequestOption) (*GetFileReply, error)
+	Copy(context.Context, *CopyRequest, ...transport.RequestOption) (*NoReply, error)
+	FileUsage(context.Context, *FileUsageRequest, ...transport.RequestOption) (*FileUsageReply, error)
 }
 
 // serviceClient an implementation of Service client.
@@ -103,6 +109,39 @@ func (c *serviceClient) Get(ctx context.Context, req *GetRequest, opts ...transp
 	return &rep, nil
 }
 
+func (c *serviceClient) GetFile(ctx context.Context, req *GetFileRequest, opts ...transport.RequestOption) (*GetFileReply, error) {
+	var rep GetFileReply
+
+	_, err := c.tp.Request("nodes.GetFile", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) Copy(ctx context.Context, req *CopyRequest, opts ...transport.RequestOption) (*NoReply, error) {
+	var rep NoReply
+
+	_, err := c.tp.Request("nodes.Copy", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) FileUsage(ctx context.Context, req *FileUsageRequest, opts ...transport.RequestOption) (*FileUsageReply, error) {
+	var rep FileUsageReply
+
+	_, err := c.tp.Request("nodes.FileUsage", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
 // NewServiceClient creates a new Service client.
 func NewServiceClient(tp transport.Transport) ServiceClient {
 	return &serviceClient{tp}
@@ -206,6 +245,45 @@ func (s *ServiceServer) Serve(ctx context.Context, opts ...transport.SubscribeOp
 	if err != nil {
 		return err
 	}
+	_, err = s.tp.Subscribe("nodes.GetFile", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req GetFileRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.GetFile(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("nodes.Copy", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req CopyRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Copy(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("nodes.FileUsage", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req FileUsageRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.FileUsage(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
 
 	sigchan := make(chan os.Signal)
 	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,279 @@
+package nodes
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/golang/protobuf/proto"
+	"github.com/rdm-academy/api/internal/mongotest"
+)
+
+// testTransport discards the published events.
+type testTransport struct {
+	transport.Transport
+}
+
+func (t *testTransport) Publish(subj string, msg proto.Message) (*transport.Message, error) {
+	return &transport.Message{}, nil
+}
+
+// testService returns a service on a scratch database and a function that
+// drops it.
+func testService(t *testing.T) (*service, func()) {
+	db, drop := mongotest.DB(t, "nodes")
+
+	if err := ensureIndexes(db); err != nil {
+		drop()
+		t.Fatal(err)
+	}
+
+	s := &service{
+		db: db,
+		tp: &testTransport{},
+	}
+
+	return s, func() {
+		drop()
+	}
+}
+
+// usages returns the project each file is used in.
+func usages(t *testing.T, s *service, exclude string, ids ...string) map[string]string {
+	rep, err := s.FileUsage(context.Background(), &FileUsageRequest{
+		Ids:            ids,
+		ExcludeProject: exclude,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := make(map[string]string, len(rep.Usages))
+	for _, u := range rep.Usages {
+		if _, ok := m[u.Id]; ok {
+			t.Errorf("expected one usage of %s, got %v", u.Id, rep.Usages)
+		}
+		m[u.Id] = u.Project
+	}
+
+	return m
+}
+
+func TestDecodeType(t *testing.T) {
+	for _, x := range []struct {
+		name string
+		typ  NodeType
+	}{
+		{"data", NodeType_DATA},
+		{"Finding", NodeType_FINDING},
+		{"MANUAL", NodeType_MANUAL},
+		{"script", NodeType_UNKNOWN},
+		{"", NodeType_UNKNOWN},
+	} {
+		if typ := decodeType(x.name); typ != x.typ {
+			t.Errorf("%q: expected %s, got %s", x.name, x.typ, typ)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, n := range []*node{
+		{ID: "a", Project: "p1", Type: NodeType_DATA, Title: "Raw data", Notes: "notes", Files: []*file{{ID: "f1", Name: "data.csv"}}},
+		{ID: "b", Project: "p1", Type: NodeType_FINDING, Title: "Result"},
+		// Already copied.
+		{ID: "b", Project: "p2", Type: NodeType_FINDING, Title: "Result"},
+		// Other project.
+		{ID: "c", Project: "p3", Type: NodeType_MANUAL, Title: "Other"},
+	} {
+		if err := s.db.C(n.Project).Insert(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, x := range []struct {
+		name string
+		req  *CopyRequest
+		code codes.Code
+	}{
+		{"no project", &CopyRequest{ToProject: "p2"}, codes.InvalidArgument},
+		{"no target", &CopyRequest{Project: "p1"}, codes.InvalidArgument},
+		{"same project", &CopyRequest{Project: "p1", ToProject: "p1"}, codes.InvalidArgument},
+	} {
+		_, err := s.Copy(ctx, x.req)
+		if code := status.Code(err); code != x.code {
+			t.Errorf("%s: expected %s, got %s", x.name, x.code, code)
+		}
+	}
+
+	// Copies are resumed when nodes already exist.
+	if _, err := s.Copy(ctx, &CopyRequest{Project: "p1", ToProject: "p2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := s.Get(ctx, &GetRequest{Project: "p2", Id: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rep.Type != NodeType_DATA || rep.Title != "Raw data" || rep.Notes != "notes" {
+		t.Errorf("unexpected node copied: %v", rep)
+	}
+
+	// File references are shared.
+	if len(rep.Files) != 1 || rep.Files[0].Id != "f1" || rep.Files[0].Name != "data.csv" {
+		t.Errorf("expected file to be copied, got %v", rep.Files)
+	}
+
+	if n, _ := s.db.C("p2").Count(); n != 2 {
+		t.Errorf("expected 2 nodes, got %d", n)
+	}
+
+	// The source is unchanged.
+	if n, _ := s.db.C("p1").Count(); n != 2 {
+		t.Errorf("expected 2 nodes in the source, got %d", n)
+	}
+
+	// Empty projects copy nothing.
+	if _, err := s.Copy(ctx, &CopyRequest{Project: "p4", ToProject: "p5"}); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := s.db.C("p5").Count(); n != 0 {
+		t.Errorf("expected no nodes, got %d", n)
+	}
+}
+
+func TestFileUsage(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, n := range []*node{
+		{ID: "a", Project: "p1", Type: NodeType_DATA},
+		{ID: "b", Project: "p1", Type: NodeType_DATA},
+		{ID: "c", Project: "p2", Type: NodeType_DATA},
+	} {
+		if err := s.db.C(n.Project).Insert(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	add := func(project, id string, files ...string) {
+		req := &AddFilesRequest{Project: project, Id: id}
+		for _, f := range files {
+			req.Files = append(req.Files, &File{Id: f, Name: f + ".csv"})
+		}
+
+		if _, err := s.AddFiles(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	add("p1", "a", "f1", "f2")
+	add("p1", "b", "f1")
+	add("p2", "c", "f1")
+
+	if u := usages(t, s, "", "f1", "f2", "f3"); len(u) != 2 || u["f2"] != "p1" || u["f1"] == "" {
+		t.Errorf("expected f1 and f2 to be used, got %v", u)
+	}
+	if u := usages(t, s, "p1", "f1", "f2"); len(u) != 1 || u["f1"] != "p2" {
+		t.Errorf("expected f1 to be used by p2, got %v", u)
+	}
+
+	// Files added to missing nodes are not referenced.
+	_, err := s.AddFiles(ctx, &AddFilesRequest{Project: "p2", Id: "x", Files: []*File{{Id: "f4"}}})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if u := usages(t, s, "", "f4"); len(u) != 0 {
+		t.Errorf("expected f4 not to be used, got %v", u)
+	}
+
+	// The file is still used by the other node.
+	if _, err := s.RemoveFiles(ctx, &RemoveFilesRequest{Project: "p1", Id: "a", FileIds: []string{"f1", "f2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if u := usages(t, s, "p2", "f1", "f2"); len(u) != 1 || u["f1"] != "p1" {
+		t.Errorf("expected f1 to be used by p1, got %v", u)
+	}
+
+	if _, err := s.RemoveFiles(ctx, &RemoveFilesRequest{Project: "p2", Id: "c", FileIds: []string{"f1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if u := usages(t, s, "p1", "f1"); len(u) != 0 {
+		t.Errorf("expected f1 not to be used outside p1, got %v", u)
+	}
+
+	// Copies reference the files.
+	if _, err := s.Copy(ctx, &CopyRequest{Project: "p1", ToProject: "p3"}); err != nil {
+		t.Fatal(err)
+	}
+	if u := usages(t, s, "p1", "f1"); u["f1"] != "p3" {
+		t.Errorf("expected f1 to be used by p3, got %v", u)
+	}
+
+	// Dropped projects do not.
+	for _, p := range []string{"p1", "p3"} {
+		if err := s.dropProject(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if u := usages(t, s, "", "f1", "f2"); len(u) != 0 {
+		t.Errorf("expected no files to be used, got %v", u)
+	}
+}
+
+func TestIndexFileRefs(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	// Nodes created before the references were kept.
+	if err := s.db.C(fileRefsCol).DropCollection(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []*node{
+		{ID: "a", Project: "p1", Files: []*file{{ID: "f1"}, {ID: "f2"}}},
+		{ID: "b", Project: "p2", Files: []*file{{ID: "f1"}}},
+		{ID: "c", Project: "p2"},
+	} {
+		if err := s.db.C(n.Project).Insert(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Interrupted runs are started over.
+	if err := addFileRefs(s.db, "p1", "a", []string{"f1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ensureIndexes(s.db); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, _ := s.db.C(fileRefsCol).Count(); n != 3 {
+		t.Errorf("expected 3 references, got %d", n)
+	}
+	if u := usages(t, s, "p1", "f1", "f2"); len(u) != 1 || u["f1"] != "p2" {
+		t.Errorf("expected f1 to be used by p2, got %v", u)
+	}
+
+	// The references are only indexed once.
+	if err := s.db.C("p3").Insert(&node{ID: "d", Project: "p3", Files: []*file{{ID: "f3"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ensureIndexes(s.db); err != nil {
+		t.Fatal(err)
+	}
+	if u := usages(t, s, "", "f3"); len(u) != 0 {
+		t.Errorf("expected f3 not to be indexed again, got %v", u)
+	}
+}
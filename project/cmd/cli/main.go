@@ -141,6 +141,30 @@ func main() {
 		}
 		rep, err = client.DeleteProject(ctx, &req)
 
+	case "ForkProject":
+		client := project.NewServiceClient(tp)
+		var req project.ForkProjectRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.ForkProject(ctx, &req)
+
+	case "RestoreProject":
+		client := project.NewServiceClient(tp)
+		var req project.RestoreProjectRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.RestoreProject(ctx, &req)
+
+	case "ListDeletedProjects":
+		client := project.NewServiceClient(tp)
+		var req project.ListDeletedProjectsRequest
+		if err := jsonUnmarshaler.Unmarshal(inpr, &req); err != nil {
+			log.Fatalf("json: %s", err)
+		}
+		rep, err = client.ListDeletedProjects(ctx, &req)
+
 	default:
 		log.Fatalf("unknown method %s", meth)
 	}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	mgo "gopkg.in/mgo.v2"
 
@@ -29,10 +30,13 @@ func main() {
 		natsAddr     string
 		mongoAddr    string
 		printVersion bool
+
+		trashRetention time.Duration
 	)
 
 	flag.StringVar(&natsAddr, "nats.addr", "nats://127.0.0.1:4222", "NATS address.")
 	flag.StringVar(&mongoAddr, "mongo.addr", "127.0.0.1:27017/project", "Mongo database URI.")
+	flag.DurationVar(&trashRetention, "trash.retention", 30*24*time.Hour, "Time deleted projects are kept in the trash before being purged. Zero disables purging.")
 	flag.BoolVar(&printVersion, "version", false, "Print version.")
 
 	flag.Parse()
@@ -77,7 +81,7 @@ func main() {
 	db := session.DB("")
 
 	// Initialize the service.
-	svc, err := project.NewService(tp, db)
+	svc, err := project.NewService(tp, db, trashRetention)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,146 @@
+package project
+
+import (
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/golang/protobuf/proto"
+	"github.com/rdm-academy/api/commitlog"
+)
+
+const accountEventSubject = "events.account"
+
+// newAccountEventHandler initializes a handler that deletes the projects
+// of deleted accounts.
+func newAccountEventHandler(s *service) transport.Handler {
+	return func(msg *transport.Message) (proto.Message, error) {
+		var e commitlog.Event
+		if err := msg.Decode(&e); err != nil {
+			return nil, err
+		}
+
+		if e.Type != "account.deleted" || e.Author == "" {
+			return nil, nil
+		}
+
+		if err := s.deleteProjects(e.Author); err != nil {
+			return nil, err
+		}
+
+		return nil, commitlog.Ack(s.tp, "project", &e)
+	}
+}
+
+// newAckHandler initializes a handler that records which services removed
+// the data of purged projects.
+func newAckHandler(s *service) transport.Handler {
+	return func(msg *transport.Message) (proto.Message, error) {
+		var e commitlog.Event
+		if err := msg.Decode(&e); err != nil {
+			return nil, err
+		}
+
+		svc := commitlog.AckService(&e)
+		if e.Type != "project.deleted" || e.Project == "" || svc == "" {
+			return nil, nil
+		}
+
+		return nil, s.ack(e.Project, svc)
+	}
+}
+
+// deleteProjects purges all projects of the account immediately.
+func (s *service) deleteProjects(account string) error {
+	q := bson.M{
+		"account": account,
+		"purging": bson.M{
+			"$exists": false,
+		},
+	}
+
+	var docs []*project
+	if err := s.db.C(projectsCol).Find(q).Select(bson.M{"_id": 1}).All(&docs); err != nil {
+		return err
+	}
+
+	for _, p := range docs {
+		if err := s.markPurged(p.ID, account); err != nil && err != mgo.ErrNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// markPurged hides the project and publishes the project.deleted event so
+// the other services remove the associated data. The project itself is
+// removed once all of them acknowledged the event.
+func (s *service) markPurged(id, account string) error {
+	q := bson.M{
+		"_id":     id,
+		"account": account,
+		"purging": bson.M{
+			"$exists": false,
+		},
+	}
+
+	now := time.Now()
+
+	u := bson.M{
+		"$set": bson.M{
+			"_name":   trashName(id),
+			"deleted": now,
+			"purging": now,
+		},
+	}
+
+	if err := s.db.C(projectsCol).Update(q, u); err != nil {
+		return err
+	}
+
+	s.logEvent("events.project", &logEvent{
+		Project: id,
+		Type:    "project.deleted",
+		Author:  account,
+	})
+
+	return nil
+}
+
+// ack records that the service removed the data of the purged project. The
+// project is removed once all services did.
+func (s *service) ack(id, svc string) error {
+	q := bson.M{
+		"_id": id,
+		"purging": bson.M{
+			"$exists": true,
+		},
+	}
+
+	u := bson.M{
+		"$addToSet": bson.M{
+			"acks": svc,
+		},
+	}
+
+	if err := s.db.C(projectsCol).Update(q, u); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil
+		}
+
+		return err
+	}
+
+	q["acks"] = bson.M{
+		"$all": commitlog.ProjectDeletedAcks,
+	}
+
+	if err := s.db.C(projectsCol).Remove(q); err != nil && err != mgo.ErrNotFound {
+		return err
+	}
+
+	return nil
+}
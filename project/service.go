@@ -3,6 +3,7 @@ package project
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"strings"
 	"time"
@@ -15,11 +16,15 @@ import (
 
 	"github.com/chop-dbhi/nats-rpc/transport"
 	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/nodes"
 	uuid "github.com/satori/go.uuid"
 )
 
 const (
 	projectsCol = "projects"
+
+	// Number of names tried before a fork fails due to a name conflict.
+	maxForkNames = 10
 )
 
 type logEvent struct {
@@ -51,6 +56,12 @@ type project struct {
 	Created     time.Time   `bson:"created"`
 	Modified    time.Time   `bson:"modified"`
 	Workflows   []*workflow `bson:"workflows"`
+	Deleted     time.Time   `bson:"deleted,omitempty"`
+
+	// Set once the project is purged. The project is kept until all
+	// services acknowledged the project.deleted event.
+	Purging time.Time `bson:"purging,omitempty"`
+	Acks    []string  `bson:"acks,omitempty"`
 
 	// Internal fields for lookups.
 	NormName string `bson:"_name"`
@@ -59,6 +70,12 @@ type project struct {
 type service struct {
 	db *mgo.Database
 	tp transport.Transport
+
+	nodeSvc      nodes.ServiceClient
+	commitlogSvc commitlog.ServiceClient
+
+	// Time projects are kept in the trash before they are purged.
+	retention time.Duration
 }
 
 func (s *service) logEvent(t string, e *logEvent) {
@@ -68,6 +85,7 @@ func (s *service) logEvent(t string, e *logEvent) {
 	}
 
 	_, err = s.tp.Publish(t, &commitlog.Event{
+		Id:      commitlog.NewEventID(),
 		Project: e.Project,
 		Time:    time.Now().Unix(),
 		Type:    e.Type,
@@ -146,6 +164,9 @@ func (s *service) UpdateProject(ctx context.Context, req *UpdateProjectRequest)
 	q := bson.M{
 		"_id":     req.Id,
 		"account": req.Account,
+		"deleted": bson.M{
+			"$exists": false,
+		},
 	}
 
 	// Update doc.
@@ -209,6 +230,9 @@ func (s *service) UpdateWorkflow(ctx context.Context, req *UpdateWorkflowRequest
 	q := bson.M{
 		"_id":     req.Id,
 		"account": req.Account,
+		"deleted": bson.M{
+			"$exists": false,
+		},
 	}
 
 	wid := uuid.NewV4().String()
@@ -347,6 +371,9 @@ func (s *service) GetProject(ctx context.Context, req *GetProjectRequest) (*GetP
 	q := bson.M{
 		"_id":     req.Id,
 		"account": req.Account,
+		"deleted": bson.M{
+			"$exists": false,
+		},
 	}
 
 	// Only select the last revision of the workflow.
@@ -402,11 +429,9 @@ func (s *service) GetProject(ctx context.Context, req *GetProjectRequest) (*GetP
 	}, nil
 }
 
-func (s *service) ListProjects(ctx context.Context, req *ListProjectsRequest) (*ListProjectsResponse, error) {
-	q := bson.M{
-		"account": req.Account,
-	}
-
+// listProjects returns the projects matching the query with the latest
+// revision of their workflow.
+func (s *service) listProjects(q bson.M) ([]*Project, error) {
 	x := bson.M{
 		"workflows": bson.M{
 			"$slice": -1,
@@ -454,6 +479,26 @@ func (s *service) ListProjects(ctx context.Context, req *ListProjectsRequest) (*
 			Modified:    p.Modified.Unix(),
 			Workflow:    w,
 		}
+
+		if !p.Deleted.IsZero() {
+			list[i].Deleted = p.Deleted.Unix()
+		}
+	}
+
+	return list, nil
+}
+
+func (s *service) ListProjects(ctx context.Context, req *ListProjectsRequest) (*ListProjectsResponse, error) {
+	q := bson.M{
+		"account": req.Account,
+		"deleted": bson.M{
+			"$exists": false,
+		},
+	}
+
+	list, err := s.listProjects(q)
+	if err != nil {
+		return nil, err
 	}
 
 	return &ListProjectsResponse{
@@ -461,13 +506,32 @@ func (s *service) ListProjects(ctx context.Context, req *ListProjectsRequest) (*
 	}, nil
 }
 
+// DeleteProject moves the project to the trash. It is purged once the
+// retention period has passed.
 func (s *service) DeleteProject(ctx context.Context, req *DeleteProjectRequest) (*DeleteProjectResponse, error) {
+	if req.Purge {
+		return s.purgeProject(req)
+	}
+
 	q := bson.M{
 		"_id":     req.Id,
 		"account": req.Account,
+		"deleted": bson.M{
+			"$exists": false,
+		},
+	}
+
+	// The name is released so a new project can use it.
+	u := bson.M{
+		"$set": bson.M{
+			"_name": trashName(req.Id),
+		},
+		"$currentDate": bson.M{
+			"deleted": true,
+		},
 	}
 
-	if err := s.db.C(projectsCol).Remove(q); err != nil {
+	if err := s.db.C(projectsCol).Update(q, u); err != nil {
 		if err == mgo.ErrNotFound {
 			return nil, status.Error(codes.NotFound, "project not found")
 		}
@@ -477,14 +541,171 @@ func (s *service) DeleteProject(ctx context.Context, req *DeleteProjectRequest)
 
 	s.logEvent("events.project", &logEvent{
 		Project: req.Id,
-		Type:    "project.deleted",
+		Type:    "project.trashed",
 		Author:  req.Account,
 	})
 
 	return &DeleteProjectResponse{}, nil
 }
 
-func NewService(tp transport.Transport, db *mgo.Database) (Service, error) {
+// purgeProject purges the project immediately, whether or not it is in
+// the trash.
+func (s *service) purgeProject(req *DeleteProjectRequest) (*DeleteProjectResponse, error) {
+	if err := s.markPurged(req.Id, req.Account); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "project not found")
+		}
+
+		return nil, err
+	}
+
+	return &DeleteProjectResponse{}, nil
+}
+
+func (s *service) ForkProject(ctx context.Context, req *ForkProjectRequest) (*ForkProjectResponse, error) {
+	if req.Account == "" {
+		return nil, status.Error(codes.InvalidArgument, "account required")
+	}
+
+	// Only select the last revision of the workflow.
+	x := bson.M{
+		"workflows": bson.M{
+			"$slice": -1,
+		},
+	}
+
+	var src project
+	if err := s.db.C(projectsCol).FindId(req.Id).Select(x).One(&src); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "project not found")
+		}
+
+		return nil, err
+	}
+
+	// Projects are currently only visible to their owner.
+	if src.Account != req.Account || !src.Deleted.IsZero() {
+		return nil, status.Error(codes.NotFound, "project not found")
+	}
+
+	// The commit the fork is based on.
+	hrep, err := s.commitlogSvc.History(ctx, &commitlog.HistoryRequest{
+		Project: src.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var commit string
+	if hrep.Commit != nil {
+		commit = hrep.Commit.Id
+	}
+
+	now := time.Now()
+
+	p := project{
+		ID:          uuid.NewV4().String(),
+		Account:     req.Account,
+		Description: src.Description,
+		Created:     now,
+		Modified:    now,
+	}
+
+	if len(src.Workflows) == 1 {
+		w := *src.Workflows[0]
+		w.ID = uuid.NewV4().String()
+		w.Modified = now
+
+		p.Workflows = []*workflow{&w}
+	}
+
+	// Try names with an increasing suffix until one is available.
+	for i := 1; ; i++ {
+		switch {
+		case req.Name != "":
+			p.Name = req.Name
+		case i == 1:
+			p.Name = fmt.Sprintf("%s (fork)", src.Name)
+		default:
+			p.Name = fmt.Sprintf("%s (fork %d)", src.Name, i)
+		}
+
+		p.NormName = strings.ToLower(p.Name)
+
+		err := s.db.C(projectsCol).Insert(&p)
+		if err == nil {
+			break
+		}
+
+		if !mgo.IsDup(err) {
+			return nil, err
+		}
+
+		if req.Name != "" || i == maxForkNames {
+			return nil, status.Error(codes.AlreadyExists, "project already exists")
+		}
+	}
+
+	// Log the event first so it starts the commitlog of the fork.
+	s.logEvent("events.project", &logEvent{
+		Type:    "project.forked",
+		Project: p.ID,
+		Author:  p.Account,
+		Data: map[string]interface{}{
+			"name": p.Name,
+			"source": map[string]interface{}{
+				"project": src.ID,
+				"commit":  commit,
+			},
+		},
+	})
+
+	_, err = s.nodeSvc.Copy(ctx, &nodes.CopyRequest{
+		Project:   src.ID,
+		ToProject: p.ID,
+	})
+	if err != nil {
+		// Remove the partial fork so it does not show up without its nodes.
+		if _, perr := s.purgeProject(&DeleteProjectRequest{Account: p.Account, Id: p.ID}); perr != nil {
+			log.Printf("project: failed to remove fork %s: %s", p.ID, perr)
+		}
+
+		return nil, err
+	}
+
+	w := &Workflow{}
+	if len(p.Workflows) == 1 {
+		w.Source = p.Workflows[0].Source
+		w.Modified = p.Workflows[0].Modified.Unix()
+		w.Nodes = make(map[string]*Node, len(p.Workflows[0].Nodes))
+
+		for k, v := range p.Workflows[0].Nodes {
+			w.Nodes[k] = &Node{
+				Type:   v.Type,
+				Title:  v.Title,
+				Input:  v.Input,
+				Output: v.Output,
+			}
+		}
+	}
+
+	return &ForkProjectResponse{
+		Project: &Project{
+			Id:          p.ID,
+			Account:     p.Account,
+			Name:        p.Name,
+			Description: p.Description,
+			Created:     p.Created.Unix(),
+			Modified:    p.Modified.Unix(),
+			Workflow:    w,
+		},
+	}, nil
+}
+
+// NewService initializes the project service. Projects in the trash are
+// purged after the retention period. A zero retention keeps them in the
+// trash.
+func NewService(tp transport.Transport, db *mgo.Database, retention time.Duration) (Service, error) {
 	err := db.C(projectsCol).EnsureIndex(mgo.Index{
 		Key:    []string{"account", "_name"},
 		Unique: true,
@@ -493,8 +714,24 @@ func NewService(tp transport.Transport, db *mgo.Database) (Service, error) {
 		return nil, err
 	}
 
-	return &service{
-		db: db,
-		tp: tp,
-	}, nil
+	s := &service{
+		db:           db,
+		tp:           tp,
+		nodeSvc:      nodes.NewServiceClient(tp),
+		commitlogSvc: commitlog.NewServiceClient(tp),
+		retention:    retention,
+	}
+
+	// This will be auto-unsubscribed when the transport is closed.
+	if _, err := tp.Subscribe(accountEventSubject, newAccountEventHandler(s)); err != nil {
+		return nil, err
+	}
+
+	if _, err := tp.Subscribe(commitlog.ProjectAckSubject, newAckHandler(s)); err != nil {
+		return nil, err
+	}
+
+	go s.runPurge()
+
+	return s, nil
 }
@@ -24,6 +24,12 @@ It has these top-level messages:
 	GetProjectResponse
 	ListProjectsRequest
 	ListProjectsResponse
+	ForkProjectRequest
+	ForkProjectResponse
+	RestoreProjectRequest
+	RestoreProjectResponse
+	ListDeletedProjectsRequest
+	ListDeletedProjectsResponse
 */
 package project
 
@@ -122,6 +128,8 @@ type Project struct {
 	Created     int64     `protobuf:"varint,5,opt,name=created" json:"created,omitempty"`
 	Modified    int64     `protobuf:"varint,6,opt,name=modified" json:"modified,omitempty"`
 	Workflow    *Workflow `protobuf:"bytes,7,opt,name=workflow" json:"workflow,omitempty"`
+	// Time the project was moved to the trash.
+	Deleted int64 `protobuf:"varint,8,opt,name=deleted" json:"deleted,omitempty"`
 }
 
 func (m *Project) Reset()                    { *m = Project{} }
@@ -178,6 +186,13 @@ func (m *Project) GetWorkflow() *Workflow {
 	return nil
 }
 
+func (m *Project) GetDeleted() int64 {
+	if m != nil {
+		return m.Deleted
+	}
+	return 0
+}
+
 type CreateProjectRequest struct {
 	Account     string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
 	Name        string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
@@ -317,6 +332,9 @@ func (*UpdateWorkflowResponse) Descriptor() ([]byte, []int) { return fileDescrip
 type DeleteProjectRequest struct {
 	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
 	Id      string `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
+	// Remove the project immediately rather than moving it to the trash,
+	// e.g. to discard a failed import.
+	Purge bool `protobuf:"varint,3,opt,name=purge" json:"purge,omitempty"`
 }
 
 func (m *DeleteProjectRequest) Reset()                    { *m = DeleteProjectRequest{} }
@@ -338,6 +356,13 @@ func (m *DeleteProjectRequest) GetId() string {
 	return ""
 }
 
+func (m *DeleteProjectRequest) GetPurge() bool {
+	if m != nil {
+		return m.Purge
+	}
+	return false
+}
+
 type DeleteProjectResponse struct {
 }
 
@@ -418,6 +443,123 @@ func (m *ListProjectsResponse) GetProjects() []*Project {
 	return nil
 }
 
+// ForkProjectRequest copies the project and its latest workflow into a
+// new project for the account.
+type ForkProjectRequest struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	Id      string `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
+	// Name of the new project. Defaults to the source name with a suffix.
+	Name string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *ForkProjectRequest) Reset()                    { *m = ForkProjectRequest{} }
+func (m *ForkProjectRequest) String() string            { return proto.CompactTextString(m) }
+func (*ForkProjectRequest) ProtoMessage()               {}
+func (*ForkProjectRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *ForkProjectRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *ForkProjectRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ForkProjectRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type ForkProjectResponse struct {
+	Project *Project `protobuf:"bytes,1,opt,name=project" json:"project,omitempty"`
+}
+
+func (m *ForkProjectResponse) Reset()                    { *m = ForkProjectResponse{} }
+func (m *ForkProjectResponse) String() string            { return proto.CompactTextString(m) }
+func (*ForkProjectResponse) ProtoMessage()               {}
+func (*ForkProjectResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *ForkProjectResponse) GetProject() *Project {
+	if m != nil {
+		return m.Project
+	}
+	return nil
+}
+
+// RestoreProjectRequest restores a project from the trash.
+type RestoreProjectRequest struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	Id      string `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *RestoreProjectRequest) Reset()                    { *m = RestoreProjectRequest{} }
+func (m *RestoreProjectRequest) String() string            { return proto.CompactTextString(m) }
+func (*RestoreProjectRequest) ProtoMessage()               {}
+func (*RestoreProjectRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *RestoreProjectRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *RestoreProjectRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type RestoreProjectResponse struct {
+}
+
+func (m *RestoreProjectResponse) Reset()                    { *m = RestoreProjectResponse{} }
+func (m *RestoreProjectResponse) String() string            { return proto.CompactTextString(m) }
+func (*RestoreProjectResponse) ProtoMessage()               {}
+func (*RestoreProjectResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+// ListDeletedProjectsRequest lists the projects in the trash.
+type ListDeletedProjectsRequest struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+}
+
+func (m *ListDeletedProjectsRequest) Reset()                    { *m = ListDeletedProjectsRequest{} }
+func (m *ListDeletedProjectsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListDeletedProjectsRequest) ProtoMessage()               {}
+func (*ListDeletedProjectsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *ListDeletedProjectsRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+type ListDeletedProjectsResponse struct {
+	Projects []*Project `protobuf:"bytes,1,rep,name=projects" json:"projects,omitempty"`
+}
+
+func (m *ListDeletedProjectsResponse) Reset()                    { *m = ListDeletedProjectsResponse{} }
+func (m *ListDeletedProjectsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListDeletedProjectsResponse) ProtoMessage()               {}
+func (*ListDeletedProjectsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *ListDeletedProjectsResponse) GetProjects() []*Project {
+	if m != nil {
+		return m.Projects
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Node)(nil), "project.Node")
 	proto.RegisterType((*Workflow)(nil), "project.Workflow")
@@ -434,47 +576,61 @@ func init() {
 	proto.RegisterType((*GetProjectResponse)(nil), "project.GetProjectResponse")
 	proto.RegisterType((*ListProjectsRequest)(nil), "project.ListProjectsRequest")
 	proto.RegisterType((*ListProjectsResponse)(nil), "project.ListProjectsResponse")
+	proto.RegisterType((*ForkProjectRequest)(nil), "project.ForkProjectRequest")
+	proto.RegisterType((*ForkProjectResponse)(nil), "project.ForkProjectResponse")
+	proto.RegisterType((*RestoreProjectRequest)(nil), "project.RestoreProjectRequest")
+	proto.RegisterType((*RestoreProjectResponse)(nil), "project.RestoreProjectResponse")
+	proto.RegisterType((*ListDeletedProjectsRequest)(nil), "project.ListDeletedProjectsRequest")
+	proto.RegisterType((*ListDeletedProjectsResponse)(nil), "project.ListDeletedProjectsResponse")
 }
 
 func init() { proto.RegisterFile("service.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 587 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0xdd, 0x6e, 0xd3, 0x4c,
-	0x10, 0x95, 0xed, 0xfc, 0x7d, 0x93, 0x2f, 0x55, 0x3b, 0xa4, 0xc5, 0x32, 0x14, 0x22, 0x73, 0x83,
-	0x10, 0x04, 0x29, 0xdc, 0x20, 0x24, 0xa4, 0x4a, 0x6d, 0xd5, 0x0b, 0x50, 0x04, 0x46, 0x08, 0x71,
-	0x83, 0x14, 0xec, 0x8d, 0xb4, 0x34, 0xf5, 0x1a, 0xef, 0x3a, 0x55, 0x9e, 0x8d, 0x77, 0xe0, 0x19,
-	0x78, 0x14, 0xb4, 0xeb, 0xf5, 0xfa, 0x27, 0x8e, 0x50, 0x73, 0xe7, 0xd9, 0x99, 0x3d, 0x73, 0xce,
-	0xd9, 0xd9, 0x35, 0x8c, 0x38, 0x49, 0xd7, 0x34, 0x24, 0xd3, 0x24, 0x65, 0x82, 0x61, 0x3f, 0x49,
-	0xd9, 0x0f, 0x12, 0x0a, 0xff, 0x1b, 0x74, 0xe6, 0x2c, 0x22, 0x38, 0x86, 0xae, 0xa0, 0x62, 0x45,
-	0x5c, 0x6b, 0x62, 0x3d, 0xfd, 0x2f, 0xc8, 0x03, 0x44, 0xe8, 0x88, 0x4d, 0x42, 0x5c, 0x5b, 0x2d,
-	0xaa, 0x6f, 0x59, 0x49, 0xe3, 0x24, 0x13, 0xae, 0x33, 0x71, 0x64, 0xa5, 0x0a, 0xf0, 0x04, 0x7a,
-	0x2c, 0x13, 0x72, 0xb9, 0xa3, 0x96, 0x75, 0xe4, 0xff, 0xb2, 0x60, 0xf0, 0x85, 0xa5, 0xd7, 0xcb,
-	0x15, 0xbb, 0x95, 0x45, 0x9c, 0x65, 0x69, 0x58, 0x74, 0xd1, 0x11, 0x7a, 0x30, 0xb8, 0x61, 0x11,
-	0x5d, 0x52, 0x12, 0xa9, 0x56, 0x4e, 0x60, 0x62, 0x9c, 0x41, 0x37, 0x66, 0x11, 0xe1, 0xaa, 0xdd,
-	0x70, 0xf6, 0x70, 0xaa, 0x99, 0x4f, 0x0b, 0xd4, 0xa9, 0xe4, 0xcf, 0x2f, 0x63, 0x91, 0x6e, 0x82,
-	0xbc, 0xd4, 0xbb, 0x02, 0x28, 0x17, 0xf1, 0x10, 0x9c, 0x6b, 0xb2, 0xd1, 0x2d, 0xe5, 0x27, 0x3e,
-	0x81, 0xee, 0x7a, 0xb1, 0xca, 0x72, 0x5d, 0xc3, 0xd9, 0xc8, 0x60, 0xca, 0x5d, 0x41, 0x9e, 0x7b,
-	0x63, 0xbf, 0xb6, 0xfc, 0xdf, 0x16, 0xf4, 0x3f, 0xe4, 0x39, 0x3c, 0x00, 0x9b, 0x46, 0x1a, 0xc5,
-	0xa6, 0x11, 0xba, 0xd0, 0x5f, 0x84, 0x21, 0xcb, 0x62, 0xa1, 0xed, 0x29, 0x42, 0xe9, 0x5a, 0xbc,
-	0xb8, 0x21, 0xae, 0x93, 0xbb, 0x26, 0xbf, 0x71, 0x02, 0xc3, 0x88, 0xf0, 0x30, 0xa5, 0x89, 0xa0,
-	0x2c, 0x76, 0x3b, 0x2a, 0x55, 0x5d, 0x92, 0x78, 0x61, 0x4a, 0x16, 0x82, 0x44, 0x6e, 0x57, 0x79,
-	0x50, 0x84, 0x35, 0x7b, 0x7a, 0x0d, 0x7b, 0x5e, 0xc0, 0xe0, 0x56, 0x1b, 0xe1, 0xf6, 0x95, 0x9a,
-	0xa3, 0x2d, 0x87, 0x02, 0x53, 0xe2, 0x2f, 0x61, 0x7c, 0xae, 0x50, 0xb5, 0xaa, 0x80, 0xfc, 0xcc,
-	0x08, 0x17, 0x55, 0x31, 0x56, 0xbb, 0x18, 0x7b, 0xb7, 0x18, 0x67, 0x4b, 0x8c, 0x7f, 0x0e, 0xc7,
-	0x8d, 0x3e, 0x3c, 0x61, 0x31, 0x27, 0xf8, 0x0c, 0x8a, 0xd1, 0x53, 0x8d, 0x86, 0xb3, 0x43, 0x43,
-	0xb7, 0x28, 0x35, 0xb3, 0xb9, 0x86, 0xf1, 0xe7, 0x24, 0xba, 0x0b, 0xd9, 0xfc, 0x8c, 0x6c, 0x73,
-	0x46, 0x7b, 0x9d, 0x84, 0x7f, 0x1f, 0x8e, 0x1b, 0x7d, 0x73, 0xf2, 0xfe, 0xd7, 0x22, 0x61, 0x9c,
-	0xbd, 0x33, 0xa3, 0xf2, 0x0a, 0x38, 0xd5, 0x2b, 0xe0, 0xbb, 0x70, 0xd2, 0x84, 0xd6, 0x4d, 0xcf,
-	0x60, 0x7c, 0x41, 0x56, 0x64, 0x7f, 0x17, 0xa4, 0x9e, 0x06, 0x82, 0x86, 0x7e, 0x0b, 0x47, 0x57,
-	0x44, 0xec, 0x8d, 0x7b, 0x06, 0x58, 0xdd, 0xbe, 0xc7, 0x09, 0xbf, 0x84, 0x7b, 0xef, 0x29, 0x2f,
-	0x20, 0xf8, 0x3f, 0x29, 0xf8, 0x17, 0x30, 0xae, 0x6f, 0xd0, 0x4d, 0x9f, 0xc3, 0x40, 0x63, 0x72,
-	0xd7, 0x52, 0x0f, 0xc5, 0x76, 0x57, 0x53, 0x31, 0xfb, 0xe3, 0x40, 0xff, 0x53, 0xfe, 0x1e, 0xe2,
-	0x1c, 0x46, 0xb5, 0x49, 0xc5, 0x53, 0xb3, 0xb1, 0xed, 0xa6, 0x78, 0x8f, 0x76, 0xa5, 0x35, 0x93,
-	0x4b, 0x80, 0xd2, 0x14, 0xf4, 0x4c, 0xf5, 0x96, 0xd1, 0xde, 0x83, 0xd6, 0x9c, 0x86, 0x79, 0x07,
-	0xff, 0x57, 0x85, 0x62, 0xf9, 0xee, 0xb5, 0x18, 0xe6, 0x9d, 0xee, 0xc8, 0x6a, 0xb0, 0x39, 0x8c,
-	0x6a, 0x03, 0x5d, 0xd1, 0xd8, 0x76, 0xc1, 0x2a, 0x1a, 0x5b, 0xef, 0x01, 0x7e, 0x84, 0x83, 0xfa,
-	0xb0, 0x62, 0x73, 0x47, 0xe3, 0x82, 0x78, 0x8f, 0x77, 0xe6, 0x4b, 0x8a, 0xb5, 0x19, 0xad, 0x50,
-	0x6c, 0x9b, 0xfe, 0x0a, 0xc5, 0xd6, 0xd1, 0xfe, 0xde, 0x53, 0xff, 0xb9, 0x57, 0x7f, 0x03, 0x00,
-	0x00, 0xff, 0xff, 0x9a, 0x26, 0x81, 0x25, 0xf8, 0x06, 0x00, 0x00,
+	// 705 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xdb, 0x6e, 0xd3, 0x4c,
+	0x10, 0x96, 0xe3, 0xa4, 0xc9, 0x3f, 0xf9, 0x53, 0xb5, 0x5b, 0xb7, 0x58, 0xdb, 0x53, 0xe4, 0x72,
+	0x81, 0x10, 0x04, 0x29, 0x48, 0x08, 0x21, 0x21, 0x51, 0xb5, 0xa5, 0x48, 0x45, 0x15, 0x18, 0x01,
+	0xe2, 0x06, 0x11, 0xec, 0x2d, 0x32, 0x4d, 0xbd, 0xc6, 0xbb, 0x6e, 0xd5, 0x67, 0xe3, 0x61, 0xb8,
+	0xe5, 0x31, 0xd0, 0x1e, 0x7c, 0x8c, 0x0d, 0xd4, 0xe2, 0xce, 0xb3, 0x33, 0xfb, 0x7d, 0x33, 0xdf,
+	0xcc, 0x4e, 0x02, 0x23, 0x46, 0xe2, 0xcb, 0xc0, 0x23, 0x93, 0x28, 0xa6, 0x9c, 0xa2, 0x7e, 0x14,
+	0xd3, 0xaf, 0xc4, 0xe3, 0xce, 0x47, 0xe8, 0x9e, 0x52, 0x9f, 0x20, 0x0b, 0x7a, 0x3c, 0xe0, 0x73,
+	0x62, 0x1b, 0x63, 0xe3, 0xce, 0x7f, 0xae, 0x32, 0x10, 0x82, 0x2e, 0xbf, 0x8e, 0x88, 0xdd, 0x91,
+	0x87, 0xf2, 0x5b, 0x44, 0x06, 0x61, 0x94, 0x70, 0xdb, 0x1c, 0x9b, 0x22, 0x52, 0x1a, 0x68, 0x03,
+	0x96, 0x68, 0xc2, 0xc5, 0x71, 0x57, 0x1e, 0x6b, 0xcb, 0xf9, 0x6e, 0xc0, 0xe0, 0x3d, 0x8d, 0xcf,
+	0xcf, 0xe6, 0xf4, 0x4a, 0x04, 0x31, 0x9a, 0xc4, 0x5e, 0xca, 0xa2, 0x2d, 0x84, 0x61, 0x70, 0x41,
+	0xfd, 0xe0, 0x2c, 0x20, 0xbe, 0xa4, 0x32, 0xdd, 0xcc, 0x46, 0x53, 0xe8, 0x85, 0xd4, 0x27, 0x4c,
+	0xd2, 0x0d, 0xa7, 0x5b, 0x13, 0x9d, 0xf9, 0x24, 0x45, 0x9d, 0x88, 0xfc, 0xd9, 0x51, 0xc8, 0xe3,
+	0x6b, 0x57, 0x85, 0xe2, 0x63, 0x80, 0xfc, 0x10, 0xad, 0x80, 0x79, 0x4e, 0xae, 0x35, 0xa5, 0xf8,
+	0x44, 0x7b, 0xd0, 0xbb, 0x9c, 0xcd, 0x13, 0x55, 0xd7, 0x70, 0x3a, 0xca, 0x30, 0xc5, 0x2d, 0x57,
+	0xf9, 0x9e, 0x74, 0x1e, 0x1b, 0xce, 0x4f, 0x03, 0xfa, 0xaf, 0x94, 0x0f, 0x2d, 0x43, 0x27, 0xf0,
+	0x35, 0x4a, 0x27, 0xf0, 0x91, 0x0d, 0xfd, 0x99, 0xe7, 0xd1, 0x24, 0xe4, 0x5a, 0x9e, 0xd4, 0x14,
+	0xaa, 0x85, 0xb3, 0x0b, 0x62, 0x9b, 0x4a, 0x35, 0xf1, 0x8d, 0xc6, 0x30, 0xf4, 0x09, 0xf3, 0xe2,
+	0x20, 0xe2, 0x01, 0x0d, 0xed, 0xae, 0x74, 0x15, 0x8f, 0x04, 0x9e, 0x17, 0x93, 0x19, 0x27, 0xbe,
+	0xdd, 0x93, 0x1a, 0xa4, 0x66, 0x49, 0x9e, 0xa5, 0x8a, 0x3c, 0xf7, 0x61, 0x70, 0xa5, 0x85, 0xb0,
+	0xfb, 0xb2, 0x9a, 0xd5, 0x05, 0x85, 0xdc, 0x2c, 0x44, 0x90, 0xf8, 0x64, 0x4e, 0x04, 0xc9, 0x40,
+	0x91, 0x68, 0xd3, 0x39, 0x03, 0xeb, 0x40, 0xf2, 0xe9, 0x7a, 0x5d, 0xf2, 0x2d, 0x21, 0x8c, 0x17,
+	0xcb, 0x34, 0xea, 0xcb, 0xec, 0x34, 0x97, 0x69, 0x2e, 0x94, 0xe9, 0x1c, 0xc0, 0x7a, 0x85, 0x87,
+	0x45, 0x34, 0x64, 0x04, 0xdd, 0x85, 0x74, 0x28, 0x25, 0xd1, 0x70, 0xba, 0x92, 0x15, 0x92, 0x86,
+	0x66, 0x53, 0x7b, 0x09, 0xd6, 0xdb, 0xc8, 0xbf, 0x49, 0xb2, 0xaa, 0x7b, 0x9d, 0xac, 0x7b, 0xad,
+	0x7a, 0xe4, 0xdc, 0x82, 0xf5, 0x0a, 0xaf, 0x4a, 0xde, 0xf9, 0x90, 0x3a, 0x32, 0xcd, 0x6f, 0x9c,
+	0x51, 0xfe, 0x38, 0xcc, 0xe2, 0xe3, 0x70, 0x6c, 0xd8, 0xa8, 0x42, 0x6b, 0xd2, 0x77, 0x60, 0x1d,
+	0xca, 0xee, 0xb5, 0x56, 0xc1, 0x82, 0x5e, 0x94, 0xc4, 0x5f, 0x14, 0xe5, 0xc0, 0x55, 0x86, 0xa8,
+	0xb2, 0x82, 0xab, 0x09, 0x9f, 0xc2, 0xea, 0x31, 0xe1, 0x6d, 0xd9, 0x9c, 0x67, 0x80, 0x8a, 0xd7,
+	0x5b, 0xf4, 0xfd, 0x01, 0xac, 0xbd, 0x0c, 0x58, 0x0a, 0xc1, 0xfe, 0x98, 0x82, 0x73, 0x08, 0x56,
+	0xf9, 0x82, 0x26, 0xbd, 0x07, 0x03, 0x8d, 0xc9, 0x6c, 0x63, 0x6c, 0xd6, 0xb2, 0x66, 0x11, 0x8e,
+	0x0b, 0xe8, 0x39, 0x8d, 0xcf, 0xff, 0xe5, 0xb0, 0x39, 0xfb, 0xb0, 0x56, 0xc2, 0x6c, 0xa1, 0xc6,
+	0x3e, 0xac, 0xbb, 0x84, 0x71, 0x1a, 0xb7, 0x1e, 0x00, 0x31, 0x5c, 0x55, 0x08, 0xdd, 0xeb, 0x47,
+	0x80, 0x85, 0x72, 0x6a, 0x10, 0xfc, 0xbf, 0x57, 0xfc, 0x04, 0x36, 0x6b, 0xef, 0xb5, 0x11, 0x7e,
+	0xfa, 0xa3, 0x07, 0xfd, 0x37, 0xea, 0x87, 0x0b, 0x9d, 0xc2, 0xa8, 0xb4, 0x38, 0xd0, 0x76, 0x76,
+	0xb1, 0x6e, 0x71, 0xe1, 0x9d, 0x26, 0xb7, 0xce, 0xe4, 0x08, 0x20, 0x9f, 0x46, 0x84, 0xb3, 0xe8,
+	0x85, 0x09, 0xc7, 0x9b, 0xb5, 0x3e, 0x0d, 0x73, 0x02, 0xff, 0x17, 0x27, 0x0c, 0xe5, 0x3f, 0x50,
+	0x35, 0x93, 0x8a, 0xb7, 0x1b, 0xbc, 0x1a, 0xec, 0x14, 0x46, 0xa5, 0xfd, 0x52, 0xa8, 0xb1, 0x6e,
+	0xdf, 0xe1, 0x9d, 0x26, 0xb7, 0xc6, 0x7b, 0x0d, 0xcb, 0xe5, 0xdd, 0x81, 0xaa, 0x37, 0x2a, 0xfb,
+	0x0a, 0xef, 0x36, 0xfa, 0xf3, 0x14, 0x4b, 0xcb, 0xa1, 0x90, 0x62, 0xdd, 0x32, 0xc2, 0x3b, 0x4d,
+	0x6e, 0x8d, 0xf7, 0x02, 0x86, 0x85, 0x77, 0x80, 0x72, 0xad, 0x17, 0x5f, 0x1c, 0xde, 0xaa, 0x77,
+	0xe6, 0xc5, 0x96, 0x67, 0xb9, 0x50, 0x6c, 0xed, 0x3b, 0xc1, 0xbb, 0x8d, 0x7e, 0x0d, 0xf9, 0x49,
+	0xed, 0x9b, 0xca, 0x30, 0xa3, 0xbd, 0x52, 0x17, 0xeb, 0x9f, 0x08, 0xbe, 0xfd, 0xfb, 0x20, 0xc5,
+	0xf0, 0x79, 0x49, 0xfe, 0x1f, 0x7b, 0xf8, 0x6b, 0x00, 0xc6, 0x40, 0xd3, 0x7f, 0xa0, 0x09, 0x00,
+	0x00,
 }
@@ -21,6 +21,9 @@ type Service interface {
 	UpdateProject(context.Context, *UpdateProjectRequest) (*UpdateProjectResponse, error)
 	UpdateWorkflow(context.Context, *UpdateWorkflowRequest) (*UpdateWorkflowResponse, error)
 	DeleteProject(context.Context, *DeleteProjectRequest) (*DeleteProjectResponse, error)
+	ForkProject(context.Context, *ForkProjectRequest) (*ForkProjectResponse, error)
+	RestoreProject(context.Context, *RestoreProjectRequest) (*RestoreProjectResponse, error)
+	ListDeletedProjects(context.Context, *ListDeletedProjectsRequest) (*ListDeletedProjectsResponse, error)
 }
 
 type ServiceClient interface {
@@ -30,6 +33,9 @@ type ServiceClient interface {
 	UpdateProject(context.Context, *UpdateProjectRequest, ...transport.RequestOption) (*UpdateProjectResponse, error)
 	UpdateWorkflow(context.Context, *UpdateWorkflowRequest, ...transport.RequestOption) (*UpdateWorkflowResponse, error)
 	DeleteProject(context.Context, *DeleteProjectRequest, ...transport.RequestOption) (*DeleteProjectResponse, error)
+	ForkProject(context.Context, *ForkProjectRequest, ...transport.RequestOption) (*ForkProjectResponse, error)
+	RestoreProject(context.Context, *RestoreProjectRequest, ...transport.RequestOption) (*RestoreProjectResponse, error)
+	ListDeletedProjects(context.Context, *ListDeletedProjectsRequest, ...transport.RequestOption) (*ListDeletedProjectsResponse, error)
 }
 
 // serviceClient an implementation of Service client.
@@ -103,6 +109,39 @@ func (c *serviceClient) DeleteProject(ctx context.Context, req *DeleteProjectReq
 	return &rep, nil
 }
 
+func (c *serviceClient) ForkProject(ctx context.Context, req *ForkProjectRequest, opts ...transport.RequestOption) (*ForkProjectResponse, error) {
+	var rep ForkProjectResponse
+
+	_, err := c.tp.Request("project.ForkProject", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) RestoreProject(ctx context.Context, req *RestoreProjectRequest, opts ...transport.RequestOption) (*RestoreProjectResponse, error) {
+	var rep RestoreProjectResponse
+
+	_, err := c.tp.Request("project.RestoreProject", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) ListDeletedProjects(ctx context.Context, req *ListDeletedProjectsRequest, opts ...transport.RequestOption) (*ListDeletedProjectsResponse, error) {
+	var rep ListDeletedProjectsResponse
+
+	_, err := c.tp.Request("project.ListDeletedProjects", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
 // NewServiceClient creates a new Service client.
 func NewServiceClient(tp transport.Transport) ServiceClient {
 	return &serviceClient{tp}
@@ -206,6 +245,45 @@ func (s *ServiceServer) Serve(ctx context.Context, opts ...transport.SubscribeOp
 	if err != nil {
 		return err
 	}
+	_, err = s.tp.Subscribe("project.ForkProject", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req ForkProjectRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.ForkProject(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("project.RestoreProject", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req RestoreProjectRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.RestoreProject(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("project.ListDeletedProjects", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req ListDeletedProjectsRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.ListDeletedProjects(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
 
 	sigchan := make(chan os.Signal)
 	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
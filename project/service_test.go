@@ -0,0 +1,204 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/internal/mongotest"
+	"github.com/rdm-academy/api/nodes"
+)
+
+// testTransport records the published events.
+type testTransport struct {
+	transport.Transport
+
+	events []*commitlog.Event
+}
+
+func (t *testTransport) Publish(subj string, msg proto.Message) (*transport.Message, error) {
+	t.events = append(t.events, msg.(*commitlog.Event))
+	return &transport.Message{}, nil
+}
+
+// types returns the types of the events published for the project.
+func (t *testTransport) types(project string) []string {
+	var types []string
+	for _, e := range t.events {
+		if e.Project == project {
+			types = append(types, e.Type)
+		}
+	}
+	return types
+}
+
+type testNodes struct {
+	nodes.ServiceClient
+
+	err    error
+	copies []*nodes.CopyRequest
+}
+
+func (n *testNodes) Copy(ctx context.Context, req *nodes.CopyRequest, opts ...transport.RequestOption) (*nodes.NoReply, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+
+	n.copies = append(n.copies, req)
+	return &nodes.NoReply{}, nil
+}
+
+type testCommitlog struct {
+	commitlog.ServiceClient
+}
+
+func (c *testCommitlog) History(ctx context.Context, req *commitlog.HistoryRequest, opts ...transport.RequestOption) (*commitlog.HistoryReply, error) {
+	return &commitlog.HistoryReply{
+		Commit: &commitlog.Commit{Id: "c-" + req.Project},
+	}, nil
+}
+
+// testService returns a service on a scratch database and a function that
+// drops it.
+func testService(t *testing.T) (*service, func()) {
+	db, drop := mongotest.DB(t, "project")
+
+	err := db.C(projectsCol).EnsureIndex(mgo.Index{
+		Key:    []string{"account", "_name"},
+		Unique: true,
+	})
+	if err != nil {
+		drop()
+		t.Fatal(err)
+	}
+
+	s := &service{
+		db:           db,
+		tp:           &testTransport{},
+		nodeSvc:      &testNodes{},
+		commitlogSvc: &testCommitlog{},
+		retention:    time.Hour,
+	}
+
+	return s, func() {
+		drop()
+	}
+}
+
+func TestForkProject(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	rep, err := s.CreateProject(ctx, &CreateProjectRequest{Account: "a1", Name: "Study"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := rep.Project.Id
+
+	source := "raw:\n  type: data\n  title: Raw data\n"
+
+	if _, err := s.UpdateWorkflow(ctx, &UpdateWorkflowRequest{Account: "a1", Id: src, Source: source}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, x := range []struct {
+		name string
+		req  *ForkProjectRequest
+		code codes.Code
+	}{
+		{"no account", &ForkProjectRequest{Id: src}, codes.InvalidArgument},
+		{"not found", &ForkProjectRequest{Account: "a1", Id: "p0"}, codes.NotFound},
+		{"other account", &ForkProjectRequest{Account: "a2", Id: src}, codes.NotFound},
+		{"name taken", &ForkProjectRequest{Account: "a1", Id: src, Name: "study"}, codes.AlreadyExists},
+	} {
+		_, err := s.ForkProject(ctx, x.req)
+		if code := status.Code(err); code != x.code {
+			t.Errorf("%s: expected %s, got %s", x.name, x.code, code)
+		}
+	}
+
+	// Names get an increasing suffix until the limit is reached.
+	names := []string{"Study (fork)"}
+	for i := 2; i <= maxForkNames; i++ {
+		names = append(names, fmt.Sprintf("Study (fork %d)", i))
+	}
+
+	for _, name := range names {
+		rep, err := s.ForkProject(ctx, &ForkProjectRequest{Account: "a1", Id: src})
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+
+		p := rep.Project
+
+		if p.Name != name {
+			t.Errorf("expected %s, got %s", name, p.Name)
+		}
+		if p.Id == src || p.Workflow.Source != source || len(p.Workflow.Nodes) != 1 {
+			t.Errorf("%s: expected a copy of the project, got %v", name, p)
+		}
+
+		// The fork starts with the forked event.
+		if types := s.tp.(*testTransport).types(p.Id); len(types) != 1 || types[0] != "project.forked" {
+			t.Errorf("%s: expected forked event, got %v", name, types)
+		}
+	}
+
+	if _, err := s.ForkProject(ctx, &ForkProjectRequest{Account: "a1", Id: src}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected %s after %d forks, got %v", codes.AlreadyExists, maxForkNames, err)
+	}
+
+	// Named forks do not get a suffix.
+	named, err := s.ForkProject(ctx, &ForkProjectRequest{Account: "a1", Id: src, Name: "Replication"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if named.Project.Name != "Replication" {
+		t.Errorf("expected Replication, got %s", named.Project.Name)
+	}
+
+	copies := s.nodeSvc.(*testNodes).copies
+	if len(copies) != maxForkNames+1 {
+		t.Fatalf("expected %d copies, got %d", maxForkNames+1, len(copies))
+	}
+	if c := copies[len(copies)-1]; c.Project != src || c.ToProject != named.Project.Id {
+		t.Errorf("unexpected copy: %v", c)
+	}
+
+	// The fork is purged when the nodes cannot be copied.
+	s.nodeSvc.(*testNodes).err = errors.New("copy failed")
+
+	if _, err := s.ForkProject(ctx, &ForkProjectRequest{Account: "a1", Id: src, Name: "Partial"}); err == nil {
+		t.Fatal("expected copy error")
+	}
+
+	n, err := s.db.C(projectsCol).Find(bson.M{"name": "Partial", "purging": bson.M{"$exists": false}}).Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Error("expected partial fork to be purged")
+	}
+
+	// The other services remove what was recorded for the fork.
+	events := s.tp.(*testTransport).events
+	forked, deleted := events[len(events)-2], events[len(events)-1]
+
+	if forked.Type != "project.forked" || deleted.Type != "project.deleted" || deleted.Project != forked.Project {
+		t.Errorf("expected fork to be deleted, got %s and %s", forked.Type, deleted.Type)
+	}
+}
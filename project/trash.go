@@ -0,0 +1,166 @@
+package project
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// How often the trash is checked for projects to purge.
+	purgeInterval = time.Hour
+)
+
+// trashName is the normalized name of a project in the trash. It is unique
+// so it does not conflict with the name index.
+func trashName(id string) string {
+	return "#trash:" + id
+}
+
+func (s *service) RestoreProject(ctx context.Context, req *RestoreProjectRequest) (*RestoreProjectResponse, error) {
+	q := bson.M{
+		"_id":     req.Id,
+		"account": req.Account,
+		"deleted": bson.M{
+			"$exists": true,
+		},
+		"purging": bson.M{
+			"$exists": false,
+		},
+	}
+
+	var p project
+	if err := s.db.C(projectsCol).Find(q).Select(bson.M{"name": 1}).One(&p); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "project not found")
+		}
+
+		return nil, err
+	}
+
+	u := bson.M{
+		"$set": bson.M{
+			"_name": strings.ToLower(p.Name),
+		},
+		"$unset": bson.M{
+			"deleted": "",
+		},
+	}
+
+	if err := s.db.C(projectsCol).Update(q, u); err != nil {
+		// Another project with the same name was created in the meantime.
+		if mgo.IsDup(err) {
+			return nil, status.Error(codes.AlreadyExists, "project already exists")
+		}
+
+		if err == mgo.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "project not found")
+		}
+
+		return nil, err
+	}
+
+	s.logEvent("events.project", &logEvent{
+		Project: req.Id,
+		Type:    "project.restored",
+		Author:  req.Account,
+	})
+
+	return &RestoreProjectResponse{}, nil
+}
+
+func (s *service) ListDeletedProjects(ctx context.Context, req *ListDeletedProjectsRequest) (*ListDeletedProjectsResponse, error) {
+	q := bson.M{
+		"account": req.Account,
+		"deleted": bson.M{
+			"$exists": true,
+		},
+		"purging": bson.M{
+			"$exists": false,
+		},
+	}
+
+	list, err := s.listProjects(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListDeletedProjectsResponse{
+		Projects: list,
+	}, nil
+}
+
+// purge purges the projects that have been in the trash longer than the
+// retention period. The project.deleted event is published again for
+// projects purged before that not all services acknowledged yet.
+func (s *service) purge() error {
+	now := time.Now()
+
+	q := bson.M{
+		"purging": bson.M{
+			"$lt": now.Add(-purgeInterval),
+		},
+	}
+
+	var docs []*project
+	if err := s.db.C(projectsCol).Find(q).Select(bson.M{"account": 1, "acks": 1}).All(&docs); err != nil {
+		return err
+	}
+
+	for _, p := range docs {
+		log.Printf("project: project %s not acknowledged as deleted by all services (%v)", p.ID, p.Acks)
+
+		s.logEvent("events.project", &logEvent{
+			Project: p.ID,
+			Type:    "project.deleted",
+			Author:  p.Account,
+		})
+	}
+
+	if s.retention <= 0 {
+		return nil
+	}
+
+	q = bson.M{
+		"deleted": bson.M{
+			"$lt": now.Add(-s.retention),
+		},
+		"purging": bson.M{
+			"$exists": false,
+		},
+	}
+
+	docs = nil
+	if err := s.db.C(projectsCol).Find(q).Select(bson.M{"account": 1}).All(&docs); err != nil {
+		return err
+	}
+
+	for _, p := range docs {
+		if err := s.markPurged(p.ID, p.Account); err != nil && err != mgo.ErrNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runPurge periodically purges the trash.
+func (s *service) runPurge() {
+	t := time.NewTicker(purgeInterval)
+	defer t.Stop()
+
+	for {
+		if err := s.purge(); err != nil {
+			log.Printf("project: purge error: %s", err)
+		}
+
+		<-t.C
+	}
+}
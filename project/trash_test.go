@@ -0,0 +1,261 @@
+package project
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rdm-academy/api/commitlog"
+)
+
+// ids returns the ids of the projects.
+func ids(list []*Project) map[string]bool {
+	m := make(map[string]bool, len(list))
+	for _, p := range list {
+		m[p.Id] = true
+	}
+	return m
+}
+
+func TestTrash(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	create := func(account, name string) string {
+		rep, err := s.CreateProject(ctx, &CreateProjectRequest{Account: account, Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rep.Project.Id
+	}
+
+	p1 := create("a1", "Study")
+	p2 := create("a1", "Other")
+	p3 := create("a2", "Study")
+
+	for _, x := range []struct {
+		name string
+		req  *DeleteProjectRequest
+		code codes.Code
+	}{
+		{"trashed", &DeleteProjectRequest{Account: "a1", Id: p1}, codes.OK},
+		{"already trashed", &DeleteProjectRequest{Account: "a1", Id: p1}, codes.NotFound},
+		{"other account", &DeleteProjectRequest{Account: "a1", Id: p3}, codes.NotFound},
+		{"not found", &DeleteProjectRequest{Account: "a1", Id: "p0"}, codes.NotFound},
+	} {
+		_, err := s.DeleteProject(ctx, x.req)
+		if code := status.Code(err); code != x.code {
+			t.Errorf("%s: expected %s, got %s", x.name, x.code, code)
+		}
+	}
+
+	// Projects in the trash are only listed as deleted.
+	list, err := s.ListProjects(ctx, &ListProjectsRequest{Account: "a1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := ids(list.Projects); len(m) != 1 || !m[p2] {
+		t.Errorf("expected only %s to be listed, got %v", p2, m)
+	}
+
+	deleted, err := s.ListDeletedProjects(ctx, &ListDeletedProjectsRequest{Account: "a1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := ids(deleted.Projects); len(m) != 1 || !m[p1] {
+		t.Errorf("expected only %s to be deleted, got %v", p1, m)
+	}
+
+	if _, err := s.GetProject(ctx, &GetProjectRequest{Account: "a1", Id: p1}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected %s for a deleted project, got %v", codes.NotFound, err)
+	}
+
+	// The name is released while the project is in the trash.
+	p4 := create("a1", "Study")
+
+	for _, x := range []struct {
+		name string
+		req  *RestoreProjectRequest
+		code codes.Code
+	}{
+		{"not deleted", &RestoreProjectRequest{Account: "a1", Id: p2}, codes.NotFound},
+		{"other account", &RestoreProjectRequest{Account: "a2", Id: p1}, codes.NotFound},
+		{"name taken", &RestoreProjectRequest{Account: "a1", Id: p1}, codes.AlreadyExists},
+	} {
+		_, err := s.RestoreProject(ctx, x.req)
+		if code := status.Code(err); code != x.code {
+			t.Errorf("%s: expected %s, got %s", x.name, x.code, code)
+		}
+	}
+
+	if _, err := s.DeleteProject(ctx, &DeleteProjectRequest{Account: "a1", Id: p4}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RestoreProject(ctx, &RestoreProjectRequest{Account: "a1", Id: p1}); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := s.GetProject(ctx, &GetProjectRequest{Account: "a1", Id: p1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Project.Name != "Study" {
+		t.Errorf("expected Study, got %s", rep.Project.Name)
+	}
+
+	// The restored project holds the name again.
+	if _, err := s.CreateProject(ctx, &CreateProjectRequest{Account: "a1", Name: "study"}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected %s, got %v", codes.AlreadyExists, err)
+	}
+
+	deleted, err = s.ListDeletedProjects(ctx, &ListDeletedProjectsRequest{Account: "a1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := ids(deleted.Projects); len(m) != 1 || !m[p4] {
+		t.Errorf("expected only %s to be deleted, got %v", p4, m)
+	}
+
+	types := s.tp.(*testTransport).types(p1)
+	if len(types) != 3 || types[1] != "project.trashed" || types[2] != "project.restored" {
+		t.Errorf("expected trashed and restored events, got %v", types)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	now := time.Now()
+
+	for _, p := range []*project{
+		{ID: "expired", Account: "a1", NormName: trashName("expired"), Deleted: now.Add(-s.retention - time.Minute)},
+		{ID: "recent", Account: "a1", NormName: trashName("recent"), Deleted: now.Add(-s.retention + time.Minute)},
+		{ID: "active", Account: "a1", NormName: "active"},
+		{ID: "unacked", Account: "a1", NormName: trashName("unacked"), Deleted: now.Add(-2 * purgeInterval), Purging: now.Add(-2 * purgeInterval)},
+		{ID: "pending", Account: "a1", NormName: trashName("pending"), Deleted: now, Purging: now},
+	} {
+		if err := s.db.C(projectsCol).Insert(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.purge(); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, purged := range map[string]bool{
+		"expired": true,
+		"recent":  false,
+		"active":  false,
+		"unacked": true,
+		"pending": false,
+	} {
+		// The other services remove the data of purged projects. Projects
+		// not acknowledged by all of them get the event again.
+		types := s.tp.(*testTransport).types(id)
+		if ok := len(types) == 1 && types[0] == "project.deleted"; ok != purged {
+			t.Errorf("%s: unexpected events %v", id, types)
+		}
+	}
+
+	// Purged projects are no longer in the trash.
+	deleted, err := s.ListDeletedProjects(context.Background(), &ListDeletedProjectsRequest{Account: "a1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := ids(deleted.Projects); len(m) != 1 || !m["recent"] {
+		t.Errorf("expected only recent to be deleted, got %v", m)
+	}
+
+	if _, err := s.RestoreProject(context.Background(), &RestoreProjectRequest{Account: "a1", Id: "expired"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected %s for a purged project, got %v", codes.NotFound, err)
+	}
+
+	// The project is removed once every service acknowledged the event.
+	for i, svc := range commitlog.ProjectDeletedAcks {
+		if err := s.ack("expired", svc); err != nil {
+			t.Fatal(err)
+		}
+
+		// Acknowledgements are idempotent.
+		if err := s.ack("expired", svc); err != nil {
+			t.Fatal(err)
+		}
+
+		n, err := s.db.C(projectsCol).FindId("expired").Count()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if last := i == len(commitlog.ProjectDeletedAcks)-1; (n == 0) != last {
+			t.Errorf("%s: expected removed to be %t", svc, last)
+		}
+	}
+
+	// Projects that are not purged are not removed by acknowledgements.
+	if err := s.ack("recent", "data"); err != nil {
+		t.Fatal(err)
+	}
+
+	var p project
+	if err := s.db.C(projectsCol).FindId("recent").One(&p); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Acks) != 0 {
+		t.Errorf("expected no acknowledgements, got %v", p.Acks)
+	}
+}
+
+func TestDeleteProjects(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	var created []string
+	for _, x := range []struct {
+		account string
+		name    string
+	}{
+		{"a1", "Study"},
+		{"a1", "Other"},
+		{"a2", "Study"},
+	} {
+		rep, err := s.CreateProject(ctx, &CreateProjectRequest{Account: x.account, Name: x.name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		created = append(created, rep.Project.Id)
+	}
+
+	if err := s.deleteProjects("a1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleting again does not publish the events again.
+	if err := s.deleteProjects("a1"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, purged := range []bool{true, true, false} {
+		types := s.tp.(*testTransport).types(created[i])
+		if ok := len(types) == 2 && types[1] == "project.deleted"; ok != purged {
+			t.Errorf("%s: unexpected events %v", created[i], types)
+		}
+	}
+
+	list, err := s.ListProjects(ctx, &ListProjectsRequest{Account: "a1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Projects) != 0 {
+		t.Errorf("expected no projects, got %d", len(list.Projects))
+	}
+}
@@ -0,0 +1,83 @@
+package project
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffGraph(t *testing.T) {
+	a, err := ParseSource(`
+raw:
+  title: Raw data
+  type: data
+  output: [clean]
+clean:
+  title: Clean
+  type: compute
+  input: [raw]
+old:
+  title: Old
+  type: manual
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ParseSource(`
+raw:
+  title: Raw data
+  type: data
+  output: [clean, plot]
+clean:
+  title: Cleaned
+  type: compute
+  input: [raw]
+plot:
+  title: Plot
+  type: finding
+  input: [raw]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := DiffGraph(a, a); d != nil {
+		t.Errorf("expected no diff, got %v", d)
+	}
+
+	d := DiffGraph(a, b)
+	if d == nil {
+		t.Fatal("expected a diff")
+	}
+
+	if len(d.Added) != 1 || d.Added["plot"] == nil {
+		t.Errorf("expected plot to be added, got %v", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed["old"] == nil {
+		t.Errorf("expected old to be removed, got %v", d.Removed)
+	}
+
+	expected := map[string]*NodeDiff{
+		"raw": {
+			Output: map[string]bool{"plot": true},
+		},
+		"clean": {
+			FromTitle: "Clean",
+			ToTitle:   "Cleaned",
+		},
+	}
+	if !reflect.DeepEqual(d.Changed, expected) {
+		t.Errorf("expected %v, got %v", expected, d.Changed)
+	}
+}
+
+func TestDiffSlice(t *testing.T) {
+	d := DiffSlice([]string{"a", "b"}, []string{"b", "c"})
+	if !reflect.DeepEqual(d, map[string]bool{"a": false, "c": true}) {
+		t.Errorf("unexpected diff %v", d)
+	}
+
+	if d := DiffSlice([]string{"a"}, []string{"a"}); d != nil {
+		t.Errorf("expected no diff, got %v", d)
+	}
+}
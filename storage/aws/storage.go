@@ -26,11 +26,23 @@ type URL struct {
 	client *s3.S3
 }
 
-func (u *URL) Get(expiry time.Duration) (string, error) {
-	req, _ := u.client.GetObjectRequest(&s3.GetObjectInput{
+func (u *URL) Get(expiry time.Duration, opts *storage.ResponseOptions) (string, error) {
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(u.object.bucket),
 		Key:    aws.String(u.object.name),
-	})
+	}
+
+	if opts != nil {
+		if opts.ContentDisposition != "" {
+			input.ResponseContentDisposition = aws.String(opts.ContentDisposition)
+		}
+
+		if opts.ContentType != "" {
+			input.ResponseContentType = aws.String(opts.ContentType)
+		}
+	}
+
+	req, _ := u.client.GetObjectRequest(input)
 
 	return req.Presign(expiry)
 }
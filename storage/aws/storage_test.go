@@ -5,11 +5,14 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/rdm-academy/api/storage"
 )
 
 // Create a bucket, create and read an object, delete the bucket.
@@ -84,7 +87,7 @@ func TestStorage(t *testing.T) {
 	data.Reset()
 
 	// Try presigned URL.
-	url, err := obj.URL().Get(time.Second * 10)
+	url, err := obj.URL().Get(time.Second*10, nil)
 
 	if err != nil {
 		t.Errorf("object.url.get: %s", err)
@@ -115,3 +118,51 @@ func TestStorage(t *testing.T) {
 		t.Errorf("bucket.delete: %s", err)
 	}
 }
+
+// Signed download URLs override the response headers. Presigning does not
+// contact AWS.
+func TestURLGet(t *testing.T) {
+	c, err := NewStorage(Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	u := c.Bucket("test").Object("dir/test.csv").URL()
+
+	s, err := u.Get(time.Minute, &storage.ResponseOptions{
+		ContentDisposition: `attachment; filename="test.csv"`,
+		ContentType:        "text/csv",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := p.Query()
+	if v := q.Get("response-content-disposition"); v != `attachment; filename="test.csv"` {
+		t.Errorf("expected the content disposition, got %q", v)
+	}
+	if v := q.Get("response-content-type"); v != "text/csv" {
+		t.Errorf("expected the content type, got %q", v)
+	}
+	if !strings.Contains(q.Get("X-Amz-SignedHeaders"), "host") || q.Get("X-Amz-Signature") == "" {
+		t.Errorf("expected a signed url, got %s", s)
+	}
+
+	// Without options the response headers are not overridden.
+	s, err = u.Get(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(s, "response-content") {
+		t.Errorf("expected no overrides, got %s", s)
+	}
+}
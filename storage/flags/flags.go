@@ -0,0 +1,79 @@
+// Package flags defines the command line flags of the storage providers
+// for the services that access storage.
+package flags
+
+import (
+	"context"
+	"flag"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/rdm-academy/api/storage"
+	"github.com/rdm-academy/api/storage/aws"
+	"github.com/rdm-academy/api/storage/gcp"
+)
+
+// Options are the storage options set on the command line.
+type Options struct {
+	awsAccessKey    string
+	awsSecretKey    string
+	awsSessionToken string
+	awsRegion       string
+	awsProfile      string
+	awsSSE          string
+
+	gcpProjectId          string
+	gcpServiceAccountFile string
+}
+
+// Register defines the storage flags on the flag set.
+func Register(fs *flag.FlagSet) *Options {
+	o := &Options{}
+
+	fs.StringVar(&o.awsAccessKey, "aws.access-key", "", "AWS access key.")
+	fs.StringVar(&o.awsSecretKey, "aws.secret-key", "", "AWS secret key.")
+	fs.StringVar(&o.awsSessionToken, "aws.session-token", "", "AWS token.")
+	fs.StringVar(&o.awsRegion, "aws.region", "us-east-1", "AWS region.")
+	fs.StringVar(&o.awsProfile, "aws.profile", "", "AWS profile.")
+	fs.StringVar(&o.awsSSE, "aws.sse", "", "AWS server-side encryption.")
+
+	fs.StringVar(&o.gcpProjectId, "gcp.project", "", "GCP project id")
+	fs.StringVar(&o.gcpServiceAccountFile, "gcp.service-account", "", "GCP service account file")
+
+	return o
+}
+
+// Open opens the configured storage. It returns nil if no storage options
+// are set.
+func (o *Options) Open(ctx context.Context) (storage.Storage, error) {
+	// AWS S3 or Minio.
+	if o.awsProfile != "" {
+		return aws.NewStorage(aws.Config{
+			Region:               o.awsRegion,
+			ServerSideEncryption: o.awsSSE,
+			Credentials:          credentials.NewSharedCredentials("", o.awsProfile),
+		})
+	}
+
+	if o.awsSecretKey != "" {
+		return aws.NewStorage(aws.Config{
+			Region:               o.awsRegion,
+			ServerSideEncryption: o.awsSSE,
+			Credentials: credentials.NewStaticCredentialsFromCreds(credentials.Value{
+				AccessKeyID:     o.awsAccessKey,
+				SecretAccessKey: o.awsSecretKey,
+				SessionToken:    o.awsSessionToken,
+			}),
+		})
+	}
+
+	// GCP.
+	if o.gcpProjectId != "" {
+		return gcp.NewStorage(gcp.Config{
+			Context:            ctx,
+			Project:            o.gcpProjectId,
+			ServiceAccountFile: o.gcpServiceAccountFile,
+		})
+	}
+
+	return nil, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net/url"
 	"os"
 	"time"
 
@@ -47,13 +48,34 @@ type URL struct {
 	cfg    *Config
 }
 
-func (u *URL) Get(expiry time.Duration) (string, error) {
-	return gcs.SignedURL(u.object.bucket, u.object.name, &gcs.SignedURLOptions{
+func (u *URL) Get(expiry time.Duration, opts *storage.ResponseOptions) (string, error) {
+	s, err := gcs.SignedURL(u.object.bucket, u.object.name, &gcs.SignedURLOptions{
 		GoogleAccessID: u.cfg.sa.ClientEmail,
 		PrivateKey:     []byte(u.cfg.sa.PrivateKey),
 		Method:         "GET",
 		Expires:        time.Now().Add(expiry),
 	})
+	if err != nil || opts == nil {
+		return s, err
+	}
+
+	// The response-* query parameters are not part of the signature
+	// so they can be appended to the signed URL.
+	p, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	q := p.Query()
+	if opts.ContentDisposition != "" {
+		q.Set("response-content-disposition", opts.ContentDisposition)
+	}
+	if opts.ContentType != "" {
+		q.Set("response-content-type", opts.ContentType)
+	}
+	p.RawQuery = q.Encode()
+
+	return p.String(), nil
 }
 
 func (u *URL) Put(expiry time.Duration) (string, error) {
@@ -3,11 +3,18 @@ package gcp
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"testing"
 	"time"
+
+	"github.com/rdm-academy/api/storage"
 )
 
 // Create a bucket, create and read an object, delete the bucket.
@@ -83,7 +90,7 @@ func TestStorage(t *testing.T) {
 	data.Reset()
 
 	// Try presigned URL.
-	url, err := obj.URL().Get(time.Second * 10)
+	url, err := obj.URL().Get(time.Second*10, nil)
 
 	if err != nil {
 		t.Errorf("object.url.get: %s", err)
@@ -114,3 +121,54 @@ func TestStorage(t *testing.T) {
 		t.Errorf("bucket.delete: %s", err)
 	}
 }
+
+// Signed download URLs override the response headers. Signing does not
+// contact GCP.
+func TestURLGet(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pk := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	u := &URL{
+		object: &Object{
+			name:   "dir/test.csv",
+			bucket: "test",
+		},
+		cfg: &Config{
+			sa: &serviceAccount{
+				ClientEmail: "test@example.iam.gserviceaccount.com",
+				PrivateKey:  string(pk),
+			},
+		},
+	}
+
+	s, err := u.Get(time.Minute, &storage.ResponseOptions{
+		ContentDisposition: `attachment; filename="test.csv"`,
+		ContentType:        "text/csv",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := p.Query()
+	if v := q.Get("response-content-disposition"); v != `attachment; filename="test.csv"` {
+		t.Errorf("expected the content disposition, got %q", v)
+	}
+	if v := q.Get("response-content-type"); v != "text/csv" {
+		t.Errorf("expected the content type, got %q", v)
+	}
+	if q.Get("Signature") == "" || q.Get("GoogleAccessId") == "" {
+		t.Errorf("expected a signed url, got %s", s)
+	}
+}
@@ -22,7 +22,7 @@ type Config struct {
 
 type url struct{}
 
-func (u *url) Get(expiry time.Duration) (string, error) {
+func (u *url) Get(expiry time.Duration, opts *ResponseOptions) (string, error) {
 	return "", ErrSignedURLUnsupported
 }
 
@@ -48,11 +48,11 @@ func (o *object) Exists(ctx context.Context) (bool, error) {
 	path := filepath.Join(o.cfg.Base, o.bucket, o.name)
 	_, err := os.Stat(path)
 
-	switch err {
-	case nil:
+	if err == nil {
 		return true, nil
+	}
 
-	case os.ErrNotExist:
+	if os.IsNotExist(err) {
 		return false, nil
 	}
 
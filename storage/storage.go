@@ -6,11 +6,19 @@ import (
 	"time"
 )
 
+// ResponseOptions overrides headers of the response when the object is
+// fetched using a signed URL. Empty values are ignored.
+type ResponseOptions struct {
+	ContentDisposition string
+	ContentType        string
+}
+
 // URL provides methods for performing various operations on an object over HTTP.
 type URL interface {
 	// Get returns a "signed" URL for fetching underlying object. The duration
-	// determines how long the URL is valid for.
-	Get(time.Duration) (string, error)
+	// determines how long the URL is valid for. The options are optional and
+	// override the response headers.
+	Get(time.Duration, *ResponseOptions) (string, error)
 
 	// Put returns a "signed" URL for uploading the underlying object. The passed
 	// duration determines how long the URL is valid for.
@@ -0,0 +1,484 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/golang/protobuf/proto"
+	"github.com/rdm-academy/api/commitlog"
+	uuid "github.com/satori/go.uuid"
+)
+
+const eventSubject = "events.>"
+
+const (
+	// Attempts made before a delivery fails.
+	maxAttempts = 8
+
+	// Delay before the first retry. It doubles with every attempt.
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 4 * time.Hour
+
+	// Time allowed for an endpoint to respond.
+	deliveryTimeout = 10 * time.Second
+
+	// Time a claimed delivery is reserved for the attempt, after which
+	// another worker may retry it, e.g. if the service was stopped.
+	claimTimeout = time.Minute
+
+	// Interval at which due retries are looked up.
+	deliveryPollInterval = 5 * time.Second
+
+	// Maximum number of concurrent requests.
+	deliveryConcurrency = 8
+
+	userAgent = "rdm-academy-webhooks"
+)
+
+var errPrivateAddress = errors.New("endpoint resolves to a private address")
+
+// Networks endpoints may not be on so webhooks cannot reach internal
+// services.
+var privateNetworks []*net.IPNet
+
+func init() {
+	for _, cidr := range []string{
+		"0.0.0.0/8",
+		"10.0.0.0/8",
+		"100.64.0.0/10",
+		"127.0.0.0/8",
+		"169.254.0.0/16",
+		"172.16.0.0/12",
+		"192.168.0.0/16",
+		"::/128",
+		"::1/128",
+		"fc00::/7",
+		"fe80::/10",
+	} {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		privateNetworks = append(privateNetworks, n)
+	}
+}
+
+func isPrivateIP(ip net.IP) bool {
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// payload is the body posted to the endpoints.
+type payload struct {
+	Delivery string        `json:"delivery"`
+	Webhook  string        `json:"webhook"`
+	Event    *payloadEvent `json:"event"`
+}
+
+type payloadEvent struct {
+	ID      string          `json:"id"`
+	Project string          `json:"project"`
+	Type    string          `json:"type"`
+	Time    int64           `json:"time"`
+	Author  string          `json:"author"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// sign returns the signature header of the body. The timestamp is signed
+// along with the body so receivers can reject replayed requests.
+func sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%s,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// backoff returns the delay before the next attempt.
+func backoff(attempts int32) time.Duration {
+	d := maxBackoff
+	if attempts < 20 {
+		if x := baseBackoff << uint(attempts-1); x < maxBackoff {
+			d = x
+		}
+	}
+
+	// Jitter spreads the retries to an endpoint that was down.
+	return d + time.Duration(rand.Int63n(int64(d)/5+1))
+}
+
+func newHTTPClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   deliveryTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	// The address is checked after the host name is resolved.
+	if !allowPrivate {
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+				return errPrivateAddress
+			}
+
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: deliveryTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: deliveryTimeout,
+			MaxIdleConnsPerHost: 2,
+		},
+		// Redirects are not followed since the signature is bound to the
+		// registered endpoint.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// newEventHandler queues deliveries of the project events and commits to
+// the matching webhooks.
+func newEventHandler(s *service) transport.Handler {
+	return func(msg *transport.Message) (proto.Message, error) {
+		var e commitlog.Event
+		if err := msg.Decode(&e); err != nil {
+			return nil, err
+		}
+
+		// The webhooks of the account no longer have an owner.
+		if e.Type == "account.deleted" && e.Author != "" {
+			if err := s.removeWebhooks(bson.M{"account": e.Author}); err != nil {
+				return nil, err
+			}
+			return nil, commitlog.Ack(s.cfg.Transport, "webhook", &e)
+		}
+
+		if e.Project == "" {
+			return nil, nil
+		}
+
+		// The project was purged.
+		if e.Type == "project.deleted" {
+			if err := s.removeWebhooks(bson.M{"project": e.Project}); err != nil {
+				return nil, err
+			}
+			return nil, commitlog.Ack(s.cfg.Transport, "webhook", &e)
+		}
+
+		return nil, s.deliverer.enqueue(&e)
+	}
+}
+
+// deliverer posts the queued deliveries. Deliveries are stored so they
+// survive restarts and are claimed before each attempt, so several
+// instances of the service can deliver concurrently.
+type deliverer struct {
+	s      *service
+	client *http.Client
+
+	// Signals new deliveries.
+	notify chan struct{}
+}
+
+func newDeliverer(s *service) *deliverer {
+	return &deliverer{
+		s:      s,
+		client: newHTTPClient(s.cfg.AllowInsecure),
+		notify: make(chan struct{}, 1),
+	}
+}
+
+func (d *deliverer) enqueue(e *commitlog.Event) error {
+	var ws []*webhook
+	err := d.s.db.C(webhooksCol).Find(bson.M{
+		"project": e.Project,
+		"active":  true,
+	}).All(&ws)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	queued := false
+
+	for _, w := range ws {
+		if !w.matches(e.Type) {
+			continue
+		}
+
+		id := uuid.NewV4().String()
+
+		body, err := json.Marshal(&payload{
+			Delivery: id,
+			Webhook:  w.ID,
+			Event: &payloadEvent{
+				ID:      e.Id,
+				Project: e.Project,
+				Type:    e.Type,
+				Time:    e.Time,
+				Author:  e.Author,
+				Data:    json.RawMessage(e.Data),
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = d.s.db.C(deliveriesCol).Insert(&delivery{
+			ID:          id,
+			Webhook:     w.ID,
+			Project:     e.Project,
+			Event:       e.Id,
+			Type:        e.Type,
+			Payload:     body,
+			State:       DeliveryState_PENDING,
+			Created:     now,
+			NextAttempt: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		queued = true
+	}
+
+	if queued {
+		select {
+		case d.notify <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+func (d *deliverer) run() {
+	ticker := time.NewTicker(deliveryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-d.notify:
+		}
+
+		d.deliverDue()
+	}
+}
+
+// deliverDue attempts the deliveries that are due.
+func (d *deliverer) deliverDue() {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, deliveryConcurrency)
+
+	for {
+		dl, err := d.claim()
+		if err != nil {
+			log.Printf("webhook: claim failed: %s", err)
+			break
+		}
+		if dl == nil {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := d.attempt(dl); err != nil {
+				log.Printf("webhook: delivery %s failed: %s", dl.ID, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// claim reserves the next due delivery, if any.
+func (d *deliverer) claim() (*delivery, error) {
+	now := time.Now()
+
+	var dl delivery
+	_, err := d.s.db.C(deliveriesCol).Find(bson.M{
+		"state": DeliveryState_PENDING,
+		"next_attempt": bson.M{
+			"$lte": now,
+		},
+	}).Sort("next_attempt").Apply(mgo.Change{
+		Update: bson.M{
+			"$set": bson.M{
+				"next_attempt": now.Add(claimTimeout),
+			},
+		},
+		ReturnNew: true,
+	}, &dl)
+
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dl, nil
+}
+
+// attempt posts the delivery and records the outcome.
+func (d *deliverer) attempt(dl *delivery) error {
+	var w webhook
+	err := d.s.db.C(webhooksCol).FindId(dl.Webhook).One(&w)
+	if err == mgo.ErrNotFound {
+		return d.s.db.C(deliveriesCol).RemoveId(dl.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	set := bson.M{}
+
+	if !w.Active {
+		set["state"] = DeliveryState_FAILED
+		set["error"] = "webhook is inactive"
+
+		return d.s.db.C(deliveriesCol).UpdateId(dl.ID, bson.M{"$set": set})
+	}
+
+	// The events are only sent on behalf of the owner of the project. If
+	// the account lost access, the webhook is deactivated until an owner
+	// reviews and enables it.
+	if err := d.checkOwner(&w); err != nil {
+		if !ownerLost(err) {
+			return err
+		}
+
+		if err := d.s.db.C(webhooksCol).UpdateId(w.ID, bson.M{"$set": bson.M{"active": false}}); err != nil {
+			return err
+		}
+
+		set["state"] = DeliveryState_FAILED
+		set["error"] = "webhook owner can no longer manage the project"
+
+		return d.s.db.C(deliveriesCol).UpdateId(dl.ID, bson.M{"$set": set})
+	}
+
+	code, err := d.post(&w, dl)
+
+	now := time.Now()
+	attempts := dl.Attempts + 1
+
+	set["attempts"] = attempts
+	set["status"] = int32(code)
+
+	switch {
+	case err == nil:
+		set["state"] = DeliveryState_DELIVERED
+		set["delivered"] = now
+		set["error"] = ""
+
+	case attempts >= maxAttempts:
+		set["state"] = DeliveryState_FAILED
+		set["error"] = err.Error()
+
+	default:
+		set["next_attempt"] = now.Add(backoff(attempts))
+		set["error"] = err.Error()
+	}
+
+	return d.s.db.C(deliveriesCol).UpdateId(dl.ID, bson.M{"$set": set})
+}
+
+// checkOwner checks the account of the webhook still owns the project.
+func (d *deliverer) checkOwner(w *webhook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+
+	return d.s.checkOwner(ctx, w.Account, w.Project)
+}
+
+// ownerLost returns true if the error is due to the account not owning
+// the project rather than a failure to check it.
+func ownerLost(err error) bool {
+	sts, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+
+	switch sts.Code() {
+	case codes.NotFound, codes.PermissionDenied, codes.InvalidArgument:
+		return true
+	}
+
+	return false
+}
+
+// post sends the payload and returns the status of the response, if any.
+// Any status other than 2xx is an error.
+func (d *deliverer) post(w *webhook, dl *delivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(dl.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Webhook-Id", w.ID)
+	req.Header.Set("X-Webhook-Delivery", dl.ID)
+	req.Header.Set("X-Webhook-Event", dl.Type)
+	req.Header.Set("X-Webhook-Signature", sign(w.Secret, time.Now(), dl.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
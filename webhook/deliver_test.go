@@ -0,0 +1,220 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/rdm-academy/api/internal/mongotest"
+	"github.com/rdm-academy/api/project"
+)
+
+// fakeProjects has the project p1 owned by u1.
+type fakeProjects struct {
+	project.ServiceClient
+}
+
+func (f *fakeProjects) GetProject(ctx context.Context, req *project.GetProjectRequest, opts ...transport.RequestOption) (*project.GetProjectResponse, error) {
+	if req.Id != "p1" || req.Account != "u1" {
+		return nil, status.Error(codes.NotFound, "project not found")
+	}
+
+	return &project.GetProjectResponse{
+		Project: &project.Project{Id: "p1", Account: "u1"},
+	}, nil
+}
+
+// testService returns a service on a scratch database and a function that
+// drops it.
+func testService(t *testing.T) (*service, func()) {
+	db, drop := mongotest.DB(t, "webhook")
+
+	s := &service{
+		db:         db,
+		cfg:        Config{AllowInsecure: true},
+		projectSvc: &fakeProjects{},
+	}
+	s.deliverer = newDeliverer(s)
+
+	return s, func() {
+		drop()
+	}
+}
+
+func TestSign(t *testing.T) {
+	sig := sign("whsec_test", time.Unix(1700000000, 0), []byte(`{"a":1}`))
+
+	if sig != "t=1700000000,v1=38877139021993b830af32feea6e18a8da83eb2f6e49ee50bd9e4cf4ca4d3789" {
+		t.Errorf("unexpected signature %s", sig)
+	}
+
+	// The timestamp is signed.
+	other := sign("whsec_test", time.Unix(1700000001, 0), []byte(`{"a":1}`))
+	if strings.SplitN(other, ",", 2)[1] == strings.SplitN(sig, ",", 2)[1] {
+		t.Error("expected the signature to depend on the timestamp")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for _, x := range []struct {
+		attempts int32
+		min      time.Duration
+	}{
+		{1, baseBackoff},
+		{2, 2 * baseBackoff},
+		{4, 8 * baseBackoff},
+		{10, maxBackoff},
+		{100, maxBackoff},
+	} {
+		for i := 0; i < 10; i++ {
+			d := backoff(x.attempts)
+			if d < x.min || d > x.min+x.min/5 {
+				t.Errorf("attempt %d: expected %s plus up to 20%% jitter, got %s", x.attempts, x.min, d)
+			}
+		}
+	}
+}
+
+func TestOwnerLost(t *testing.T) {
+	for _, x := range []struct {
+		err  error
+		lost bool
+	}{
+		{status.Error(codes.NotFound, "project not found"), true},
+		{status.Error(codes.PermissionDenied, "not the owner"), true},
+		{status.Error(codes.InvalidArgument, "account required"), true},
+		{status.Error(codes.Unavailable, "no responders"), false},
+		{context.DeadlineExceeded, false},
+		{nil, false},
+	} {
+		if lost := ownerLost(x.err); lost != x.lost {
+			t.Errorf("%v: expected %t, got %t", x.err, x.lost, lost)
+		}
+	}
+}
+
+func TestDialGuard(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// The test server listens on the loopback address.
+	if _, err := newHTTPClient(false).Get(srv.URL); err == nil || !strings.Contains(err.Error(), errPrivateAddress.Error()) {
+		t.Errorf("expected private address error, got %v", err)
+	}
+
+	resp, err := newHTTPClient(true).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", resp.StatusCode)
+	}
+}
+
+func TestClaim(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	now := time.Now()
+
+	for _, dl := range []*delivery{
+		{ID: "due", State: DeliveryState_PENDING, NextAttempt: now.Add(-time.Minute)},
+		{ID: "later", State: DeliveryState_PENDING, NextAttempt: now.Add(time.Hour)},
+		{ID: "done", State: DeliveryState_DELIVERED, NextAttempt: now.Add(-time.Hour)},
+	} {
+		if err := s.db.C(deliveriesCol).Insert(dl); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dl, err := s.deliverer.claim()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dl == nil || dl.ID != "due" {
+		t.Fatalf("expected the due delivery, got %v", dl)
+	}
+
+	// Claimed deliveries are reserved for the attempt.
+	if !dl.NextAttempt.After(now) {
+		t.Errorf("expected the next attempt to be reserved, got %s", dl.NextAttempt)
+	}
+
+	dl, err = s.deliverer.claim()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dl != nil {
+		t.Errorf("expected no due delivery, got %s", dl.ID)
+	}
+}
+
+func TestAttempt(t *testing.T) {
+	s, cleanup := testService(t)
+	defer cleanup()
+
+	var header http.Header
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	for _, w := range []*webhook{
+		{ID: "w1", Project: "p1", Account: "u1", URL: srv.URL, Secret: "whsec_test", Active: true},
+		// Created by an account that no longer owns the project.
+		{ID: "w2", Project: "p1", Account: "u2", URL: srv.URL, Secret: "whsec_test", Active: true},
+	} {
+		if err := s.db.C(webhooksCol).Insert(w); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, dl := range []*delivery{
+		{ID: "d1", Webhook: "w1", Type: "node.added", Payload: []byte(`{}`), State: DeliveryState_PENDING},
+		{ID: "d2", Webhook: "w2", Type: "node.added", Payload: []byte(`{}`), State: DeliveryState_PENDING},
+	} {
+		if err := s.db.C(deliveriesCol).Insert(dl); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.deliverer.attempt(dl); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var d1, d2 delivery
+	s.db.C(deliveriesCol).FindId("d1").One(&d1)
+	s.db.C(deliveriesCol).FindId("d2").One(&d2)
+
+	if d1.State != DeliveryState_DELIVERED || d1.Status != http.StatusNoContent {
+		t.Errorf("expected d1 to be delivered, got %s %d", d1.State, d1.Status)
+	}
+
+	if header.Get("X-Webhook-Delivery") != "d1" || !strings.HasPrefix(header.Get("X-Webhook-Signature"), "t=") {
+		t.Errorf("unexpected headers %v", header)
+	}
+
+	if d2.State != DeliveryState_FAILED {
+		t.Errorf("expected d2 to fail, got %s", d2.State)
+	}
+
+	var w2 webhook
+	s.db.C(webhooksCol).FindId("w2").One(&w2)
+
+	if w2.Active {
+		t.Error("expected w2 to be deactivated")
+	}
+}
@@ -0,0 +1,481 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/url"
+	"regexp"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/rdm-academy/api/commitlog"
+	"github.com/rdm-academy/api/project"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	webhooksCol   = "webhooks"
+	deliveriesCol = "deliveries"
+
+	// Prefix of the webhook secrets.
+	secretPrefix = "whsec_"
+
+	// Maximum number of webhooks per project.
+	maxWebhooks = 20
+
+	// Default and maximum number of deliveries listed.
+	defaultDeliveries = 50
+	maxDeliveries     = 500
+
+	// Time the delivery log is kept.
+	deliveryRetention = 30 * 24 * time.Hour
+)
+
+// Type matching all events.
+const allEvents = "*"
+
+// Event types are of the form <object>.<action>, e.g. node.added-files.
+var eventTypeRe = regexp.MustCompile(`^[a-z]+\.[a-z-]+$`)
+
+type Config struct {
+	DB        *mgo.Database
+	Transport transport.Transport
+
+	// Allow endpoints over plain HTTP and on private networks, e.g. for
+	// local development.
+	AllowInsecure bool
+}
+
+type webhook struct {
+	ID       string    `bson:"_id"`
+	Project  string    `bson:"project"`
+	Account  string    `bson:"account"`
+	URL      string    `bson:"url"`
+	Events   []string  `bson:"events"`
+	Secret   string    `bson:"secret"`
+	Active   bool      `bson:"active"`
+	Created  time.Time `bson:"created"`
+	Modified time.Time `bson:"modified"`
+}
+
+func (w *webhook) proto() *Webhook {
+	return &Webhook{
+		Id:       w.ID,
+		Project:  w.Project,
+		Account:  w.Account,
+		Url:      w.URL,
+		Events:   w.Events,
+		Active:   w.Active,
+		Created:  w.Created.Unix(),
+		Modified: w.Modified.Unix(),
+	}
+}
+
+// matches returns true if the webhook is subscribed to the event type.
+func (w *webhook) matches(typ string) bool {
+	for _, e := range w.Events {
+		if e == allEvents || e == typ {
+			return true
+		}
+	}
+	return false
+}
+
+type delivery struct {
+	ID      string `bson:"_id"`
+	Webhook string `bson:"webhook"`
+	Project string `bson:"project"`
+	Event   string `bson:"event"`
+	Type    string `bson:"type"`
+
+	// The request body.
+	Payload []byte `bson:"payload"`
+
+	State       DeliveryState `bson:"state"`
+	Attempts    int32         `bson:"attempts"`
+	Status      int32         `bson:"status"`
+	Error       string        `bson:"error"`
+	Created     time.Time     `bson:"created"`
+	NextAttempt time.Time     `bson:"next_attempt"`
+	Delivered   time.Time     `bson:"delivered"`
+}
+
+func (d *delivery) proto() *Delivery {
+	x := &Delivery{
+		Id:       d.ID,
+		Webhook:  d.Webhook,
+		Event:    d.Event,
+		Type:     d.Type,
+		State:    d.State,
+		Attempts: d.Attempts,
+		Status:   d.Status,
+		Error:    d.Error,
+		Created:  d.Created.Unix(),
+	}
+
+	if d.State == DeliveryState_PENDING {
+		x.NextAttempt = d.NextAttempt.Unix()
+	}
+
+	if !d.Delivered.IsZero() {
+		x.Delivered = d.Delivered.Unix()
+	}
+
+	return x
+}
+
+type service struct {
+	db  *mgo.Database
+	cfg Config
+
+	projectSvc project.ServiceClient
+
+	deliverer *deliverer
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+// checkOwner ensures the account owns the project. Webhooks send the
+// events of the project to a third party so only owners manage them.
+func (s *service) checkOwner(ctx context.Context, account, id string) error {
+	if account == "" {
+		return status.Error(codes.InvalidArgument, "account required")
+	}
+
+	if id == "" {
+		return status.Error(codes.InvalidArgument, "project required")
+	}
+
+	rep, err := s.projectSvc.GetProject(ctx, &project.GetProjectRequest{
+		Id:      id,
+		Account: account,
+	})
+	if err != nil {
+		return err
+	}
+
+	if rep.Project.Account != account {
+		return status.Error(codes.PermissionDenied, "only the owner can manage the webhooks of the project")
+	}
+
+	return nil
+}
+
+// validate checks the url and the event types of a webhook.
+func (s *service) validate(rawurl string, events []string) error {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Host == "" {
+		return status.Error(codes.InvalidArgument, "invalid url")
+	}
+
+	if u.Scheme != "https" && !(s.cfg.AllowInsecure && u.Scheme == "http") {
+		return status.Error(codes.InvalidArgument, "url must be https")
+	}
+
+	if u.User != nil {
+		return status.Error(codes.InvalidArgument, "url must not have credentials")
+	}
+
+	// Addresses of host names are checked when connecting.
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !s.cfg.AllowInsecure && isPrivateIP(ip) {
+		return status.Error(codes.InvalidArgument, "url must not be a private address")
+	}
+
+	if len(events) == 0 {
+		return status.Error(codes.InvalidArgument, "events required")
+	}
+
+	for _, e := range events {
+		if e != allEvents && !eventTypeRe.MatchString(e) {
+			return status.Errorf(codes.InvalidArgument, "invalid event type %q", e)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) find(project, id string) (*webhook, error) {
+	var w webhook
+
+	err := s.db.C(webhooksCol).Find(bson.M{
+		"_id":     id,
+		"project": project,
+	}).One(&w)
+
+	if err == mgo.ErrNotFound {
+		return nil, status.Error(codes.NotFound, "webhook not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (s *service) Create(ctx context.Context, req *CreateRequest) (*CreateReply, error) {
+	if err := s.checkOwner(ctx, req.Account, req.Project); err != nil {
+		return nil, err
+	}
+
+	if err := s.validate(req.Url, req.Events); err != nil {
+		return nil, err
+	}
+
+	n, err := s.db.C(webhooksCol).Find(bson.M{"project": req.Project}).Count()
+	if err != nil {
+		return nil, err
+	}
+	if n >= maxWebhooks {
+		return nil, status.Errorf(codes.FailedPrecondition, "projects may have up to %d webhooks", maxWebhooks)
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	w := webhook{
+		ID:       uuid.NewV4().String(),
+		Project:  req.Project,
+		Account:  req.Account,
+		URL:      req.Url,
+		Events:   req.Events,
+		Secret:   secret,
+		Active:   true,
+		Created:  now,
+		Modified: now,
+	}
+
+	if err := s.db.C(webhooksCol).Insert(&w); err != nil {
+		return nil, err
+	}
+
+	return &CreateReply{
+		Webhook: w.proto(),
+		Secret:  secret,
+	}, nil
+}
+
+func (s *service) List(ctx context.Context, req *ListRequest) (*ListReply, error) {
+	if err := s.checkOwner(ctx, req.Account, req.Project); err != nil {
+		return nil, err
+	}
+
+	var ws []*webhook
+	err := s.db.C(webhooksCol).
+		Find(bson.M{"project": req.Project}).
+		Sort("created").
+		All(&ws)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &ListReply{
+		Webhooks: make([]*Webhook, len(ws)),
+	}
+	for i, w := range ws {
+		rep.Webhooks[i] = w.proto()
+	}
+
+	return rep, nil
+}
+
+func (s *service) Get(ctx context.Context, req *GetRequest) (*GetReply, error) {
+	if err := s.checkOwner(ctx, req.Account, req.Project); err != nil {
+		return nil, err
+	}
+
+	w, err := s.find(req.Project, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetReply{
+		Webhook: w.proto(),
+	}, nil
+}
+
+func (s *service) Update(ctx context.Context, req *UpdateRequest) (*UpdateReply, error) {
+	if err := s.checkOwner(ctx, req.Account, req.Project); err != nil {
+		return nil, err
+	}
+
+	if err := s.validate(req.Url, req.Events); err != nil {
+		return nil, err
+	}
+
+	w, err := s.find(req.Project, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deliveries are sent on behalf of the owner updating the webhook.
+	w.Account = req.Account
+	w.URL = req.Url
+	w.Events = req.Events
+	w.Active = req.Active
+	w.Modified = time.Now()
+
+	var secret string
+	if req.RotateSecret {
+		secret, err = newSecret()
+		if err != nil {
+			return nil, err
+		}
+		w.Secret = secret
+	}
+
+	if err := s.db.C(webhooksCol).UpdateId(w.ID, w); err != nil {
+		return nil, err
+	}
+
+	return &UpdateReply{
+		Webhook: w.proto(),
+		Secret:  secret,
+	}, nil
+}
+
+func (s *service) Delete(ctx context.Context, req *DeleteRequest) (*DeleteReply, error) {
+	if err := s.checkOwner(ctx, req.Account, req.Project); err != nil {
+		return nil, err
+	}
+
+	w, err := s.find(req.Project, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.removeWebhooks(bson.M{"_id": w.ID}); err != nil {
+		return nil, err
+	}
+
+	return &DeleteReply{}, nil
+}
+
+func (s *service) Deliveries(ctx context.Context, req *DeliveriesRequest) (*DeliveriesReply, error) {
+	if err := s.checkOwner(ctx, req.Account, req.Project); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.find(req.Project, req.Webhook); err != nil {
+		return nil, err
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultDeliveries
+	} else if limit > maxDeliveries {
+		limit = maxDeliveries
+	}
+
+	var ds []*delivery
+	err := s.db.C(deliveriesCol).
+		Find(bson.M{"webhook": req.Webhook}).
+		Select(bson.M{"payload": 0}).
+		Sort("-created").
+		Limit(limit).
+		All(&ds)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &DeliveriesReply{
+		Deliveries: make([]*Delivery, len(ds)),
+	}
+	for i, d := range ds {
+		rep.Deliveries[i] = d.proto()
+	}
+
+	return rep, nil
+}
+
+// removeWebhooks removes the matching webhooks along with their deliveries.
+func (s *service) removeWebhooks(q bson.M) error {
+	var ws []*webhook
+	if err := s.db.C(webhooksCol).Find(q).Select(bson.M{"_id": 1}).All(&ws); err != nil {
+		return err
+	}
+
+	if len(ws) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(ws))
+	for i, w := range ws {
+		ids[i] = w.ID
+	}
+
+	if _, err := s.db.C(deliveriesCol).RemoveAll(bson.M{"webhook": bson.M{"$in": ids}}); err != nil {
+		return err
+	}
+
+	_, err := s.db.C(webhooksCol).RemoveAll(bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+func NewService(cfg Config) (Service, error) {
+	if cfg.DB == nil {
+		return nil, errors.New("db required")
+	}
+
+	if cfg.Transport == nil {
+		return nil, errors.New("transport required")
+	}
+
+	db := cfg.DB
+
+	for _, idx := range []mgo.Index{
+		{Key: []string{"project"}},
+		{Key: []string{"account"}},
+	} {
+		if err := db.C(webhooksCol).EnsureIndex(idx); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, idx := range []mgo.Index{
+		{Key: []string{"state", "next_attempt"}},
+		{Key: []string{"webhook", "-created"}},
+		{Key: []string{"created"}, ExpireAfter: deliveryRetention},
+	} {
+		if err := db.C(deliveriesCol).EnsureIndex(idx); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &service{
+		db:         db,
+		cfg:        cfg,
+		projectSvc: project.NewServiceClient(cfg.Transport),
+	}
+
+	s.deliverer = newDeliverer(s)
+
+	// These will be auto-unsubscribed when the transport is closed.
+	for _, subj := range []string{eventSubject, commitlog.CommitSubject} {
+		if _, err := cfg.Transport.Subscribe(subj, newEventHandler(s)); err != nil {
+			return nil, err
+		}
+	}
+
+	go s.deliverer.run()
+
+	return s, nil
+}
@@ -0,0 +1,653 @@
+// Code generated by protoc-gen-go.
+// source: service.proto
+// DO NOT EDIT!
+
+/*
+Package webhook is a generated protocol buffer package.
+
+It is generated from these files:
+	service.proto
+
+It has these top-level messages:
+	Webhook
+	Delivery
+	CreateRequest
+	CreateReply
+	ListRequest
+	ListReply
+	GetRequest
+	GetReply
+	UpdateRequest
+	UpdateReply
+	DeleteRequest
+	DeleteReply
+	DeliveriesRequest
+	DeliveriesReply
+*/
+package webhook
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type DeliveryState int32
+
+const (
+	DeliveryState_PENDING   DeliveryState = 0
+	DeliveryState_DELIVERED DeliveryState = 1
+	DeliveryState_FAILED    DeliveryState = 2
+)
+
+var DeliveryState_name = map[int32]string{
+	0: "PENDING",
+	1: "DELIVERED",
+	2: "FAILED",
+}
+var DeliveryState_value = map[string]int32{
+	"PENDING":   0,
+	"DELIVERED": 1,
+	"FAILED":    2,
+}
+
+func (x DeliveryState) String() string {
+	return proto.EnumName(DeliveryState_name, int32(x))
+}
+func (DeliveryState) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type Webhook struct {
+	Id      string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Project string `protobuf:"bytes,2,opt,name=project" json:"project,omitempty"`
+	// Account that registered the webhook.
+	Account string `protobuf:"bytes,3,opt,name=account" json:"account,omitempty"`
+	// HTTPS endpoint the events are posted to.
+	Url string `protobuf:"bytes,4,opt,name=url" json:"url,omitempty"`
+	// Event types delivered, e.g. node.added-files or commit.created. The
+	// type * matches all events.
+	Events []string `protobuf:"bytes,5,rep,name=events" json:"events,omitempty"`
+	// Inactive webhooks are not delivered to.
+	Active   bool  `protobuf:"varint,6,opt,name=active" json:"active,omitempty"`
+	Created  int64 `protobuf:"varint,7,opt,name=created" json:"created,omitempty"`
+	Modified int64 `protobuf:"varint,8,opt,name=modified" json:"modified,omitempty"`
+}
+
+func (m *Webhook) Reset()                    { *m = Webhook{} }
+func (m *Webhook) String() string            { return proto.CompactTextString(m) }
+func (*Webhook) ProtoMessage()               {}
+func (*Webhook) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *Webhook) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Webhook) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *Webhook) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *Webhook) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Webhook) GetEvents() []string {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *Webhook) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+func (m *Webhook) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *Webhook) GetModified() int64 {
+	if m != nil {
+		return m.Modified
+	}
+	return 0
+}
+
+type Delivery struct {
+	Id      string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Webhook string `protobuf:"bytes,2,opt,name=webhook" json:"webhook,omitempty"`
+	// ID and type of the event or commit delivered.
+	Event string        `protobuf:"bytes,3,opt,name=event" json:"event,omitempty"`
+	Type  string        `protobuf:"bytes,4,opt,name=type" json:"type,omitempty"`
+	State DeliveryState `protobuf:"varint,5,opt,name=state,enum=webhook.DeliveryState" json:"state,omitempty"`
+	// Number of attempts made so far.
+	Attempts int32 `protobuf:"varint,6,opt,name=attempts" json:"attempts,omitempty"`
+	// HTTP status and error of the last attempt.
+	Status  int32  `protobuf:"varint,7,opt,name=status" json:"status,omitempty"`
+	Error   string `protobuf:"bytes,8,opt,name=error" json:"error,omitempty"`
+	Created int64  `protobuf:"varint,9,opt,name=created" json:"created,omitempty"`
+	// Time of the next attempt, if pending.
+	NextAttempt int64 `protobuf:"varint,10,opt,name=next_attempt,json=nextAttempt" json:"next_attempt,omitempty"`
+	// Time the event was delivered.
+	Delivered int64 `protobuf:"varint,11,opt,name=delivered" json:"delivered,omitempty"`
+}
+
+func (m *Delivery) Reset()                    { *m = Delivery{} }
+func (m *Delivery) String() string            { return proto.CompactTextString(m) }
+func (*Delivery) ProtoMessage()               {}
+func (*Delivery) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Delivery) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Delivery) GetWebhook() string {
+	if m != nil {
+		return m.Webhook
+	}
+	return ""
+}
+
+func (m *Delivery) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+func (m *Delivery) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Delivery) GetState() DeliveryState {
+	if m != nil {
+		return m.State
+	}
+	return DeliveryState_PENDING
+}
+
+func (m *Delivery) GetAttempts() int32 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *Delivery) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *Delivery) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *Delivery) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *Delivery) GetNextAttempt() int64 {
+	if m != nil {
+		return m.NextAttempt
+	}
+	return 0
+}
+
+func (m *Delivery) GetDelivered() int64 {
+	if m != nil {
+		return m.Delivered
+	}
+	return 0
+}
+
+type CreateRequest struct {
+	Account string   `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	Project string   `protobuf:"bytes,2,opt,name=project" json:"project,omitempty"`
+	Url     string   `protobuf:"bytes,3,opt,name=url" json:"url,omitempty"`
+	Events  []string `protobuf:"bytes,4,rep,name=events" json:"events,omitempty"`
+}
+
+func (m *CreateRequest) Reset()                    { *m = CreateRequest{} }
+func (m *CreateRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateRequest) ProtoMessage()               {}
+func (*CreateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *CreateRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *CreateRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *CreateRequest) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *CreateRequest) GetEvents() []string {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+type CreateReply struct {
+	Webhook *Webhook `protobuf:"bytes,1,opt,name=webhook" json:"webhook,omitempty"`
+	// Secret of the HMAC signatures. It is only returned when the webhook
+	// is created or the secret is rotated.
+	Secret string `protobuf:"bytes,2,opt,name=secret" json:"secret,omitempty"`
+}
+
+func (m *CreateReply) Reset()                    { *m = CreateReply{} }
+func (m *CreateReply) String() string            { return proto.CompactTextString(m) }
+func (*CreateReply) ProtoMessage()               {}
+func (*CreateReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *CreateReply) GetWebhook() *Webhook {
+	if m != nil {
+		return m.Webhook
+	}
+	return nil
+}
+
+func (m *CreateReply) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+type ListRequest struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	Project string `protobuf:"bytes,2,opt,name=project" json:"project,omitempty"`
+}
+
+func (m *ListRequest) Reset()                    { *m = ListRequest{} }
+func (m *ListRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListRequest) ProtoMessage()               {}
+func (*ListRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *ListRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *ListRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+type ListReply struct {
+	Webhooks []*Webhook `protobuf:"bytes,1,rep,name=webhooks" json:"webhooks,omitempty"`
+}
+
+func (m *ListReply) Reset()                    { *m = ListReply{} }
+func (m *ListReply) String() string            { return proto.CompactTextString(m) }
+func (*ListReply) ProtoMessage()               {}
+func (*ListReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *ListReply) GetWebhooks() []*Webhook {
+	if m != nil {
+		return m.Webhooks
+	}
+	return nil
+}
+
+type GetRequest struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	Project string `protobuf:"bytes,2,opt,name=project" json:"project,omitempty"`
+	Id      string `protobuf:"bytes,3,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *GetRequest) Reset()                    { *m = GetRequest{} }
+func (m *GetRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetRequest) ProtoMessage()               {}
+func (*GetRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *GetRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *GetRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *GetRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type GetReply struct {
+	Webhook *Webhook `protobuf:"bytes,1,opt,name=webhook" json:"webhook,omitempty"`
+}
+
+func (m *GetReply) Reset()                    { *m = GetReply{} }
+func (m *GetReply) String() string            { return proto.CompactTextString(m) }
+func (*GetReply) ProtoMessage()               {}
+func (*GetReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *GetReply) GetWebhook() *Webhook {
+	if m != nil {
+		return m.Webhook
+	}
+	return nil
+}
+
+// UpdateRequest replaces the url, events and state of the webhook.
+type UpdateRequest struct {
+	Account string   `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	Project string   `protobuf:"bytes,2,opt,name=project" json:"project,omitempty"`
+	Id      string   `protobuf:"bytes,3,opt,name=id" json:"id,omitempty"`
+	Url     string   `protobuf:"bytes,4,opt,name=url" json:"url,omitempty"`
+	Events  []string `protobuf:"bytes,5,rep,name=events" json:"events,omitempty"`
+	Active  bool     `protobuf:"varint,6,opt,name=active" json:"active,omitempty"`
+	// Generate a new secret.
+	RotateSecret bool `protobuf:"varint,7,opt,name=rotate_secret,json=rotateSecret" json:"rotate_secret,omitempty"`
+}
+
+func (m *UpdateRequest) Reset()                    { *m = UpdateRequest{} }
+func (m *UpdateRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateRequest) ProtoMessage()               {}
+func (*UpdateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *UpdateRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *UpdateRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *UpdateRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *UpdateRequest) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *UpdateRequest) GetEvents() []string {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *UpdateRequest) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+func (m *UpdateRequest) GetRotateSecret() bool {
+	if m != nil {
+		return m.RotateSecret
+	}
+	return false
+}
+
+type UpdateReply struct {
+	Webhook *Webhook `protobuf:"bytes,1,opt,name=webhook" json:"webhook,omitempty"`
+	// The new secret, if rotated.
+	Secret string `protobuf:"bytes,2,opt,name=secret" json:"secret,omitempty"`
+}
+
+func (m *UpdateReply) Reset()                    { *m = UpdateReply{} }
+func (m *UpdateReply) String() string            { return proto.CompactTextString(m) }
+func (*UpdateReply) ProtoMessage()               {}
+func (*UpdateReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *UpdateReply) GetWebhook() *Webhook {
+	if m != nil {
+		return m.Webhook
+	}
+	return nil
+}
+
+func (m *UpdateReply) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+type DeleteRequest struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	Project string `protobuf:"bytes,2,opt,name=project" json:"project,omitempty"`
+	Id      string `protobuf:"bytes,3,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *DeleteRequest) Reset()                    { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()               {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *DeleteRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type DeleteReply struct {
+}
+
+func (m *DeleteReply) Reset()                    { *m = DeleteReply{} }
+func (m *DeleteReply) String() string            { return proto.CompactTextString(m) }
+func (*DeleteReply) ProtoMessage()               {}
+func (*DeleteReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+type DeliveriesRequest struct {
+	Account string `protobuf:"bytes,1,opt,name=account" json:"account,omitempty"`
+	Project string `protobuf:"bytes,2,opt,name=project" json:"project,omitempty"`
+	Webhook string `protobuf:"bytes,3,opt,name=webhook" json:"webhook,omitempty"`
+	// Maximum number of deliveries, latest first.
+	Limit int32 `protobuf:"varint,4,opt,name=limit" json:"limit,omitempty"`
+}
+
+func (m *DeliveriesRequest) Reset()                    { *m = DeliveriesRequest{} }
+func (m *DeliveriesRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeliveriesRequest) ProtoMessage()               {}
+func (*DeliveriesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *DeliveriesRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *DeliveriesRequest) GetProject() string {
+	if m != nil {
+		return m.Project
+	}
+	return ""
+}
+
+func (m *DeliveriesRequest) GetWebhook() string {
+	if m != nil {
+		return m.Webhook
+	}
+	return ""
+}
+
+func (m *DeliveriesRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type DeliveriesReply struct {
+	Deliveries []*Delivery `protobuf:"bytes,1,rep,name=deliveries" json:"deliveries,omitempty"`
+}
+
+func (m *DeliveriesReply) Reset()                    { *m = DeliveriesReply{} }
+func (m *DeliveriesReply) String() string            { return proto.CompactTextString(m) }
+func (*DeliveriesReply) ProtoMessage()               {}
+func (*DeliveriesReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *DeliveriesReply) GetDeliveries() []*Delivery {
+	if m != nil {
+		return m.Deliveries
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Webhook)(nil), "webhook.Webhook")
+	proto.RegisterType((*Delivery)(nil), "webhook.Delivery")
+	proto.RegisterType((*CreateRequest)(nil), "webhook.CreateRequest")
+	proto.RegisterType((*CreateReply)(nil), "webhook.CreateReply")
+	proto.RegisterType((*ListRequest)(nil), "webhook.ListRequest")
+	proto.RegisterType((*ListReply)(nil), "webhook.ListReply")
+	proto.RegisterType((*GetRequest)(nil), "webhook.GetRequest")
+	proto.RegisterType((*GetReply)(nil), "webhook.GetReply")
+	proto.RegisterType((*UpdateRequest)(nil), "webhook.UpdateRequest")
+	proto.RegisterType((*UpdateReply)(nil), "webhook.UpdateReply")
+	proto.RegisterType((*DeleteRequest)(nil), "webhook.DeleteRequest")
+	proto.RegisterType((*DeleteReply)(nil), "webhook.DeleteReply")
+	proto.RegisterType((*DeliveriesRequest)(nil), "webhook.DeliveriesRequest")
+	proto.RegisterType((*DeliveriesReply)(nil), "webhook.DeliveriesReply")
+	proto.RegisterEnum("webhook.DeliveryState", DeliveryState_name, DeliveryState_value)
+}
+
+func init() { proto.RegisterFile("service.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 678 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x55, 0xcf, 0x6e, 0xd3, 0x4e,
+	0x10, 0xfe, 0x39, 0x8e, 0x63, 0x67, 0x5c, 0xf7, 0x97, 0x2e, 0x55, 0xb5, 0xb2, 0x38, 0x04, 0x73,
+	0x89, 0xaa, 0x52, 0x41, 0x91, 0x8a, 0x38, 0x16, 0x1c, 0xa2, 0x4a, 0x55, 0xd5, 0x3a, 0x02, 0x8e,
+	0x55, 0x6a, 0x0f, 0xc2, 0xe0, 0xd4, 0xc6, 0xde, 0xa4, 0xe4, 0xb1, 0xb8, 0x72, 0xe7, 0x15, 0x78,
+	0x1e, 0xb4, 0x7f, 0x1c, 0xdb, 0x4d, 0x38, 0xa0, 0xe4, 0xe6, 0x6f, 0x66, 0x77, 0xe7, 0x9b, 0xf9,
+	0x66, 0xc6, 0xe0, 0x14, 0x98, 0xcf, 0xe3, 0x10, 0x8f, 0xb3, 0x3c, 0x65, 0x29, 0x31, 0xef, 0xf1,
+	0xf6, 0x73, 0x9a, 0x7e, 0xf5, 0x7e, 0x69, 0x60, 0x7e, 0x94, 0xdf, 0x64, 0x17, 0x5a, 0x71, 0x44,
+	0xb5, 0xbe, 0x36, 0xe8, 0x06, 0xad, 0x38, 0x22, 0x14, 0xcc, 0x2c, 0x4f, 0xbf, 0x60, 0xc8, 0x68,
+	0x4b, 0x18, 0x4b, 0xc8, 0x3d, 0x93, 0x30, 0x4c, 0x67, 0x77, 0x8c, 0xea, 0xd2, 0xa3, 0x20, 0xe9,
+	0x81, 0x3e, 0xcb, 0x13, 0xda, 0x16, 0x56, 0xfe, 0x49, 0x0e, 0xa0, 0x83, 0x73, 0xbc, 0x63, 0x05,
+	0x35, 0xfa, 0xfa, 0xa0, 0x1b, 0x28, 0xc4, 0xed, 0x93, 0x90, 0xc5, 0x73, 0xa4, 0x9d, 0xbe, 0x36,
+	0xb0, 0x02, 0x85, 0xf8, 0xdb, 0x61, 0x8e, 0x13, 0x86, 0x11, 0x35, 0xfb, 0xda, 0x40, 0x0f, 0x4a,
+	0x48, 0x5c, 0xb0, 0xa6, 0x69, 0x14, 0x7f, 0x8a, 0x31, 0xa2, 0x96, 0x70, 0x2d, 0xb1, 0xf7, 0xa3,
+	0x05, 0x96, 0x8f, 0x49, 0x3c, 0xc7, 0x7c, 0xb1, 0x2e, 0x11, 0x95, 0x6f, 0x99, 0x88, 0x82, 0x64,
+	0x1f, 0x0c, 0x41, 0x47, 0xa5, 0x21, 0x01, 0x21, 0xd0, 0x66, 0x8b, 0x0c, 0x55, 0x16, 0xe2, 0x9b,
+	0x1c, 0x81, 0x51, 0xb0, 0x09, 0x43, 0x6a, 0xf4, 0xb5, 0xc1, 0xee, 0xc9, 0xc1, 0xb1, 0x7a, 0xe2,
+	0xb8, 0x8c, 0x3a, 0xe6, 0xde, 0x40, 0x1e, 0xe2, 0x54, 0x27, 0x8c, 0xe1, 0x34, 0x63, 0x85, 0x48,
+	0xcf, 0x08, 0x96, 0x98, 0x27, 0xce, 0x0f, 0xcd, 0x0a, 0x91, 0x9f, 0x11, 0x28, 0x24, 0xb8, 0xe4,
+	0x79, 0x9a, 0x53, 0x4b, 0x71, 0xe1, 0xa0, 0x5e, 0x8e, 0x6e, 0xb3, 0x1c, 0x4f, 0x60, 0xe7, 0x0e,
+	0xbf, 0xb3, 0x1b, 0xf5, 0x30, 0x05, 0xe1, 0xb6, 0xb9, 0xed, 0x4c, 0x9a, 0xc8, 0x63, 0xe8, 0x46,
+	0x92, 0x1e, 0x46, 0xd4, 0x16, 0xfe, 0xca, 0xe0, 0x4d, 0xc1, 0x79, 0x2b, 0xde, 0x0a, 0xf0, 0xdb,
+	0x0c, 0x8b, 0x86, 0xac, 0x5a, 0x53, 0xd6, 0xbf, 0xb7, 0x82, 0x12, 0x5c, 0x5f, 0x27, 0x78, 0xbb,
+	0x2e, 0xb8, 0x77, 0x0d, 0x76, 0x19, 0x2e, 0x4b, 0x16, 0xe4, 0xb0, 0x12, 0x85, 0x07, 0xb3, 0x4f,
+	0x7a, 0xcb, 0x92, 0xaa, 0x86, 0xac, 0x64, 0xe2, 0x25, 0xc3, 0x30, 0xc7, 0x32, 0xba, 0x42, 0xde,
+	0x19, 0xd8, 0x17, 0x71, 0xc1, 0x36, 0xe0, 0xef, 0xbd, 0x86, 0xae, 0x7c, 0x82, 0x73, 0x3a, 0x02,
+	0x4b, 0x85, 0x2c, 0xa8, 0xd6, 0xd7, 0xd7, 0x92, 0x5a, 0x9e, 0xf0, 0xae, 0x00, 0x46, 0xb8, 0x49,
+	0x70, 0xd5, 0xa8, 0x7a, 0xd9, 0xa8, 0xde, 0x29, 0x58, 0x23, 0x54, 0x5c, 0xfe, 0xa1, 0x3e, 0xde,
+	0x4f, 0x0d, 0x9c, 0xf7, 0x59, 0xb4, 0xa1, 0x94, 0x0f, 0xd8, 0x6c, 0x61, 0x96, 0x9f, 0x82, 0x93,
+	0xa7, 0x7c, 0x20, 0x6e, 0x94, 0x7c, 0xa6, 0x70, 0xef, 0x48, 0xe3, 0x58, 0x8a, 0x78, 0x0d, 0x76,
+	0xc9, 0x7d, 0x5b, 0x7d, 0x31, 0x06, 0xc7, 0xc7, 0x04, 0xb7, 0x5a, 0x0e, 0xcf, 0x01, 0xbb, 0x7c,
+	0x34, 0x4b, 0x16, 0xde, 0x3d, 0xec, 0xa9, 0xd1, 0x8f, 0xb1, 0xd8, 0x24, 0x4e, 0x6d, 0x3b, 0xe9,
+	0x2b, 0xdb, 0x29, 0x89, 0xa7, 0x31, 0x13, 0x12, 0x18, 0x81, 0x04, 0x9e, 0x0f, 0xff, 0xd7, 0x03,
+	0xf3, 0x9a, 0xbd, 0x00, 0x88, 0x96, 0x26, 0xd5, 0xb9, 0x7b, 0x2b, 0x1b, 0x2a, 0xa8, 0x1d, 0x3a,
+	0x7c, 0x25, 0x4a, 0x54, 0x6d, 0x2e, 0x62, 0x83, 0x79, 0x35, 0xbc, 0xf4, 0xcf, 0x2f, 0x47, 0xbd,
+	0xff, 0x88, 0x03, 0x5d, 0x7f, 0x78, 0x71, 0xfe, 0x61, 0x18, 0x0c, 0xfd, 0x9e, 0x46, 0x00, 0x3a,
+	0xef, 0xce, 0xce, 0x2f, 0x86, 0x7e, 0xaf, 0x75, 0xf2, 0xbb, 0x05, 0xe6, 0x58, 0xfe, 0x4c, 0xc8,
+	0x29, 0x74, 0xe4, 0x48, 0x93, 0x6a, 0x1f, 0x36, 0x56, 0x8a, 0xbb, 0xbf, 0x62, 0xe7, 0x7c, 0x9f,
+	0x43, 0x9b, 0x0f, 0x1d, 0xa9, 0xbc, 0xb5, 0x31, 0x76, 0xc9, 0x03, 0x2b, 0xbf, 0xf1, 0x0c, 0xf4,
+	0x11, 0x32, 0xf2, 0x68, 0xe9, 0xaa, 0x26, 0xcf, 0xdd, 0x6b, 0x1a, 0xf9, 0xf1, 0x53, 0xe8, 0xc8,
+	0x9e, 0xaa, 0x11, 0x6b, 0x0c, 0x88, 0xbb, 0xbf, 0x62, 0x57, 0xf7, 0xa4, 0xc6, 0xa4, 0xb1, 0xe0,
+	0x71, 0xdd, 0xbd, 0x5a, 0x33, 0x90, 0x37, 0x00, 0x95, 0x26, 0xc4, 0x7d, 0x58, 0xfa, 0xaa, 0x43,
+	0x5c, 0xba, 0xd6, 0x97, 0x25, 0x8b, 0xdb, 0x8e, 0xf8, 0x35, 0xbf, 0xfc, 0x33, 0x00, 0xb3, 0x28,
+	0xcd, 0x8f, 0xab, 0x07, 0x00, 0x00,
+}
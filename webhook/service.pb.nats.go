@@ -0,0 +1,216 @@
+package webhook
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/chop-dbhi/nats-rpc/transport"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	traceIdKey = struct{}{}
+)
+
+type Service interface {
+	Create(context.Context, *CreateRequest) (*CreateReply, error)
+	List(context.Context, *ListRequest) (*ListReply, error)
+	Get(context.Context, *GetRequest) (*GetReply, error)
+	Update(context.Context, *UpdateRequest) (*UpdateReply, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteReply, error)
+	Deliveries(context.Context, *DeliveriesRequest) (*DeliveriesReply, error)
+}
+
+type ServiceClient interface {
+	Create(context.Context, *CreateRequest, ...transport.RequestOption) (*CreateReply, error)
+	List(context.Context, *ListRequest, ...transport.RequestOption) (*ListReply, error)
+	Get(context.Context, *GetRequest, ...transport.RequestOption) (*GetReply, error)
+	Update(context.Context, *UpdateRequest, ...transport.RequestOption) (*UpdateReply, error)
+	Delete(context.Context, *DeleteRequest, ...transport.RequestOption) (*DeleteReply, error)
+	Deliveries(context.Context, *DeliveriesRequest, ...transport.RequestOption) (*DeliveriesReply, error)
+}
+
+// serviceClient an implementation of Service client.
+type serviceClient struct {
+	tp transport.Transport
+}
+
+func (c *serviceClient) Create(ctx context.Context, req *CreateRequest, opts ...transport.RequestOption) (*CreateReply, error) {
+	var rep CreateReply
+
+	_, err := c.tp.Request("webhook.Create", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) List(ctx context.Context, req *ListRequest, opts ...transport.RequestOption) (*ListReply, error) {
+	var rep ListReply
+
+	_, err := c.tp.Request("webhook.List", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) Get(ctx context.Context, req *GetRequest, opts ...transport.RequestOption) (*GetReply, error) {
+	var rep GetReply
+
+	_, err := c.tp.Request("webhook.Get", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) Update(ctx context.Context, req *UpdateRequest, opts ...transport.RequestOption) (*UpdateReply, error) {
+	var rep UpdateReply
+
+	_, err := c.tp.Request("webhook.Update", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) Delete(ctx context.Context, req *DeleteRequest, opts ...transport.RequestOption) (*DeleteReply, error) {
+	var rep DeleteReply
+
+	_, err := c.tp.Request("webhook.Delete", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+func (c *serviceClient) Deliveries(ctx context.Context, req *DeliveriesRequest, opts ...transport.RequestOption) (*DeliveriesReply, error) {
+	var rep DeliveriesReply
+
+	_, err := c.tp.Request("webhook.Deliveries", req, &rep, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rep, nil
+}
+
+// NewServiceClient creates a new Service client.
+func NewServiceClient(tp transport.Transport) ServiceClient {
+	return &serviceClient{tp}
+}
+
+type ServiceServer struct {
+	tp  transport.Transport
+	svc Service
+}
+
+func NewServiceServer(tp transport.Transport, svc Service) *ServiceServer {
+	return &ServiceServer{
+		tp:  tp,
+		svc: svc,
+	}
+}
+
+func (s *ServiceServer) Serve(ctx context.Context, opts ...transport.SubscribeOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+	}()
+
+	var err error
+
+	_, err = s.tp.Subscribe("webhook.Create", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req CreateRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Create(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("webhook.List", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req ListRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.List(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("webhook.Get", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req GetRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Get(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("webhook.Update", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req UpdateRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Update(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("webhook.Delete", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req DeleteRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Delete(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = s.tp.Subscribe("webhook.Deliveries", func(msg *transport.Message) (proto.Message, error) {
+		ctx := context.WithValue(ctx, traceIdKey, msg.Id)
+
+		var req DeliveriesRequest
+		if err := msg.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return s.svc.Deliveries(ctx, &req)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	sigchan := make(chan os.Signal)
+	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
+
+	<-sigchan
+
+	return nil
+}